@@ -0,0 +1,42 @@
+// Command cardvault adds test cards to the local development card vault and
+// prints the token to pass as payment_method_token.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+
+	"github.com/hsibAD/payment-service/internal/config"
+	"github.com/hsibAD/payment-service/internal/domain"
+	"github.com/hsibAD/payment-service/internal/infrastructure/vault"
+)
+
+func main() {
+	var card domain.CreditCardInfo
+	flag.StringVar(&card.CardNumber, "number", "", "card number")
+	flag.StringVar(&card.ExpiryMonth, "exp-month", "", "expiry month, 1-12")
+	flag.StringVar(&card.ExpiryYear, "exp-year", "", "four digit expiry year")
+	flag.StringVar(&card.CVV, "cvv", "", "card verification value")
+	flag.StringVar(&card.CardholderName, "name", "", "cardholder name")
+	flag.Parse()
+
+	cfg := config.Load()
+	if cfg.CardVaultPath == "" {
+		log.Fatal("CARD_VAULT_PATH is not set")
+	}
+
+	v, err := vault.NewLocalVaultFromHexKey(cfg.CardVaultPath, cfg.CardVaultKey)
+	if err != nil {
+		log.Fatalf("Failed to open card vault: %v", err)
+	}
+
+	token, err := v.Tokenize(context.Background(), &card)
+	if err != nil {
+		log.Fatalf("Failed to tokenize card: %v", err)
+	}
+
+	log.Printf("Stored %s", card.Summary())
+	fmt.Println(token)
+}
@@ -1,23 +1,372 @@
-package main
-
-import (
-	"log"
-
-	"github.com/hsibAD/payment-service/internal/config"
-	"github.com/hsibAD/payment-service/internal/server"
-)
-
-func main() {
-	// Load configuration
-	cfg := config.Load()
-
-	// Create and start server
-	srv, err := server.NewServer(cfg)
-	if err != nil {
-		log.Fatalf("Failed to create server: %v", err)
-	}
-
-	if err := srv.Run(); err != nil {
-		log.Fatalf("Failed to run server: %v", err)
-	}
-} 
\ No newline at end of file
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"log"
+	"time"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/hsibAD/payment-service/internal/config"
+	"github.com/hsibAD/payment-service/internal/domain"
+	"github.com/hsibAD/payment-service/internal/handler"
+	"github.com/hsibAD/payment-service/internal/infrastructure/auth"
+	"github.com/hsibAD/payment-service/internal/infrastructure/bin"
+	"github.com/hsibAD/payment-service/internal/infrastructure/blockchain"
+	"github.com/hsibAD/payment-service/internal/infrastructure/cache"
+	"github.com/hsibAD/payment-service/internal/infrastructure/email"
+	"github.com/hsibAD/payment-service/internal/infrastructure/events"
+	"github.com/hsibAD/payment-service/internal/infrastructure/payment"
+	"github.com/hsibAD/payment-service/internal/infrastructure/pricing"
+	"github.com/hsibAD/payment-service/internal/infrastructure/risk"
+	"github.com/hsibAD/payment-service/internal/infrastructure/vault"
+	"github.com/hsibAD/payment-service/internal/infrastructure/velocity"
+	"github.com/hsibAD/payment-service/internal/jobs"
+	"github.com/hsibAD/payment-service/internal/repository/mongodb"
+	"github.com/hsibAD/payment-service/internal/server"
+	"github.com/hsibAD/payment-service/internal/usecase"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func main() {
+	// Load configuration
+	cfg := config.Load()
+
+	// Connect to MongoDB
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	mongoClient, err := mongo.Connect(ctx, options.Client().ApplyURI(cfg.MongoURI))
+	if err != nil {
+		log.Fatalf("Failed to connect to MongoDB: %v", err)
+	}
+	defer mongoClient.Disconnect(context.Background())
+
+	paymentRepo := mongodb.NewPaymentRepository(mongoClient.Database(cfg.MongoDB))
+	refundRepo := mongodb.NewRefundRepository(mongoClient.Database(cfg.MongoDB))
+	chainRepo := mongodb.NewChainWatcherRepository(mongoClient.Database(cfg.MongoDB))
+	walletRepo := mongodb.NewWalletRepository(mongoClient.Database(cfg.MongoDB))
+	eventRepo := mongodb.NewProcessedEventRepository(mongoClient.Database(cfg.MongoDB))
+	riskRepo := mongodb.NewRiskDecisionRepository(mongoClient.Database(cfg.MongoDB))
+
+	migrated, err := paymentRepo.MigrateLegacyAmounts(ctx)
+	if err != nil {
+		log.Fatalf("Failed to migrate legacy payment amounts: %v", err)
+	}
+	if migrated > 0 {
+		log.Printf("Migrated %d payments to minor-unit amounts", migrated)
+	}
+	migrated, err = paymentRepo.MigrateOrderHashes(ctx)
+	if err != nil {
+		log.Fatalf("Failed to migrate payment order hashes: %v", err)
+	}
+	if migrated > 0 {
+		log.Printf("Stored order hashes on %d crypto payments", migrated)
+	}
+
+	if err := paymentRepo.EnsureIndexes(ctx); err != nil {
+		log.Fatalf("Failed to create payment indexes: %v", err)
+	}
+	if err := walletRepo.EnsureIndexes(ctx); err != nil {
+		log.Fatalf("Failed to create wallet indexes: %v", err)
+	}
+	if err := riskRepo.EnsureIndexes(ctx); err != nil {
+		log.Fatalf("Failed to create risk decision indexes: %v", err)
+	}
+
+	// Infrastructure
+	redisCache := cache.NewRedisCache(cfg.RedisURL, cfg.RedisPassword, cfg.RedisDB)
+
+	publisher, err := events.NewNATSPublisher(cfg.NatsURL)
+	if err != nil {
+		log.Fatalf("Failed to connect to NATS: %v", err)
+	}
+	defer publisher.Close()
+
+	var cardVault domain.CardVault
+	if cfg.CardVaultPath != "" {
+		localVault, err := vault.NewLocalVaultFromHexKey(cfg.CardVaultPath, cfg.CardVaultKey)
+		if err != nil {
+			log.Fatalf("Failed to open card vault: %v", err)
+		}
+		log.Printf("Using local card vault at %s; do not enable this in production", cfg.CardVaultPath)
+		cardVault = localVault
+	}
+
+	cardProviders := []payment.CardProvider{{
+		Name:    cfg.PaymentGateway,
+		Gateway: cfg.PaymentGateway,
+	}}
+	if cfg.CardProvidersPath != "" {
+		cardProviders, err = payment.LoadCardProviders(cfg.CardProvidersPath)
+		if err != nil {
+			log.Fatalf("Failed to load card providers: %v", err)
+		}
+	}
+
+	cardProcessors := make(map[string]domain.CreditCardProcessor, len(cardProviders))
+	for _, provider := range cardProviders {
+		var gateway domain.PaymentGateway
+		switch provider.Gateway {
+		case "stripe":
+			secretKey := cfg.StripeSecretKey
+			if provider.SecretKeyEnv != "" {
+				secretKey = provider.SecretKey()
+			}
+			gateway = payment.NewStripeGateway(secretKey, cardVault, cfg.StripeReturnURL)
+		case "fake":
+			log.Printf("Card provider %s uses the fake payment gateway; cards are never charged", provider.Name)
+			gateway = payment.NewFakeGateway(cardVault)
+		default:
+			log.Fatalf("Unknown payment gateway %q", provider.Gateway)
+		}
+		cardProcessors[provider.Name] = payment.NewCreditCardProcessor(gateway)
+	}
+
+	var bins domain.BINLookup
+	if cfg.BINTablePath != "" {
+		binTable, err := bin.LoadTable(cfg.BINTablePath)
+		if err != nil {
+			log.Fatalf("Failed to load BIN table: %v", err)
+		}
+		bins = binTable
+	}
+
+	screening := payment.CardScreening{
+		Vault: cardVault,
+		BINs:  bins,
+		Policy: domain.CardPolicy{
+			BlockPrepaid:  cfg.BlockPrepaidCards,
+			BlockForeign:  cfg.BlockForeignCards,
+			HomeCountries: cfg.HomeCountries,
+		},
+		FingerprintKey: []byte(cfg.CardFingerprintKey),
+	}
+
+	cardProcessor, err := payment.NewCardRouter(cardProviders, cardProcessors, screening, payment.BreakerConfig{
+		FailureThreshold: cfg.CardBreakerThreshold,
+		Cooldown:         time.Duration(cfg.CardBreakerCooldown) * time.Second,
+	})
+	if err != nil {
+		log.Fatalf("Failed to create card router: %v", err)
+	}
+
+	riskControls := usecase.RiskControls{
+		Velocity: velocity.NewLimiter(velocity.Config{
+			UserAttempts:  velocityLimit(cfg.VelocityUserAttempts, cfg.VelocityUserAttemptsWindow),
+			UserCards:     velocityLimit(cfg.VelocityUserCards, cfg.VelocityUserCardsWindow),
+			IPDeclines:    velocityLimit(cfg.VelocityIPDeclines, cfg.VelocityIPDeclinesWindow),
+			CardDeclines:  velocityLimit(cfg.VelocityCardDeclines, cfg.VelocityCardDeclinesWindow),
+			Lockout:       time.Duration(cfg.VelocityLockout) * time.Second,
+			MaxLockout:    time.Duration(cfg.VelocityMaxLockout) * time.Second,
+			LockoutMemory: time.Duration(cfg.VelocityLockoutMemory) * time.Second,
+		}, redisCache, redisCache),
+		Cards:     cardProcessor,
+		Decisions: riskRepo,
+	}
+	if cfg.RiskRulesPath != "" {
+		riskRules, err := risk.LoadRules(cfg.RiskRulesPath)
+		if err != nil {
+			log.Fatalf("Failed to load risk rules: %v", err)
+		}
+		riskControls.Engine = risk.NewEngine(riskRules, redisCache)
+	}
+
+	var refundKey *ecdsa.PrivateKey
+	if cfg.RefundKeystorePath != "" {
+		refundKey, err = blockchain.LoadRefundKey(cfg.RefundKeystorePath, cfg.RefundKeystorePassphrase)
+		if err != nil {
+			log.Fatalf("Failed to load refund wallet: %v", err)
+		}
+	}
+
+	tokens := blockchain.DefaultTokenRegistry()
+	if cfg.TokenRegistryPath != "" {
+		tokens, err = blockchain.LoadTokenRegistry(cfg.TokenRegistryPath)
+		if err != nil {
+			log.Fatalf("Failed to load token registry: %v", err)
+		}
+	}
+
+	chains := []blockchain.Chain{{
+		ID:               uint64(cfg.ChainID),
+		Name:             "ethereum",
+		RPCURL:           cfg.EthereumRPC,
+		ContractAddress:  cfg.PaymentContractAddress,
+		MinConfirmations: uint64(cfg.MinConfirmations),
+		NativeCurrency:   "ETH",
+		Default:          true,
+	}}
+	if cfg.ChainsPath != "" {
+		chains, err = blockchain.LoadChains(cfg.ChainsPath)
+		if err != nil {
+			log.Fatalf("Failed to load chains: %v", err)
+		}
+	}
+
+	ethClients := make(map[uint64]*ethclient.Client, len(chains))
+	chainProcessors := make(map[uint64]*blockchain.MetaMaskProcessor, len(chains))
+	var defaultChain uint64
+	for _, chain := range chains {
+		ethClient, err := ethclient.Dial(chain.RPCURL)
+		if err != nil {
+			log.Fatalf("Failed to connect to %s node: %v", chain.Name, err)
+		}
+		defer ethClient.Close()
+
+		processor, err := blockchain.NewMetaMaskProcessorWithBackend(
+			ethClient,
+			chain,
+			blockchain.PaymentContractABI,
+			tokens,
+			refundKey,
+		)
+		if err != nil {
+			log.Fatalf("Failed to create MetaMask processor for %s: %v", chain.Name, err)
+		}
+
+		ethClients[chain.ID] = ethClient
+		chainProcessors[chain.ID] = processor
+		if chain.Default {
+			defaultChain = chain.ID
+		}
+	}
+
+	metaMaskProcessor, err := blockchain.NewMultiChainProcessor(chainProcessors, defaultChain)
+	if err != nil {
+		log.Fatalf("Failed to create MetaMask processor: %v", err)
+	}
+
+	var priceOracle domain.PriceOracle
+	switch cfg.PriceOracle {
+	case "static":
+		priceOracle, err = pricing.LoadStaticOracle(cfg.PriceFilePath)
+		if err != nil {
+			log.Fatalf("Failed to load prices: %v", err)
+		}
+	case "http":
+		priceOracle = pricing.NewHTTPOracle(cfg.PriceFeedURL, 5*time.Second, 30*time.Second)
+	default:
+		log.Fatalf("Unknown price oracle %q", cfg.PriceOracle)
+	}
+
+	notifier := email.NewSMTPNotifier(email.SMTPConfig{
+		Host:     cfg.SMTPHost,
+		Port:     cfg.SMTPPort,
+		Username: cfg.SMTPUsername,
+		Password: cfg.SMTPPassword,
+		From:     cfg.SMTPFrom,
+	})
+
+	paymentService := usecase.NewPaymentService(
+		paymentRepo,
+		refundRepo,
+		cardProcessor,
+		metaMaskProcessor,
+		redisCache,
+		publisher,
+		notifier,
+		priceOracle,
+		usecase.QuotePolicy{
+			TTL:         time.Duration(cfg.QuoteTTL) * time.Second,
+			SlippageBps: cfg.QuoteSlippageBps,
+		},
+		usecase.WalletProof{
+			Challenges:    redisCache,
+			Wallets:       walletRepo,
+			Verifier:      blockchain.NewOwnershipVerifier(cfg.WalletProofDomain),
+			ChallengeTTL:  time.Duration(cfg.WalletChallengeTTL) * time.Second,
+			Required:      cfg.RequireWalletProof,
+			Issued:        redisCache,
+			MaxChallenges: cfg.WalletChallengeLimit,
+		},
+		eventRepo,
+		riskControls,
+	)
+
+	// Background jobs
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+
+	go jobs.NewAuthorizationExpiryJob(
+		paymentService,
+		time.Duration(cfg.AuthorizationExpiryInterval)*time.Second,
+		time.Duration(cfg.AuthorizationHoldTTL)*time.Second,
+	).Run(jobsCtx)
+
+	go jobs.NewRefundConfirmationJob(
+		paymentService,
+		time.Duration(cfg.RefundConfirmationInterval)*time.Second,
+	).Run(jobsCtx)
+
+	for _, chain := range chains {
+		if cfg.ChainWatcherInterval <= 0 || chain.ContractAddress == "" {
+			continue
+		}
+
+		watcher, err := blockchain.NewPaymentWatcher(
+			ethClients[chain.ID],
+			chain,
+			blockchain.PaymentContractABI,
+			tokens,
+			paymentService,
+			chainRepo,
+			blockchain.WatcherConfig{
+				PollInterval:  time.Duration(cfg.ChainWatcherInterval) * time.Second,
+				StartBlock:    uint64(cfg.ChainWatcherStartBlock),
+				MaxBlockRange: uint64(cfg.ChainWatcherMaxBlockRange),
+			},
+		)
+		if err != nil {
+			log.Fatalf("Failed to create chain watcher for %s: %v", chain.Name, err)
+		}
+		go watcher.Run(jobsCtx)
+	}
+
+	paymentHandler := handler.NewPaymentHandler(paymentService, redisCache, handler.IdempotencyConfig{
+		LockTTL:   cfg.IdempotencyLockTTL,
+		Retention: cfg.IdempotencyRetention,
+	})
+
+	var webhookHandler *handler.StripeWebhookHandler
+	if cfg.StripeWebhookSecret != "" {
+		webhookHandler = handler.NewStripeWebhookHandler(payment.NewStripeWebhook(cfg.StripeWebhookSecret, 0), paymentService)
+	}
+
+	var verifier domain.TokenVerifier
+	if cfg.AuthDisabled {
+		log.Printf("Authentication is disabled; anyone can call any RPC")
+	} else {
+		authCfg := auth.Config{
+			Secret:     []byte(cfg.JWTSecret),
+			Issuer:     cfg.JWTIssuer,
+			Audience:   cfg.JWTAudience,
+			RolesClaim: cfg.JWTRolesClaim,
+		}
+		if cfg.JWTJWKSPath != "" {
+			authCfg.RSAKeys, err = auth.LoadJWKS(cfg.JWTJWKSPath)
+			if err != nil {
+				log.Fatalf("Failed to load JWKS: %v", err)
+			}
+		}
+		verifier, err = auth.NewJWTVerifier(authCfg)
+		if err != nil {
+			log.Fatalf("Failed to create token verifier: %v", err)
+		}
+	}
+
+	// Create and start server
+	srv, err := server.NewServer(cfg, paymentHandler, webhookHandler, verifier)
+	if err != nil {
+		log.Fatalf("Failed to create server: %v", err)
+	}
+
+	if err := srv.Run(); err != nil {
+		log.Fatalf("Failed to run server: %v", err)
+	}
+}
+
+func velocityLimit(limit, windowSeconds int) velocity.Limit {
+	return velocity.Limit{Max: limit, Window: time.Duration(windowSeconds) * time.Second}
+}
@@ -1,54 +1,278 @@
-package config
-
-import (
-	"os"
-	"strconv"
-)
-
-type Config struct {
-	Port            string
-	RedisURL        string
-	RedisPassword   string
-	RedisDB         int
-	MongoURI        string
-	MongoDB         string
-	NatsURL         string
-	JWTSecret       string
-	RateLimit       int
-	RateLimitBurst  int
-	StripeSecretKey string
-	EthereumRPC     string
-}
-
-func Load() *Config {
-	return &Config{
-		Port:            getEnv("PORT", "50052"),
-		RedisURL:        getEnv("REDIS_URL", "redis:6379"),
-		RedisPassword:   getEnv("REDIS_PASSWORD", ""),
-		RedisDB:         getEnvAsInt("REDIS_DB", 0),
-		MongoURI:        getEnv("MONGO_URI", "mongodb://mongodb:27017"),
-		MongoDB:         getEnv("MONGO_DB", "payments"),
-		NatsURL:         getEnv("NATS_URL", "nats://nats:4222"),
-		JWTSecret:       getEnv("JWT_SECRET", "your-secret-key"),
-		RateLimit:       getEnvAsInt("RATE_LIMIT", 60),
-		RateLimitBurst:  getEnvAsInt("RATE_LIMIT_BURST", 10),
-		StripeSecretKey: getEnv("STRIPE_SECRET_KEY", ""),
-		EthereumRPC:     getEnv("ETHEREUM_RPC", "https://mainnet.infura.io/v3/your-project-id"),
-	}
-}
-
-func getEnv(key, defaultValue string) string {
-	if value, exists := os.LookupEnv(key); exists {
-		return value
-	}
-	return defaultValue
-}
-
-func getEnvAsInt(key string, defaultValue int) int {
-	if value, exists := os.LookupEnv(key); exists {
-		if intValue, err := strconv.Atoi(value); err == nil {
-			return intValue
-		}
-	}
-	return defaultValue
-} 
\ No newline at end of file
+package config
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+type Config struct {
+	Port            string
+	RedisURL        string
+	RedisPassword   string
+	RedisDB         int
+	MongoURI        string
+	MongoDB         string
+	NatsURL         string
+	JWTSecret       string
+	RateLimit       int
+	RateLimitBurst  int
+	StripeSecretKey string
+	PaymentGateway  string
+	EthereumRPC     string
+
+	PaymentContractAddress string
+	MinConfirmations       int
+	ChainID                int
+	ChainsPath             string
+	TokenRegistryPath      string
+
+	PriceOracle      string
+	PriceFilePath    string
+	PriceFeedURL     string
+	QuoteTTL         int
+	QuoteSlippageBps int
+
+	WalletChallengeTTL   int
+	RequireWalletProof   bool
+	WalletProofDomain    string
+	WalletChallengeLimit int
+
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	SMTPFrom     string
+
+	IdempotencyLockTTL   int
+	IdempotencyRetention int
+
+	AuthorizationHoldTTL        int
+	AuthorizationExpiryInterval int
+
+	CardVaultPath string
+	CardVaultKey  string
+
+	BINTablePath      string
+	BlockPrepaidCards bool
+	BlockForeignCards bool
+	HomeCountries     []string
+
+	CardProvidersPath    string
+	CardBreakerThreshold int
+	CardBreakerCooldown  int
+
+	RiskRulesPath      string
+	CardFingerprintKey string
+	TrustForwardedFor  bool
+
+	VelocityUserAttempts       int
+	VelocityUserAttemptsWindow int
+	VelocityUserCards          int
+	VelocityUserCardsWindow    int
+	VelocityIPDeclines         int
+	VelocityIPDeclinesWindow   int
+	VelocityCardDeclines       int
+	VelocityCardDeclinesWindow int
+	VelocityLockout            int
+	VelocityMaxLockout         int
+	VelocityLockoutMemory      int
+
+	WebhookPort         string
+	StripeWebhookSecret string
+	StripeReturnURL     string
+
+	RefundKeystorePath         string
+	RefundKeystorePassphrase   string
+	RefundConfirmationInterval int
+
+	ChainWatcherInterval      int
+	ChainWatcherStartBlock    int
+	ChainWatcherMaxBlockRange int
+
+	AuthDisabled  bool
+	JWTJWKSPath   string
+	JWTIssuer     string
+	JWTAudience   string
+	JWTRolesClaim string
+}
+
+func Load() *Config {
+	return &Config{
+		Port:            getEnv("PORT", "50052"),
+		RedisURL:        getEnv("REDIS_URL", "redis:6379"),
+		RedisPassword:   getEnv("REDIS_PASSWORD", ""),
+		RedisDB:         getEnvAsInt("REDIS_DB", 0),
+		MongoURI:        getEnv("MONGO_URI", "mongodb://mongodb:27017"),
+		MongoDB:         getEnv("MONGO_DB", "payments"),
+		NatsURL:         getEnv("NATS_URL", "nats://nats:4222"),
+		JWTSecret:       getEnv("JWT_SECRET", ""),
+		RateLimit:       getEnvAsInt("RATE_LIMIT", 60),
+		RateLimitBurst:  getEnvAsInt("RATE_LIMIT_BURST", 10),
+		StripeSecretKey: getEnv("STRIPE_SECRET_KEY", ""),
+		// Card payments go through "stripe", or through "fake", an
+		// in-memory gateway that never charges a card.
+		PaymentGateway: getEnv("PAYMENT_GATEWAY", "stripe"),
+		EthereumRPC:    getEnv("ETHEREUM_RPC", "https://mainnet.infura.io/v3/your-project-id"),
+
+		PaymentContractAddress: getEnv("PAYMENT_CONTRACT_ADDRESS", ""),
+		MinConfirmations:       getEnvAsInt("MIN_CONFIRMATIONS", 12),
+		ChainID:                getEnvAsInt("CHAIN_ID", 1),
+		// JSON list of chains to accept payments on; when set it replaces
+		// the single chain above. See chains.example.json.
+		ChainsPath: getEnv("CHAINS_PATH", ""),
+		// JSON list of accepted ERC-20 tokens; the built-in stablecoins are
+		// used when unset.
+		TokenRegistryPath: getEnv("TOKEN_REGISTRY_PATH", ""),
+
+		// Fiat payments paid in crypto are quoted from the oracle: "http"
+		// reads PRICE_FEED_URL, "static" reads rates from PRICE_FILE.
+		PriceOracle:      getEnv("PRICE_ORACLE", "http"),
+		PriceFilePath:    getEnv("PRICE_FILE", ""),
+		PriceFeedURL:     getEnv("PRICE_FEED_URL", "https://api.coinbase.com/v2/prices/{base}-{quote}/spot"),
+		QuoteTTL:         getEnvAsInt("QUOTE_TTL", 900),
+		QuoteSlippageBps: getEnvAsInt("QUOTE_SLIPPAGE_BPS", 100),
+
+		// Customers prove they control a wallet by signing a challenge;
+		// with REQUIRE_WALLET_PROOF crypto payments are only initiated from
+		// verified wallets. The domain name is shown in the wallet prompt.
+		// A payment gets at most WALLET_CHALLENGE_LIMIT challenges an hour.
+		WalletChallengeTTL:   getEnvAsInt("WALLET_CHALLENGE_TTL", 300),
+		RequireWalletProof:   getEnvAsBool("REQUIRE_WALLET_PROOF", false),
+		WalletProofDomain:    getEnv("WALLET_PROOF_DOMAIN", "PaymentService"),
+		WalletChallengeLimit: getEnvAsInt("WALLET_CHALLENGE_LIMIT", 10),
+
+		SMTPHost:     getEnv("SMTP_HOST", "smtp.gmail.com"),
+		SMTPPort:     getEnvAsInt("SMTP_PORT", 587),
+		SMTPUsername: getEnv("SMTP_USERNAME", ""),
+		SMTPPassword: getEnv("SMTP_PASSWORD", ""),
+		SMTPFrom:     getEnv("SMTP_FROM", "payments@example.com"),
+
+		IdempotencyLockTTL:   getEnvAsInt("IDEMPOTENCY_LOCK_TTL", 60),
+		IdempotencyRetention: getEnvAsInt("IDEMPOTENCY_RETENTION", 86400),
+
+		// Card networks drop uncaptured holds after about seven days.
+		AuthorizationHoldTTL:        getEnvAsInt("AUTHORIZATION_HOLD_TTL", 6*24*3600),
+		AuthorizationExpiryInterval: getEnvAsInt("AUTHORIZATION_EXPIRY_INTERVAL", 3600),
+
+		// The local card vault is for development only and stays disabled
+		// unless a path is set. The key is hex encoded AES-256.
+		CardVaultPath: getEnv("CARD_VAULT_PATH", ""),
+		CardVaultKey:  getEnv("CARD_VAULT_KEY", ""),
+
+		// CSV table of BIN ranges giving the brand, funding type and
+		// issuing country of vault cards. Prepaid cards, and cards issued
+		// outside HOME_COUNTRIES, can be refused.
+		BINTablePath:      getEnv("BIN_TABLE_PATH", ""),
+		BlockPrepaidCards: getEnvAsBool("BLOCK_PREPAID_CARDS", false),
+		BlockForeignCards: getEnvAsBool("BLOCK_FOREIGN_CARDS", false),
+		HomeCountries:     getEnvAsList("HOME_COUNTRIES", []string{"US"}),
+
+		// JSON list of card providers in order of preference; when unset
+		// PAYMENT_GATEWAY is the only provider. A provider that fails
+		// CARD_BREAKER_THRESHOLD times in a row without an answer from the
+		// issuer is skipped for CARD_BREAKER_COOLDOWN seconds.
+		CardProvidersPath:    getEnv("CARD_PROVIDERS_PATH", ""),
+		CardBreakerThreshold: getEnvAsInt("CARD_BREAKER_THRESHOLD", 5),
+		CardBreakerCooldown:  getEnvAsInt("CARD_BREAKER_COOLDOWN", 30),
+
+		// YAML risk rules card payments are scored against before they
+		// are charged; risk checks are off while unset. Cards are counted
+		// by an HMAC of their number keyed with CARD_FINGERPRINT_KEY.
+		// Client IPs are taken from X-Forwarded-For only behind a proxy
+		// that sets it.
+		RiskRulesPath:      getEnv("RISK_RULES_PATH", ""),
+		CardFingerprintKey: getEnv("CARD_FINGERPRINT_KEY", ""),
+		TrustForwardedFor:  getEnvAsBool("TRUST_FORWARDED_FOR", false),
+
+		// Card testing protection: card payment attempts per user, distinct
+		// cards per user, and declines per client IP and per card, each
+		// within a window in seconds. A zero limit is off. Tripping a limit
+		// locks the user, IP or card out for VELOCITY_LOCKOUT seconds,
+		// doubling with every further lockout up to VELOCITY_MAX_LOCKOUT,
+		// until VELOCITY_LOCKOUT_MEMORY seconds pass without one.
+		VelocityUserAttempts:       getEnvAsInt("VELOCITY_USER_ATTEMPTS", 10),
+		VelocityUserAttemptsWindow: getEnvAsInt("VELOCITY_USER_ATTEMPTS_WINDOW", 3600),
+		VelocityUserCards:          getEnvAsInt("VELOCITY_USER_CARDS", 3),
+		VelocityUserCardsWindow:    getEnvAsInt("VELOCITY_USER_CARDS_WINDOW", 86400),
+		VelocityIPDeclines:         getEnvAsInt("VELOCITY_IP_DECLINES", 10),
+		VelocityIPDeclinesWindow:   getEnvAsInt("VELOCITY_IP_DECLINES_WINDOW", 3600),
+		VelocityCardDeclines:       getEnvAsInt("VELOCITY_CARD_DECLINES", 3),
+		VelocityCardDeclinesWindow: getEnvAsInt("VELOCITY_CARD_DECLINES_WINDOW", 86400),
+		VelocityLockout:            getEnvAsInt("VELOCITY_LOCKOUT", 60),
+		VelocityMaxLockout:         getEnvAsInt("VELOCITY_MAX_LOCKOUT", 86400),
+		VelocityLockoutMemory:      getEnvAsInt("VELOCITY_LOCKOUT_MEMORY", 86400),
+
+		// Stripe webhooks are received over HTTP on WEBHOOK_PORT at
+		// /webhooks/stripe; the endpoint is off until a signing secret is
+		// set.
+		WebhookPort:         getEnv("WEBHOOK_PORT", "8080"),
+		StripeWebhookSecret: getEnv("STRIPE_WEBHOOK_SECRET", ""),
+		// Where the bank sends customers back to after 3-D Secure; the
+		// client then calls ConfirmCreditCardPayment.
+		StripeReturnURL: getEnv("STRIPE_RETURN_URL", ""),
+
+		// Hot wallet for on-chain refunds; refunds of crypto payments are
+		// rejected when no keystore is configured.
+		RefundKeystorePath:         getEnv("REFUND_KEYSTORE_PATH", ""),
+		RefundKeystorePassphrase:   getEnv("REFUND_KEYSTORE_PASSPHRASE", ""),
+		RefundConfirmationInterval: getEnvAsInt("REFUND_CONFIRMATION_INTERVAL", 60),
+
+		// The chain watcher completes crypto payments from contract events;
+		// an interval of 0 disables it. A start block of 0 starts at the
+		// current head on first run.
+		ChainWatcherInterval:      getEnvAsInt("CHAIN_WATCHER_INTERVAL", 15),
+		ChainWatcherStartBlock:    getEnvAsInt("CHAIN_WATCHER_START_BLOCK", 0),
+		ChainWatcherMaxBlockRange: getEnvAsInt("CHAIN_WATCHER_MAX_BLOCK_RANGE", 1000),
+
+		// Every RPC needs a JWT, HS256 signed with JWT_SECRET or RS256
+		// signed with a key from the JWKS file. The user ID is the sub
+		// claim and roles are read from JWT_ROLES_CLAIM. AUTH_DISABLED is
+		// for local development only.
+		AuthDisabled:  getEnvAsBool("AUTH_DISABLED", false),
+		JWTJWKSPath:   getEnv("JWT_JWKS_PATH", ""),
+		JWTIssuer:     getEnv("JWT_ISSUER", ""),
+		JWTAudience:   getEnv("JWT_AUDIENCE", ""),
+		JWTRolesClaim: getEnv("JWT_ROLES_CLAIM", "roles"),
+	}
+}
+
+func getEnv(key, defaultValue string) string {
+	if value, exists := os.LookupEnv(key); exists {
+		return value
+	}
+	return defaultValue
+}
+
+func getEnvAsInt(key string, defaultValue int) int {
+	if value, exists := os.LookupEnv(key); exists {
+		if intValue, err := strconv.Atoi(value); err == nil {
+			return intValue
+		}
+	}
+	return defaultValue
+}
+
+// getEnvAsList reads a comma separated list.
+func getEnvAsList(key string, defaultValue []string) []string {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return defaultValue
+	}
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+func getEnvAsBool(key string, defaultValue bool) bool {
+	if value, exists := os.LookupEnv(key); exists {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+	}
+	return defaultValue
+}
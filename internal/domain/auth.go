@@ -0,0 +1,72 @@
+package domain
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	ErrUnauthenticated  = errors.New("missing or invalid access token")
+	ErrPermissionDenied = errors.New("permission denied")
+)
+
+// Role is what a caller is allowed to do. Callers without a role are
+// customers.
+type Role string
+
+const (
+	RoleCustomer Role = "customer"
+	// RoleService is held by other services, e.g. order and fulfilment,
+	// acting on payments of any user.
+	RoleService Role = "service"
+	RoleAdmin   Role = "admin"
+)
+
+// Principal is the authenticated caller of a request.
+type Principal struct {
+	UserID string
+	Roles  []Role
+}
+
+// HasRole reports whether the principal holds any of roles.
+func (p *Principal) HasRole(roles ...Role) bool {
+	for _, have := range p.Roles {
+		for _, want := range roles {
+			if have == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// IsCustomer reports whether the principal may only act on its own
+// payments.
+func (p *Principal) IsCustomer() bool {
+	return !p.HasRole(RoleService, RoleAdmin)
+}
+
+// CanAccess reports whether the principal may see payments of userID.
+func (p *Principal) CanAccess(userID string) bool {
+	return !p.IsCustomer() || p.UserID == userID
+}
+
+// TokenVerifier checks an access token and returns the caller it was
+// issued to. Invalid tokens are rejected with ErrUnauthenticated.
+type TokenVerifier interface {
+	Verify(ctx context.Context, token string) (*Principal, error)
+}
+
+type principalContextKey struct{}
+
+// WithPrincipal attaches the authenticated caller to the context.
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalContextKey{}, principal)
+}
+
+// PrincipalFromContext returns the caller attached to the context, or nil
+// for calls that were not authenticated, such as the service's own jobs.
+func PrincipalFromContext(ctx context.Context) *Principal {
+	principal, _ := ctx.Value(principalContextKey{}).(*Principal)
+	return principal
+}
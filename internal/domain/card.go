@@ -0,0 +1,305 @@
+package domain
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidCardToken  = errors.New("invalid card token")
+	ErrCardTokenNotFound = errors.New("card token not found")
+
+	ErrInvalidCardNumber  = errors.New("invalid card number")
+	ErrInvalidExpiryMonth = errors.New("invalid expiry month")
+	ErrInvalidExpiryYear  = errors.New("invalid expiry year")
+	ErrInvalidCVV         = errors.New("invalid CVV")
+	ErrCardExpired        = errors.New("card has expired")
+
+	// ErrCardNotAccepted is returned for cards the card policy blocks.
+	ErrCardNotAccepted = errors.New("card not accepted")
+)
+
+type CardBrand string
+
+const (
+	CardBrandUnknown    CardBrand = "unknown"
+	CardBrandVisa       CardBrand = "visa"
+	CardBrandMastercard CardBrand = "mastercard"
+	CardBrandAmex       CardBrand = "amex"
+	CardBrandDiscover   CardBrand = "discover"
+	CardBrandJCB        CardBrand = "jcb"
+	CardBrandDiners     CardBrand = "diners"
+	CardBrandUnionPay   CardBrand = "unionpay"
+)
+
+// CardFunding is how a card is funded.
+type CardFunding string
+
+const (
+	CardFundingUnknown CardFunding = "unknown"
+	CardFundingCredit  CardFunding = "credit"
+	CardFundingDebit   CardFunding = "debit"
+	CardFundingPrepaid CardFunding = "prepaid"
+)
+
+// cardRule is the PAN lengths and CVV length of a card network.
+type cardRule struct {
+	lengths   []int
+	cvvLength int
+}
+
+var cardRules = map[CardBrand]cardRule{
+	CardBrandVisa:       {lengths: []int{13, 16, 19}, cvvLength: 3},
+	CardBrandMastercard: {lengths: []int{16}, cvvLength: 3},
+	CardBrandAmex:       {lengths: []int{15}, cvvLength: 4},
+	CardBrandDiscover:   {lengths: []int{16, 17, 18, 19}, cvvLength: 3},
+	CardBrandJCB:        {lengths: []int{16, 17, 18, 19}, cvvLength: 3},
+	CardBrandDiners:     {lengths: []int{14, 15, 16, 17, 18, 19}, cvvLength: 3},
+	CardBrandUnionPay:   {lengths: []int{16, 17, 18, 19}, cvvLength: 3},
+}
+
+func (r cardRule) allowsLength(n int) bool {
+	for _, length := range r.lengths {
+		if n == length {
+			return true
+		}
+	}
+	return false
+}
+
+// CreditCardInfo is raw card data. It only ever exists inside a CardVault
+// implementation and must never be logged, published or stored in clear.
+type CreditCardInfo struct {
+	CardNumber     string
+	ExpiryMonth    string
+	ExpiryYear     string
+	CVV            string
+	CardholderName string
+}
+
+// CardSummary is the masked view of a card that is safe to log, publish and
+// store. Funding and Country, an ISO 3166 alpha-2 code, are set when the
+// card's BIN is known.
+type CardSummary struct {
+	Brand   CardBrand
+	Last4   string
+	Funding CardFunding
+	Country string
+}
+
+// BINInfo is what the issuer identification number, the leading digits of
+// a card number, says about the card.
+type BINInfo struct {
+	Brand   CardBrand
+	Funding CardFunding
+	Country string
+}
+
+// BINLookup finds the BIN range a card number falls into.
+type BINLookup interface {
+	Lookup(number string) (BINInfo, bool)
+}
+
+// CardPolicy says which cards are accepted. Cards whose funding or country
+// is unknown are accepted.
+type CardPolicy struct {
+	BlockPrepaid bool
+	// BlockForeign rejects cards issued outside HomeCountries.
+	BlockForeign  bool
+	HomeCountries []string
+}
+
+// Check returns ErrCardNotAccepted if the policy blocks the card.
+func (p CardPolicy) Check(card CardSummary) error {
+	if p.BlockPrepaid && card.Funding == CardFundingPrepaid {
+		return fmt.Errorf("%w: prepaid cards are not accepted", ErrCardNotAccepted)
+	}
+	if p.BlockForeign && card.Country != "" && !containsCountry(p.HomeCountries, card.Country) {
+		return fmt.Errorf("%w: cards issued in %s are not accepted", ErrCardNotAccepted, card.Country)
+	}
+	return nil
+}
+
+func containsCountry(countries []string, country string) bool {
+	for _, c := range countries {
+		if strings.EqualFold(c, country) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c CardSummary) String() string {
+	return string(c.Brand) + " ****" + c.Last4
+}
+
+// CardVault keeps card data out of the service. Callers exchange raw card data
+// for an opaque token once and pass the token around afterwards.
+type CardVault interface {
+	Tokenize(ctx context.Context, card *CreditCardInfo) (string, error)
+	Detokenize(ctx context.Context, token string) (*CreditCardInfo, error)
+	Delete(ctx context.Context, token string) error
+}
+
+// Summary returns the masked view of the card.
+func (c *CreditCardInfo) Summary() CardSummary {
+	return c.Describe(nil)
+}
+
+// Describe returns the masked view of the card with what bins knows about
+// it. Without a BIN range the brand is inferred from the card number.
+func (c *CreditCardInfo) Describe(bins BINLookup) CardSummary {
+	number := normalizeCardNumber(c.CardNumber)
+	last4 := number
+	if len(number) > 4 {
+		last4 = number[len(number)-4:]
+	}
+
+	summary := CardSummary{
+		Brand:   DetectCardBrand(number),
+		Last4:   last4,
+		Funding: CardFundingUnknown,
+	}
+	if bins == nil {
+		return summary
+	}
+	if info, ok := bins.Lookup(number); ok {
+		if info.Brand != "" && info.Brand != CardBrandUnknown {
+			summary.Brand = info.Brand
+		}
+		if info.Funding != "" {
+			summary.Funding = info.Funding
+		}
+		summary.Country = info.Country
+	}
+	return summary
+}
+
+// Fingerprint identifies the card number across tokens without revealing
+// it. Only holders of key can link a fingerprint to a card number.
+func (c *CreditCardInfo) Fingerprint(key []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(normalizeCardNumber(c.CardNumber)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Validate checks the card number checksum, expiry date and CVV format.
+// Card numbers and CVVs must have the lengths of the card's network, e.g. a
+// 15 digit number and a 4 digit CVV for Amex.
+func (c *CreditCardInfo) Validate() error {
+	number := normalizeCardNumber(c.CardNumber)
+	if !isValidCardNumber(number) {
+		return ErrInvalidCardNumber
+	}
+	rule, known := cardRules[DetectCardBrand(number)]
+	if known && !rule.allowsLength(len(number)) {
+		return ErrInvalidCardNumber
+	}
+
+	month, err := strconv.Atoi(c.ExpiryMonth)
+	if err != nil || month < 1 || month > 12 {
+		return ErrInvalidExpiryMonth
+	}
+
+	year, err := strconv.Atoi(c.ExpiryYear)
+	if err != nil {
+		return ErrInvalidExpiryYear
+	}
+
+	now := time.Now()
+	if year < now.Year() || (year == now.Year() && month < int(now.Month())) {
+		return ErrCardExpired
+	}
+
+	if !isValidCVV(c.CVV) || (known && len(c.CVV) != rule.cvvLength) {
+		return ErrInvalidCVV
+	}
+
+	return nil
+}
+
+// DetectCardBrand infers the card network from the leading digits of the
+// card number.
+func DetectCardBrand(number string) CardBrand {
+	number = normalizeCardNumber(number)
+	prefix := func(n int) int {
+		if len(number) < n {
+			return -1
+		}
+		v, _ := strconv.Atoi(number[:n])
+		return v
+	}
+
+	switch {
+	case prefix(1) == 4:
+		return CardBrandVisa
+	case prefix(2) >= 51 && prefix(2) <= 55,
+		prefix(4) >= 2221 && prefix(4) <= 2720:
+		return CardBrandMastercard
+	case prefix(2) == 34 || prefix(2) == 37:
+		return CardBrandAmex
+	case prefix(4) == 6011 || prefix(2) == 65,
+		prefix(3) >= 644 && prefix(3) <= 649:
+		return CardBrandDiscover
+	case prefix(4) >= 3528 && prefix(4) <= 3589:
+		return CardBrandJCB
+	case prefix(2) == 36 || prefix(2) == 38,
+		prefix(3) >= 300 && prefix(3) <= 305:
+		return CardBrandDiners
+	case prefix(2) == 62:
+		return CardBrandUnionPay
+	default:
+		return CardBrandUnknown
+	}
+}
+
+func normalizeCardNumber(number string) string {
+	return strings.NewReplacer(" ", "", "-", "").Replace(number)
+}
+
+// isValidCardNumber checks the length and Luhn checksum of a card number.
+func isValidCardNumber(number string) bool {
+	if len(number) < 13 || len(number) > 19 {
+		return false
+	}
+
+	sum := 0
+	isEven := false
+	for i := len(number) - 1; i >= 0; i-- {
+		if number[i] < '0' || number[i] > '9' {
+			return false
+		}
+		digit := int(number[i] - '0')
+
+		if isEven {
+			digit *= 2
+			if digit > 9 {
+				digit -= 9
+			}
+		}
+
+		sum += digit
+		isEven = !isEven
+	}
+
+	return sum%10 == 0
+}
+
+func isValidCVV(cvv string) bool {
+	if len(cvv) < 3 || len(cvv) > 4 {
+		return false
+	}
+	for _, r := range cvv {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
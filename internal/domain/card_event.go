@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var ErrInvalidEventSignature = errors.New("invalid webhook signature")
+
+// CardEventType is a change to a card payment reported by the processor
+// after the call that started it returned.
+type CardEventType string
+
+const (
+	CardEventPaymentSucceeded  CardEventType = "payment_succeeded"
+	CardEventPaymentAuthorized CardEventType = "payment_authorized"
+	CardEventPaymentFailed     CardEventType = "payment_failed"
+	CardEventPaymentCanceled   CardEventType = "payment_canceled"
+	// CardEventRefunded carries the total refunded on the payment, which
+	// includes refunds issued outside this service.
+	CardEventRefunded      CardEventType = "refunded"
+	CardEventDisputeOpened CardEventType = "dispute_opened"
+	CardEventDisputeWon    CardEventType = "dispute_won"
+	CardEventDisputeLost   CardEventType = "dispute_lost"
+)
+
+// CardEvent is a processor notification about a card payment.
+type CardEvent struct {
+	// ID is the processor's event ID; an event is applied at most once.
+	ID   string
+	Type CardEventType
+	// PaymentID is set when the processor echoes the payment ID back;
+	// otherwise the payment is found by TransactionID.
+	PaymentID     string
+	TransactionID string
+	// Amount is the refunded total for refunds and the disputed amount for
+	// disputes.
+	Amount Money
+	// Reference is the processor's ID of the refund or dispute.
+	Reference string
+	Reason    string
+	CreatedAt time.Time
+}
+
+// ProcessedEventStore remembers which processor events were applied.
+type ProcessedEventStore interface {
+	// Claim reserves the event for lease and reports whether it may be
+	// applied: it was neither applied yet nor claimed by a lease that is
+	// still running. The lease of a claimant that died before applying
+	// the event runs out, so the processor's retry can claim it again.
+	Claim(ctx context.Context, eventID string, eventType CardEventType, lease time.Duration) (bool, error)
+	// Complete records that the claimed event was applied.
+	Complete(ctx context.Context, eventID string) error
+	// Release gives up the claim on an event that could not be applied,
+	// so the processor's retry applies it.
+	Release(ctx context.Context, eventID string) error
+}
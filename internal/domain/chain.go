@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"golang.org/x/crypto/sha3"
+)
+
+var (
+	ErrUnsupportedChain = errors.New("unsupported chain")
+	ErrWrongChain       = errors.New("transaction is on a different chain than the payment")
+)
+
+// OrderHash is how the payment contract's events identify an order. They
+// index the order ID as a string, so a log carries the hex Keccak-256 hash of
+// the ID instead of the ID itself.
+func OrderHash(orderID string) string {
+	hash := sha3.NewLegacyKeccak256()
+	hash.Write([]byte(orderID))
+	return "0x" + hex.EncodeToString(hash.Sum(nil))
+}
+
+// ChainInfo describes a chain crypto payments can be made on.
+type ChainInfo struct {
+	ID             uint64
+	Name           string
+	NativeCurrency string
+}
+
+// ChainCheckpoint is the last block a chain watcher has scanned.
+type ChainCheckpoint struct {
+	BlockNumber uint64
+	BlockHash   string
+	UpdatedAt   time.Time
+}
+
+// ChainMatch is a payment transaction seen on chain that is waiting for
+// enough confirmations. Matches whose block is orphaned by a reorganization
+// are dropped instead of completing the payment.
+type ChainMatch struct {
+	TransactionHash string
+	BlockNumber     uint64
+	BlockHash       string
+	PaymentID       string
+	SeenAt          time.Time
+}
+
+// ChainWatcherRepository persists chain watcher progress so a restarted
+// watcher resumes where it stopped. watcher names the watcher instance, e.g.
+// the contract it follows.
+type ChainWatcherRepository interface {
+	// GetCheckpoint returns nil when the watcher has no checkpoint yet.
+	GetCheckpoint(ctx context.Context, watcher string) (*ChainCheckpoint, error)
+	SaveCheckpoint(ctx context.Context, watcher string, checkpoint *ChainCheckpoint) error
+	SaveMatch(ctx context.Context, watcher string, match *ChainMatch) error
+	GetMatches(ctx context.Context, watcher string) ([]*ChainMatch, error)
+	DeleteMatch(ctx context.Context, watcher string, transactionHash string) error
+}
@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrInvalidReportPeriod = errors.New("report period must end after it starts")
+
+// FeeEstimate is the expected EIP-1559 network fee of a payment
+// transaction. Per-gas prices are in wei of the chain's native currency.
+type FeeEstimate struct {
+	// GasLimit is zero when the gas could not be estimated, e.g. for a
+	// token payment that is only valid once the approval is mined.
+	GasLimit             uint64
+	BaseFeePerGas        string
+	MaxPriorityFeePerGas string
+	MaxFeePerGas         string
+	// MaxCost is the most the transaction can cost, GasLimit times
+	// MaxFeePerGas; zero when the gas limit is unknown.
+	MaxCost Money
+}
+
+// NetworkFee is the gas the customer paid to get a crypto payment mined.
+type NetworkFee struct {
+	ChainID uint64
+	GasUsed uint64
+	// EffectiveGasPrice is the price per gas the transaction paid, in wei.
+	EffectiveGasPrice string
+	// Amount is GasUsed times EffectiveGasPrice in the chain's native
+	// currency.
+	Amount     Money
+	RecordedAt time.Time
+}
+
+// NetworkFeeTotal sums the network fees recorded on payments of one chain
+// and currency.
+type NetworkFeeTotal struct {
+	ChainID  uint64
+	Total    Money
+	GasUsed  uint64
+	Payments int64
+}
@@ -0,0 +1,91 @@
+package domain
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	// ErrCardDeclined and ErrInsufficientFunds are returned by gateways when
+	// the issuer declines a card.
+	ErrCardDeclined      = errors.New("card declined")
+	ErrInsufficientFunds = errors.New("insufficient funds")
+	// ErrGatewayTimeout is returned when the gateway did not give a
+	// definite answer, e.g. it timed out or failed on its side, so the card
+	// may or may not have been charged. ErrGatewayUnavailable is returned
+	// when the request provably never reached the gateway and can safely be
+	// sent to another provider.
+	ErrGatewayTimeout      = errors.New("payment gateway timed out")
+	ErrGatewayUnavailable  = errors.New("payment gateway unavailable")
+	ErrTransactionNotFound = errors.New("gateway transaction not found")
+	// ErrNoCardProvider is returned when no card provider accepts the
+	// payment, or all that do are failing.
+	ErrNoCardProvider = errors.New("no card provider available for the payment")
+)
+
+// GatewayStatus is the state of a card transaction at the gateway.
+type GatewayStatus string
+
+const (
+	// GatewayStatusSucceeded means the funds were captured.
+	GatewayStatusSucceeded GatewayStatus = "succeeded"
+	// GatewayStatusAuthorized means a hold was placed and awaits capture.
+	GatewayStatusAuthorized GatewayStatus = "authorized"
+	// GatewayStatusRequiresAction means the customer has to authenticate,
+	// as described by the transaction's NextAction.
+	GatewayStatusRequiresAction GatewayStatus = "requires_action"
+	// GatewayStatusProcessing means the issuer has not answered yet.
+	GatewayStatusProcessing GatewayStatus = "processing"
+	GatewayStatusFailed     GatewayStatus = "failed"
+	GatewayStatusCancelled  GatewayStatus = "cancelled"
+)
+
+// GatewayTransaction is a card transaction as the gateway reports it.
+type GatewayTransaction struct {
+	ID         string
+	Status     GatewayStatus
+	Card       *CardSummary
+	NextAction *NextAction
+	// FailureReason explains a failed transaction.
+	FailureReason string
+}
+
+// PaymentGateway is a card payment provider. Card tokens are either the
+// provider's own payment method IDs or CardVault tokens. Gateways use the
+// idempotency key in the context, if any, so retried calls do not charge
+// twice.
+type PaymentGateway interface {
+	// Authorize charges the card for the payment amount, or only places a
+	// hold when capture is false. Declines are returned as ErrCardDeclined
+	// or ErrInsufficientFunds.
+	Authorize(ctx context.Context, payment *Payment, cardToken string, capture bool) (*GatewayTransaction, error)
+	// Capture captures up to the held amount; any remainder is released.
+	Capture(ctx context.Context, transactionID string, amount Money) error
+	// Refund refunds refund.Amount of the payment and returns the
+	// provider's reference for the refund. Refunds are idempotent on
+	// refund.ID.
+	Refund(ctx context.Context, payment *Payment, refund *Refund) (string, error)
+	// Void releases a hold that was not captured.
+	Void(ctx context.Context, transactionID string) error
+	GetStatus(ctx context.Context, transactionID string) (*GatewayTransaction, error)
+}
+
+// IsGatewayFailure reports whether a gateway call failed without an answer
+// from the issuer, as opposed to a decline or a bad request.
+func IsGatewayFailure(err error) bool {
+	return errors.Is(err, ErrGatewayTimeout) || errors.Is(err, ErrGatewayUnavailable)
+}
+
+// IsRetryableGatewayError reports whether a gateway call failed before it
+// reached the gateway, so retrying it elsewhere cannot charge twice.
+func IsRetryableGatewayError(err error) bool {
+	return errors.Is(err, ErrGatewayUnavailable)
+}
+
+// IsCardDecline reports whether a card was refused, by its issuer or
+// because its details were wrong. Card testing produces many of these.
+func IsCardDecline(err error) bool {
+	return errors.Is(err, ErrCardDeclined) ||
+		errors.Is(err, ErrInsufficientFunds) ||
+		errors.Is(err, ErrInvalidCardInfo)
+}
@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"context"
+	"errors"
+)
+
+// MaxIdempotencyKeyLength bounds client supplied idempotency keys.
+const MaxIdempotencyKeyLength = 255
+
+var (
+	ErrIdempotencyKeyReused  = errors.New("idempotency key was already used with a different request")
+	ErrIdempotencyInProgress = errors.New("a request with this idempotency key is still in progress")
+	ErrIdempotencyKeyTooLong = errors.New("idempotency key is too long")
+)
+
+type idempotencyContextKey struct{}
+
+// IdempotencyRecord is what the idempotency store keeps for a key: the
+// fingerprint of the request that claimed it and, once that request finished,
+// its serialized response. Requests that failed after a provider may have
+// acted on them keep their serialized error instead, so they are not run
+// again.
+type IdempotencyRecord struct {
+	Fingerprint string
+	Completed   bool
+	Response    []byte
+	Error       []byte
+}
+
+// IdempotencyStore implements a lock-then-record protocol. Lock claims a key
+// for the caller and returns nil, or returns the record of whoever claimed it
+// first. The owner either stores its response with Complete or gives the key
+// up with Release so the request can be retried.
+type IdempotencyStore interface {
+	Lock(ctx context.Context, key, fingerprint string, ttl int) (*IdempotencyRecord, error)
+	Complete(ctx context.Context, key string, record *IdempotencyRecord, ttl int) error
+	Release(ctx context.Context, key string) error
+}
+
+// WithIdempotencyKey attaches a client supplied idempotency key to the
+// context so processors can forward it to their providers.
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyContextKey{}, key)
+}
+
+// IdempotencyKeyFromContext returns the idempotency key attached to the
+// context, or an empty string.
+func IdempotencyKeyFromContext(ctx context.Context) string {
+	key, _ := ctx.Value(idempotencyContextKey{}).(string)
+	return key
+}
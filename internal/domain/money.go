@@ -0,0 +1,251 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrUnknownCurrency  = errors.New("unknown currency")
+	ErrCurrencyMismatch = errors.New("currency mismatch")
+	ErrInvalidPrecision = errors.New("amount has more decimals than the currency allows")
+	ErrAmountOverflow   = errors.New("amount overflows minor units")
+)
+
+// currencyExponents lists the number of decimal places of the minor unit for
+// every currency the service accepts. Fiat codes follow ISO 4217; native
+// chain currencies such as ETH use wei. Stablecoins are kept to a millionth
+// of a dollar so large amounts fit in an int64 and are scaled to the token's
+// own decimals when paid on chain.
+var currencyExponents = map[string]int{
+	"USD":  2,
+	"EUR":  2,
+	"GBP":  2,
+	"CHF":  2,
+	"CAD":  2,
+	"AUD":  2,
+	"KZT":  2,
+	"RUB":  2,
+	"CNY":  2,
+	"INR":  2,
+	"JPY":  0,
+	"KRW":  0,
+	"VND":  0,
+	"CLP":  0,
+	"KWD":  3,
+	"BHD":  3,
+	"OMR":  3,
+	"JOD":  3,
+	"TND":  3,
+	"ETH":  18,
+	"POL":  18,
+	"USDC": 6,
+	"USDT": 6,
+	"DAI":  6,
+}
+
+// CurrencyExponent returns the number of decimals of the currency's minor
+// unit.
+func CurrencyExponent(currency string) (int, error) {
+	exp, ok := currencyExponents[strings.ToUpper(currency)]
+	if !ok {
+		return 0, fmt.Errorf("%w: %q", ErrUnknownCurrency, currency)
+	}
+	return exp, nil
+}
+
+// Money is an exact monetary amount expressed as an integer number of minor
+// units (cents for USD, wei for ETH). Minor units are an int64, which holds
+// up to about 9.22 ETH in wei. Amounts beyond that are never wrapped around:
+// parsing, scaling, quoting and arithmetic return ErrAmountOverflow.
+type Money struct {
+	MinorUnits int64
+	Currency   string
+}
+
+// NewMoney builds a Money value from minor units, normalising the currency
+// code and rejecting currencies with an unknown exponent.
+func NewMoney(minorUnits int64, currency string) (Money, error) {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if currency == "" {
+		return Money{}, ErrInvalidCurrency
+	}
+	if _, err := CurrencyExponent(currency); err != nil {
+		return Money{}, err
+	}
+	return Money{MinorUnits: minorUnits, Currency: currency}, nil
+}
+
+// ParseMoney converts a decimal string such as "19.99" into minor units
+// without going through floating point.
+func ParseMoney(amount, currency string) (Money, error) {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if currency == "" {
+		return Money{}, ErrInvalidCurrency
+	}
+	exp, err := CurrencyExponent(currency)
+	if err != nil {
+		return Money{}, err
+	}
+
+	amount = strings.TrimSpace(amount)
+	negative := strings.HasPrefix(amount, "-")
+	amount = strings.TrimPrefix(amount, "-")
+
+	whole, frac, _ := strings.Cut(amount, ".")
+	if whole == "" {
+		whole = "0"
+	}
+	frac = strings.TrimRight(frac, "0")
+	if len(frac) > exp {
+		return Money{}, ErrInvalidPrecision
+	}
+	frac += strings.Repeat("0", exp-len(frac))
+
+	units, ok := new(big.Int).SetString(whole+frac, 10)
+	if !ok {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidAmount, amount)
+	}
+	if negative {
+		units.Neg(units)
+	}
+	if !units.IsInt64() {
+		return Money{}, ErrAmountOverflow
+	}
+
+	return Money{MinorUnits: units.Int64(), Currency: currency}, nil
+}
+
+// MoneyFromFloat converts a legacy floating point amount using its shortest
+// decimal representation, so 19.99 becomes exactly 1999 cents.
+func MoneyFromFloat(amount float64, currency string) (Money, error) {
+	if math.IsNaN(amount) || math.IsInf(amount, 0) {
+		return Money{}, ErrInvalidAmount
+	}
+	return ParseMoney(strconv.FormatFloat(amount, 'f', -1, 64), currency)
+}
+
+// Exponent returns the number of decimals of the money's minor unit.
+func (m Money) Exponent() int {
+	return currencyExponents[m.Currency]
+}
+
+func (m Money) IsZero() bool {
+	return m.MinorUnits == 0
+}
+
+func (m Money) IsPositive() bool {
+	return m.MinorUnits > 0
+}
+
+func (m Money) Add(other Money) (Money, error) {
+	if m.Currency != other.Currency {
+		return Money{}, ErrCurrencyMismatch
+	}
+	sum := m.MinorUnits + other.MinorUnits
+	if (sum > m.MinorUnits) != (other.MinorUnits > 0) {
+		return Money{}, ErrAmountOverflow
+	}
+	return Money{MinorUnits: sum, Currency: m.Currency}, nil
+}
+
+func (m Money) Sub(other Money) (Money, error) {
+	if other.MinorUnits == math.MinInt64 {
+		// Has no positive counterpart to add.
+		return Money{}, ErrAmountOverflow
+	}
+	return m.Add(Money{MinorUnits: -other.MinorUnits, Currency: other.Currency})
+}
+
+// Cmp compares two amounts of the same currency and returns -1, 0 or +1.
+func (m Money) Cmp(other Money) (int, error) {
+	if m.Currency != other.Currency {
+		return 0, ErrCurrencyMismatch
+	}
+	switch {
+	case m.MinorUnits < other.MinorUnits:
+		return -1, nil
+	case m.MinorUnits > other.MinorUnits:
+		return 1, nil
+	default:
+		return 0, nil
+	}
+}
+
+// ScaledTo returns the amount expressed with the given number of decimals,
+// e.g. ScaledTo(18) turns an ETH amount into wei. Scaling down truncates.
+func (m Money) ScaledTo(decimals int) *big.Int {
+	v := big.NewInt(m.MinorUnits)
+	diff := decimals - m.Exponent()
+	if diff == 0 {
+		return v
+	}
+	factor := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs(diff))), nil)
+	if diff > 0 {
+		return v.Mul(v, factor)
+	}
+	return v.Quo(v, factor)
+}
+
+// MoneyFromScaled is the inverse of ScaledTo: it reads v, an amount with
+// the given number of decimals such as wei, as money in currency.
+// Scaling down truncates.
+func MoneyFromScaled(v *big.Int, decimals int, currency string) (Money, error) {
+	exp, err := CurrencyExponent(currency)
+	if err != nil {
+		return Money{}, err
+	}
+
+	units := new(big.Int).Set(v)
+	diff := exp - decimals
+	factor := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs(diff))), nil)
+	if diff > 0 {
+		units.Mul(units, factor)
+	} else if diff < 0 {
+		units.Quo(units, factor)
+	}
+	if !units.IsInt64() {
+		return Money{}, ErrAmountOverflow
+	}
+
+	return Money{MinorUnits: units.Int64(), Currency: strings.ToUpper(currency)}, nil
+}
+
+// Decimal formats the amount as a plain decimal string, e.g. "19.99".
+func (m Money) Decimal() string {
+	exp := m.Exponent()
+	units := strconv.FormatInt(m.MinorUnits, 10)
+	sign := ""
+	if strings.HasPrefix(units, "-") {
+		sign, units = "-", units[1:]
+	}
+	if exp == 0 {
+		return sign + units
+	}
+	if len(units) <= exp {
+		units = strings.Repeat("0", exp-len(units)+1) + units
+	}
+	return sign + units[:len(units)-exp] + "." + units[len(units)-exp:]
+}
+
+// Float64 approximates the amount as a float. It exists only to fill
+// deprecated floating point fields and must not be used for arithmetic.
+func (m Money) Float64() float64 {
+	f, _ := strconv.ParseFloat(m.Decimal(), 64)
+	return f
+}
+
+func (m Money) String() string {
+	return m.Decimal() + " " + m.Currency
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
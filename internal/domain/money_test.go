@@ -0,0 +1,247 @@
+package domain_test
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/hsibAD/payment-service/internal/domain"
+)
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		amount   string
+		currency string
+		want     domain.Money
+		wantErr  error
+	}{
+		{amount: "19.99", currency: "USD", want: domain.Money{MinorUnits: 1999, Currency: "USD"}},
+		{amount: "19.9", currency: "usd", want: domain.Money{MinorUnits: 1990, Currency: "USD"}},
+		{amount: "19.990000", currency: "USD", want: domain.Money{MinorUnits: 1999, Currency: "USD"}},
+		{amount: ".5", currency: "EUR", want: domain.Money{MinorUnits: 50, Currency: "EUR"}},
+		{amount: " -3.25 ", currency: "GBP", want: domain.Money{MinorUnits: -325, Currency: "GBP"}},
+		{amount: "1500", currency: "JPY", want: domain.Money{MinorUnits: 1500, Currency: "JPY"}},
+		{amount: "1.234", currency: "KWD", want: domain.Money{MinorUnits: 1234, Currency: "KWD"}},
+		{amount: "0.1", currency: "ETH", want: domain.Money{MinorUnits: 1e17, Currency: "ETH"}},
+		{amount: "0.000000000000000001", currency: "ETH", want: domain.Money{MinorUnits: 1, Currency: "ETH"}},
+		{amount: "9.223372036854775807", currency: "ETH", want: domain.Money{MinorUnits: math.MaxInt64, Currency: "ETH"}},
+		{amount: "12.5", currency: "USDC", want: domain.Money{MinorUnits: 12500000, Currency: "USDC"}},
+		{amount: "9.223372036854775808", currency: "ETH", wantErr: domain.ErrAmountOverflow},
+		{amount: "100000000000000000", currency: "USD", wantErr: domain.ErrAmountOverflow},
+		{amount: "19.999", currency: "USD", wantErr: domain.ErrInvalidPrecision},
+		{amount: "1.5", currency: "JPY", wantErr: domain.ErrInvalidPrecision},
+		{amount: "12,50", currency: "USD", wantErr: domain.ErrInvalidAmount},
+		{amount: "1e3", currency: "USD", wantErr: domain.ErrInvalidAmount},
+		{amount: "10", currency: "XYZ", wantErr: domain.ErrUnknownCurrency},
+		{amount: "10", currency: " ", wantErr: domain.ErrInvalidCurrency},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.amount+" "+tt.currency, func(t *testing.T) {
+			got, err := domain.ParseMoney(tt.amount, tt.currency)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseMoney: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseMoney = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoneyFromFloat(t *testing.T) {
+	tests := []struct {
+		amount   float64
+		currency string
+		want     int64
+		wantErr  error
+	}{
+		// 19.99 and 0.29 have no exact binary representation.
+		{amount: 19.99, currency: "USD", want: 1999},
+		{amount: 0.29, currency: "USD", want: 29},
+		{amount: 1234567.89, currency: "USD", want: 123456789},
+		{amount: 0.1, currency: "ETH", want: 1e17},
+		{amount: 19.999, currency: "USD", wantErr: domain.ErrInvalidPrecision},
+		{amount: math.NaN(), currency: "USD", wantErr: domain.ErrInvalidAmount},
+		{amount: math.Inf(1), currency: "USD", wantErr: domain.ErrInvalidAmount},
+		{amount: 1e30, currency: "USD", wantErr: domain.ErrAmountOverflow},
+	}
+
+	for _, tt := range tests {
+		got, err := domain.MoneyFromFloat(tt.amount, tt.currency)
+		if tt.wantErr != nil {
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("MoneyFromFloat(%v, %s) error = %v, want %v", tt.amount, tt.currency, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || got.MinorUnits != tt.want {
+			t.Errorf("MoneyFromFloat(%v, %s) = %d, %v, want %d", tt.amount, tt.currency, got.MinorUnits, err, tt.want)
+		}
+	}
+}
+
+func TestMoneyArithmetic(t *testing.T) {
+	usd := func(units int64) domain.Money { return domain.Money{MinorUnits: units, Currency: "USD"} }
+	eur := domain.Money{MinorUnits: 100, Currency: "EUR"}
+
+	tests := []struct {
+		name    string
+		op      func() (domain.Money, error)
+		want    domain.Money
+		wantErr error
+	}{
+		{name: "add", op: func() (domain.Money, error) { return usd(1999).Add(usd(1)) }, want: usd(2000)},
+		{name: "add negative", op: func() (domain.Money, error) { return usd(500).Add(usd(-700)) }, want: usd(-200)},
+		{name: "add zero", op: func() (domain.Money, error) { return usd(math.MaxInt64).Add(usd(0)) }, want: usd(math.MaxInt64)},
+		{name: "add up to the limit", op: func() (domain.Money, error) { return usd(math.MaxInt64 - 1).Add(usd(1)) }, want: usd(math.MaxInt64)},
+		{name: "add overflow", op: func() (domain.Money, error) { return usd(math.MaxInt64).Add(usd(1)) }, wantErr: domain.ErrAmountOverflow},
+		{name: "add underflow", op: func() (domain.Money, error) { return usd(math.MinInt64).Add(usd(-1)) }, wantErr: domain.ErrAmountOverflow},
+		{name: "add other currency", op: func() (domain.Money, error) { return usd(100).Add(eur) }, wantErr: domain.ErrCurrencyMismatch},
+		{name: "sub", op: func() (domain.Money, error) { return usd(2500).Sub(usd(1000)) }, want: usd(1500)},
+		{name: "sub below zero", op: func() (domain.Money, error) { return usd(1000).Sub(usd(2500)) }, want: usd(-1500)},
+		{name: "sub overflow", op: func() (domain.Money, error) { return usd(math.MaxInt64).Sub(usd(-1)) }, wantErr: domain.ErrAmountOverflow},
+		{name: "sub minimum", op: func() (domain.Money, error) { return usd(0).Sub(usd(math.MinInt64)) }, wantErr: domain.ErrAmountOverflow},
+		{name: "sub other currency", op: func() (domain.Money, error) { return usd(100).Sub(eur) }, wantErr: domain.ErrCurrencyMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.op()
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoneyCmp(t *testing.T) {
+	a := domain.Money{MinorUnits: 100, Currency: "USD"}
+	tests := []struct {
+		other   domain.Money
+		want    int
+		wantErr error
+	}{
+		{other: domain.Money{MinorUnits: 99, Currency: "USD"}, want: 1},
+		{other: domain.Money{MinorUnits: 100, Currency: "USD"}, want: 0},
+		{other: domain.Money{MinorUnits: 101, Currency: "USD"}, want: -1},
+		{other: domain.Money{MinorUnits: 100, Currency: "EUR"}, wantErr: domain.ErrCurrencyMismatch},
+	}
+
+	for _, tt := range tests {
+		got, err := a.Cmp(tt.other)
+		if !errors.Is(err, tt.wantErr) || got != tt.want {
+			t.Errorf("Cmp(%v) = %d, %v, want %d, %v", tt.other, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestMoneyDecimal(t *testing.T) {
+	tests := []struct {
+		money domain.Money
+		want  string
+	}{
+		{money: domain.Money{MinorUnits: 1999, Currency: "USD"}, want: "19.99"},
+		{money: domain.Money{MinorUnits: 5, Currency: "USD"}, want: "0.05"},
+		{money: domain.Money{MinorUnits: 0, Currency: "USD"}, want: "0.00"},
+		{money: domain.Money{MinorUnits: -5, Currency: "USD"}, want: "-0.05"},
+		{money: domain.Money{MinorUnits: 1500, Currency: "JPY"}, want: "1500"},
+		{money: domain.Money{MinorUnits: 1234, Currency: "KWD"}, want: "1.234"},
+		{money: domain.Money{MinorUnits: 1e17, Currency: "ETH"}, want: "0.100000000000000000"},
+		{money: domain.Money{MinorUnits: math.MinInt64, Currency: "ETH"}, want: "-9.223372036854775808"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.money.Decimal(); got != tt.want {
+			t.Errorf("Decimal(%d %s) = %q, want %q", tt.money.MinorUnits, tt.money.Currency, got, tt.want)
+		}
+		if tt.money.MinorUnits == math.MinInt64 {
+			continue
+		}
+		parsed, err := domain.ParseMoney(tt.money.Decimal(), tt.money.Currency)
+		if err != nil || parsed != tt.money {
+			t.Errorf("ParseMoney(%q) = %+v, %v, want %+v", tt.money.Decimal(), parsed, err, tt.money)
+		}
+	}
+}
+
+func TestMoneyScaling(t *testing.T) {
+	tests := []struct {
+		money    domain.Money
+		decimals int
+		want     string
+	}{
+		{money: domain.Money{MinorUnits: 1e17, Currency: "ETH"}, decimals: 18, want: "100000000000000000"},
+		{money: domain.Money{MinorUnits: 1e17, Currency: "ETH"}, decimals: 9, want: "100000000"},
+		{money: domain.Money{MinorUnits: 12500000, Currency: "USDC"}, decimals: 6, want: "12500000"},
+		// DAI is kept in millionths but has 18 decimals on chain.
+		{money: domain.Money{MinorUnits: 12500000, Currency: "DAI"}, decimals: 18, want: "12500000000000000000"},
+		{money: domain.Money{MinorUnits: 1999, Currency: "USD"}, decimals: 0, want: "19"},
+		// Scaled beyond int64 without wrapping around.
+		{money: domain.Money{MinorUnits: math.MaxInt64, Currency: "USDC"}, decimals: 18, want: "9223372036854775807000000000000"},
+	}
+
+	for _, tt := range tests {
+		got := tt.money.ScaledTo(tt.decimals)
+		if got.String() != tt.want {
+			t.Errorf("%v ScaledTo(%d) = %s, want %s", tt.money, tt.decimals, got, tt.want)
+		}
+	}
+}
+
+func TestMoneyFromScaled(t *testing.T) {
+	wei := func(s string) *big.Int {
+		v, _ := new(big.Int).SetString(s, 10)
+		return v
+	}
+
+	tests := []struct {
+		name     string
+		v        *big.Int
+		decimals int
+		currency string
+		want     domain.Money
+		wantErr  error
+	}{
+		{name: "wei", v: wei("100000000000000000"), decimals: 18, currency: "eth", want: domain.Money{MinorUnits: 1e17, Currency: "ETH"}},
+		{name: "token decimals", v: wei("12500000000000000000"), decimals: 18, currency: "DAI", want: domain.Money{MinorUnits: 12500000, Currency: "DAI"}},
+		{name: "truncates", v: wei("12500000999999999999"), decimals: 18, currency: "DAI", want: domain.Money{MinorUnits: 12500000, Currency: "DAI"}},
+		{name: "scales up", v: big.NewInt(125), decimals: 1, currency: "USDC", want: domain.Money{MinorUnits: 12500000, Currency: "USDC"}},
+		{name: "overflow", v: wei("10000000000000000000"), decimals: 18, currency: "ETH", wantErr: domain.ErrAmountOverflow},
+		{name: "unknown currency", v: big.NewInt(1), decimals: 18, currency: "XYZ", wantErr: domain.ErrUnknownCurrency},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := domain.MoneyFromScaled(tt.v, tt.decimals, tt.currency)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,161 +1,442 @@
-package domain
-
-import (
-	"errors"
-	"time"
-)
-
-var (
-	ErrInvalidPaymentID     = errors.New("invalid payment ID")
-	ErrInvalidOrderID       = errors.New("invalid order ID")
-	ErrInvalidUserID        = errors.New("invalid user ID")
-	ErrInvalidAmount        = errors.New("invalid amount")
-	ErrInvalidCurrency      = errors.New("invalid currency")
-	ErrInvalidPaymentMethod = errors.New("invalid payment method")
-)
-
-type PaymentStatus string
-
-const (
-	PaymentStatusPending    PaymentStatus = "PENDING"
-	PaymentStatusProcessing PaymentStatus = "PROCESSING"
-	PaymentStatusCompleted  PaymentStatus = "COMPLETED"
-	PaymentStatusFailed     PaymentStatus = "FAILED"
-	PaymentStatusCancelled  PaymentStatus = "CANCELLED"
-	PaymentStatusRefunded   PaymentStatus = "REFUNDED"
-)
-
-type PaymentMethod string
-
-const (
-	PaymentMethodCreditCard PaymentMethod = "CREDIT_CARD"
-	PaymentMethodMetaMask   PaymentMethod = "METAMASK"
-)
-
-type Payment struct {
-	ID            string
-	OrderID       string
-	UserID        string
-	Amount        float64
-	Currency      string
-	Status        string
-	PaymentMethod string
-	TransactionID string
-	ErrorMessage  string
-	CreatedAt     time.Time
-	UpdatedAt     time.Time
-}
-
-type CreditCardInfo struct {
-	CardNumber     string
-	ExpiryMonth    string
-	ExpiryYear     string
-	CVV            string
-	CardholderName string
-}
-
-type MetaMaskInfo struct {
-	WalletAddress   string
-	TransactionHash string
-	ContractAddress string
-	PaymentData     string
-}
-
-func NewPayment(
-	orderID string,
-	userID string,
-	amount float64,
-	currency string,
-	method PaymentMethod,
-) (*Payment, error) {
-	if orderID == "" {
-		return nil, ErrInvalidOrderID
-	}
-
-	if userID == "" {
-		return nil, ErrInvalidUserID
-	}
-
-	if amount <= 0 {
-		return nil, ErrInvalidAmount
-	}
-
-	if currency == "" {
-		return nil, ErrInvalidCurrency
-	}
-
-	if method != PaymentMethodCreditCard && method != PaymentMethodMetaMask {
-		return nil, ErrInvalidPaymentMethod
-	}
-
-	return &Payment{
-		OrderID:       orderID,
-		UserID:        userID,
-		Amount:        amount,
-		Currency:      currency,
-		Status:        string(PaymentStatusPending),
-		PaymentMethod: string(method),
-		CreatedAt:     time.Now(),
-		UpdatedAt:     time.Now(),
-	}, nil
-}
-
-func (p *Payment) UpdateStatus(status PaymentStatus) {
-	p.Status = string(status)
-	p.UpdatedAt = time.Now()
-}
-
-func (p *Payment) SetTransactionID(txID string) {
-	p.TransactionID = txID
-	p.UpdatedAt = time.Now()
-}
-
-func (p *Payment) SetError(err string) {
-	p.ErrorMessage = err
-	p.Status = string(PaymentStatusFailed)
-	p.UpdatedAt = time.Now()
-}
-
-func (p *Payment) IsCompleted() bool {
-	return p.Status == string(PaymentStatusCompleted)
-}
-
-func (p *Payment) IsPending() bool {
-	return p.Status == string(PaymentStatusPending) || p.Status == string(PaymentStatusProcessing)
-}
-
-func (p *Payment) CanBeRetried() bool {
-	return p.Status == string(PaymentStatusFailed) || p.Status == string(PaymentStatusCancelled)
-}
-
-func (p *Payment) MarkAsProcessing() {
-	if p.Status == string(PaymentStatusPending) {
-		p.Status = string(PaymentStatusProcessing)
-		p.UpdatedAt = time.Now()
-	}
-}
-
-func (p *Payment) MarkAsCompleted(transactionID string) {
-	if p.IsPending() {
-		p.Status = string(PaymentStatusCompleted)
-		p.TransactionID = transactionID
-		p.UpdatedAt = time.Now()
-	}
-}
-
-func (p *Payment) Cancel() {
-	if p.IsPending() {
-		p.Status = string(PaymentStatusCancelled)
-		p.UpdatedAt = time.Now()
-	}
-}
-
-func (p *Payment) Refund() error {
-	if p.Status != string(PaymentStatusCompleted) {
-		return errors.New("only completed payments can be refunded")
-	}
-
-	p.Status = string(PaymentStatusRefunded)
-	p.UpdatedAt = time.Now()
-	return nil
-} 
\ No newline at end of file
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrInvalidPaymentID      = errors.New("invalid payment ID")
+	ErrInvalidOrderID        = errors.New("invalid order ID")
+	ErrInvalidUserID         = errors.New("invalid user ID")
+	ErrInvalidAmount         = errors.New("invalid amount")
+	ErrInvalidCurrency       = errors.New("invalid currency")
+	ErrInvalidPaymentMethod  = errors.New("invalid payment method")
+	ErrInvalidStatus         = errors.New("invalid payment status")
+	ErrStatusNotSettable     = errors.New("payment status is set by the operation that moves the money")
+	ErrPaymentNotPending     = errors.New("payment is not pending")
+	ErrPaymentNotRetryable   = errors.New("payment cannot be retried")
+	ErrPaymentConflict       = errors.New("payment was changed since it was loaded")
+	ErrPaymentMethodMismatch = errors.New("payment method does not match request")
+	ErrInvalidCardInfo       = errors.New("invalid card information")
+	ErrInvalidWalletAddress  = errors.New("invalid wallet address")
+
+	ErrInsufficientConfirmations   = errors.New("insufficient confirmations")
+	ErrTransactionNotMined         = errors.New("transaction is not mined yet")
+	ErrTransactionFailed           = errors.New("transaction failed")
+	ErrCaptureExceedsAuthorization = errors.New("capture amount exceeds authorized amount")
+
+	// A crypto payment transaction that does not pay for the payment it is
+	// submitted for fails verification with one of these.
+	ErrPaymentLogNotFound     = errors.New("transaction did not emit a PaymentReceived event")
+	ErrWrongPaymentContract   = errors.New("payment event was not emitted by the payment contract")
+	ErrPaymentOrderMismatch   = errors.New("payment event is for a different order")
+	ErrPaymentUnderpaid       = errors.New("payment event amount is less than the payment amount")
+	ErrPaymentTokenMismatch   = errors.New("transaction did not transfer the payment token to the payment contract")
+	ErrTransactionAlreadyUsed = errors.New("transaction is already used by another payment")
+
+	// ErrCurrencyNotPayable is returned for crypto payments in a currency
+	// that is neither the chain's native currency nor a registered token.
+	ErrCurrencyNotPayable = errors.New("currency cannot be paid on chain")
+
+	// ErrActionRequired is returned by card processors when the customer
+	// has to authenticate the payment, e.g. with 3-D Secure, before it
+	// can go through. The processor records what to do on NextAction.
+	ErrActionRequired           = errors.New("payment requires customer authentication")
+	ErrPaymentNotAwaitingAction = errors.New("payment is not waiting for customer authentication")
+)
+
+type PaymentStatus string
+
+const (
+	PaymentStatusPending    PaymentStatus = "PENDING"
+	PaymentStatusProcessing PaymentStatus = "PROCESSING"
+	// A card payment waiting for the customer to authenticate it.
+	PaymentStatusRequiresAction PaymentStatus = "REQUIRES_ACTION"
+	PaymentStatusCompleted      PaymentStatus = "COMPLETED"
+	PaymentStatusFailed         PaymentStatus = "FAILED"
+	PaymentStatusCancelled      PaymentStatus = "CANCELLED"
+	PaymentStatusRefunded       PaymentStatus = "REFUNDED"
+
+	PaymentStatusPartiallyRefunded PaymentStatus = "PARTIALLY_REFUNDED"
+
+	// Authorize-then-capture flow.
+	PaymentStatusAuthorized PaymentStatus = "AUTHORIZED"
+	PaymentStatusCaptured   PaymentStatus = "CAPTURED"
+	PaymentStatusVoided     PaymentStatus = "VOIDED"
+
+	// A card payment the risk engine flagged. It is authorized and waits
+	// for a reviewer to approve or reject it.
+	PaymentStatusManualReview PaymentStatus = "MANUAL_REVIEW"
+
+	// A card payment the cardholder disputed with their bank. A lost
+	// dispute charges the payment back.
+	PaymentStatusDisputed    PaymentStatus = "DISPUTED"
+	PaymentStatusChargedBack PaymentStatus = "CHARGED_BACK"
+)
+
+type PaymentMethod string
+
+const (
+	PaymentMethodCreditCard PaymentMethod = "CREDIT_CARD"
+	PaymentMethodMetaMask   PaymentMethod = "METAMASK"
+)
+
+// IsValid reports whether the method is one the service can process.
+func (m PaymentMethod) IsValid() bool {
+	return m == PaymentMethodCreditCard || m == PaymentMethodMetaMask
+}
+
+// ParsePaymentMethod converts a stored or transported method name into a
+// PaymentMethod, rejecting unknown values.
+func ParsePaymentMethod(s string) (PaymentMethod, error) {
+	m := PaymentMethod(s)
+	if !m.IsValid() {
+		return "", fmt.Errorf("%w: %q", ErrInvalidPaymentMethod, s)
+	}
+	return m, nil
+}
+
+type Payment struct {
+	ID            string
+	OrderID       string
+	UserID        string
+	Amount        Money
+	Status        PaymentStatus
+	PaymentMethod PaymentMethod
+	TransactionID string
+	ErrorMessage  string
+	CustomerEmail string
+	History       []StatusChange
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+
+	// Authorize-then-capture payments hold AuthorizedAmount on the card
+	// and later collect CapturedAmount. Immediate charges capture the
+	// full Amount.
+	AuthorizedAmount Money
+	CapturedAmount   Money
+	AuthorizedAt     time.Time
+
+	// RefundedAmount is the sum of successful refunds.
+	RefundedAmount Money
+
+	// NextAction tells the client how the customer authenticates a
+	// payment that requires action.
+	NextAction *NextAction
+
+	// Card is the masked card a credit card payment was made with.
+	Card *CardSummary
+	// CardProvider is the card provider that handled the payment. Captures,
+	// voids and refunds go back to it.
+	CardProvider string
+
+	// ChainID is the chain a crypto payment is paid on. Zero means the
+	// default chain.
+	ChainID uint64
+
+	// WalletAddress is the wallet the customer proved they control for a
+	// crypto payment.
+	WalletAddress string
+
+	// NetworkFee is the gas paid for the transaction that paid a crypto
+	// payment, recorded when it is verified.
+	NetworkFee *NetworkFee
+
+	// Quote locks the crypto amount of a fiat-priced crypto payment.
+	Quote *PriceQuote
+
+	// Risk is how the risk engine scored a card payment.
+	Risk *RiskAssessment
+	// CaptureOnApproval is set on payments held for review that are
+	// captured when approved rather than left authorized.
+	CaptureOnApproval bool
+
+	// Version counts the saved changes to the payment. A payment is only
+	// saved if nobody saved it since it was loaded.
+	Version int64
+}
+
+type MetaMaskInfo struct {
+	WalletAddress   string
+	TransactionHash string
+	ContractAddress string
+	AmountWei       string
+	PaymentData     string
+
+	// Token is set for payments in an ERC-20 token. The wallet first sends
+	// ApproveData to the token contract, then TransferData to the payment
+	// contract, which pulls TokenAmount base units from the wallet.
+	Token        *TokenInfo
+	ApproveData  string
+	TransferData string
+	TokenAmount  string
+
+	ChainID uint64
+
+	// Quote is the locked price of a fiat payment.
+	Quote *PriceQuote
+
+	// Transaction is the payment call ready for the wallet to sign: the
+	// native payment, or the token payment sent after the approval.
+	Transaction *TransactionRequest
+	// Fee is the expected network fee of Transaction.
+	Fee *FeeEstimate
+	// PaymentURI is an EIP-681 request for the native payment that mobile
+	// wallets can open from a QR code. Token payments have none since they
+	// need an approval first.
+	PaymentURI string
+}
+
+// TransactionRequest is an unsigned transaction from the paying wallet.
+type TransactionRequest struct {
+	From    string
+	To      string
+	Value   string // wei, decimal
+	Data    string // hex encoded call data
+	ChainID uint64
+	// Gas is the estimated gas limit, zero when the node could not estimate
+	// it, e.g. because the wallet is not funded yet.
+	Gas uint64
+	// EIP-1559 fee caps in wei.
+	MaxPriorityFeePerGas string
+	MaxFeePerGas         string
+}
+
+// NextActionType is how the customer authenticates a card payment.
+type NextActionType string
+
+const (
+	// NextActionRedirect sends the customer to RedirectURL.
+	NextActionRedirect NextActionType = "redirect_to_url"
+	// NextActionUseSDK completes the action in the processor's client SDK
+	// with the ClientSecret, e.g. Stripe.js handleNextAction.
+	NextActionUseSDK NextActionType = "use_sdk"
+)
+
+type NextAction struct {
+	Type         NextActionType
+	RedirectURL  string
+	ClientSecret string
+}
+
+// TokenInfo identifies an ERC-20 token on a chain.
+type TokenInfo struct {
+	Symbol   string
+	Address  string
+	Decimals int
+}
+
+func NewPayment(
+	orderID string,
+	userID string,
+	amount Money,
+	method PaymentMethod,
+) (*Payment, error) {
+	if orderID == "" {
+		return nil, ErrInvalidOrderID
+	}
+
+	if userID == "" {
+		return nil, ErrInvalidUserID
+	}
+
+	if !amount.IsPositive() {
+		return nil, ErrInvalidAmount
+	}
+
+	if _, err := CurrencyExponent(amount.Currency); err != nil {
+		return nil, ErrInvalidCurrency
+	}
+
+	if !method.IsValid() {
+		return nil, ErrInvalidPaymentMethod
+	}
+
+	return &Payment{
+		OrderID:       orderID,
+		UserID:        userID,
+		Amount:        amount,
+		Status:        PaymentStatusPending,
+		PaymentMethod: method,
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+
+		AuthorizedAmount: Money{Currency: amount.Currency},
+		CapturedAmount:   Money{Currency: amount.Currency},
+		RefundedAmount:   Money{Currency: amount.Currency},
+	}, nil
+}
+
+func (p *Payment) SetTransactionID(txID string) {
+	p.TransactionID = txID
+	p.UpdatedAt = time.Now()
+}
+
+// SetError fails the payment and records the reason.
+func (p *Payment) SetError(err string) error {
+	if err := p.TransitionTo(PaymentStatusFailed, err); err != nil {
+		return err
+	}
+	p.ErrorMessage = err
+	return nil
+}
+
+func (p *Payment) IsCompleted() bool {
+	return p.Status == PaymentStatusCompleted
+}
+
+func (p *Payment) IsPending() bool {
+	return p.Status == PaymentStatusPending || p.Status == PaymentStatusProcessing
+}
+
+func (p *Payment) CanBeRetried() bool {
+	return p.Status == PaymentStatusFailed || p.Status == PaymentStatusCancelled
+}
+
+// MarkAsProcessing moves a pending payment to processing. Calling it on a
+// payment that is already processing is a no-op.
+func (p *Payment) MarkAsProcessing() error {
+	if p.Status == PaymentStatusProcessing {
+		return nil
+	}
+	return p.TransitionTo(PaymentStatusProcessing, "")
+}
+
+// MarkAsCompleted completes a processing payment. An empty transactionID
+// keeps the one already recorded on the payment.
+func (p *Payment) MarkAsCompleted(transactionID string) error {
+	if err := p.TransitionTo(PaymentStatusCompleted, ""); err != nil {
+		return err
+	}
+	if transactionID != "" {
+		p.TransactionID = transactionID
+	}
+	p.CapturedAmount = p.Amount
+	return nil
+}
+
+// MarkAsAuthorized records a successful hold for the full payment amount.
+func (p *Payment) MarkAsAuthorized(transactionID string) error {
+	if err := p.TransitionTo(PaymentStatusAuthorized, ""); err != nil {
+		return err
+	}
+	p.TransactionID = transactionID
+	p.AuthorizedAmount = p.Amount
+	p.AuthorizedAt = p.UpdatedAt
+	return nil
+}
+
+// FlaggedForReview reports whether the risk engine wants a person to look
+// at the payment before it is captured.
+func (p *Payment) FlaggedForReview() bool {
+	return p.Risk != nil && p.Risk.Decision == RiskDecisionReview
+}
+
+// HoldForReview records a successful hold for the full payment amount and
+// parks the payment until a reviewer approves or rejects it.
+func (p *Payment) HoldForReview(transactionID string) error {
+	if err := p.TransitionTo(PaymentStatusManualReview, "held for manual review"); err != nil {
+		return err
+	}
+	p.TransactionID = transactionID
+	p.AuthorizedAmount = p.Amount
+	p.AuthorizedAt = p.UpdatedAt
+	return nil
+}
+
+// ValidateCapture checks that amount can be captured from the payment's
+// authorization without changing the payment.
+func (p *Payment) ValidateCapture(amount Money) error {
+	if !CanTransition(p.Status, PaymentStatusCaptured) {
+		return ErrInvalidTransition{From: p.Status, To: PaymentStatusCaptured}
+	}
+	if !amount.IsPositive() {
+		return ErrInvalidAmount
+	}
+	cmp, err := amount.Cmp(p.AuthorizedAmount)
+	if err != nil {
+		return err
+	}
+	if cmp > 0 {
+		return ErrCaptureExceedsAuthorization
+	}
+	return nil
+}
+
+// Capture records that amount, at most the authorized amount, was collected.
+func (p *Payment) Capture(amount Money) error {
+	if err := p.ValidateCapture(amount); err != nil {
+		return err
+	}
+	if err := p.TransitionTo(PaymentStatusCaptured, ""); err != nil {
+		return err
+	}
+	p.CapturedAmount = amount
+	return nil
+}
+
+// RequireAction parks a processing card payment until the customer has
+// authenticated it. The processor has recorded the transaction and the
+// NextAction on the payment.
+func (p *Payment) RequireAction() error {
+	next := p.NextAction
+	if err := p.TransitionTo(PaymentStatusRequiresAction, "customer authentication required"); err != nil {
+		return err
+	}
+	p.NextAction = next
+	return nil
+}
+
+// OpenDispute records that the cardholder disputed the payment.
+func (p *Payment) OpenDispute(reason string) error {
+	return p.TransitionTo(PaymentStatusDisputed, reason)
+}
+
+// CloseDispute settles a dispute. A won dispute returns the payment to the
+// status it had before the dispute; a lost one charges it back.
+func (p *Payment) CloseDispute(won bool, reason string) error {
+	if !won {
+		return p.TransitionTo(PaymentStatusChargedBack, reason)
+	}
+
+	for i := len(p.History) - 1; i >= 0; i-- {
+		if p.History[i].To == PaymentStatusDisputed {
+			return p.TransitionTo(p.History[i].From, reason)
+		}
+	}
+	return p.TransitionTo(PaymentStatusCompleted, reason)
+}
+
+// Void releases an authorization that will not be captured.
+func (p *Payment) Void(reason string) error {
+	return p.TransitionTo(PaymentStatusVoided, reason)
+}
+
+func (p *Payment) Cancel(reason string) error {
+	return p.TransitionTo(PaymentStatusCancelled, reason)
+}
+
+// Retry puts a failed or cancelled payment back into the pending state so it
+// can be processed again, optionally with a different payment method.
+func (p *Payment) Retry(method PaymentMethod) error {
+	if !p.CanBeRetried() {
+		return ErrPaymentNotRetryable
+	}
+
+	if method != "" && !method.IsValid() {
+		return ErrInvalidPaymentMethod
+	}
+
+	if err := p.TransitionTo(PaymentStatusPending, "retry"); err != nil {
+		return err
+	}
+
+	if method != "" {
+		p.PaymentMethod = method
+	}
+	p.TransactionID = ""
+	p.ErrorMessage = ""
+	return nil
+}
@@ -0,0 +1,169 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+var (
+	ErrQuoteExpired     = errors.New("price quote expired before the payment was made")
+	ErrPriceUnavailable = errors.New("no exchange rate available")
+	ErrInvalidRate      = errors.New("invalid exchange rate")
+	// ErrTransactionSubmitted is returned for quoting a payment again, or
+	// moving it to another chain, after a transaction paying it was
+	// submitted. The transaction is verified against the quote it paid.
+	ErrTransactionSubmitted = errors.New("a transaction was already submitted for the payment")
+)
+
+// cryptoCurrencies are the currencies that can be paid on chain.
+var cryptoCurrencies = map[string]bool{
+	"ETH":  true,
+	"POL":  true,
+	"USDC": true,
+	"USDT": true,
+	"DAI":  true,
+}
+
+// IsCryptoCurrency reports whether currency is paid on chain rather than
+// priced in fiat.
+func IsCryptoCurrency(currency string) bool {
+	return cryptoCurrencies[strings.ToUpper(currency)]
+}
+
+// PriceOracle supplies exchange rates for quoting fiat payments in crypto.
+type PriceOracle interface {
+	// Rate returns the price of one whole unit of base in quote, e.g.
+	// Rate(ctx, "ETH", "USD") might return 3012.55.
+	Rate(ctx context.Context, base, quote string) (*big.Rat, error)
+}
+
+// PriceQuote locks the crypto amount a fiat payment is paid with. The
+// customer must pay at least the amount less the slippage tolerance before
+// the quote expires.
+type PriceQuote struct {
+	// Currency is the crypto currency paid on chain.
+	Currency string
+	// Rate is the price of one whole unit of Currency in the payment
+	// currency, as an exact decimal.
+	Rate        string
+	Amount      Money
+	SlippageBps int
+	ExpiresAt   time.Time
+	CreatedAt   time.Time
+}
+
+// NewPriceQuote prices amount in currency at rate. The quoted amount is
+// rounded up so the merchant is never paid less than the fiat amount.
+func NewPriceQuote(amount Money, currency string, rate *big.Rat, ttl time.Duration, slippageBps int) (*PriceQuote, error) {
+	currency = strings.ToUpper(currency)
+	if !IsCryptoCurrency(currency) {
+		return nil, fmt.Errorf("%w: %s", ErrCurrencyNotPayable, currency)
+	}
+	if rate == nil || rate.Sign() <= 0 {
+		return nil, ErrInvalidRate
+	}
+	if slippageBps < 0 || slippageBps >= 10000 {
+		return nil, fmt.Errorf("invalid slippage tolerance: %d bps", slippageBps)
+	}
+
+	due, err := convert(amount, currency, rate, true)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	return &PriceQuote{
+		Currency:    currency,
+		Rate:        formatRate(rate),
+		Amount:      due,
+		SlippageBps: slippageBps,
+		ExpiresAt:   now.Add(ttl),
+		CreatedAt:   now,
+	}, nil
+}
+
+// Expired reports whether the quote no longer holds at the given time.
+func (q *PriceQuote) Expired(at time.Time) bool {
+	return at.After(q.ExpiresAt)
+}
+
+// MinimumAmount is the smallest payment accepted for the quote.
+func (q *PriceQuote) MinimumAmount() Money {
+	min := new(big.Int).Mul(big.NewInt(q.Amount.MinorUnits), big.NewInt(int64(10000-q.SlippageBps)))
+	min.Quo(min, big.NewInt(10000))
+	return Money{MinorUnits: min.Int64(), Currency: q.Amount.Currency}
+}
+
+// Convert turns an amount in the payment currency into the quoted currency
+// at the quoted rate, rounding down. Refunds of quoted payments use it so
+// the customer gets back what they paid for that part of the order.
+func (q *PriceQuote) Convert(amount Money) (Money, error) {
+	rate, ok := new(big.Rat).SetString(q.Rate)
+	if !ok || rate.Sign() <= 0 {
+		return Money{}, ErrInvalidRate
+	}
+	return convert(amount, q.Currency, rate, false)
+}
+
+// ChainAmount returns amount, given in the payment currency, in the
+// currency that is paid on chain.
+func (p *Payment) ChainAmount(amount Money) (Money, error) {
+	if p.Quote == nil || amount.Currency == p.Quote.Currency {
+		return amount, nil
+	}
+	return p.Quote.Convert(amount)
+}
+
+// PayableAmount is the amount the customer is asked to pay on chain.
+func (p *Payment) PayableAmount() Money {
+	if p.Quote == nil {
+		return p.Amount
+	}
+	return p.Quote.Amount
+}
+
+// MinimumPayment is the smallest on-chain payment accepted for the payment.
+func (p *Payment) MinimumPayment() Money {
+	if p.Quote == nil {
+		return p.Amount
+	}
+	return p.Quote.MinimumAmount()
+}
+
+func convert(amount Money, currency string, rate *big.Rat, roundUp bool) (Money, error) {
+	exp, err := CurrencyExponent(currency)
+	if err != nil {
+		return Money{}, err
+	}
+
+	// minor units of currency = amount / 10^amountExp / rate * 10^exp
+	v := new(big.Rat).SetInt64(amount.MinorUnits)
+	v.Mul(v, new(big.Rat).SetInt(pow10(exp)))
+	v.Quo(v, new(big.Rat).SetInt(pow10(amount.Exponent())))
+	v.Quo(v, rate)
+
+	units, rem := new(big.Int).QuoRem(v.Num(), v.Denom(), new(big.Int))
+	if roundUp && rem.Sign() > 0 {
+		units.Add(units, big.NewInt(1))
+	}
+	if !units.IsInt64() {
+		return Money{}, fmt.Errorf("%w: %s is more than can be paid in %s", ErrAmountOverflow, amount, currency)
+	}
+
+	return Money{MinorUnits: units.Int64(), Currency: currency}, nil
+}
+
+func formatRate(rate *big.Rat) string {
+	if rate.IsInt() {
+		return rate.Num().String()
+	}
+	return strings.TrimRight(strings.TrimRight(rate.FloatString(18), "0"), ".")
+}
+
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
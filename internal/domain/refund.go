@@ -0,0 +1,169 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrInvalidRefundID       = errors.New("invalid refund ID")
+	ErrInvalidRefundStatus   = errors.New("invalid refund status")
+	ErrPaymentNotRefundable  = errors.New("payment cannot be refunded")
+	ErrRefundExceedsCaptured = errors.New("refund amount exceeds the captured amount")
+	ErrRefundNotSupported    = errors.New("refunds are not supported for this payment method")
+	// ErrRefundSendUnknown is returned when a refund may or may not have
+	// been sent, e.g. the node did not answer the broadcast. The refund
+	// stays pending, with its transaction hash, so it is never sent twice.
+	ErrRefundSendUnknown = errors.New("refund may have been sent")
+)
+
+type RefundStatus string
+
+const (
+	RefundStatusPending   RefundStatus = "PENDING"
+	RefundStatusSucceeded RefundStatus = "SUCCEEDED"
+	RefundStatusFailed    RefundStatus = "FAILED"
+)
+
+// IsValid reports whether the status is one the service knows about.
+func (s RefundStatus) IsValid() bool {
+	return s == RefundStatusPending || s == RefundStatusSucceeded || s == RefundStatusFailed
+}
+
+// ParseRefundStatus converts a stored or transported status name into a
+// RefundStatus, rejecting unknown values.
+func ParseRefundStatus(s string) (RefundStatus, error) {
+	status := RefundStatus(s)
+	if !status.IsValid() {
+		return "", fmt.Errorf("%w: %q", ErrInvalidRefundStatus, s)
+	}
+	return status, nil
+}
+
+// Refund returns part or all of a captured payment to the customer. A
+// payment can have any number of refunds as long as their sum stays within
+// the captured amount.
+type Refund struct {
+	ID           string
+	PaymentID    string
+	Amount       Money
+	Reason       string
+	Status       RefundStatus
+	ProcessorRef string
+	ErrorMessage string
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+func NewRefund(paymentID string, amount Money, reason string) (*Refund, error) {
+	if paymentID == "" {
+		return nil, ErrInvalidPaymentID
+	}
+
+	if !amount.IsPositive() {
+		return nil, ErrInvalidAmount
+	}
+
+	now := time.Now()
+	return &Refund{
+		PaymentID: paymentID,
+		Amount:    amount,
+		Reason:    reason,
+		Status:    RefundStatusPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}, nil
+}
+
+// Succeed records that the processor accepted the refund.
+func (r *Refund) Succeed() {
+	r.Status = RefundStatusSucceeded
+	r.UpdatedAt = time.Now()
+}
+
+// Fail records that the processor rejected the refund.
+func (r *Refund) Fail(reason string) {
+	r.Status = RefundStatusFailed
+	r.ErrorMessage = reason
+	r.UpdatedAt = time.Now()
+}
+
+// IsRefundable reports whether the payment is in a state that allows refunds.
+func (p *Payment) IsRefundable() bool {
+	switch p.Status {
+	case PaymentStatusCompleted, PaymentStatusCaptured, PaymentStatusPartiallyRefunded:
+		return true
+	}
+	return false
+}
+
+// ValidateRefund checks that amount can be refunded on top of the payment's
+// existing refunds. Pending refunds count against the captured amount so two
+// refunds in flight cannot together exceed it.
+func (p *Payment) ValidateRefund(amount Money, existing []*Refund) error {
+	if !p.IsRefundable() {
+		return ErrPaymentNotRefundable
+	}
+	if !amount.IsPositive() {
+		return ErrInvalidAmount
+	}
+
+	remaining, err := p.RemainingRefundable(existing)
+	if err != nil {
+		return err
+	}
+
+	cmp, err := amount.Cmp(remaining)
+	if err != nil {
+		return err
+	}
+	if cmp > 0 {
+		return ErrRefundExceedsCaptured
+	}
+	return nil
+}
+
+// RemainingRefundable returns the captured amount minus all refunds that
+// have not failed.
+func (p *Payment) RemainingRefundable(existing []*Refund) (Money, error) {
+	remaining := p.CapturedAmount
+	for _, refund := range existing {
+		if refund.Status == RefundStatusFailed {
+			continue
+		}
+		var err error
+		if remaining, err = remaining.Sub(refund.Amount); err != nil {
+			return Money{}, err
+		}
+	}
+	return remaining, nil
+}
+
+// ApplyRefund records a successful refund of amount, moving the payment to
+// REFUNDED once the whole captured amount has been returned.
+func (p *Payment) ApplyRefund(amount Money, reason string) error {
+	refunded, err := p.RefundedAmount.Add(amount)
+	if err != nil {
+		return err
+	}
+
+	cmp, err := refunded.Cmp(p.CapturedAmount)
+	if err != nil {
+		return err
+	}
+	if cmp > 0 {
+		return ErrRefundExceedsCaptured
+	}
+
+	to := PaymentStatusPartiallyRefunded
+	if cmp == 0 {
+		to = PaymentStatusRefunded
+	}
+	if err := p.TransitionTo(to, reason); err != nil {
+		return err
+	}
+
+	p.RefundedAmount = refunded
+	return nil
+}
@@ -1,44 +1,100 @@
-package domain
-
-import "context"
-
-type PaymentRepository interface {
-	Create(ctx context.Context, payment *Payment) error
-	GetByID(ctx context.Context, id string) (*Payment, error)
-	GetByOrderID(ctx context.Context, orderID string) ([]*Payment, error)
-	GetByUserID(ctx context.Context, userID string, page, limit int) ([]*Payment, int, error)
-	Update(ctx context.Context, payment *Payment) error
-	UpdateStatus(ctx context.Context, paymentID string, status PaymentStatus) error
-}
-
-type CreditCardProcessor interface {
-	ProcessPayment(ctx context.Context, payment *Payment, cardInfo *CreditCardInfo) error
-	RefundPayment(ctx context.Context, payment *Payment) error
-	ValidateCard(ctx context.Context, cardInfo *CreditCardInfo) error
-}
-
-type MetaMaskProcessor interface {
-	InitiateTransaction(ctx context.Context, payment *Payment, walletAddress string) (*MetaMaskInfo, error)
-	VerifyTransaction(ctx context.Context, payment *Payment, transactionHash string) error
-	GetTransactionStatus(ctx context.Context, transactionHash string) (string, error)
-}
-
-type Cache interface {
-	Set(ctx context.Context, key string, value interface{}, ttl int) error
-	Get(ctx context.Context, key string) (interface{}, error)
-	Delete(ctx context.Context, key string) error
-}
-
-type EventPublisher interface {
-	PublishPaymentCreated(ctx context.Context, payment *Payment) error
-	PublishPaymentStatusUpdated(ctx context.Context, payment *Payment) error
-	PublishPaymentCompleted(ctx context.Context, payment *Payment) error
-	PublishPaymentFailed(ctx context.Context, payment *Payment) error
-	PublishPaymentRefunded(ctx context.Context, payment *Payment) error
-}
-
-type EmailNotifier interface {
-	SendPaymentConfirmation(ctx context.Context, payment *Payment) error
-	SendPaymentFailure(ctx context.Context, payment *Payment) error
-	SendRefundConfirmation(ctx context.Context, payment *Payment) error
-} 
\ No newline at end of file
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+type PaymentRepository interface {
+	Create(ctx context.Context, payment *Payment) error
+	GetByID(ctx context.Context, id string) (*Payment, error)
+	GetByOrderID(ctx context.Context, orderID string) ([]*Payment, error)
+	GetByTransactionID(ctx context.Context, transactionID string) (*Payment, error)
+	GetByUserID(ctx context.Context, userID string, page, limit int) ([]*Payment, int, error)
+	GetPendingByUserID(ctx context.Context, userID string, page, limit int) ([]*Payment, int, error)
+	// GetPendingByOrderHashes returns the pending or processing payments
+	// made with method whose order has one of the OrderHash values.
+	GetPendingByOrderHashes(ctx context.Context, method PaymentMethod, orderHashes []string) ([]*Payment, error)
+	GetAuthorizedBefore(ctx context.Context, before time.Time, limit int) ([]*Payment, error)
+	// HasPaid reports whether the user ever completed or captured a
+	// payment, including ones refunded since.
+	HasPaid(ctx context.Context, userID string) (bool, error)
+	// Update saves the payment and bumps its version. A payment that was
+	// saved by someone else since it was loaded is not overwritten; Update
+	// returns ErrPaymentConflict instead.
+	Update(ctx context.Context, payment *Payment) error
+	UpdateStatus(ctx context.Context, paymentID string, from, to PaymentStatus) error
+	// SumNetworkFees totals the network fees recorded in [from, to) per
+	// chain and currency.
+	SumNetworkFees(ctx context.Context, from, to time.Time) ([]*NetworkFeeTotal, error)
+}
+
+type RefundRepository interface {
+	Create(ctx context.Context, refund *Refund) error
+	GetByID(ctx context.Context, id string) (*Refund, error)
+	GetByPaymentID(ctx context.Context, paymentID string) ([]*Refund, error)
+	// GetUnconfirmed returns pending refunds that were already sent to the
+	// processor, i.e. have a processor reference, oldest first.
+	GetUnconfirmed(ctx context.Context, limit int) ([]*Refund, error)
+	Update(ctx context.Context, refund *Refund) error
+}
+
+type CreditCardProcessor interface {
+	// ProcessPayment and AuthorizePayment take a card token, either a
+	// provider payment method ID or a CardVault token, and record the
+	// masked card on the payment. Both return ErrActionRequired when the
+	// customer must authenticate first.
+	ProcessPayment(ctx context.Context, payment *Payment, cardToken string) error
+	// RefundPayment refunds refund.Amount of the payment and records the
+	// provider's reference on the refund.
+	RefundPayment(ctx context.Context, payment *Payment, refund *Refund) error
+	AuthorizePayment(ctx context.Context, payment *Payment, cardToken string) error
+	// ConfirmPayment checks a payment that required action once the
+	// customer has authenticated. It returns PaymentStatusCompleted for a
+	// charge or PaymentStatusAuthorized for a hold, and ErrActionRequired
+	// while authentication is still outstanding.
+	ConfirmPayment(ctx context.Context, payment *Payment) (PaymentStatus, error)
+	CapturePayment(ctx context.Context, payment *Payment, amount Money) error
+	VoidPayment(ctx context.Context, payment *Payment) error
+}
+
+// MetaMaskProcessor handles crypto payments. Every call runs on the chain
+// the payment is assigned to; payments without a chain use the default one.
+type MetaMaskProcessor interface {
+	// Chain describes a supported chain, or the default chain for ID 0.
+	Chain(chainID uint64) (*ChainInfo, error)
+	InitiateTransaction(ctx context.Context, payment *Payment, walletAddress string) (*MetaMaskInfo, error)
+	// VerifyTransaction checks that the transaction paid the payment
+	// contract at least the payment amount for the payment's order and has
+	// enough confirmations. On success it records the network fee the
+	// transaction paid on the payment.
+	VerifyTransaction(ctx context.Context, payment *Payment, transactionHash string) error
+	GetTransactionStatus(ctx context.Context, payment *Payment, transactionHash string) (string, error)
+	// RefundTransaction sends refund.Amount back to the account that paid.
+	// It signs the transfer, records its hash on the refund and calls
+	// record, which must save the refund, before broadcasting it. A
+	// broadcast that may have gone through returns ErrRefundSendUnknown.
+	// The refund is final once VerifyRefund succeeds.
+	RefundTransaction(ctx context.Context, payment *Payment, refund *Refund, record func(context.Context, *Refund) error) error
+	VerifyRefund(ctx context.Context, payment *Payment, refund *Refund) error
+}
+
+type Cache interface {
+	Set(ctx context.Context, key string, value interface{}, ttl int) error
+	Get(ctx context.Context, key string) (interface{}, error)
+	Delete(ctx context.Context, key string) error
+}
+
+type EventPublisher interface {
+	PublishPaymentCreated(ctx context.Context, payment *Payment) error
+	PublishPaymentStatusUpdated(ctx context.Context, payment *Payment) error
+	PublishPaymentCompleted(ctx context.Context, payment *Payment) error
+	PublishPaymentFailed(ctx context.Context, payment *Payment) error
+	PublishPaymentRefunded(ctx context.Context, payment *Payment, refund *Refund) error
+}
+
+type EmailNotifier interface {
+	SendPaymentConfirmation(ctx context.Context, payment *Payment) error
+	SendPaymentFailure(ctx context.Context, payment *Payment) error
+	SendRefundConfirmation(ctx context.Context, payment *Payment, refund *Refund) error
+}
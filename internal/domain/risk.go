@@ -0,0 +1,128 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrPaymentDeclinedByRisk is recorded on card payments the risk engine
+	// denied. Customers are not told which rules matched.
+	ErrPaymentDeclinedByRisk = errors.New("payment declined by risk checks")
+	ErrPaymentNotInReview    = errors.New("payment is not waiting for manual review")
+	ErrInvalidReviewer       = errors.New("invalid reviewer")
+)
+
+// RiskDecision is what happens to a card payment after it was scored.
+type RiskDecision string
+
+const (
+	RiskDecisionAllow RiskDecision = "allow"
+	// RiskDecisionReview authorizes the payment and holds it for a person
+	// to approve or reject.
+	RiskDecisionReview RiskDecision = "review"
+	RiskDecisionDeny   RiskDecision = "deny"
+)
+
+// Severity orders decisions from allow to deny.
+func (d RiskDecision) Severity() int {
+	switch d {
+	case RiskDecisionReview:
+		return 1
+	case RiskDecisionDeny:
+		return 2
+	default:
+		return 0
+	}
+}
+
+// ClientInfo is what the service knows about the client that made a
+// request. Country is an ISO 3166 alpha-2 code and may be empty.
+type ClientInfo struct {
+	IP      string
+	Country string
+}
+
+type clientInfoContextKey struct{}
+
+// WithClientInfo attaches the calling client to the context.
+func WithClientInfo(ctx context.Context, client ClientInfo) context.Context {
+	return context.WithValue(ctx, clientInfoContextKey{}, client)
+}
+
+// ClientInfoFromContext returns the client attached to the context, or the
+// zero ClientInfo.
+func ClientInfoFromContext(ctx context.Context) ClientInfo {
+	client, _ := ctx.Value(clientInfoContextKey{}).(ClientInfo)
+	return client
+}
+
+// RiskSignals is what the risk engine scores a card payment attempt on.
+type RiskSignals struct {
+	Payment *Payment
+	// Card is the card about to be charged, nil when it cannot be inspected
+	// before the charge.
+	Card *CardSummary
+	// CardKey identifies the card across payments and tokens.
+	CardKey string
+	Client  ClientInfo
+	// FirstOrder is set when the user has never paid successfully before.
+	FirstOrder bool
+}
+
+// RiskAssessment is the outcome of scoring a payment. Rules names the rules
+// that matched; it is for reviewers and never shown to customers.
+type RiskAssessment struct {
+	Score      int
+	Decision   RiskDecision
+	Rules      []string
+	AssessedAt time.Time
+}
+
+// RiskEngine scores card payments before they are charged. Record feeds the
+// outcome of an attempt back so velocity rules can count it.
+type RiskEngine interface {
+	Assess(ctx context.Context, signals RiskSignals) (*RiskAssessment, error)
+	Record(ctx context.Context, signals RiskSignals, failed bool) error
+}
+
+// CardInspector describes the card behind a card token before the card is
+// charged. The fingerprint identifies the card independently of the token.
+// ok is false for tokens that cannot be inspected.
+type CardInspector interface {
+	InspectCard(ctx context.Context, cardToken string) (card CardSummary, fingerprint string, ok bool)
+}
+
+// EventCounter counts events in sliding time windows.
+type EventCounter interface {
+	// CountEvents returns how many events were recorded under key within
+	// the last window.
+	CountEvents(ctx context.Context, key string, window time.Duration) (int, error)
+	// RecordEvent records an event under key and keeps it for retention.
+	RecordEvent(ctx context.Context, key string, retention time.Duration) error
+	// RecordDistinctEvent records an event for member under key. Only the
+	// latest event of each member is kept, so CountEvents counts distinct
+	// members.
+	RecordDistinctEvent(ctx context.Context, key, member string, retention time.Duration) error
+}
+
+// RiskDecisionRecord is an audit entry for a risk decision on a payment,
+// made either by the engine or by a reviewer.
+type RiskDecisionRecord struct {
+	ID        string
+	PaymentID string
+	UserID    string
+	Decision  RiskDecision
+	Score     int
+	Rules     []string
+	// Reviewer is who decided a held payment; empty for engine decisions.
+	Reviewer  string
+	Note      string
+	CreatedAt time.Time
+}
+
+type RiskDecisionRepository interface {
+	Save(ctx context.Context, record *RiskDecisionRecord) error
+	GetByPaymentID(ctx context.Context, paymentID string) ([]*RiskDecisionRecord, error)
+}
@@ -0,0 +1,172 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// ErrInvalidTransition is returned when a payment is asked to move to a status
+// that the transition table does not allow from its current status.
+type ErrInvalidTransition struct {
+	From PaymentStatus
+	To   PaymentStatus
+}
+
+func (e ErrInvalidTransition) Error() string {
+	return fmt.Sprintf("invalid payment status transition from %s to %s", e.From, e.To)
+}
+
+// StatusChange is a single accepted transition in a payment's history.
+type StatusChange struct {
+	From   PaymentStatus
+	To     PaymentStatus
+	Reason string
+	At     time.Time
+}
+
+// transitions lists, for every status, the statuses a payment may move to.
+// Statuses without an entry are terminal.
+var transitions = map[PaymentStatus][]PaymentStatus{
+	PaymentStatusPending: {
+		PaymentStatusProcessing,
+		PaymentStatusFailed,
+		PaymentStatusCancelled,
+	},
+	PaymentStatusProcessing: {
+		PaymentStatusCompleted,
+		PaymentStatusAuthorized,
+		PaymentStatusRequiresAction,
+		PaymentStatusManualReview,
+		PaymentStatusFailed,
+		PaymentStatusCancelled,
+	},
+	PaymentStatusRequiresAction: {
+		PaymentStatusCompleted,
+		PaymentStatusAuthorized,
+		PaymentStatusManualReview,
+		PaymentStatusFailed,
+		PaymentStatusCancelled,
+	},
+	// An approved payment is captured or stays authorized; a rejected one
+	// is voided.
+	PaymentStatusManualReview: {
+		PaymentStatusCompleted,
+		PaymentStatusAuthorized,
+		PaymentStatusVoided,
+	},
+	PaymentStatusAuthorized: {
+		PaymentStatusCaptured,
+		PaymentStatusVoided,
+	},
+	PaymentStatusCaptured: {
+		PaymentStatusPartiallyRefunded,
+		PaymentStatusRefunded,
+		PaymentStatusDisputed,
+	},
+	PaymentStatusCompleted: {
+		PaymentStatusPartiallyRefunded,
+		PaymentStatusRefunded,
+		PaymentStatusDisputed,
+	},
+	PaymentStatusPartiallyRefunded: {
+		PaymentStatusPartiallyRefunded,
+		PaymentStatusRefunded,
+		PaymentStatusDisputed,
+	},
+	// A won dispute returns the payment to where it was.
+	PaymentStatusDisputed: {
+		PaymentStatusCompleted,
+		PaymentStatusCaptured,
+		PaymentStatusPartiallyRefunded,
+		PaymentStatusChargedBack,
+	},
+	// A charge the processor reports as succeeded after the payment was
+	// given up on completes it after all.
+	PaymentStatusFailed: {
+		PaymentStatusPending,
+		PaymentStatusCompleted,
+	},
+	PaymentStatusCancelled: {
+		PaymentStatusPending,
+	},
+}
+
+// CanTransition reports whether the transition table allows moving from one
+// status to another.
+func CanTransition(from, to PaymentStatus) bool {
+	for _, allowed := range transitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// IsTerminal reports whether no further transitions are possible from the
+// status.
+func (s PaymentStatus) IsTerminal() bool {
+	return len(transitions[s]) == 0
+}
+
+// IsValid reports whether the status is one the service knows about.
+func (s PaymentStatus) IsValid() bool {
+	switch s {
+	case PaymentStatusPending,
+		PaymentStatusProcessing,
+		PaymentStatusRequiresAction,
+		PaymentStatusCompleted,
+		PaymentStatusFailed,
+		PaymentStatusCancelled,
+		PaymentStatusRefunded,
+		PaymentStatusPartiallyRefunded,
+		PaymentStatusAuthorized,
+		PaymentStatusCaptured,
+		PaymentStatusVoided,
+		PaymentStatusManualReview,
+		PaymentStatusDisputed,
+		PaymentStatusChargedBack:
+		return true
+	}
+	return false
+}
+
+// Settable reports whether another service may move a payment to the status
+// directly. Every other status is reached only through the operation that
+// collects, holds, releases or returns the money, or through review.
+func (s PaymentStatus) Settable() bool {
+	return s == PaymentStatusFailed || s == PaymentStatusCancelled
+}
+
+// ParsePaymentStatus converts a stored or transported status name into a
+// PaymentStatus, rejecting unknown values.
+func ParsePaymentStatus(s string) (PaymentStatus, error) {
+	status := PaymentStatus(s)
+	if !status.IsValid() {
+		return "", fmt.Errorf("%w: %q", ErrInvalidStatus, s)
+	}
+	return status, nil
+}
+
+// TransitionTo moves the payment to the given status if the transition table
+// allows it and records the change in the payment's history.
+func (p *Payment) TransitionTo(to PaymentStatus, reason string) error {
+	from := p.Status
+	if !CanTransition(from, to) {
+		return ErrInvalidTransition{From: from, To: to}
+	}
+
+	now := time.Now()
+	p.History = append(p.History, StatusChange{
+		From:   from,
+		To:     to,
+		Reason: reason,
+		At:     now,
+	})
+	p.Status = to
+	p.UpdatedAt = now
+	// The action is done with, whichever way it went.
+	if from == PaymentStatusRequiresAction {
+		p.NextAction = nil
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+)
+
+// ErrRateLimited is returned for card payment attempts made while the user,
+// client IP or card is locked out after tripping a velocity limit.
+type ErrRateLimited struct {
+	RetryAfter time.Duration
+}
+
+func (e ErrRateLimited) Error() string {
+	seconds := math.Ceil(e.RetryAfter.Seconds())
+	return fmt.Sprintf("too many payment attempts, retry after %s", time.Duration(seconds)*time.Second)
+}
+
+// VelocityLimiter guards card payments against card testing: many attempts,
+// many cards or many declines in a short time. Check rejects an attempt with
+// ErrRateLimited; Record feeds the outcome of an attempt back.
+type VelocityLimiter interface {
+	Check(ctx context.Context, signals RiskSignals) error
+	Record(ctx context.Context, signals RiskSignals, declined bool) error
+}
+
+// LockoutStore keeps lockouts and the strikes that lengthen them.
+type LockoutStore interface {
+	// Lockout returns how much longer key is locked out, zero when it is
+	// not.
+	Lockout(ctx context.Context, key string) (time.Duration, error)
+	LockOut(ctx context.Context, key string, d time.Duration) error
+	// AddStrike counts a lockout against key and returns the number of
+	// strikes so far. Strikes are forgotten once key goes memory without
+	// one.
+	AddStrike(ctx context.Context, key string, memory time.Duration) (int, error)
+}
@@ -0,0 +1,85 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrChallengeNotFound    = errors.New("wallet challenge not found or expired")
+	ErrInvalidSignature     = errors.New("signature was not made by the wallet")
+	ErrWalletNotVerified    = errors.New("wallet ownership has not been verified")
+	ErrInvalidSignatureType = errors.New("invalid signature scheme")
+	ErrTooManyChallenges    = errors.New("too many wallet challenges for this payment")
+)
+
+// SignatureScheme is how a wallet signs an ownership challenge.
+type SignatureScheme string
+
+const (
+	// SignatureSchemeEIP712 signs typed data with eth_signTypedData_v4.
+	SignatureSchemeEIP712 SignatureScheme = "EIP712"
+	// SignatureSchemeEIP191 signs a text message with personal_sign.
+	SignatureSchemeEIP191 SignatureScheme = "EIP191"
+)
+
+func (s SignatureScheme) IsValid() bool {
+	return s == SignatureSchemeEIP712 || s == SignatureSchemeEIP191
+}
+
+func ParseSignatureScheme(s string) (SignatureScheme, error) {
+	scheme := SignatureScheme(s)
+	if !scheme.IsValid() {
+		return "", fmt.Errorf("%w: %q", ErrInvalidSignatureType, s)
+	}
+	return scheme, nil
+}
+
+// WalletChallenge is a single-use nonce the customer signs, together with
+// the payment it is for, to prove control of WalletAddress.
+type WalletChallenge struct {
+	Nonce         string
+	Scheme        SignatureScheme
+	PaymentID     string
+	UserID        string
+	WalletAddress string
+	ChainID       uint64
+	Amount        Money
+	ExpiresAt     time.Time
+}
+
+// VerifiedWallet links a wallet to the user who proved they control it.
+type VerifiedWallet struct {
+	UserID     string
+	Address    string
+	PaymentID  string
+	Scheme     SignatureScheme
+	VerifiedAt time.Time
+}
+
+// WalletChallengeStore keeps outstanding challenges until they expire.
+// TakeChallenge removes the challenge it returns, so every nonce can be
+// answered only once, and returns ErrChallengeNotFound for unknown or
+// expired nonces.
+type WalletChallengeStore interface {
+	SaveChallenge(ctx context.Context, challenge *WalletChallenge, ttl int) error
+	TakeChallenge(ctx context.Context, nonce string) (*WalletChallenge, error)
+}
+
+type WalletRepository interface {
+	// Save links the wallet to the user; linking it again refreshes it.
+	Save(ctx context.Context, wallet *VerifiedWallet) error
+	IsVerified(ctx context.Context, userID, address string) (bool, error)
+	GetByUserID(ctx context.Context, userID string) ([]*VerifiedWallet, error)
+}
+
+// OwnershipVerifier builds what the customer signs for a challenge and
+// recovers the address that signed it.
+type OwnershipVerifier interface {
+	// SigningPayload returns the EIP-712 typed data as JSON, or the
+	// EIP-191 text message, depending on the challenge's scheme.
+	SigningPayload(challenge *WalletChallenge) (string, error)
+	RecoverSigner(challenge *WalletChallenge, signature string) (string, error)
+}
@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"context"
+	"strings"
+
+	"github.com/hsibAD/payment-service/internal/domain"
+	pb "github.com/hsibAD/payment-service/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// authorizationHeader carries the caller's access token as "Bearer <token>".
+const authorizationHeader = "authorization"
+
+// methodRoles limits RPCs to callers holding one of the listed roles. Any
+// authenticated caller may use the others; customers are limited to their
+// own payments by the service.
+var methodRoles = map[string][]domain.Role{
+	pb.PaymentService_CapturePayment_FullMethodName:      {domain.RoleService, domain.RoleAdmin},
+	pb.PaymentService_VoidPayment_FullMethodName:         {domain.RoleService, domain.RoleAdmin},
+	pb.PaymentService_UpdatePaymentStatus_FullMethodName: {domain.RoleService, domain.RoleAdmin},
+	pb.PaymentService_RefundPayment_FullMethodName:       {domain.RoleService, domain.RoleAdmin},
+	pb.PaymentService_ListRefunds_FullMethodName:         {domain.RoleService, domain.RoleAdmin},
+	pb.PaymentService_GetNetworkFeeReport_FullMethodName: {domain.RoleService, domain.RoleAdmin},
+	pb.PaymentService_ReviewPayment_FullMethodName:       {domain.RoleAdmin},
+	pb.PaymentService_GetRiskDecisions_FullMethodName:    {domain.RoleAdmin},
+}
+
+// AuthInterceptor authenticates every unary call with verifier and
+// attaches the caller to its context.
+func AuthInterceptor(verifier domain.TokenVerifier) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := authenticate(ctx, verifier, info.FullMethod)
+		if err != nil {
+			return nil, toStatusError(err)
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamAuthInterceptor is AuthInterceptor for streaming calls.
+func StreamAuthInterceptor(verifier domain.TokenVerifier) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticate(ss.Context(), verifier, info.FullMethod)
+		if err != nil {
+			return toStatusError(err)
+		}
+		return handler(srv, &authenticatedStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// authenticatedStream carries the caller in the stream's context.
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
+
+func authenticate(ctx context.Context, verifier domain.TokenVerifier, method string) (context.Context, error) {
+	token := bearerToken(ctx)
+	if token == "" {
+		return nil, domain.ErrUnauthenticated
+	}
+
+	principal, err := verifier.Verify(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	if roles, ok := methodRoles[method]; ok && !principal.HasRole(roles...) {
+		return nil, domain.ErrPermissionDenied
+	}
+	return domain.WithPrincipal(ctx, principal), nil
+}
+
+func bearerToken(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	values := md.Get(authorizationHeader)
+	if len(values) == 0 {
+		return ""
+	}
+
+	scheme, token, ok := strings.Cut(values[0], " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
@@ -0,0 +1,81 @@
+package handler_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hsibAD/payment-service/internal/domain"
+	"github.com/hsibAD/payment-service/internal/handler"
+	pb "github.com/hsibAD/payment-service/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// stubVerifier accepts the tokens it knows.
+type stubVerifier map[string]*domain.Principal
+
+func (v stubVerifier) Verify(ctx context.Context, token string) (*domain.Principal, error) {
+	principal, ok := v[token]
+	if !ok {
+		return nil, domain.ErrUnauthenticated
+	}
+	return principal, nil
+}
+
+var testVerifier = stubVerifier{
+	"customer-token": {UserID: "user-1"},
+	"service-token":  {UserID: "order-service", Roles: []domain.Role{domain.RoleService}},
+	"admin-token":    {UserID: "admin-1", Roles: []domain.Role{domain.RoleAdmin}},
+}
+
+func withToken(authorization string) context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", authorization))
+}
+
+func TestAuthInterceptor(t *testing.T) {
+	tests := []struct {
+		name          string
+		ctx           context.Context
+		method        string
+		want          codes.Code
+		wantPrincipal string
+	}{
+		{name: "no token", ctx: context.Background(), method: pb.PaymentService_GetPayment_FullMethodName, want: codes.Unauthenticated},
+		{name: "other scheme", ctx: withToken("Basic customer-token"), method: pb.PaymentService_GetPayment_FullMethodName, want: codes.Unauthenticated},
+		{name: "unknown token", ctx: withToken("Bearer forged-token"), method: pb.PaymentService_GetPayment_FullMethodName, want: codes.Unauthenticated},
+		{name: "customer", ctx: withToken("Bearer customer-token"), method: pb.PaymentService_GetPayment_FullMethodName, want: codes.OK, wantPrincipal: "user-1"},
+		{name: "lowercase scheme", ctx: withToken("bearer customer-token"), method: pb.PaymentService_GetPayment_FullMethodName, want: codes.OK, wantPrincipal: "user-1"},
+		{name: "customer refunding", ctx: withToken("Bearer customer-token"), method: pb.PaymentService_RefundPayment_FullMethodName, want: codes.PermissionDenied},
+		{name: "customer setting a status", ctx: withToken("Bearer customer-token"), method: pb.PaymentService_UpdatePaymentStatus_FullMethodName, want: codes.PermissionDenied},
+		{name: "service refunding", ctx: withToken("Bearer service-token"), method: pb.PaymentService_RefundPayment_FullMethodName, want: codes.OK, wantPrincipal: "order-service"},
+		{name: "service reviewing", ctx: withToken("Bearer service-token"), method: pb.PaymentService_ReviewPayment_FullMethodName, want: codes.PermissionDenied},
+		{name: "admin reviewing", ctx: withToken("Bearer admin-token"), method: pb.PaymentService_ReviewPayment_FullMethodName, want: codes.OK, wantPrincipal: "admin-1"},
+	}
+
+	interceptor := handler.AuthInterceptor(testVerifier)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var principal *domain.Principal
+			next := func(ctx context.Context, req interface{}) (interface{}, error) {
+				principal = domain.PrincipalFromContext(ctx)
+				return req, nil
+			}
+
+			_, err := interceptor(tt.ctx, nil, &grpc.UnaryServerInfo{FullMethod: tt.method}, next)
+			if got := status.Code(err); got != tt.want {
+				t.Fatalf("code = %s, want %s (%v)", got, tt.want, err)
+			}
+			if tt.want != codes.OK {
+				if principal != nil {
+					t.Error("handler ran for a rejected call")
+				}
+				return
+			}
+			if principal == nil || principal.UserID != tt.wantPrincipal {
+				t.Errorf("principal = %+v, want user %s", principal, tt.wantPrincipal)
+			}
+		})
+	}
+}
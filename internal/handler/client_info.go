@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"context"
+	"net"
+	"strings"
+
+	"github.com/hsibAD/payment-service/internal/domain"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+const (
+	// forwardedForHeader lists the client and the proxies a request passed
+	// through, client first.
+	forwardedForHeader = "x-forwarded-for"
+	// clientCountryHeader carries the ISO 3166 alpha-2 country the client
+	// connects from, as resolved by the edge, e.g. a CDN's geo-IP header.
+	clientCountryHeader = "x-client-country"
+)
+
+// ClientInfoInterceptor attaches the calling client to the context of every
+// unary call. The client IP is the peer address unless trustForwardedFor
+// is set, in which case it is the first X-Forwarded-For address; only set
+// it behind a proxy that overwrites that header.
+func ClientInfoInterceptor(trustForwardedFor bool) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return handler(domain.WithClientInfo(ctx, clientInfo(ctx, trustForwardedFor)), req)
+	}
+}
+
+func clientInfo(ctx context.Context, trustForwardedFor bool) domain.ClientInfo {
+	var client domain.ClientInfo
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		client.IP = p.Addr.String()
+		if host, _, err := net.SplitHostPort(client.IP); err == nil {
+			client.IP = host
+		}
+	}
+
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return client
+	}
+	if trustForwardedFor {
+		if values := md.Get(forwardedForHeader); len(values) > 0 {
+			if first := strings.TrimSpace(strings.Split(values[0], ",")[0]); first != "" {
+				client.IP = first
+			}
+		}
+	}
+	if values := md.Get(clientCountryHeader); len(values) > 0 {
+		client.Country = strings.ToUpper(strings.TrimSpace(values[0]))
+	}
+	return client
+}
@@ -0,0 +1,165 @@
+package handler
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log"
+	"strconv"
+
+	"github.com/hsibAD/payment-service/internal/domain"
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// IdempotencyConfig controls how long idempotency keys are held.
+type IdempotencyConfig struct {
+	// LockTTL bounds how long an in-flight request holds its key, in
+	// seconds, so a crashed instance cannot block a key forever.
+	LockTTL int
+	// Retention is how long completed responses are replayed, in seconds.
+	Retention int
+}
+
+// idempotent runs call at most once per caller and idempotency key. Replays
+// of the same request get the stored response; a different request under the
+// same key is rejected. Keys of different callers never meet, so one caller
+// cannot replay another's response. A key is only given up when call failed before any provider
+// acted on it; other failures are replayed like responses.
+func idempotent[T proto.Message](
+	ctx context.Context,
+	h *PaymentHandler,
+	method, key string,
+	req proto.Message,
+	call func(ctx context.Context) (T, error),
+) (T, error) {
+	var zero T
+	if key == "" || h.idempotency == nil {
+		return call(ctx)
+	}
+	if len(key) > domain.MaxIdempotencyKeyLength {
+		return zero, domain.ErrIdempotencyKeyTooLong
+	}
+
+	fingerprint, err := requestFingerprint(req)
+	if err != nil {
+		return zero, err
+	}
+
+	storeKey := method + ":" + key
+	if principal := domain.PrincipalFromContext(ctx); principal != nil {
+		// Quoted so a colon in the user ID cannot shift it into the key.
+		storeKey = method + ":" + strconv.Quote(principal.UserID) + ":" + key
+	}
+	record, err := h.idempotency.Lock(ctx, storeKey, fingerprint, h.idempotencyCfg.LockTTL)
+	if err != nil {
+		return zero, err
+	}
+	if record != nil {
+		return replay[T](record, fingerprint)
+	}
+
+	resp, err := call(domain.WithIdempotencyKey(ctx, key))
+	if err != nil && !outcomeUnknown(err) {
+		// Nothing happened, so let the client retry with the same key.
+		if releaseErr := h.idempotency.Release(ctx, storeKey); releaseErr != nil {
+			log.Printf("failed to release idempotency key %s: %v", storeKey, releaseErr)
+		}
+		return zero, err
+	}
+	if err != nil {
+		// The charge, capture or refund may have gone through; running it
+		// again could move the money twice.
+		data, marshalErr := proto.Marshal(status.Convert(toStatusError(err)).Proto())
+		if marshalErr == nil {
+			failed := &domain.IdempotencyRecord{
+				Fingerprint: fingerprint,
+				Completed:   true,
+				Error:       data,
+			}
+			marshalErr = h.idempotency.Complete(ctx, storeKey, failed, h.idempotencyCfg.Retention)
+		}
+		if marshalErr != nil {
+			log.Printf("failed to record idempotent failure for %s: %v", storeKey, marshalErr)
+		}
+		return zero, err
+	}
+
+	data, err := proto.Marshal(resp)
+	if err != nil {
+		return zero, err
+	}
+
+	completed := &domain.IdempotencyRecord{
+		Fingerprint: fingerprint,
+		Completed:   true,
+		Response:    data,
+	}
+	if err := h.idempotency.Complete(ctx, storeKey, completed, h.idempotencyCfg.Retention); err != nil {
+		log.Printf("failed to record idempotent response for %s: %v", storeKey, err)
+	}
+
+	return resp, nil
+}
+
+func replay[T proto.Message](record *domain.IdempotencyRecord, fingerprint string) (T, error) {
+	var zero T
+	if record.Fingerprint != fingerprint {
+		return zero, domain.ErrIdempotencyKeyReused
+	}
+	if !record.Completed {
+		return zero, domain.ErrIdempotencyInProgress
+	}
+	if len(record.Error) > 0 {
+		var st spb.Status
+		if err := proto.Unmarshal(record.Error, &st); err != nil {
+			return zero, err
+		}
+		return zero, status.ErrorProto(&st)
+	}
+
+	resp := zero.ProtoReflect().New().Interface().(T)
+	if err := proto.Unmarshal(record.Response, resp); err != nil {
+		return zero, err
+	}
+	return resp, nil
+}
+
+// outcomeUnknown reports whether a call failed in a way that leaves open
+// whether a provider acted on it: the provider did not answer in time, or
+// the call failed unexpectedly, possibly after the provider answered.
+func outcomeUnknown(err error) bool {
+	switch {
+	case errors.Is(err, domain.ErrGatewayTimeout):
+		return true
+	case domain.IsRetryableGatewayError(err), domain.IsCardDecline(err):
+		return false
+	}
+
+	switch status.Code(toStatusError(err)) {
+	case codes.Internal, codes.Unknown, codes.DeadlineExceeded, codes.Canceled:
+		return true
+	}
+	return false
+}
+
+// requestFingerprint hashes the request without its idempotency key, so two
+// requests match only if every other field is identical.
+func requestFingerprint(req proto.Message) (string, error) {
+	clone := proto.Clone(req)
+	msg := clone.ProtoReflect()
+	if field := msg.Descriptor().Fields().ByName("idempotency_key"); field != nil {
+		msg.Clear(field)
+	}
+
+	data, err := proto.MarshalOptions{Deterministic: true}.Marshal(clone)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -1,54 +1,494 @@
-package handler
-
-import (
-	"context"
-
-	"github.com/hsibAD/payment-service/internal/domain"
-	pb "github.com/hsibAD/payment-service/proto"
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
-)
-
-type PaymentHandler struct {
-	pb.UnimplementedPaymentServiceServer
-}
-
-func RegisterServices(s *grpc.Server, cfg interface{}) {
-	pb.RegisterPaymentServiceServer(s, &PaymentHandler{})
-}
-
-func (h *PaymentHandler) InitiatePayment(ctx context.Context, req *pb.InitiatePaymentRequest) (*pb.Payment, error) {
-	// TODO: Implement payment initiation logic
-	return nil, status.Error(codes.Unimplemented, "method InitiatePayment not implemented")
-}
-
-func (h *PaymentHandler) ProcessCreditCardPayment(ctx context.Context, req *pb.CreditCardPaymentRequest) (*pb.Payment, error) {
-	// TODO: Implement credit card payment processing logic
-	return nil, status.Error(codes.Unimplemented, "method ProcessCreditCardPayment not implemented")
-}
-
-func (h *PaymentHandler) InitiateMetaMaskPayment(ctx context.Context, req *pb.MetaMaskPaymentRequest) (*pb.MetaMaskPaymentResponse, error) {
-	// TODO: Implement MetaMask payment initiation logic
-	return nil, status.Error(codes.Unimplemented, "method InitiateMetaMaskPayment not implemented")
-}
-
-func (h *PaymentHandler) ConfirmMetaMaskPayment(ctx context.Context, req *pb.ConfirmMetaMaskPaymentRequest) (*pb.Payment, error) {
-	// TODO: Implement MetaMask payment confirmation logic
-	return nil, status.Error(codes.Unimplemented, "method ConfirmMetaMaskPayment not implemented")
-}
-
-func (h *PaymentHandler) GetPayment(ctx context.Context, req *pb.GetPaymentRequest) (*pb.Payment, error) {
-	// TODO: Implement get payment logic
-	return nil, status.Error(codes.Unimplemented, "method GetPayment not implemented")
-}
-
-func (h *PaymentHandler) GetPaymentsByOrder(ctx context.Context, req *pb.GetPaymentsByOrderRequest) (*pb.GetPaymentsByOrderResponse, error) {
-	// TODO: Implement get payments by order logic
-	return nil, status.Error(codes.Unimplemented, "method GetPaymentsByOrder not implemented")
-}
-
-func (h *PaymentHandler) GetPendingPayments(ctx context.Context, req *pb.PendingPaymentsRequest) (*pb.GetPaymentsByOrderResponse, error) {
-	// TODO: Implement get pending payments logic
-	return nil, status.Error(codes.Unimplemented, "method GetPendingPayments not implemented")
-} 
\ No newline at end of file
+package handler
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/hsibAD/payment-service/internal/domain"
+	"github.com/hsibAD/payment-service/internal/infrastructure/qr"
+	"github.com/hsibAD/payment-service/internal/mapper"
+	"github.com/hsibAD/payment-service/internal/usecase"
+	pb "github.com/hsibAD/payment-service/proto"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+type PaymentHandler struct {
+	pb.UnimplementedPaymentServiceServer
+	service        *usecase.PaymentService
+	idempotency    domain.IdempotencyStore
+	idempotencyCfg IdempotencyConfig
+}
+
+func NewPaymentHandler(service *usecase.PaymentService, idempotency domain.IdempotencyStore, idempotencyCfg IdempotencyConfig) *PaymentHandler {
+	return &PaymentHandler{
+		service:        service,
+		idempotency:    idempotency,
+		idempotencyCfg: idempotencyCfg,
+	}
+}
+
+func RegisterServices(s *grpc.Server, h *PaymentHandler) {
+	pb.RegisterPaymentServiceServer(s, h)
+}
+
+func (h *PaymentHandler) InitiatePayment(ctx context.Context, req *pb.InitiatePaymentRequest) (*pb.Payment, error) {
+	resp, err := idempotent(ctx, h, "InitiatePayment", req.GetIdempotencyKey(), req, func(ctx context.Context) (*pb.Payment, error) {
+		return h.initiatePayment(ctx, req)
+	})
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return resp, nil
+}
+
+func (h *PaymentHandler) initiatePayment(ctx context.Context, req *pb.InitiatePaymentRequest) (*pb.Payment, error) {
+	method, err := mapper.PaymentMethodFromProto(req.GetPaymentMethod())
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	amount, err := initiateAmount(req)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	// Customers pay for themselves whatever user the request names.
+	userID := req.GetUserId()
+	if principal := domain.PrincipalFromContext(ctx); principal != nil && principal.IsCustomer() {
+		userID = principal.UserID
+	}
+
+	payment, err := h.service.InitiatePayment(ctx, usecase.InitiatePaymentInput{
+		OrderID:       req.GetOrderId(),
+		UserID:        userID,
+		Amount:        amount,
+		Method:        method,
+		CustomerEmail: req.GetCustomerEmail(),
+	})
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return paymentResponse(payment)
+}
+
+func (h *PaymentHandler) ProcessCreditCardPayment(ctx context.Context, req *pb.CreditCardPaymentRequest) (*pb.Payment, error) {
+	resp, err := idempotent(ctx, h, "ProcessCreditCardPayment", req.GetIdempotencyKey(), req, func(ctx context.Context) (*pb.Payment, error) {
+		return h.processCreditCardPayment(ctx, req)
+	})
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return resp, nil
+}
+
+func (h *PaymentHandler) processCreditCardPayment(ctx context.Context, req *pb.CreditCardPaymentRequest) (*pb.Payment, error) {
+	payment, err := h.service.ProcessCreditCardPayment(ctx, req.GetPaymentId(), req.GetPaymentMethodToken())
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return paymentResponse(payment)
+}
+
+func (h *PaymentHandler) AuthorizeCreditCardPayment(ctx context.Context, req *pb.CreditCardPaymentRequest) (*pb.Payment, error) {
+	resp, err := idempotent(ctx, h, "AuthorizeCreditCardPayment", req.GetIdempotencyKey(), req, func(ctx context.Context) (*pb.Payment, error) {
+		payment, err := h.service.AuthorizeCreditCardPayment(ctx, req.GetPaymentId(), req.GetPaymentMethodToken())
+		if err != nil {
+			return nil, err
+		}
+		return paymentResponse(payment)
+	})
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return resp, nil
+}
+
+func (h *PaymentHandler) CapturePayment(ctx context.Context, req *pb.CapturePaymentRequest) (*pb.Payment, error) {
+	resp, err := idempotent(ctx, h, "CapturePayment", req.GetIdempotencyKey(), req, func(ctx context.Context) (*pb.Payment, error) {
+		var amount *domain.Money
+		if m := req.GetAmount(); m != nil {
+			parsed, err := mapper.MoneyFromProto(m)
+			if err != nil {
+				return nil, err
+			}
+			amount = &parsed
+		}
+
+		payment, err := h.service.CapturePayment(ctx, req.GetPaymentId(), amount)
+		if err != nil {
+			return nil, err
+		}
+		return paymentResponse(payment)
+	})
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return resp, nil
+}
+
+func (h *PaymentHandler) VoidPayment(ctx context.Context, req *pb.VoidPaymentRequest) (*pb.Payment, error) {
+	payment, err := h.service.VoidPayment(ctx, req.GetPaymentId(), req.GetReason())
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return paymentResponse(payment)
+}
+
+func (h *PaymentHandler) ConfirmCreditCardPayment(ctx context.Context, req *pb.ConfirmCreditCardPaymentRequest) (*pb.Payment, error) {
+	payment, err := h.service.ConfirmCreditCardPayment(ctx, req.GetPaymentId())
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return paymentResponse(payment)
+}
+
+func (h *PaymentHandler) ReviewPayment(ctx context.Context, req *pb.ReviewPaymentRequest) (*pb.Payment, error) {
+	payment, err := h.service.ReviewPayment(ctx, req.GetPaymentId(), req.GetApprove(), req.GetReviewer(), req.GetNote())
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return paymentResponse(payment)
+}
+
+func (h *PaymentHandler) GetRiskDecisions(ctx context.Context, req *pb.GetRiskDecisionsRequest) (*pb.GetRiskDecisionsResponse, error) {
+	records, err := h.service.GetRiskDecisions(ctx, req.GetPaymentId())
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return &pb.GetRiskDecisionsResponse{
+		Decisions: mapper.RiskDecisionRecordsToProto(records),
+	}, nil
+}
+
+func (h *PaymentHandler) InitiateMetaMaskPayment(ctx context.Context, req *pb.MetaMaskPaymentRequest) (*pb.MetaMaskPaymentResponse, error) {
+	info, err := h.service.InitiateMetaMaskPayment(ctx, req.GetPaymentId(), req.GetWalletAddress(), req.GetCurrency(), req.GetChainId())
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	resp := &pb.MetaMaskPaymentResponse{
+		PaymentId:        req.GetPaymentId(),
+		TransactionHash:  info.TransactionHash,
+		ContractAddress:  info.ContractAddress,
+		PaymentAmountWei: info.AmountWei,
+		Token:            mapper.TokenToProto(info.Token),
+		TokenAmount:      info.TokenAmount,
+		ApproveCallData:  info.ApproveData,
+		TransferCallData: info.TransferData,
+		Quote:            mapper.PriceQuoteToProto(info.Quote),
+		ChainId:          info.ChainID,
+		Transaction:      mapper.TransactionRequestToProto(info.Transaction),
+		PaymentUri:       info.PaymentURI,
+		Fee:              mapper.FeeEstimateToProto(info.Fee),
+	}
+
+	if info.PaymentURI != "" {
+		resp.QrCode, resp.QrCodeContentType, err = renderQRCode(info.PaymentURI, req.GetQrCodeFormat())
+		if err != nil {
+			return nil, toStatusError(err)
+		}
+	}
+
+	return resp, nil
+}
+
+// renderQRCode encodes a payment URI in the requested format; no format
+// means no code.
+func renderQRCode(uri string, format pb.QRCodeFormat) ([]byte, string, error) {
+	switch format {
+	case pb.QRCodeFormat_QR_CODE_FORMAT_UNSPECIFIED:
+		return nil, "", nil
+	case pb.QRCodeFormat_QR_CODE_FORMAT_PNG:
+		code, err := qr.PNG(uri)
+		return code, qr.ContentTypePNG, err
+	case pb.QRCodeFormat_QR_CODE_FORMAT_SVG:
+		code, err := qr.SVG(uri)
+		return code, qr.ContentTypeSVG, err
+	default:
+		return nil, "", status.Errorf(codes.InvalidArgument, "unknown QR code format %s", format)
+	}
+}
+
+func (h *PaymentHandler) ConfirmMetaMaskPayment(ctx context.Context, req *pb.ConfirmMetaMaskPaymentRequest) (*pb.Payment, error) {
+	payment, err := h.service.ConfirmMetaMaskPayment(ctx, req.GetPaymentId(), req.GetTransactionHash(), req.GetChainId())
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return paymentResponse(payment)
+}
+
+func (h *PaymentHandler) RequestWalletChallenge(ctx context.Context, req *pb.WalletChallengeRequest) (*pb.WalletChallenge, error) {
+	scheme, err := mapper.SignatureSchemeFromProto(req.GetScheme())
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	challenge, payload, err := h.service.RequestWalletChallenge(ctx, req.GetPaymentId(), req.GetWalletAddress(), scheme, req.GetChainId())
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return mapper.WalletChallengeToProto(challenge, payload), nil
+}
+
+func (h *PaymentHandler) VerifyWalletOwnership(ctx context.Context, req *pb.VerifyWalletOwnershipRequest) (*pb.Payment, error) {
+	payment, err := h.service.VerifyWalletOwnership(ctx, req.GetPaymentId(), req.GetNonce(), req.GetSignature())
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return paymentResponse(payment)
+}
+
+func (h *PaymentHandler) RefundPayment(ctx context.Context, req *pb.RefundPaymentRequest) (*pb.Refund, error) {
+	resp, err := idempotent(ctx, h, "RefundPayment", req.GetIdempotencyKey(), req, func(ctx context.Context) (*pb.Refund, error) {
+		var amount *domain.Money
+		if m := req.GetAmount(); m != nil {
+			parsed, err := mapper.MoneyFromProto(m)
+			if err != nil {
+				return nil, err
+			}
+			amount = &parsed
+		}
+
+		refund, err := h.service.RefundPayment(ctx, req.GetPaymentId(), amount, req.GetReason())
+		if err != nil {
+			return nil, err
+		}
+		return mapper.RefundToProto(refund)
+	})
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return resp, nil
+}
+
+func (h *PaymentHandler) ListRefunds(ctx context.Context, req *pb.ListRefundsRequest) (*pb.ListRefundsResponse, error) {
+	refunds, err := h.service.ListRefunds(ctx, req.GetPaymentId())
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	result, err := mapper.RefundsToProto(refunds)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return &pb.ListRefundsResponse{
+		Refunds: result,
+	}, nil
+}
+
+func (h *PaymentHandler) GetPayment(ctx context.Context, req *pb.GetPaymentRequest) (*pb.Payment, error) {
+	payment, err := h.service.GetPayment(ctx, req.GetPaymentId())
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return paymentResponse(payment)
+}
+
+func (h *PaymentHandler) GetPaymentsByOrder(ctx context.Context, req *pb.GetPaymentsByOrderRequest) (*pb.GetPaymentsByOrderResponse, error) {
+	payments, err := h.service.GetPaymentsByOrder(ctx, req.GetOrderId())
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	result, err := mapper.PaymentsToProto(payments)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return &pb.GetPaymentsByOrderResponse{
+		Payments: result,
+	}, nil
+}
+
+func (h *PaymentHandler) UpdatePaymentStatus(ctx context.Context, req *pb.UpdatePaymentStatusRequest) (*pb.Payment, error) {
+	paymentStatus, err := mapper.PaymentStatusFromProto(req.GetStatus())
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	payment, err := h.service.UpdatePaymentStatus(ctx, req.GetPaymentId(), usecase.StatusUpdate{
+		Status:       paymentStatus,
+		ErrorMessage: req.GetErrorMessage(),
+	})
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return paymentResponse(payment)
+}
+
+func (h *PaymentHandler) GetPendingPayments(ctx context.Context, req *pb.GetPendingPaymentsRequest) (*pb.GetPendingPaymentsResponse, error) {
+	payments, total, err := h.service.GetPendingPayments(ctx, req.GetUserId(), int(req.GetPage()), int(req.GetLimit()))
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	result, err := mapper.PaymentsToProto(payments)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return &pb.GetPendingPaymentsResponse{
+		Payments: result,
+		Total:    int32(total),
+	}, nil
+}
+
+func (h *PaymentHandler) RetryPayment(ctx context.Context, req *pb.RetryPaymentRequest) (*pb.Payment, error) {
+	var method domain.PaymentMethod
+	if req.GetNewPaymentMethod() != pb.PaymentMethod_PAYMENT_METHOD_UNSPECIFIED {
+		m, err := mapper.PaymentMethodFromProto(req.GetNewPaymentMethod())
+		if err != nil {
+			return nil, toStatusError(err)
+		}
+		method = m
+	}
+
+	payment, err := h.service.RetryPayment(ctx, req.GetPaymentId(), method)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return paymentResponse(payment)
+}
+
+func (h *PaymentHandler) GetNetworkFeeReport(ctx context.Context, req *pb.NetworkFeeReportRequest) (*pb.NetworkFeeReport, error) {
+	var to time.Time
+	if req.GetTo() != nil {
+		to = req.GetTo().AsTime()
+	}
+
+	totals, err := h.service.NetworkFeeReport(ctx, req.GetFrom().AsTime(), to)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return &pb.NetworkFeeReport{Totals: mapper.NetworkFeeTotalsToProto(totals)}, nil
+}
+
+func paymentResponse(payment *domain.Payment) (*pb.Payment, error) {
+	result, err := mapper.PaymentToProto(payment)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return result, nil
+}
+
+// toStatusError maps domain errors onto gRPC status codes. Anything that is
+// not a known domain error is reported as an internal error.
+func toStatusError(err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	var transitionErr domain.ErrInvalidTransition
+	if errors.As(err, &transitionErr) {
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+
+	// Clients are told when to retry through a RetryInfo detail.
+	var rateLimitErr domain.ErrRateLimited
+	if errors.As(err, &rateLimitErr) {
+		st := status.New(codes.ResourceExhausted, err.Error())
+		detailed, detailErr := st.WithDetails(&errdetails.RetryInfo{
+			RetryDelay: durationpb.New(rateLimitErr.RetryAfter),
+		})
+		if detailErr != nil {
+			return st.Err()
+		}
+		return detailed.Err()
+	}
+
+	switch {
+	case errors.Is(err, domain.ErrInvalidPaymentID),
+		errors.Is(err, domain.ErrCardTokenNotFound),
+		errors.Is(err, domain.ErrInvalidRefundID),
+		errors.Is(err, domain.ErrChallengeNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, domain.ErrInvalidOrderID),
+		errors.Is(err, domain.ErrInvalidUserID),
+		errors.Is(err, domain.ErrInvalidAmount),
+		errors.Is(err, domain.ErrInvalidCurrency),
+		errors.Is(err, domain.ErrUnknownCurrency),
+		errors.Is(err, domain.ErrCurrencyMismatch),
+		errors.Is(err, domain.ErrInvalidPrecision),
+		errors.Is(err, domain.ErrAmountOverflow),
+		errors.Is(err, domain.ErrInvalidPaymentMethod),
+		errors.Is(err, domain.ErrInvalidStatus),
+		errors.Is(err, domain.ErrStatusNotSettable),
+		errors.Is(err, domain.ErrInvalidCardInfo),
+		errors.Is(err, domain.ErrInvalidWalletAddress),
+		errors.Is(err, domain.ErrIdempotencyKeyTooLong),
+		errors.Is(err, domain.ErrCaptureExceedsAuthorization),
+		errors.Is(err, domain.ErrInvalidCardToken),
+		errors.Is(err, domain.ErrRefundExceedsCaptured),
+		errors.Is(err, domain.ErrPaymentLogNotFound),
+		errors.Is(err, domain.ErrWrongPaymentContract),
+		errors.Is(err, domain.ErrPaymentOrderMismatch),
+		errors.Is(err, domain.ErrPaymentUnderpaid),
+		errors.Is(err, domain.ErrPaymentTokenMismatch),
+		errors.Is(err, domain.ErrUnsupportedChain),
+		errors.Is(err, domain.ErrWrongChain),
+		errors.Is(err, domain.ErrInvalidSignature),
+		errors.Is(err, domain.ErrInvalidSignatureType),
+		errors.Is(err, domain.ErrInvalidReportPeriod),
+		errors.Is(err, domain.ErrInvalidReviewer):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, domain.ErrIdempotencyKeyReused),
+		errors.Is(err, domain.ErrTransactionAlreadyUsed):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, domain.ErrIdempotencyInProgress),
+		errors.Is(err, domain.ErrPaymentConflict):
+		return status.Error(codes.Aborted, err.Error())
+	case errors.Is(err, domain.ErrPaymentNotPending),
+		errors.Is(err, domain.ErrPaymentNotRetryable),
+		errors.Is(err, domain.ErrPaymentMethodMismatch),
+		errors.Is(err, domain.ErrPaymentNotRefundable),
+		errors.Is(err, domain.ErrCurrencyNotPayable),
+		errors.Is(err, domain.ErrQuoteExpired),
+		errors.Is(err, domain.ErrTransactionSubmitted),
+		errors.Is(err, domain.ErrWalletNotVerified),
+		errors.Is(err, domain.ErrPaymentNotAwaitingAction),
+		errors.Is(err, domain.ErrPaymentNotInReview):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, domain.ErrUnauthenticated):
+		return status.Error(codes.Unauthenticated, err.Error())
+	case errors.Is(err, domain.ErrPermissionDenied):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, domain.ErrTooManyChallenges):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	case errors.Is(err, domain.ErrRefundNotSupported):
+		return status.Error(codes.Unimplemented, err.Error())
+	case errors.Is(err, domain.ErrInsufficientConfirmations),
+		errors.Is(err, domain.ErrTransactionNotMined),
+		errors.Is(err, domain.ErrPriceUnavailable):
+		return status.Error(codes.Unavailable, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
+
+// initiateAmount reads the exact money field, falling back to the deprecated
+// floating point amount for clients that have not migrated yet.
+func initiateAmount(req *pb.InitiatePaymentRequest) (domain.Money, error) {
+	if m := req.GetMoney(); m != nil {
+		return mapper.MoneyFromProto(m)
+	}
+	return domain.MoneyFromFloat(req.GetAmount(), req.GetCurrency())
+}
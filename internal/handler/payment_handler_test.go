@@ -0,0 +1,124 @@
+package handler_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/hsibAD/payment-service/internal/domain"
+	"github.com/hsibAD/payment-service/internal/handler"
+	"github.com/hsibAD/payment-service/internal/repository/memory"
+	"github.com/hsibAD/payment-service/internal/usecase"
+	pb "github.com/hsibAD/payment-service/proto"
+)
+
+// memoryIdempotency is an in-memory domain.IdempotencyStore that ignores
+// TTLs.
+type memoryIdempotency struct {
+	mu      sync.Mutex
+	records map[string]*domain.IdempotencyRecord
+}
+
+func (s *memoryIdempotency) Lock(ctx context.Context, key, fingerprint string, ttl int) (*domain.IdempotencyRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if record, ok := s.records[key]; ok {
+		return record, nil
+	}
+	if s.records == nil {
+		s.records = make(map[string]*domain.IdempotencyRecord)
+	}
+	s.records[key] = &domain.IdempotencyRecord{Fingerprint: fingerprint}
+	return nil, nil
+}
+
+func (s *memoryIdempotency) Complete(ctx context.Context, key string, record *domain.IdempotencyRecord, ttl int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records[key] = record
+	return nil
+}
+
+func (s *memoryIdempotency) Release(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.records, key)
+	return nil
+}
+
+func newPaymentHandler() *handler.PaymentHandler {
+	service := usecase.NewPaymentService(
+		memory.NewPaymentRepository(), memory.NewRefundRepository(), nil, nil,
+		noCache{}, &recordingPublisher{}, noNotifier{}, nil,
+		usecase.QuotePolicy{}, usecase.WalletProof{},
+		memory.NewProcessedEventRepository(), usecase.RiskControls{},
+	)
+	return handler.NewPaymentHandler(service, &memoryIdempotency{}, handler.IdempotencyConfig{LockTTL: 30, Retention: 3600})
+}
+
+func asCaller(userID string, roles ...domain.Role) context.Context {
+	return domain.WithPrincipal(context.Background(), &domain.Principal{UserID: userID, Roles: roles})
+}
+
+func initiateRequest(userID, idempotencyKey string) *pb.InitiatePaymentRequest {
+	return &pb.InitiatePaymentRequest{
+		OrderId:        "order-1",
+		UserId:         userID,
+		Money:          &pb.Money{MinorUnits: 2500, Currency: "USD"},
+		PaymentMethod:  pb.PaymentMethod_PAYMENT_METHOD_CREDIT_CARD,
+		IdempotencyKey: idempotencyKey,
+	}
+}
+
+func TestInitiatePaymentPaysForTheCustomer(t *testing.T) {
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want string
+	}{
+		{name: "customer naming another user", ctx: asCaller("user-1"), want: "user-1"},
+		{name: "customer role naming another user", ctx: asCaller("user-1", domain.RoleCustomer), want: "user-1"},
+		{name: "service on behalf of a user", ctx: asCaller("order-service", domain.RoleService), want: "user-2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newPaymentHandler()
+
+			payment, err := h.InitiatePayment(tt.ctx, initiateRequest("user-2", ""))
+			if err != nil {
+				t.Fatalf("InitiatePayment: %v", err)
+			}
+			if payment.GetUserId() != tt.want {
+				t.Errorf("payment of user %q, want %q", payment.GetUserId(), tt.want)
+			}
+		})
+	}
+}
+
+func TestIdempotencyKeysAreScopedToTheCaller(t *testing.T) {
+	h := newPaymentHandler()
+
+	first, err := h.InitiatePayment(asCaller("user-1"), initiateRequest("user-1", "key-1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	replayed, err := h.InitiatePayment(asCaller("user-1"), initiateRequest("user-1", "key-1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replayed.GetId() != first.GetId() {
+		t.Errorf("replay created payment %s, want %s again", replayed.GetId(), first.GetId())
+	}
+
+	// Another customer reusing the key gets their own payment, not a
+	// replay of the first customer's.
+	other, err := h.InitiatePayment(asCaller("user-2"), initiateRequest("user-2", "key-1"))
+	if err != nil {
+		t.Fatalf("InitiatePayment of another customer: %v", err)
+	}
+	if other.GetId() == first.GetId() || other.GetUserId() != "user-2" {
+		t.Errorf("other customer got payment %s of %s", other.GetId(), other.GetUserId())
+	}
+}
@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/hsibAD/payment-service/internal/domain"
+	"github.com/hsibAD/payment-service/internal/usecase"
+)
+
+// maxWebhookBodySize bounds webhook deliveries; Stripe events are a few
+// kilobytes.
+const maxWebhookBodySize = 1 << 16
+
+// CardEventParser verifies a processor webhook delivery and decodes the
+// event it carries, returning nil for events the service ignores.
+type CardEventParser interface {
+	ParseEvent(payload []byte, signature string) (*domain.CardEvent, error)
+}
+
+// StripeWebhookHandler receives Stripe webhook deliveries over HTTP.
+// Anything but a 2xx response makes Stripe retry the delivery, so only
+// failures to apply a genuine event return 500.
+type StripeWebhookHandler struct {
+	parser  CardEventParser
+	service *usecase.PaymentService
+}
+
+func NewStripeWebhookHandler(parser CardEventParser, service *usecase.PaymentService) *StripeWebhookHandler {
+	return &StripeWebhookHandler{
+		parser:  parser,
+		service: service,
+	}
+}
+
+func (h *StripeWebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	payload, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookBodySize))
+	if err != nil {
+		http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	event, err := h.parser.ParseEvent(payload, r.Header.Get("Stripe-Signature"))
+	if err != nil {
+		if !errors.Is(err, domain.ErrInvalidEventSignature) {
+			log.Printf("stripe webhook: %v", err)
+		}
+		http.Error(w, "invalid event", http.StatusBadRequest)
+		return
+	}
+	if event == nil {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if err := h.service.HandleCardEvent(r.Context(), event); err != nil {
+		log.Printf("stripe webhook: failed to apply event %s: %v", event.ID, err)
+		http.Error(w, "failed to apply event", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,435 @@
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hsibAD/payment-service/internal/domain"
+	"github.com/hsibAD/payment-service/internal/handler"
+	"github.com/hsibAD/payment-service/internal/infrastructure/payment"
+	"github.com/hsibAD/payment-service/internal/repository/memory"
+	"github.com/hsibAD/payment-service/internal/usecase"
+	"github.com/stripe/stripe-go/v74/webhook"
+)
+
+const testWebhookSecret = "whsec_test"
+
+type webhookTest struct {
+	handler   *handler.StripeWebhookHandler
+	payments  *memory.PaymentRepository
+	refunds   *memory.RefundRepository
+	events    *memory.ProcessedEventRepository
+	publisher *recordingPublisher
+}
+
+func newWebhookTest() *webhookTest {
+	wt := &webhookTest{
+		payments:  memory.NewPaymentRepository(),
+		refunds:   memory.NewRefundRepository(),
+		events:    memory.NewProcessedEventRepository(),
+		publisher: &recordingPublisher{},
+	}
+	service := usecase.NewPaymentService(
+		wt.payments, wt.refunds, nil, nil,
+		noCache{}, wt.publisher, noNotifier{}, nil,
+		usecase.QuotePolicy{}, usecase.WalletProof{},
+		wt.events, usecase.RiskControls{},
+	)
+	wt.handler = handler.NewStripeWebhookHandler(payment.NewStripeWebhook(testWebhookSecret, 0), service)
+	return wt
+}
+
+// completedPayment stores a card payment of 25.00 USD charged as PaymentIntent
+// txID.
+func (wt *webhookTest) completedPayment(t *testing.T, txID string) *domain.Payment {
+	t.Helper()
+
+	p := wt.processingPayment(t)
+	if err := p.MarkAsCompleted(txID); err != nil {
+		t.Fatal(err)
+	}
+	if err := wt.payments.Update(context.Background(), p); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func (wt *webhookTest) processingPayment(t *testing.T) *domain.Payment {
+	t.Helper()
+
+	ctx := context.Background()
+	p, err := domain.NewPayment("order-1", "user-1", domain.Money{MinorUnits: 2500, Currency: "USD"}, domain.PaymentMethodCreditCard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := wt.payments.Create(ctx, p); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.MarkAsProcessing(); err != nil {
+		t.Fatal(err)
+	}
+	if err := wt.payments.Update(ctx, p); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+// deliver posts a Stripe event signed with the endpoint secret and returns
+// the response status.
+func (wt *webhookTest) deliver(t *testing.T, id, eventType string, object map[string]interface{}) int {
+	t.Helper()
+
+	payload, err := json.Marshal(map[string]interface{}{
+		"id":          id,
+		"object":      "event",
+		"api_version": "2022-11-15",
+		"created":     time.Now().Unix(),
+		"type":        eventType,
+		"data":        map[string]interface{}{"object": object},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	signature := fmt.Sprintf("t=%d,v1=%x", now.Unix(), webhook.ComputeSignature(now, payload, testWebhookSecret))
+	return wt.post(payload, signature)
+}
+
+func (wt *webhookTest) post(payload []byte, signature string) int {
+	req := httptest.NewRequest(http.MethodPost, "/webhooks/stripe", bytes.NewReader(payload))
+	req.Header.Set("Stripe-Signature", signature)
+	rec := httptest.NewRecorder()
+	wt.handler.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func (wt *webhookTest) status(t *testing.T, paymentID string) domain.PaymentStatus {
+	t.Helper()
+
+	p, err := wt.payments.GetByID(context.Background(), paymentID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p.Status
+}
+
+func paymentIntent(id, paymentID string) map[string]interface{} {
+	return map[string]interface{}{
+		"id":              id,
+		"object":          "payment_intent",
+		"amount":          2500,
+		"amount_received": 2500,
+		"currency":        "usd",
+		"metadata":        map[string]string{"payment_id": paymentID},
+	}
+}
+
+func dispute(status, paymentIntent string) map[string]interface{} {
+	return map[string]interface{}{
+		"id":             "dp_1",
+		"object":         "dispute",
+		"amount":         2500,
+		"currency":       "usd",
+		"payment_intent": paymentIntent,
+		"reason":         "fraudulent",
+		"status":         status,
+	}
+}
+
+func TestStripeWebhookCompletesSucceededPayment(t *testing.T) {
+	wt := newWebhookTest()
+	p := wt.processingPayment(t)
+
+	if code := wt.deliver(t, "evt_1", "payment_intent.succeeded", paymentIntent("pi_1", p.ID)); code != http.StatusOK {
+		t.Fatalf("status code = %d, want %d", code, http.StatusOK)
+	}
+
+	stored, err := wt.payments.GetByID(context.Background(), p.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Status != domain.PaymentStatusCompleted {
+		t.Errorf("payment status = %s, want %s", stored.Status, domain.PaymentStatusCompleted)
+	}
+	if stored.TransactionID != "pi_1" {
+		t.Errorf("transaction ID = %q, want pi_1", stored.TransactionID)
+	}
+	if got := wt.publisher.count("completed"); got != 1 {
+		t.Errorf("published %d completed events, want 1", got)
+	}
+}
+
+func TestStripeWebhookCompletesFailedPaymentCharged(t *testing.T) {
+	tests := []struct {
+		name   string
+		intent string
+		want   domain.PaymentStatus
+	}{
+		{name: "same intent", intent: "pi_1", want: domain.PaymentStatusCompleted},
+		{name: "other intent", intent: "pi_2", want: domain.PaymentStatusFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wt := newWebhookTest()
+			p := wt.processingPayment(t)
+			p.TransactionID = "pi_1"
+			if err := p.SetError("payment failed at processor"); err != nil {
+				t.Fatal(err)
+			}
+			if err := wt.payments.Update(context.Background(), p); err != nil {
+				t.Fatal(err)
+			}
+
+			if code := wt.deliver(t, "evt_1", "payment_intent.succeeded", paymentIntent(tt.intent, p.ID)); code != http.StatusOK {
+				t.Fatalf("status code = %d, want %d", code, http.StatusOK)
+			}
+			if got := wt.status(t, p.ID); got != tt.want {
+				t.Errorf("payment status = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStripeWebhookRecordsDashboardRefund(t *testing.T) {
+	wt := newWebhookTest()
+	p := wt.completedPayment(t, "pi_1")
+
+	code := wt.deliver(t, "evt_1", "charge.refunded", map[string]interface{}{
+		"id":              "ch_1",
+		"object":          "charge",
+		"amount":          2500,
+		"amount_refunded": 1000,
+		"currency":        "usd",
+		"payment_intent":  "pi_1",
+	})
+	if code != http.StatusOK {
+		t.Fatalf("status code = %d, want %d", code, http.StatusOK)
+	}
+
+	stored, err := wt.payments.GetByID(context.Background(), p.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Status != domain.PaymentStatusPartiallyRefunded {
+		t.Errorf("payment status = %s, want %s", stored.Status, domain.PaymentStatusPartiallyRefunded)
+	}
+	if want := (domain.Money{MinorUnits: 1000, Currency: "USD"}); stored.RefundedAmount != want {
+		t.Errorf("refunded amount = %v, want %v", stored.RefundedAmount, want)
+	}
+
+	refunds, err := wt.refunds.GetByPaymentID(context.Background(), p.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(refunds) != 1 || refunds[0].Status != domain.RefundStatusSucceeded || refunds[0].ProcessorRef != "ch_1" {
+		t.Errorf("refunds = %+v, want one succeeded refund for ch_1", refunds)
+	}
+	if got := wt.publisher.count("refunded"); got != 1 {
+		t.Errorf("published %d refunded events, want 1", got)
+	}
+}
+
+func TestStripeWebhookAppliesDisputes(t *testing.T) {
+	tests := []struct {
+		closedStatus string
+		want         domain.PaymentStatus
+	}{
+		{closedStatus: "won", want: domain.PaymentStatusCompleted},
+		{closedStatus: "lost", want: domain.PaymentStatusChargedBack},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.closedStatus, func(t *testing.T) {
+			wt := newWebhookTest()
+			p := wt.completedPayment(t, "pi_1")
+
+			if code := wt.deliver(t, "evt_1", "charge.dispute.created", dispute("needs_response", "pi_1")); code != http.StatusOK {
+				t.Fatalf("status code = %d, want %d", code, http.StatusOK)
+			}
+			if got := wt.status(t, p.ID); got != domain.PaymentStatusDisputed {
+				t.Fatalf("payment status = %s, want %s", got, domain.PaymentStatusDisputed)
+			}
+
+			if code := wt.deliver(t, "evt_2", "charge.dispute.closed", dispute(tt.closedStatus, "pi_1")); code != http.StatusOK {
+				t.Fatalf("status code = %d, want %d", code, http.StatusOK)
+			}
+			if got := wt.status(t, p.ID); got != tt.want {
+				t.Errorf("payment status = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStripeWebhookAppliesEventsOnce(t *testing.T) {
+	wt := newWebhookTest()
+	p := wt.completedPayment(t, "pi_1")
+
+	wt.deliver(t, "evt_1", "charge.dispute.created", dispute("needs_response", "pi_1"))
+	wt.deliver(t, "evt_2", "charge.dispute.closed", dispute("won", "pi_1"))
+
+	// A redelivery of the opening event must not reopen the won dispute.
+	if code := wt.deliver(t, "evt_1", "charge.dispute.created", dispute("needs_response", "pi_1")); code != http.StatusOK {
+		t.Fatalf("status code = %d, want %d", code, http.StatusOK)
+	}
+	if got := wt.status(t, p.ID); got != domain.PaymentStatusCompleted {
+		t.Errorf("payment status = %s, want %s", got, domain.PaymentStatusCompleted)
+	}
+}
+
+func TestStripeWebhookReclaimsEventsOfDeadDeliveries(t *testing.T) {
+	tests := []struct {
+		name  string
+		lease time.Duration
+		want  domain.PaymentStatus
+	}{
+		// The delivery that claimed the event may still apply it.
+		{name: "lease running", lease: time.Hour, want: domain.PaymentStatusProcessing},
+		{name: "lease ran out", lease: 0, want: domain.PaymentStatusCompleted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wt := newWebhookTest()
+			p := wt.processingPayment(t)
+
+			// A delivery claimed the event and died before applying it.
+			if _, err := wt.events.Claim(context.Background(), "evt_1", domain.CardEventPaymentSucceeded, tt.lease); err != nil {
+				t.Fatal(err)
+			}
+
+			if code := wt.deliver(t, "evt_1", "payment_intent.succeeded", paymentIntent("pi_1", p.ID)); code != http.StatusOK {
+				t.Fatalf("status code = %d, want %d", code, http.StatusOK)
+			}
+			if got := wt.status(t, p.ID); got != tt.want {
+				t.Errorf("payment status = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStripeWebhookAcknowledgesDisputeOfRefundedPayment(t *testing.T) {
+	wt := newWebhookTest()
+	p := wt.completedPayment(t, "pi_1")
+	wt.deliver(t, "evt_1", "charge.refunded", map[string]interface{}{
+		"id":              "ch_1",
+		"object":          "charge",
+		"amount":          2500,
+		"amount_refunded": 2500,
+		"currency":        "usd",
+		"payment_intent":  "pi_1",
+	})
+	if got := wt.status(t, p.ID); got != domain.PaymentStatusRefunded {
+		t.Fatalf("payment status = %s, want %s", got, domain.PaymentStatusRefunded)
+	}
+
+	// Retrying could never apply it, so Stripe must not be asked to.
+	if code := wt.deliver(t, "evt_2", "charge.dispute.created", dispute("needs_response", "pi_1")); code != http.StatusOK {
+		t.Fatalf("status code = %d, want %d", code, http.StatusOK)
+	}
+	if got := wt.status(t, p.ID); got != domain.PaymentStatusRefunded {
+		t.Errorf("payment status = %s, want %s", got, domain.PaymentStatusRefunded)
+	}
+}
+
+func TestStripeWebhookRejectsBadSignature(t *testing.T) {
+	wt := newWebhookTest()
+	p := wt.processingPayment(t)
+
+	payload, err := json.Marshal(map[string]interface{}{
+		"id":     "evt_1",
+		"object": "event",
+		"type":   "payment_intent.succeeded",
+		"data":   map[string]interface{}{"object": paymentIntent("pi_1", p.ID)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	signature := fmt.Sprintf("t=%d,v1=%x", now.Unix(), webhook.ComputeSignature(now, payload, "whsec_other"))
+
+	if code := wt.post(payload, signature); code != http.StatusBadRequest {
+		t.Fatalf("status code = %d, want %d", code, http.StatusBadRequest)
+	}
+	if got := wt.status(t, p.ID); got != domain.PaymentStatusProcessing {
+		t.Errorf("payment status = %s, want %s", got, domain.PaymentStatusProcessing)
+	}
+}
+
+// recordingPublisher counts the events published per kind.
+type recordingPublisher struct {
+	mu     sync.Mutex
+	events map[string]int
+}
+
+func (p *recordingPublisher) record(kind string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.events == nil {
+		p.events = make(map[string]int)
+	}
+	p.events[kind]++
+	return nil
+}
+
+func (p *recordingPublisher) count(kind string) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.events[kind]
+}
+
+func (p *recordingPublisher) PublishPaymentCreated(ctx context.Context, payment *domain.Payment) error {
+	return p.record("created")
+}
+
+func (p *recordingPublisher) PublishPaymentStatusUpdated(ctx context.Context, payment *domain.Payment) error {
+	return p.record("status updated")
+}
+
+func (p *recordingPublisher) PublishPaymentCompleted(ctx context.Context, payment *domain.Payment) error {
+	return p.record("completed")
+}
+
+func (p *recordingPublisher) PublishPaymentFailed(ctx context.Context, payment *domain.Payment) error {
+	return p.record("failed")
+}
+
+func (p *recordingPublisher) PublishPaymentRefunded(ctx context.Context, payment *domain.Payment, refund *domain.Refund) error {
+	return p.record("refunded")
+}
+
+type noNotifier struct{}
+
+func (noNotifier) SendPaymentConfirmation(ctx context.Context, payment *domain.Payment) error {
+	return nil
+}
+
+func (noNotifier) SendPaymentFailure(ctx context.Context, payment *domain.Payment) error {
+	return nil
+}
+
+func (noNotifier) SendRefundConfirmation(ctx context.Context, payment *domain.Payment, refund *domain.Refund) error {
+	return nil
+}
+
+// noCache never holds anything, so every read goes to the repository.
+type noCache struct{}
+
+func (noCache) Set(ctx context.Context, key string, value interface{}, ttl int) error {
+	return nil
+}
+
+func (noCache) Get(ctx context.Context, key string) (interface{}, error) {
+	return nil, nil
+}
+
+func (noCache) Delete(ctx context.Context, key string) error {
+	return nil
+}
@@ -0,0 +1,95 @@
+package auth_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/hsibAD/payment-service/internal/infrastructure/auth"
+)
+
+func jwk(kid string, key *rsa.PublicKey) map[string]string {
+	return map[string]string{
+		"kty": "RSA",
+		"kid": kid,
+		"use": "sig",
+		"alg": "RS256",
+		"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+func jwks(t *testing.T, keys ...map[string]string) []byte {
+	t.Helper()
+
+	data, err := json.Marshal(map[string]interface{}{"keys": keys})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestParseJWKS(t *testing.T) {
+	keyA, keyB := rsaKey(t), rsaKey(t)
+	short, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encryption := jwk("enc", &keyB.PublicKey)
+	encryption["use"] = "enc"
+	otherAlg := jwk("ps", &keyB.PublicKey)
+	otherAlg["alg"] = "PS256"
+	ec := map[string]string{"kty": "EC", "kid": "ec", "crv": "P-256", "x": "AA", "y": "AA"}
+	badExponent := jwk("bad", &keyB.PublicKey)
+	badExponent["e"] = "!"
+
+	tests := []struct {
+		name     string
+		data     []byte
+		wantKids []string
+		wantErr  bool
+	}{
+		{name: "signing keys", data: jwks(t, jwk("a", &keyA.PublicKey), jwk("b", &keyB.PublicKey)), wantKids: []string{"a", "b"}},
+		{name: "skips other keys", data: jwks(t, jwk("a", &keyA.PublicKey), encryption, otherAlg, ec), wantKids: []string{"a"}},
+		{name: "only other keys", data: jwks(t, encryption, ec), wantErr: true},
+		{name: "duplicate kid", data: jwks(t, jwk("a", &keyA.PublicKey), jwk("a", &keyB.PublicKey)), wantErr: true},
+		{name: "short modulus", data: jwks(t, jwk("short", &short.PublicKey)), wantErr: true},
+		{name: "invalid exponent", data: jwks(t, badExponent), wantErr: true},
+		{name: "not JSON", data: []byte("keys"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys, err := auth.ParseJWKS(tt.data)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ParseJWKS accepted %s", tt.data)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseJWKS: %v", err)
+			}
+			if len(keys) != len(tt.wantKids) {
+				t.Errorf("parsed %d keys, want %v", len(keys), tt.wantKids)
+			}
+			for _, kid := range tt.wantKids {
+				if _, ok := keys[kid]; !ok {
+					t.Errorf("key %q missing", kid)
+				}
+			}
+		})
+	}
+
+	keys, err := auth.ParseJWKS(jwks(t, jwk("a", &keyA.PublicKey)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !keys["a"].Equal(&keyA.PublicKey) {
+		t.Error("parsed key differs from the published one")
+	}
+}
@@ -0,0 +1,123 @@
+package auth
+
+import (
+	"context"
+	"crypto/rsa"
+	"fmt"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/hsibAD/payment-service/internal/domain"
+)
+
+// Config holds the keys access tokens may be signed with and the claims
+// they must carry. Tokens are HS256 signed with Secret or RS256 signed with
+// one of RSAKeys, by key ID; either may be left out but not both.
+type Config struct {
+	Secret  []byte
+	RSAKeys map[string]*rsa.PublicKey
+	// Issuer and Audience, when set, must match the token's iss and aud.
+	Issuer   string
+	Audience string
+	// RolesClaim names the claim holding the caller's roles, either a
+	// list or a space separated string. The user ID is always sub.
+	RolesClaim string
+}
+
+// JWTVerifier implements domain.TokenVerifier for JSON Web Tokens.
+type JWTVerifier struct {
+	cfg    Config
+	parser *jwt.Parser
+}
+
+func NewJWTVerifier(cfg Config) (*JWTVerifier, error) {
+	var methods []string
+	if len(cfg.Secret) > 0 {
+		methods = append(methods, jwt.SigningMethodHS256.Alg())
+	}
+	if len(cfg.RSAKeys) > 0 {
+		methods = append(methods, jwt.SigningMethodRS256.Alg())
+	}
+	if len(methods) == 0 {
+		return nil, fmt.Errorf("no keys to verify access tokens with")
+	}
+	if cfg.RolesClaim == "" {
+		cfg.RolesClaim = "roles"
+	}
+
+	return &JWTVerifier{
+		cfg:    cfg,
+		parser: jwt.NewParser(jwt.WithValidMethods(methods)),
+	}, nil
+}
+
+// Verify checks the token's signature, expiry, issuer and audience. Tokens
+// without an expiry or a subject are rejected.
+func (v *JWTVerifier) Verify(ctx context.Context, token string) (*domain.Principal, error) {
+	claims := jwt.MapClaims{}
+	if _, err := v.parser.ParseWithClaims(token, claims, v.key); err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrUnauthenticated, err)
+	}
+
+	if _, ok := claims["exp"]; !ok {
+		return nil, fmt.Errorf("%w: token has no expiry", domain.ErrUnauthenticated)
+	}
+	if v.cfg.Issuer != "" && !claims.VerifyIssuer(v.cfg.Issuer, true) {
+		return nil, fmt.Errorf("%w: unexpected issuer", domain.ErrUnauthenticated)
+	}
+	if v.cfg.Audience != "" && !claims.VerifyAudience(v.cfg.Audience, true) {
+		return nil, fmt.Errorf("%w: unexpected audience", domain.ErrUnauthenticated)
+	}
+
+	subject, _ := claims["sub"].(string)
+	if subject == "" {
+		return nil, fmt.Errorf("%w: token has no subject", domain.ErrUnauthenticated)
+	}
+
+	return &domain.Principal{
+		UserID: subject,
+		Roles:  roles(claims[v.cfg.RolesClaim]),
+	}, nil
+}
+
+// key picks the key the token must be signed with; RS256 tokens name
+// theirs with kid unless the key set holds a single key.
+func (v *JWTVerifier) key(token *jwt.Token) (interface{}, error) {
+	switch token.Method.Alg() {
+	case jwt.SigningMethodHS256.Alg():
+		return v.cfg.Secret, nil
+	case jwt.SigningMethodRS256.Alg():
+		kid, _ := token.Header["kid"].(string)
+		if key, ok := v.cfg.RSAKeys[kid]; ok {
+			return key, nil
+		}
+		if kid == "" && len(v.cfg.RSAKeys) == 1 {
+			for _, key := range v.cfg.RSAKeys {
+				return key, nil
+			}
+		}
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	default:
+		return nil, fmt.Errorf("unexpected signing method %s", token.Method.Alg())
+	}
+}
+
+func roles(claim interface{}) []domain.Role {
+	var names []string
+	switch v := claim.(type) {
+	case string:
+		names = strings.Fields(v)
+	case []interface{}:
+		for _, name := range v {
+			if s, ok := name.(string); ok {
+				names = append(names, s)
+			}
+		}
+	}
+
+	roles := make([]domain.Role, 0, len(names))
+	for _, name := range names {
+		roles = append(roles, domain.Role(strings.ToLower(name)))
+	}
+	return roles
+}
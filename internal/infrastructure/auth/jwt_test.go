@@ -0,0 +1,212 @@
+package auth_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/hsibAD/payment-service/internal/domain"
+	"github.com/hsibAD/payment-service/internal/infrastructure/auth"
+)
+
+var testSecret = []byte("test-secret")
+
+func rsaKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+// claims are valid for a customer, expiring in an hour.
+func claims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"sub":   "user-1",
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"iss":   "https://auth.example.com",
+		"aud":   "payments",
+		"roles": []string{"customer"},
+	}
+}
+
+// sign signs claims with method and key, naming the key kid when set.
+func sign(t *testing.T, method jwt.SigningMethod, kid string, key interface{}, claims jwt.MapClaims) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func verifier(t *testing.T, cfg auth.Config) *auth.JWTVerifier {
+	t.Helper()
+
+	v, err := auth.NewJWTVerifier(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func TestJWTVerifierChecksClaims(t *testing.T) {
+	v := verifier(t, auth.Config{
+		Secret:   testSecret,
+		Issuer:   "https://auth.example.com",
+		Audience: "payments",
+	})
+
+	tests := []struct {
+		name    string
+		edit    func(jwt.MapClaims)
+		want    *domain.Principal
+		wantErr bool
+	}{
+		{
+			name: "valid",
+			edit: func(c jwt.MapClaims) {},
+			want: &domain.Principal{UserID: "user-1", Roles: []domain.Role{domain.RoleCustomer}},
+		},
+		{
+			name: "space separated roles",
+			edit: func(c jwt.MapClaims) { c["roles"] = "Service admin" },
+			want: &domain.Principal{UserID: "user-1", Roles: []domain.Role{domain.RoleService, domain.RoleAdmin}},
+		},
+		{name: "expired", edit: func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Minute).Unix() }, wantErr: true},
+		{name: "no expiry", edit: func(c jwt.MapClaims) { delete(c, "exp") }, wantErr: true},
+		{name: "no subject", edit: func(c jwt.MapClaims) { delete(c, "sub") }, wantErr: true},
+		{name: "other issuer", edit: func(c jwt.MapClaims) { c["iss"] = "https://evil.example.com" }, wantErr: true},
+		{name: "other audience", edit: func(c jwt.MapClaims) { c["aud"] = "orders" }, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := claims()
+			tt.edit(c)
+
+			principal, err := v.Verify(context.Background(), sign(t, jwt.SigningMethodHS256, "", testSecret, c))
+			if tt.wantErr {
+				if !errors.Is(err, domain.ErrUnauthenticated) {
+					t.Errorf("Verify error = %v, want %v", err, domain.ErrUnauthenticated)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Verify: %v", err)
+			}
+			if !reflect.DeepEqual(principal, tt.want) {
+				t.Errorf("principal = %+v, want %+v", principal, tt.want)
+			}
+		})
+	}
+}
+
+func TestJWTVerifierSelectsKeyByKid(t *testing.T) {
+	keyA, keyB := rsaKey(t), rsaKey(t)
+	both := map[string]*rsa.PublicKey{"a": &keyA.PublicKey, "b": &keyB.PublicKey}
+	onlyA := map[string]*rsa.PublicKey{"a": &keyA.PublicKey}
+
+	tests := []struct {
+		name    string
+		keys    map[string]*rsa.PublicKey
+		kid     string
+		signer  *rsa.PrivateKey
+		wantErr bool
+	}{
+		{name: "named key", keys: both, kid: "b", signer: keyB},
+		{name: "signed with another key than named", keys: both, kid: "a", signer: keyB, wantErr: true},
+		{name: "unknown key", keys: both, kid: "c", signer: keyA, wantErr: true},
+		{name: "no kid with several keys", keys: both, signer: keyA, wantErr: true},
+		{name: "no kid with a single key", keys: onlyA, signer: keyA},
+		{name: "no kid signed with another key", keys: onlyA, signer: keyB, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := verifier(t, auth.Config{RSAKeys: tt.keys})
+
+			_, err := v.Verify(context.Background(), sign(t, jwt.SigningMethodRS256, tt.kid, tt.signer, claims()))
+			if tt.wantErr && !errors.Is(err, domain.ErrUnauthenticated) {
+				t.Errorf("Verify error = %v, want %v", err, domain.ErrUnauthenticated)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("Verify: %v", err)
+			}
+		})
+	}
+}
+
+// An attacker who knows the RSA public key signs an HS256 token with it as
+// the secret; the verifier must not accept the public key as an HMAC key.
+func TestJWTVerifierRejectsAlgorithmConfusion(t *testing.T) {
+	key := rsaKey(t)
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+	keys := map[string]*rsa.PublicKey{"a": &key.PublicKey}
+
+	tests := []struct {
+		name  string
+		cfg   auth.Config
+		token string
+	}{
+		{
+			name:  "HS256 with the public key, RSA keys only",
+			cfg:   auth.Config{RSAKeys: keys},
+			token: sign(t, jwt.SigningMethodHS256, "a", publicPEM, claims()),
+		},
+		{
+			name:  "HS256 with the public key, secret and RSA keys",
+			cfg:   auth.Config{Secret: testSecret, RSAKeys: keys},
+			token: sign(t, jwt.SigningMethodHS256, "a", publicPEM, claims()),
+		},
+		{
+			name:  "HS256 with the secret, RSA keys only",
+			cfg:   auth.Config{RSAKeys: keys},
+			token: sign(t, jwt.SigningMethodHS256, "", testSecret, claims()),
+		},
+		{
+			name:  "RS256, secret only",
+			cfg:   auth.Config{Secret: testSecret},
+			token: sign(t, jwt.SigningMethodRS256, "a", key, claims()),
+		},
+		{
+			name:  "unsigned",
+			cfg:   auth.Config{Secret: testSecret, RSAKeys: keys},
+			token: sign(t, jwt.SigningMethodNone, "", jwt.UnsafeAllowNoneSignatureType, claims()),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := verifier(t, tt.cfg)
+
+			if _, err := v.Verify(context.Background(), tt.token); !errors.Is(err, domain.ErrUnauthenticated) {
+				t.Errorf("Verify error = %v, want %v", err, domain.ErrUnauthenticated)
+			}
+		})
+	}
+}
+
+func TestNewJWTVerifierNeedsAKey(t *testing.T) {
+	if _, err := auth.NewJWTVerifier(auth.Config{}); err == nil {
+		t.Error("NewJWTVerifier accepted a config without keys")
+	}
+}
@@ -0,0 +1,168 @@
+package bin
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/hsibAD/payment-service/internal/domain"
+)
+
+// binRange covers the card numbers whose first len(start) digits lie
+// between start and end inclusive.
+type binRange struct {
+	start, end string
+	info       domain.BINInfo
+}
+
+// Table is a BIN range table. When ranges of several prefix lengths match
+// a card number, the longest prefix wins.
+type Table struct {
+	// ranges holds the ranges of every prefix length, longest first, each
+	// sorted by start.
+	ranges [][]binRange
+}
+
+// LoadTable reads a CSV file with a header row and the columns bin_start,
+// bin_end, brand, funding and country, e.g.
+//
+//	bin_start,bin_end,brand,funding,country
+//	424242,424242,visa,credit,US
+//
+// Both ends of a range have the same number of digits, from 4 to 11, and
+// ranges of the same length must not overlap.
+func LoadTable(path string) (*Table, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read BIN table: %w", err)
+	}
+	defer f.Close()
+
+	return ParseTable(f)
+}
+
+// ParseTable reads a BIN table in the format LoadTable expects.
+func ParseTable(r io.Reader) (*Table, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 5
+	reader.TrimLeadingSpace = true
+
+	if _, err := reader.Read(); err != nil {
+		return nil, fmt.Errorf("failed to read BIN table header: %w", err)
+	}
+
+	byLength := make(map[int][]binRange)
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse BIN table: %w", err)
+		}
+
+		rng, err := parseRange(record)
+		if err != nil {
+			line, _ := reader.FieldPos(0)
+			return nil, fmt.Errorf("BIN table line %d: %w", line, err)
+		}
+		byLength[len(rng.start)] = append(byLength[len(rng.start)], rng)
+	}
+
+	lengths := make([]int, 0, len(byLength))
+	for length := range byLength {
+		lengths = append(lengths, length)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(lengths)))
+
+	t := &Table{}
+	for _, length := range lengths {
+		ranges := byLength[length]
+		sort.Slice(ranges, func(i, j int) bool { return ranges[i].start < ranges[j].start })
+		for i := 1; i < len(ranges); i++ {
+			if ranges[i].start <= ranges[i-1].end {
+				return nil, fmt.Errorf("BIN ranges %s-%s and %s-%s overlap",
+					ranges[i-1].start, ranges[i-1].end, ranges[i].start, ranges[i].end)
+			}
+		}
+		t.ranges = append(t.ranges, ranges)
+	}
+
+	return t, nil
+}
+
+// Lookup implements domain.BINLookup.
+func (t *Table) Lookup(number string) (domain.BINInfo, bool) {
+	number = strings.NewReplacer(" ", "", "-", "").Replace(number)
+
+	for _, ranges := range t.ranges {
+		length := len(ranges[0].start)
+		if len(number) < length {
+			continue
+		}
+		prefix := number[:length]
+
+		// The candidate is the last range starting at or before prefix.
+		i := sort.Search(len(ranges), func(i int) bool { return ranges[i].start > prefix }) - 1
+		if i >= 0 && prefix <= ranges[i].end {
+			return ranges[i].info, true
+		}
+	}
+
+	return domain.BINInfo{}, false
+}
+
+func parseRange(record []string) (binRange, error) {
+	start, end := strings.TrimSpace(record[0]), strings.TrimSpace(record[1])
+	if len(start) < 4 || len(start) > 11 || len(end) != len(start) || !isDigits(start) || !isDigits(end) {
+		return binRange{}, fmt.Errorf("invalid BIN range %q-%q", start, end)
+	}
+	if end < start {
+		return binRange{}, fmt.Errorf("BIN range %s-%s ends before it starts", start, end)
+	}
+
+	brand := domain.CardBrand(strings.ToLower(strings.TrimSpace(record[2])))
+	switch brand {
+	case domain.CardBrandVisa, domain.CardBrandMastercard, domain.CardBrandAmex, domain.CardBrandDiscover,
+		domain.CardBrandJCB, domain.CardBrandDiners, domain.CardBrandUnionPay, domain.CardBrandUnknown:
+	default:
+		return binRange{}, fmt.Errorf("unknown card brand %q", record[2])
+	}
+
+	funding := domain.CardFunding(strings.ToLower(strings.TrimSpace(record[3])))
+	switch funding {
+	case domain.CardFundingCredit, domain.CardFundingDebit, domain.CardFundingPrepaid, domain.CardFundingUnknown:
+	case "":
+		funding = domain.CardFundingUnknown
+	default:
+		return binRange{}, fmt.Errorf("unknown funding type %q", record[3])
+	}
+
+	country := strings.ToUpper(strings.TrimSpace(record[4]))
+	if country != "" && len(country) != 2 {
+		return binRange{}, fmt.Errorf("invalid country %q", record[4])
+	}
+
+	return binRange{
+		start: start,
+		end:   end,
+		info: domain.BINInfo{
+			Brand:   brand,
+			Funding: funding,
+			Country: country,
+		},
+	}, nil
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
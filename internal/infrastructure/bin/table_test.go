@@ -0,0 +1,133 @@
+package bin_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hsibAD/payment-service/internal/domain"
+	"github.com/hsibAD/payment-service/internal/infrastructure/bin"
+)
+
+const testTable = `bin_start,bin_end,brand,funding,country
+4000,4999,visa,credit,
+400005,400005,Visa,debit,us
+4000056,4000056,visa,prepaid,GB
+510000,519999,mastercard,,
+`
+
+func parse(t *testing.T, csv string) *bin.Table {
+	t.Helper()
+
+	table, err := bin.ParseTable(strings.NewReader(csv))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return table
+}
+
+func TestTableLookup(t *testing.T) {
+	table := parse(t, testTable)
+
+	tests := []struct {
+		name   string
+		number string
+		want   domain.BINInfo
+		found  bool
+	}{
+		{
+			name:   "short range",
+			number: "4111111111111111",
+			want:   domain.BINInfo{Brand: domain.CardBrandVisa, Funding: domain.CardFundingCredit},
+			found:  true,
+		},
+		{
+			name:   "six digits win over four",
+			number: "4000051111111111",
+			want:   domain.BINInfo{Brand: domain.CardBrandVisa, Funding: domain.CardFundingDebit, Country: "US"},
+			found:  true,
+		},
+		{
+			name:   "seven digits win over six",
+			number: "4000056655665556",
+			want:   domain.BINInfo{Brand: domain.CardBrandVisa, Funding: domain.CardFundingPrepaid, Country: "GB"},
+			found:  true,
+		},
+		{
+			name:   "spaces and dashes",
+			number: "4000 0566-5566 5556",
+			want:   domain.BINInfo{Brand: domain.CardBrandVisa, Funding: domain.CardFundingPrepaid, Country: "GB"},
+			found:  true,
+		},
+		{
+			name:   "start of range",
+			number: "5100000000000000",
+			want:   domain.BINInfo{Brand: domain.CardBrandMastercard, Funding: domain.CardFundingUnknown},
+			found:  true,
+		},
+		{
+			name:   "end of range",
+			number: "5199999999999999",
+			want:   domain.BINInfo{Brand: domain.CardBrandMastercard, Funding: domain.CardFundingUnknown},
+			found:  true,
+		},
+		{name: "after range", number: "5200000000000000"},
+		{name: "before every range", number: "3782822463100005"},
+		{name: "shorter than every prefix", number: "411"},
+		{name: "empty", number: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, found := table.Lookup(tt.number)
+			if found != tt.found {
+				t.Fatalf("found = %v, want %v", found, tt.found)
+			}
+			if got != tt.want {
+				t.Errorf("info = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTableRejects(t *testing.T) {
+	const header = "bin_start,bin_end,brand,funding,country\n"
+
+	tests := []struct {
+		name string
+		csv  string
+	}{
+		{name: "empty file", csv: ""},
+		{name: "too few columns", csv: header + "424242,424242,visa,credit\n"},
+		{name: "prefix too short", csv: header + "424,424,visa,credit,US\n"},
+		{name: "prefix too long", csv: header + "424242424242,424242424242,visa,credit,US\n"},
+		{name: "ends of different length", csv: header + "42424,424242,visa,credit,US\n"},
+		{name: "not digits", csv: header + "4242a2,424242,visa,credit,US\n"},
+		{name: "ends before it starts", csv: header + "424243,424242,visa,credit,US\n"},
+		{name: "unknown brand", csv: header + "424242,424242,maestro,credit,US\n"},
+		{name: "unknown funding", csv: header + "424242,424242,visa,charge,US\n"},
+		{name: "invalid country", csv: header + "424242,424242,visa,credit,USA\n"},
+		{name: "overlapping ranges", csv: header + "510000,519999,mastercard,credit,\n515000,515999,mastercard,debit,\n"},
+		{name: "ranges sharing an end", csv: header + "510000,515000,mastercard,credit,\n515000,519999,mastercard,debit,\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := bin.ParseTable(strings.NewReader(tt.csv)); err == nil {
+				t.Error("ParseTable accepted the table")
+			}
+		})
+	}
+}
+
+func TestLoadTableExample(t *testing.T) {
+	table, err := bin.LoadTable("../../../bins.example.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info, found := table.Lookup("4242424242424242"); !found || info.Brand != domain.CardBrandVisa {
+		t.Errorf("Lookup(4242…) = %+v, %v, want a visa card", info, found)
+	}
+	if _, err := bin.LoadTable("missing.csv"); err == nil {
+		t.Error("LoadTable read a missing file")
+	}
+}
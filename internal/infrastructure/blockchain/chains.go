@@ -0,0 +1,91 @@
+package blockchain
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/hsibAD/payment-service/internal/domain"
+)
+
+// Chain is an EVM chain crypto payments are accepted on, with the node the
+// service talks to and the payment contract deployed there.
+type Chain struct {
+	ID               uint64 `json:"chain_id"`
+	Name             string `json:"name"`
+	RPCURL           string `json:"rpc_url"`
+	ContractAddress  string `json:"contract_address"`
+	MinConfirmations uint64 `json:"min_confirmations"`
+	NativeCurrency   string `json:"native_currency"`
+	// Default marks the chain used for payments that do not name one.
+	Default bool `json:"default"`
+}
+
+func (c Chain) Info() *domain.ChainInfo {
+	return &domain.ChainInfo{
+		ID:             c.ID,
+		Name:           c.Name,
+		NativeCurrency: c.NativeCurrency,
+	}
+}
+
+func (c Chain) validate() error {
+	if c.ID == 0 {
+		return fmt.Errorf("chain %q has no chain ID", c.Name)
+	}
+	if c.RPCURL == "" {
+		return fmt.Errorf("chain %d has no RPC URL", c.ID)
+	}
+	if !common.IsHexAddress(c.ContractAddress) {
+		return fmt.Errorf("chain %d has an invalid contract address %q", c.ID, c.ContractAddress)
+	}
+	if !domain.IsCryptoCurrency(c.NativeCurrency) {
+		return fmt.Errorf("chain %d has an unknown native currency %q", c.ID, c.NativeCurrency)
+	}
+	return nil
+}
+
+// LoadChains reads a JSON array of chains. Exactly one chain may be marked
+// default; if none is, the first one is.
+func LoadChains(path string) ([]Chain, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read chains: %w", err)
+	}
+
+	var chains []Chain
+	if err := json.Unmarshal(data, &chains); err != nil {
+		return nil, fmt.Errorf("failed to parse chains: %w", err)
+	}
+	if len(chains) == 0 {
+		return nil, fmt.Errorf("no chains in %s", path)
+	}
+
+	seen := make(map[uint64]bool, len(chains))
+	defaults := 0
+	for i := range chains {
+		chains[i].NativeCurrency = strings.ToUpper(chains[i].NativeCurrency)
+		if err := chains[i].validate(); err != nil {
+			return nil, err
+		}
+		if seen[chains[i].ID] {
+			return nil, fmt.Errorf("chain %d is listed twice", chains[i].ID)
+		}
+		seen[chains[i].ID] = true
+		if chains[i].Default {
+			defaults++
+		}
+	}
+
+	switch defaults {
+	case 0:
+		chains[0].Default = true
+	case 1:
+	default:
+		return nil, fmt.Errorf("%d chains are marked default, expected one", defaults)
+	}
+
+	return chains, nil
+}
@@ -1,223 +1,891 @@
-package blockchain
-
-import (
-	"context"
-	"errors"
-	"fmt"
-	"math/big"
-
-	"github.com/ethereum/go-ethereum"
-	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/ethclient"
-	"github.com/hsibAD/payment-service/internal/domain"
-)
-
-var (
-	ErrInvalidWalletAddress = errors.New("invalid wallet address")
-	ErrInvalidTransaction   = errors.New("invalid transaction")
-	ErrTransactionFailed   = errors.New("transaction failed")
-)
-
-type MetaMaskProcessor struct {
-	client         *ethclient.Client
-	contractAddr   common.Address
-	contractABI    string
-	minConfirmations uint64
-}
-
-func NewMetaMaskProcessor(ethNodeURL, contractAddress, contractABI string, minConfirmations uint64) (*MetaMaskProcessor, error) {
-	client, err := ethclient.Dial(ethNodeURL)
-	if err != nil {
-		return nil, err
-	}
-
-	return &MetaMaskProcessor{
-		client:         client,
-		contractAddr:   common.HexToAddress(contractAddress),
-		contractABI:    contractABI,
-		minConfirmations: minConfirmations,
-	}, nil
-}
-
-func (p *MetaMaskProcessor) InitiateTransaction(ctx context.Context, payment *domain.Payment, walletAddress string) (*domain.MetaMaskInfo, error) {
-	if !common.IsHexAddress(walletAddress) {
-		return nil, ErrInvalidWalletAddress
-	}
-
-	// Convert payment amount to Wei
-	amountWei := p.convertToWei(payment.Amount)
-
-	// Create MetaMask info with transaction details
-	info := &domain.MetaMaskInfo{
-		WalletAddress:   walletAddress,
-		ContractAddress: p.contractAddr.Hex(),
-		AmountWei:      amountWei.String(),
-	}
-
-	return info, nil
-}
-
-func (p *MetaMaskProcessor) VerifyTransaction(ctx context.Context, payment *domain.Payment, transactionHash string) error {
-	if len(transactionHash) != 66 || transactionHash[:2] != "0x" {
-		return ErrInvalidTransaction
-	}
-
-	txHash := common.HexToHash(transactionHash)
-
-	// Get transaction receipt
-	receipt, err := p.client.TransactionReceipt(ctx, txHash)
-	if err != nil {
-		if err == ethereum.NotFound {
-			return ErrInvalidTransaction
-		}
-		return err
-	}
-
-	// Check if transaction was successful
-	if receipt.Status != types.ReceiptStatusSuccessful {
-		return ErrTransactionFailed
-	}
-
-	// Get current block number
-	currentBlock, err := p.client.BlockNumber(ctx)
-	if err != nil {
-		return err
-	}
-
-	// Check confirmations
-	confirmations := currentBlock - receipt.BlockNumber.Uint64()
-	if confirmations < p.minConfirmations {
-		return errors.New("insufficient confirmations")
-	}
-
-	return nil
-}
-
-func (p *MetaMaskProcessor) GetTransactionStatus(ctx context.Context, transactionHash string) (string, error) {
-	if len(transactionHash) != 66 || transactionHash[:2] != "0x" {
-		return "", ErrInvalidTransaction
-	}
-
-	txHash := common.HexToHash(transactionHash)
-
-	// Get transaction receipt
-	receipt, err := p.client.TransactionReceipt(ctx, txHash)
-	if err != nil {
-		if err == ethereum.NotFound {
-			return "PENDING", nil
-		}
-		return "", err
-	}
-
-	// Get current block number
-	currentBlock, err := p.client.BlockNumber(ctx)
-	if err != nil {
-		return "", err
-	}
-
-	// Check confirmations
-	confirmations := currentBlock - receipt.BlockNumber.Uint64()
-
-	if receipt.Status != types.ReceiptStatusSuccessful {
-		return "FAILED", nil
-	}
-
-	if confirmations < p.minConfirmations {
-		return "CONFIRMING", nil
-	}
-
-	return "CONFIRMED", nil
-}
-
-func (p *MetaMaskProcessor) convertToWei(amount float64) *big.Int {
-	// Convert amount to Wei (1 ETH = 10^18 Wei)
-	amountStr := big.NewFloat(amount)
-	multiplier := big.NewFloat(1e18)
-	amountStr.Mul(amountStr, multiplier)
-
-	amountWei := new(big.Int)
-	amountStr.Int(amountWei)
-
-	return amountWei
-}
-
-// Smart Contract Interface
-const PaymentContractABI = `[
-	{
-		"inputs": [],
-		"stateMutability": "nonpayable",
-		"type": "constructor"
-	},
-	{
-		"anonymous": false,
-		"inputs": [
-			{
-				"indexed": true,
-				"internalType": "string",
-				"name": "orderID",
-				"type": "string"
-			},
-			{
-				"indexed": true,
-				"internalType": "address",
-				"name": "payer",
-				"type": "address"
-			},
-			{
-				"indexed": false,
-				"internalType": "uint256",
-				"name": "amount",
-				"type": "uint256"
-			}
-		],
-		"name": "PaymentReceived",
-		"type": "event"
-	},
-	{
-		"inputs": [
-			{
-				"internalType": "string",
-				"name": "orderID",
-				"type": "string"
-			}
-		],
-		"name": "makePayment",
-		"outputs": [],
-		"stateMutability": "payable",
-		"type": "function"
-	},
-	{
-		"inputs": [],
-		"name": "owner",
-		"outputs": [
-			{
-				"internalType": "address",
-				"name": "",
-				"type": "address"
-			}
-		],
-		"stateMutability": "view",
-		"type": "function"
-	},
-	{
-		"inputs": [
-			{
-				"internalType": "address",
-				"name": "newOwner",
-				"type": "address"
-			}
-		],
-		"name": "transferOwnership",
-		"outputs": [],
-		"stateMutability": "nonpayable",
-		"type": "function"
-	},
-	{
-		"inputs": [],
-		"name": "withdraw",
-		"outputs": [],
-		"stateMutability": "nonpayable",
-		"type": "function"
-	}
-]` 
\ No newline at end of file
+package blockchain
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/hsibAD/payment-service/internal/domain"
+)
+
+var (
+	ErrInvalidWalletAddress = domain.ErrInvalidWalletAddress
+	ErrInvalidTransaction   = errors.New("invalid transaction")
+	ErrTransactionNotMined  = domain.ErrTransactionNotMined
+	ErrTransactionFailed    = domain.ErrTransactionFailed
+	ErrNoRefundWallet       = fmt.Errorf("%w: no refund wallet configured", domain.ErrRefundNotSupported)
+
+	ErrPaymentLogNotFound   = domain.ErrPaymentLogNotFound
+	ErrWrongPaymentContract = domain.ErrWrongPaymentContract
+	ErrPaymentOrderMismatch = domain.ErrPaymentOrderMismatch
+	ErrPaymentUnderpaid     = domain.ErrPaymentUnderpaid
+	ErrPaymentTokenMismatch = domain.ErrPaymentTokenMismatch
+	ErrQuoteExpired         = domain.ErrQuoteExpired
+	ErrWrongChain           = domain.ErrWrongChain
+)
+
+const (
+	paymentReceivedEvent      = "PaymentReceived"
+	tokenPaymentReceivedEvent = "TokenPaymentReceived"
+	makePaymentMethod         = "makePayment"
+	makeTokenPaymentMethod    = "makeTokenPayment"
+)
+
+// Backend is the part of the Ethereum client API the processor needs. Both
+// *ethclient.Client and go-ethereum's simulated backend client satisfy it.
+type Backend interface {
+	ethereum.BlockNumberReader
+	ethereum.ChainReader
+	ethereum.ChainIDReader
+	ethereum.GasEstimator
+	ethereum.GasPricer1559
+	ethereum.LogFilterer
+	ethereum.PendingStateReader
+	ethereum.TransactionReader
+	ethereum.TransactionSender
+}
+
+// MetaMaskProcessor handles crypto payments on a single chain; see
+// MultiChainProcessor for serving several.
+type MetaMaskProcessor struct {
+	client           Backend
+	chain            Chain
+	contractAddr     common.Address
+	contractABI      abi.ABI
+	tokenABI         abi.ABI
+	tokens           *TokenRegistry
+	minConfirmations uint64
+
+	chainIDMu sync.Mutex
+	chainID   *big.Int
+
+	// refundKey signs refund transactions; nil disables on-chain refunds.
+	// refundMu serializes refunds so concurrent sends never reuse a nonce.
+	refundKey *ecdsa.PrivateKey
+	refundMu  sync.Mutex
+}
+
+func NewMetaMaskProcessor(chain Chain, contractABI string, tokens *TokenRegistry, refundKey *ecdsa.PrivateKey) (*MetaMaskProcessor, error) {
+	client, err := ethclient.Dial(chain.RPCURL)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewMetaMaskProcessorWithBackend(client, chain, contractABI, tokens, refundKey)
+}
+
+// NewMetaMaskProcessorWithBackend creates a processor on top of an existing
+// client, such as a simulated backend in tests. A chain without an ID takes
+// the ID the node reports.
+func NewMetaMaskProcessorWithBackend(client Backend, chain Chain, contractABI string, tokens *TokenRegistry, refundKey *ecdsa.PrivateKey) (*MetaMaskProcessor, error) {
+	parsedABI, err := abi.JSON(strings.NewReader(contractABI))
+	if err != nil {
+		return nil, fmt.Errorf("invalid contract ABI: %w", err)
+	}
+	if _, ok := parsedABI.Events[paymentReceivedEvent]; !ok {
+		return nil, fmt.Errorf("contract ABI has no %s event", paymentReceivedEvent)
+	}
+
+	tokenABI, err := abi.JSON(strings.NewReader(ERC20ABI))
+	if err != nil {
+		return nil, fmt.Errorf("invalid ERC-20 ABI: %w", err)
+	}
+
+	return &MetaMaskProcessor{
+		client:           client,
+		chain:            chain,
+		contractAddr:     common.HexToAddress(chain.ContractAddress),
+		contractABI:      parsedABI,
+		tokenABI:         tokenABI,
+		tokens:           tokens,
+		minConfirmations: chain.MinConfirmations,
+		refundKey:        refundKey,
+	}, nil
+}
+
+// LoadRefundKey decrypts the hot wallet key used to send refunds from a
+// go-ethereum keystore file.
+func LoadRefundKey(keystorePath, passphrase string) (*ecdsa.PrivateKey, error) {
+	keyJSON, err := os.ReadFile(keystorePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keystore: %w", err)
+	}
+
+	key, err := keystore.DecryptKey(keyJSON, passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt keystore: %w", err)
+	}
+
+	return key.PrivateKey, nil
+}
+
+// Chain describes the processor's chain, which is also its default.
+func (p *MetaMaskProcessor) Chain(chainID uint64) (*domain.ChainInfo, error) {
+	p.chainIDMu.Lock()
+	defer p.chainIDMu.Unlock()
+
+	if chainID != 0 && chainID != p.chain.ID {
+		return nil, fmt.Errorf("%w: %d", domain.ErrUnsupportedChain, chainID)
+	}
+	return p.chain.Info(), nil
+}
+
+func (p *MetaMaskProcessor) InitiateTransaction(ctx context.Context, payment *domain.Payment, walletAddress string) (*domain.MetaMaskInfo, error) {
+	if !common.IsHexAddress(walletAddress) {
+		return nil, ErrInvalidWalletAddress
+	}
+
+	chainID, err := p.checkChain(ctx, payment)
+	if err != nil {
+		return nil, err
+	}
+
+	payable := payment.PayableAmount()
+	token, err := p.paymentToken(ctx, payable.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &domain.MetaMaskInfo{
+		WalletAddress:   walletAddress,
+		ContractAddress: p.contractAddr.Hex(),
+		ChainID:         chainID,
+	}
+
+	if token == nil {
+		value := p.convertToWei(payable)
+		data, err := p.contractABI.Pack(makePaymentMethod, payment.OrderID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode payment call: %w", err)
+		}
+
+		gas := p.estimatePaymentGas(ctx, common.HexToAddress(walletAddress), value, data)
+		fee, err := p.estimateFee(ctx, gas)
+		if err != nil {
+			return nil, err
+		}
+
+		info.AmountWei = value.String()
+		info.PaymentData = hexutil.Encode(data)
+		info.Fee = fee
+		info.Transaction = &domain.TransactionRequest{
+			From:                 common.HexToAddress(walletAddress).Hex(),
+			To:                   p.contractAddr.Hex(),
+			Value:                value.String(),
+			Data:                 info.PaymentData,
+			ChainID:              chainID,
+			Gas:                  gas,
+			MaxPriorityFeePerGas: fee.MaxPriorityFeePerGas,
+			MaxFeePerGas:         fee.MaxFeePerGas,
+		}
+		info.PaymentURI = PaymentURI(p.contractAddr, chainID, payment.OrderID, value, gas)
+		return info, nil
+	}
+
+	// The wallet approves the payment contract to spend the amount, then
+	// calls makeTokenPayment, which pulls the tokens and emits the event
+	// VerifyTransaction looks for.
+	amount := payable.ScaledTo(token.Decimals)
+
+	approveData, err := p.tokenABI.Pack("approve", p.contractAddr, amount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode approve call: %w", err)
+	}
+	transferData, err := p.contractABI.Pack(makeTokenPaymentMethod, payment.OrderID, token.Address, amount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode token payment call: %w", err)
+	}
+
+	info.Token = &domain.TokenInfo{
+		Symbol:   token.Symbol,
+		Address:  token.Address.Hex(),
+		Decimals: token.Decimals,
+	}
+	info.TokenAmount = amount.String()
+	info.ApproveData = hexutil.Encode(approveData)
+	info.TransferData = hexutil.Encode(transferData)
+
+	// The gas of the token payment cannot be estimated before the approval
+	// is mined, so the wallet estimates it.
+	fee, err := p.estimateFee(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+	info.Fee = fee
+	info.Transaction = &domain.TransactionRequest{
+		From:                 common.HexToAddress(walletAddress).Hex(),
+		To:                   p.contractAddr.Hex(),
+		Value:                "0",
+		Data:                 info.TransferData,
+		ChainID:              chainID,
+		MaxPriorityFeePerGas: fee.MaxPriorityFeePerGas,
+		MaxFeePerGas:         fee.MaxFeePerGas,
+	}
+
+	return info, nil
+}
+
+// suggestFees returns the current base fee and the EIP-1559 tip and fee
+// caps for a new transaction.
+func (p *MetaMaskProcessor) suggestFees(ctx context.Context) (baseFee, tipCap, feeCap *big.Int, err error) {
+	tipCap, err = p.client.SuggestGasTipCap(ctx)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to suggest gas tip: %w", err)
+	}
+
+	head, err := p.client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	baseFee = head.BaseFee
+	if baseFee == nil {
+		return nil, nil, nil, fmt.Errorf("chain %d does not support EIP-1559 fees", p.chain.ID)
+	}
+
+	// Leave room for the base fee to double before the transaction is mined.
+	feeCap = new(big.Int).Add(tipCap, new(big.Int).Mul(baseFee, big.NewInt(2)))
+	return baseFee, tipCap, feeCap, nil
+}
+
+// estimateFee prices a payment transaction of gas gas, which may be zero
+// when unknown.
+func (p *MetaMaskProcessor) estimateFee(ctx context.Context, gas uint64) (*domain.FeeEstimate, error) {
+	baseFee, tipCap, feeCap, err := p.suggestFees(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	fee := &domain.FeeEstimate{
+		GasLimit:             gas,
+		BaseFeePerGas:        baseFee.String(),
+		MaxPriorityFeePerGas: tipCap.String(),
+		MaxFeePerGas:         feeCap.String(),
+	}
+	if gas != 0 {
+		fee.MaxCost, err = p.nativeAmount(new(big.Int).Mul(feeCap, new(big.Int).SetUint64(gas)))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return fee, nil
+}
+
+// nativeAmount converts wei into the chain's native currency.
+func (p *MetaMaskProcessor) nativeAmount(wei *big.Int) (domain.Money, error) {
+	return domain.MoneyFromScaled(wei, 18, p.chain.NativeCurrency)
+}
+
+// estimatePaymentGas returns the gas limit of the payment call, or zero
+// when the node rejects the call, typically because the wallet cannot
+// cover it yet. The wallet estimates the gas itself in that case.
+func (p *MetaMaskProcessor) estimatePaymentGas(ctx context.Context, from common.Address, value *big.Int, data []byte) uint64 {
+	gas, err := p.client.EstimateGas(ctx, ethereum.CallMsg{
+		From:  from,
+		To:    &p.contractAddr,
+		Value: value,
+		Data:  data,
+	})
+	if err != nil {
+		return 0
+	}
+	return gas
+}
+
+func (p *MetaMaskProcessor) VerifyTransaction(ctx context.Context, payment *domain.Payment, transactionHash string) error {
+	txHash, err := parseTransactionHash(transactionHash)
+	if err != nil {
+		return err
+	}
+
+	if _, err := p.checkChain(ctx, payment); err != nil {
+		return err
+	}
+
+	token, err := p.paymentToken(ctx, payment.MinimumPayment().Currency)
+	if err != nil {
+		return err
+	}
+
+	receipt, err := p.confirmedReceipt(ctx, txHash)
+	if err != nil {
+		return err
+	}
+
+	if err := p.verifyPaymentLog(payment, receipt, token); err != nil {
+		return err
+	}
+
+	if err := p.verifyQuote(ctx, payment, receipt); err != nil {
+		return err
+	}
+
+	return p.recordNetworkFee(payment, receipt)
+}
+
+// recordNetworkFee stores the gas the payment transaction used and the
+// price it paid per gas. Nodes that predate EIP-1559 receipts leave the
+// price out, in which case no fee is recorded.
+func (p *MetaMaskProcessor) recordNetworkFee(payment *domain.Payment, receipt *types.Receipt) error {
+	if receipt.EffectiveGasPrice == nil {
+		return nil
+	}
+
+	amount, err := p.nativeAmount(new(big.Int).Mul(receipt.EffectiveGasPrice, new(big.Int).SetUint64(receipt.GasUsed)))
+	if err != nil {
+		return err
+	}
+
+	payment.NetworkFee = &domain.NetworkFee{
+		ChainID:           p.chain.ID,
+		GasUsed:           receipt.GasUsed,
+		EffectiveGasPrice: receipt.EffectiveGasPrice.String(),
+		Amount:            amount,
+		RecordedAt:        time.Now(),
+	}
+	return nil
+}
+
+// verifyQuote checks that a quoted payment was mined before its quote
+// expired. The block time is used rather than the current time, so waiting
+// for confirmations does not expire a payment made in time.
+func (p *MetaMaskProcessor) verifyQuote(ctx context.Context, payment *domain.Payment, receipt *types.Receipt) error {
+	if payment.Quote == nil {
+		return nil
+	}
+
+	header, err := p.client.HeaderByNumber(ctx, receipt.BlockNumber)
+	if err != nil {
+		return err
+	}
+
+	if payment.Quote.Expired(time.Unix(int64(header.Time), 0)) {
+		return ErrQuoteExpired
+	}
+	return nil
+}
+
+// verifyPaymentLog looks for a payment event in the receipt that was emitted
+// by the payment contract for the payment's order with at least the payment
+// amount, less the quote's slippage tolerance. Native payments emit
+// PaymentReceived; token payments emit TokenPaymentReceived for the token and
+// must also carry the token's own Transfer into the contract. When no log
+// qualifies, the error describes the log that came closest.
+func (p *MetaMaskProcessor) verifyPaymentLog(payment *domain.Payment, receipt *types.Receipt, token *Token) error {
+	minimum := payment.MinimumPayment()
+	eventName, expected := paymentReceivedEvent, p.convertToWei(minimum)
+	if token != nil {
+		eventName, expected = tokenPaymentReceivedEvent, minimum.ScaledTo(token.Decimals)
+	}
+	event, ok := p.contractABI.Events[eventName]
+	if !ok {
+		return ErrPaymentLogNotFound
+	}
+	// orderID is an indexed string, so the topic holds its hash.
+	orderTopic := crypto.Keccak256Hash([]byte(payment.OrderID))
+
+	closest := ErrPaymentLogNotFound
+	for _, vLog := range receipt.Logs {
+		if len(vLog.Topics) < 3 || vLog.Topics[0] != event.ID {
+			continue
+		}
+
+		if vLog.Address != p.contractAddr {
+			closest = moreSpecific(closest, ErrWrongPaymentContract)
+			continue
+		}
+
+		if vLog.Topics[1] != orderTopic {
+			closest = moreSpecific(closest, ErrPaymentOrderMismatch)
+			continue
+		}
+
+		if token != nil && (len(vLog.Topics) != 4 || vLog.Topics[3] != common.BytesToHash(token.Address.Bytes())) {
+			closest = moreSpecific(closest, ErrPaymentTokenMismatch)
+			continue
+		}
+
+		values, err := event.Inputs.NonIndexed().Unpack(vLog.Data)
+		if err != nil || len(values) != 1 {
+			continue
+		}
+		amount, ok := values[0].(*big.Int)
+		if !ok {
+			continue
+		}
+
+		if amount.Cmp(expected) < 0 {
+			closest = moreSpecific(closest, ErrPaymentUnderpaid)
+			continue
+		}
+
+		if token != nil && !p.hasTokenTransfer(receipt, token, expected) {
+			closest = moreSpecific(closest, ErrPaymentTokenMismatch)
+			continue
+		}
+
+		return nil
+	}
+
+	return closest
+}
+
+// hasTokenTransfer reports whether the receipt moved at least amount of the
+// token into the payment contract.
+func (p *MetaMaskProcessor) hasTokenTransfer(receipt *types.Receipt, token *Token, amount *big.Int) bool {
+	transfer := p.tokenABI.Events["Transfer"]
+	contractTopic := common.BytesToHash(p.contractAddr.Bytes())
+
+	for _, vLog := range receipt.Logs {
+		if vLog.Address != token.Address || len(vLog.Topics) != 3 ||
+			vLog.Topics[0] != transfer.ID || vLog.Topics[2] != contractTopic {
+			continue
+		}
+
+		values, err := transfer.Inputs.NonIndexed().Unpack(vLog.Data)
+		if err != nil || len(values) != 1 {
+			continue
+		}
+		if value, ok := values[0].(*big.Int); ok && value.Cmp(amount) >= 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// verificationErrors ranks log verification failures from least to most
+// specific.
+var verificationErrors = []error{
+	ErrPaymentLogNotFound,
+	ErrWrongPaymentContract,
+	ErrPaymentOrderMismatch,
+	ErrPaymentTokenMismatch,
+	ErrPaymentUnderpaid,
+}
+
+func moreSpecific(a, b error) error {
+	for _, err := range verificationErrors {
+		if err == a {
+			return b
+		}
+		if err == b {
+			return a
+		}
+	}
+	return b
+}
+
+// RefundTransaction signs a transfer of refund.Amount from the refund wallet
+// back to the account that sent the original payment and broadcasts it.
+// Native refunds send value; token refunds call transfer on the token. The
+// refund transaction hash is recorded as the refund's processor reference
+// and saved through record before the transaction is sent, so a refund
+// whose broadcast went wrong can always be traced on chain. A broadcast the
+// node did not answer is looked up by hash: if the node has the
+// transaction, the refund was sent, otherwise ErrRefundSendUnknown is
+// returned. VerifyRefund reports when the refund is confirmed.
+func (p *MetaMaskProcessor) RefundTransaction(ctx context.Context, payment *domain.Payment, refund *domain.Refund, record func(context.Context, *domain.Refund) error) error {
+	if p.refundKey == nil {
+		return ErrNoRefundWallet
+	}
+
+	if _, err := p.checkChain(ctx, payment); err != nil {
+		return err
+	}
+
+	paymentHash, err := parseTransactionHash(payment.TransactionID)
+	if err != nil {
+		return err
+	}
+
+	// Refunds are priced in the payment currency; quoted payments are
+	// refunded at the rate they were paid at.
+	amount, err := payment.ChainAmount(refund.Amount)
+	if err != nil {
+		return err
+	}
+
+	token, err := p.paymentToken(ctx, amount.Currency)
+	if err != nil {
+		return err
+	}
+
+	chainID, err := p.networkID(ctx)
+	if err != nil {
+		return err
+	}
+	signer := types.LatestSignerForChainID(chainID)
+
+	paymentTx, _, err := p.client.TransactionByHash(ctx, paymentHash)
+	if err != nil {
+		if errors.Is(err, ethereum.NotFound) {
+			return ErrTransactionNotMined
+		}
+		return err
+	}
+
+	payer, err := types.Sender(signer, paymentTx)
+	if err != nil {
+		return fmt.Errorf("failed to recover payer: %w", err)
+	}
+
+	from := crypto.PubkeyToAddress(p.refundKey.PublicKey)
+	to, value, data := payer, p.convertToWei(amount), []byte(nil)
+	if token != nil {
+		data, err = p.tokenABI.Pack("transfer", payer, amount.ScaledTo(token.Decimals))
+		if err != nil {
+			return fmt.Errorf("failed to encode token refund: %w", err)
+		}
+		to, value = token.Address, new(big.Int)
+	}
+
+	p.refundMu.Lock()
+	defer p.refundMu.Unlock()
+
+	nonce, err := p.client.PendingNonceAt(ctx, from)
+	if err != nil {
+		return err
+	}
+
+	_, tipCap, feeCap, err := p.suggestFees(ctx)
+	if err != nil {
+		return err
+	}
+
+	gas, err := p.client.EstimateGas(ctx, ethereum.CallMsg{
+		From:  from,
+		To:    &to,
+		Value: value,
+		Data:  data,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to estimate refund gas: %w", err)
+	}
+
+	tx, err := types.SignNewTx(p.refundKey, signer, &types.DynamicFeeTx{
+		ChainID:   chainID,
+		Nonce:     nonce,
+		GasTipCap: tipCap,
+		GasFeeCap: feeCap,
+		Gas:       gas,
+		To:        &to,
+		Value:     value,
+		Data:      data,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to sign refund: %w", err)
+	}
+
+	refund.ProcessorRef = tx.Hash().Hex()
+	if err := record(ctx, refund); err != nil {
+		refund.ProcessorRef = ""
+		return fmt.Errorf("failed to record refund transaction: %w", err)
+	}
+
+	if err := p.client.SendTransaction(ctx, tx); err != nil {
+		// The node answered and turned the transaction down.
+		var rpcErr rpc.Error
+		if errors.As(err, &rpcErr) {
+			return fmt.Errorf("failed to send refund: %w", err)
+		}
+		if _, _, lookupErr := p.client.TransactionByHash(ctx, tx.Hash()); lookupErr == nil {
+			return nil
+		}
+		return fmt.Errorf("%w: %w", domain.ErrRefundSendUnknown, err)
+	}
+	return nil
+}
+
+// VerifyRefund checks a broadcast refund the same way VerifyTransaction
+// checks a payment: it must have succeeded and have enough confirmations.
+func (p *MetaMaskProcessor) VerifyRefund(ctx context.Context, payment *domain.Payment, refund *domain.Refund) error {
+	if _, err := p.checkChain(ctx, payment); err != nil {
+		return err
+	}
+
+	txHash, err := parseTransactionHash(refund.ProcessorRef)
+	if err != nil {
+		return err
+	}
+
+	_, err = p.confirmedReceipt(ctx, txHash)
+	if errors.Is(err, ErrTransactionNotMined) {
+		// Not mined yet.
+		return domain.ErrInsufficientConfirmations
+	}
+	return err
+}
+
+func (p *MetaMaskProcessor) GetTransactionStatus(ctx context.Context, payment *domain.Payment, transactionHash string) (string, error) {
+	if _, err := p.checkChain(ctx, payment); err != nil {
+		return "", err
+	}
+
+	txHash, err := parseTransactionHash(transactionHash)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = p.confirmedReceipt(ctx, txHash)
+	switch {
+	case err == nil:
+		return "CONFIRMED", nil
+	case errors.Is(err, ErrTransactionNotMined):
+		return "PENDING", nil
+	case errors.Is(err, ErrTransactionFailed):
+		return "FAILED", nil
+	case errors.Is(err, domain.ErrInsufficientConfirmations):
+		return "CONFIRMING", nil
+	default:
+		return "", err
+	}
+}
+
+// confirmedReceipt returns the receipt of a successful transaction with at
+// least minConfirmations blocks on top of it.
+func (p *MetaMaskProcessor) confirmedReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	// Get transaction receipt
+	receipt, err := p.client.TransactionReceipt(ctx, txHash)
+	if err != nil {
+		if errors.Is(err, ethereum.NotFound) {
+			return nil, ErrTransactionNotMined
+		}
+		return nil, err
+	}
+
+	// Check if transaction was successful
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return nil, ErrTransactionFailed
+	}
+
+	// Get current block number
+	currentBlock, err := p.client.BlockNumber(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check confirmations. A node that lags behind the one that served
+	// the receipt may not have the receipt's block yet.
+	if currentBlock < receipt.BlockNumber.Uint64() {
+		return nil, domain.ErrInsufficientConfirmations
+	}
+	confirmations := currentBlock - receipt.BlockNumber.Uint64()
+	if confirmations < p.minConfirmations {
+		return nil, domain.ErrInsufficientConfirmations
+	}
+
+	return receipt, nil
+}
+
+// paymentToken returns the token an amount in currency is paid with, or nil
+// for the native currency.
+func (p *MetaMaskProcessor) paymentToken(ctx context.Context, currency string) (*Token, error) {
+	if strings.EqualFold(currency, p.chain.NativeCurrency) {
+		return nil, nil
+	}
+
+	chainID, err := p.networkID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	token, ok := p.tokens.Lookup(chainID.Uint64(), currency)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s on chain %s", domain.ErrCurrencyNotPayable, currency, chainID)
+	}
+	return &token, nil
+}
+
+// checkChain rejects payments assigned to a different chain and returns the
+// processor's chain ID.
+func (p *MetaMaskProcessor) checkChain(ctx context.Context, payment *domain.Payment) (uint64, error) {
+	chainID, err := p.networkID(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	if payment.ChainID != 0 && payment.ChainID != chainID.Uint64() {
+		return 0, fmt.Errorf("%w: payment is on chain %d, processor on chain %s", ErrWrongChain, payment.ChainID, chainID)
+	}
+	return chainID.Uint64(), nil
+}
+
+// networkID returns the chain ID of the node, asking it only once. A node
+// on a different chain than configured is an error.
+func (p *MetaMaskProcessor) networkID(ctx context.Context) (*big.Int, error) {
+	p.chainIDMu.Lock()
+	defer p.chainIDMu.Unlock()
+
+	if p.chainID == nil {
+		chainID, err := p.client.ChainID(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if p.chain.ID != 0 && chainID.Uint64() != p.chain.ID {
+			return nil, fmt.Errorf("node for chain %d reports chain ID %s", p.chain.ID, chainID)
+		}
+		p.chainID = chainID
+		p.chain.ID = chainID.Uint64()
+	}
+	return p.chainID, nil
+}
+
+func parseTransactionHash(transactionHash string) (common.Hash, error) {
+	if len(transactionHash) != 66 || transactionHash[:2] != "0x" {
+		return common.Hash{}, ErrInvalidTransaction
+	}
+	return common.HexToHash(transactionHash), nil
+}
+
+func (p *MetaMaskProcessor) convertToWei(amount domain.Money) *big.Int {
+	// Convert amount to Wei (1 ETH = 10^18 Wei)
+	return amount.ScaledTo(18)
+}
+
+// Smart Contract Interface
+const PaymentContractABI = `[
+	{
+		"inputs": [],
+		"stateMutability": "nonpayable",
+		"type": "constructor"
+	},
+	{
+		"anonymous": false,
+		"inputs": [
+			{
+				"indexed": true,
+				"internalType": "string",
+				"name": "orderID",
+				"type": "string"
+			},
+			{
+				"indexed": true,
+				"internalType": "address",
+				"name": "payer",
+				"type": "address"
+			},
+			{
+				"indexed": false,
+				"internalType": "uint256",
+				"name": "amount",
+				"type": "uint256"
+			}
+		],
+		"name": "PaymentReceived",
+		"type": "event"
+	},
+	{
+		"anonymous": false,
+		"inputs": [
+			{
+				"indexed": true,
+				"internalType": "string",
+				"name": "orderID",
+				"type": "string"
+			},
+			{
+				"indexed": true,
+				"internalType": "address",
+				"name": "payer",
+				"type": "address"
+			},
+			{
+				"indexed": true,
+				"internalType": "address",
+				"name": "token",
+				"type": "address"
+			},
+			{
+				"indexed": false,
+				"internalType": "uint256",
+				"name": "amount",
+				"type": "uint256"
+			}
+		],
+		"name": "TokenPaymentReceived",
+		"type": "event"
+	},
+	{
+		"inputs": [
+			{
+				"internalType": "string",
+				"name": "orderID",
+				"type": "string"
+			}
+		],
+		"name": "makePayment",
+		"outputs": [],
+		"stateMutability": "payable",
+		"type": "function"
+	},
+	{
+		"inputs": [
+			{
+				"internalType": "string",
+				"name": "orderID",
+				"type": "string"
+			},
+			{
+				"internalType": "address",
+				"name": "token",
+				"type": "address"
+			},
+			{
+				"internalType": "uint256",
+				"name": "amount",
+				"type": "uint256"
+			}
+		],
+		"name": "makeTokenPayment",
+		"outputs": [],
+		"stateMutability": "nonpayable",
+		"type": "function"
+	},
+	{
+		"inputs": [],
+		"name": "owner",
+		"outputs": [
+			{
+				"internalType": "address",
+				"name": "",
+				"type": "address"
+			}
+		],
+		"stateMutability": "view",
+		"type": "function"
+	},
+	{
+		"inputs": [
+			{
+				"internalType": "address",
+				"name": "newOwner",
+				"type": "address"
+			}
+		],
+		"name": "transferOwnership",
+		"outputs": [],
+		"stateMutability": "nonpayable",
+		"type": "function"
+	},
+	{
+		"inputs": [],
+		"name": "withdraw",
+		"outputs": [],
+		"stateMutability": "nonpayable",
+		"type": "function"
+	}
+]`
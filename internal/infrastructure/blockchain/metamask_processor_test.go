@@ -0,0 +1,381 @@
+package blockchain_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient/simulated"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/hsibAD/payment-service/internal/domain"
+	"github.com/hsibAD/payment-service/internal/infrastructure/blockchain"
+)
+
+// logEmitterCode is the runtime code of a stand-in for the payment
+// contract. Called with three topics followed by an amount, it emits a log
+// with those topics and the amount as data, so a test can emit the
+// PaymentReceived event of any order, payer and amount.
+var logEmitterCode = common.FromHex(
+	"6020606060003760403560203560003560206000a300",
+)
+
+const minConfirmations = 2
+
+var (
+	contractAddr = common.HexToAddress("0x00000000000000000000000000000000000c0de1")
+	impostorAddr = common.HexToAddress("0x00000000000000000000000000000000000c0de2")
+	strangerAddr = common.HexToAddress("0x0000000000000000000000000000000000005eed")
+)
+
+// chainTest is a simulated chain with the payment contract, a paying
+// customer and a funded refund wallet.
+type chainTest struct {
+	backend  *simulated.Backend
+	client   simulated.Client
+	chain    blockchain.Chain
+	payer    *ecdsa.PrivateKey
+	refunder *ecdsa.PrivateKey
+	received abi.Event
+}
+
+func newChainTest(t *testing.T) *chainTest {
+	t.Helper()
+
+	payer, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	refunder, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	funds := new(big.Int).Mul(big.NewInt(100), big.NewInt(params.Ether))
+	backend := simulated.NewBackend(types.GenesisAlloc{
+		crypto.PubkeyToAddress(payer.PublicKey):    {Balance: funds},
+		crypto.PubkeyToAddress(refunder.PublicKey): {Balance: funds},
+		contractAddr: {Code: logEmitterCode},
+		impostorAddr: {Code: logEmitterCode},
+	})
+	t.Cleanup(func() { backend.Close() })
+
+	client := backend.Client()
+	chainID, err := client.ChainID(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	contractABI, err := abi.JSON(strings.NewReader(blockchain.PaymentContractABI))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &chainTest{
+		backend: backend,
+		client:  client,
+		chain: blockchain.Chain{
+			ID:               chainID.Uint64(),
+			Name:             "simulated",
+			ContractAddress:  contractAddr.Hex(),
+			MinConfirmations: minConfirmations,
+			NativeCurrency:   "ETH",
+			Default:          true,
+		},
+		payer:    payer,
+		refunder: refunder,
+		received: contractABI.Events["PaymentReceived"],
+	}
+}
+
+func (ct *chainTest) processor(t *testing.T, refundKey *ecdsa.PrivateKey) *blockchain.MetaMaskProcessor {
+	t.Helper()
+	return ct.processorWithBackend(t, ct.client, refundKey)
+}
+
+func (ct *chainTest) processorWithBackend(t *testing.T, backend blockchain.Backend, refundKey *ecdsa.PrivateKey) *blockchain.MetaMaskProcessor {
+	t.Helper()
+
+	p, err := blockchain.NewMetaMaskProcessorWithBackend(backend, ct.chain, blockchain.PaymentContractABI, nil, refundKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+// pay sends a transaction that makes contract emit PaymentReceived for the
+// order and amount, and mines it.
+func (ct *chainTest) pay(t *testing.T, contract common.Address, orderID string, amount *big.Int) common.Hash {
+	t.Helper()
+
+	var data []byte
+	data = append(data, ct.received.ID.Bytes()...)
+	data = append(data, crypto.Keccak256Hash([]byte(orderID)).Bytes()...)
+	data = append(data, common.LeftPadBytes(crypto.PubkeyToAddress(ct.payer.PublicKey).Bytes(), 32)...)
+	data = append(data, common.LeftPadBytes(amount.Bytes(), 32)...)
+
+	hash := ct.send(t, &contract, big.NewInt(0), data)
+	ct.backend.Commit()
+	return hash
+}
+
+// send signs and broadcasts a transaction from the payer without mining it.
+func (ct *chainTest) send(t *testing.T, to *common.Address, value *big.Int, data []byte) common.Hash {
+	t.Helper()
+
+	ctx := context.Background()
+	from := crypto.PubkeyToAddress(ct.payer.PublicKey)
+	nonce, err := ct.client.PendingNonceAt(ctx, from)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tipCap, err := ct.client.SuggestGasTipCap(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	head, err := ct.client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	feeCap := new(big.Int).Add(tipCap, new(big.Int).Mul(head.BaseFee, big.NewInt(2)))
+	gas, err := ct.client.EstimateGas(ctx, ethereum.CallMsg{From: from, To: to, Value: value, Data: data})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tx, err := types.SignNewTx(ct.payer, types.LatestSignerForChainID(new(big.Int).SetUint64(ct.chain.ID)), &types.DynamicFeeTx{
+		ChainID:   new(big.Int).SetUint64(ct.chain.ID),
+		Nonce:     nonce,
+		GasTipCap: tipCap,
+		GasFeeCap: feeCap,
+		Gas:       gas,
+		To:        to,
+		Value:     value,
+		Data:      data,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ct.client.SendTransaction(ctx, tx); err != nil {
+		t.Fatal(err)
+	}
+	return tx.Hash()
+}
+
+// confirm mines enough blocks on top of the last one to confirm it.
+func (ct *chainTest) confirm() {
+	for i := 0; i < minConfirmations; i++ {
+		ct.backend.Commit()
+	}
+}
+
+func (ct *chainTest) payerBalance(t *testing.T) *big.Int {
+	t.Helper()
+
+	balance, err := ct.client.BalanceAt(context.Background(), crypto.PubkeyToAddress(ct.payer.PublicKey), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return balance
+}
+
+// noRecord stands in for saving the refund.
+func noRecord(ctx context.Context, refund *domain.Refund) error {
+	return nil
+}
+
+// lostAnswerBackend fails every broadcast the way a dropped connection
+// does. With send set the node gets the transaction first, so only its
+// answer is lost.
+type lostAnswerBackend struct {
+	simulated.Client
+	send bool
+}
+
+func (b lostAnswerBackend) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	if b.send {
+		if err := b.Client.SendTransaction(ctx, tx); err != nil {
+			return err
+		}
+	}
+	return errors.New("connection reset by peer")
+}
+
+// ethPayment is a pending crypto payment of amount wei for the order.
+func ethPayment(orderID string, amount int64) *domain.Payment {
+	return &domain.Payment{
+		ID:             "payment-" + orderID,
+		OrderID:        orderID,
+		UserID:         "user-1",
+		Amount:         domain.Money{MinorUnits: amount, Currency: "ETH"},
+		Status:         domain.PaymentStatusProcessing,
+		PaymentMethod:  domain.PaymentMethodMetaMask,
+		RefundedAmount: domain.Money{Currency: "ETH"},
+	}
+}
+
+func TestMetaMaskProcessorPaysAndRefunds(t *testing.T) {
+	ct := newChainTest(t)
+	p := ct.processor(t, ct.refunder)
+	ctx := context.Background()
+
+	payment := ethPayment("order-1", 1e17)
+	txHash := ct.pay(t, contractAddr, payment.OrderID, big.NewInt(1e17))
+
+	if err := p.VerifyTransaction(ctx, payment, txHash.Hex()); !errors.Is(err, domain.ErrInsufficientConfirmations) {
+		t.Fatalf("VerifyTransaction before confirmations: %v, want %v", err, domain.ErrInsufficientConfirmations)
+	}
+	ct.confirm()
+	if err := p.VerifyTransaction(ctx, payment, txHash.Hex()); err != nil {
+		t.Fatalf("VerifyTransaction: %v", err)
+	}
+	if payment.NetworkFee == nil || payment.NetworkFee.GasUsed == 0 {
+		t.Errorf("network fee = %+v, want the gas the payment used", payment.NetworkFee)
+	}
+	payment.TransactionID = txHash.Hex()
+
+	refund, err := domain.NewRefund(payment.ID, domain.Money{MinorUnits: 4e16, Currency: "ETH"}, "damaged item")
+	if err != nil {
+		t.Fatal(err)
+	}
+	before := ct.payerBalance(t)
+	if err := p.RefundTransaction(ctx, payment, refund, noRecord); err != nil {
+		t.Fatalf("RefundTransaction: %v", err)
+	}
+	if refund.ProcessorRef == "" {
+		t.Fatal("refund has no transaction hash")
+	}
+
+	// Broadcast but not mined.
+	if err := p.VerifyRefund(ctx, payment, refund); !errors.Is(err, domain.ErrInsufficientConfirmations) {
+		t.Fatalf("VerifyRefund before mining: %v, want %v", err, domain.ErrInsufficientConfirmations)
+	}
+	ct.backend.Commit()
+	if err := p.VerifyRefund(ctx, payment, refund); !errors.Is(err, domain.ErrInsufficientConfirmations) {
+		t.Fatalf("VerifyRefund before confirmations: %v, want %v", err, domain.ErrInsufficientConfirmations)
+	}
+	ct.confirm()
+	if err := p.VerifyRefund(ctx, payment, refund); err != nil {
+		t.Fatalf("VerifyRefund: %v", err)
+	}
+
+	got := new(big.Int).Sub(ct.payerBalance(t), before)
+	if got.Cmp(big.NewInt(4e16)) != 0 {
+		t.Errorf("payer received %s wei, want %d", got, int64(4e16))
+	}
+}
+
+func TestMetaMaskProcessorRefundNeedsWallet(t *testing.T) {
+	ct := newChainTest(t)
+	p := ct.processor(t, nil)
+
+	payment := ethPayment("order-1", 1e17)
+	payment.TransactionID = ct.pay(t, contractAddr, payment.OrderID, big.NewInt(1e17)).Hex()
+	refund, err := domain.NewRefund(payment.ID, payment.Amount, "damaged item")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = p.RefundTransaction(context.Background(), payment, refund, noRecord)
+	if !errors.Is(err, domain.ErrRefundNotSupported) {
+		t.Fatalf("RefundTransaction: %v, want %v", err, domain.ErrRefundNotSupported)
+	}
+}
+
+func TestMetaMaskProcessorRefundRecordsBeforeSending(t *testing.T) {
+	recordErr := errors.New("database unavailable")
+	tests := []struct {
+		name      string
+		send      bool
+		recordErr error
+		want      error
+		wantSent  bool
+	}{
+		{name: "answer lost", send: true, wantSent: true},
+		{name: "never sent", want: domain.ErrRefundSendUnknown},
+		{name: "not recorded", send: true, recordErr: recordErr, want: recordErr},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ct := newChainTest(t)
+			p := ct.processorWithBackend(t, lostAnswerBackend{Client: ct.client, send: tt.send}, ct.refunder)
+			ctx := context.Background()
+
+			payment := ethPayment("order-1", 1e17)
+			payment.TransactionID = ct.pay(t, contractAddr, payment.OrderID, big.NewInt(1e17)).Hex()
+			refund, err := domain.NewRefund(payment.ID, payment.Amount, "damaged item")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			recorded := ""
+			err = p.RefundTransaction(ctx, payment, refund, func(ctx context.Context, refund *domain.Refund) error {
+				recorded = refund.ProcessorRef
+				if _, _, err := ct.client.TransactionByHash(ctx, common.HexToHash(refund.ProcessorRef)); !errors.Is(err, ethereum.NotFound) {
+					t.Errorf("refund transaction known before it was recorded: %v", err)
+				}
+				return tt.recordErr
+			})
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("RefundTransaction: %v, want %v", err, tt.want)
+			}
+			if recorded == "" {
+				t.Fatal("refund was not recorded")
+			}
+
+			_, _, err = ct.client.TransactionByHash(ctx, common.HexToHash(recorded))
+			if sent := err == nil; sent != tt.wantSent {
+				t.Errorf("refund sent = %v, want %v", sent, tt.wantSent)
+			}
+		})
+	}
+}
+
+func TestMetaMaskProcessorVerifyTransactionErrors(t *testing.T) {
+	ct := newChainTest(t)
+	p := ct.processor(t, nil)
+
+	noLog := ct.send(t, &strangerAddr, big.NewInt(1e17), nil)
+	ct.backend.Commit()
+	wrongContract := ct.pay(t, impostorAddr, "order-1", big.NewInt(1e17))
+	wrongOrder := ct.pay(t, contractAddr, "order-2", big.NewInt(1e17))
+	underpaid := ct.pay(t, contractAddr, "order-1", big.NewInt(1e17-1))
+	paid := ct.pay(t, contractAddr, "order-1", big.NewInt(1e17))
+	ct.confirm()
+
+	tests := []struct {
+		name    string
+		chainID uint64
+		txHash  string
+		want    error
+	}{
+		{name: "malformed hash", txHash: "0x1234", want: blockchain.ErrInvalidTransaction},
+		{name: "unknown transaction", txHash: common.HexToHash("0x01").Hex(), want: domain.ErrTransactionNotMined},
+		{name: "no payment log", txHash: noLog.Hex(), want: domain.ErrPaymentLogNotFound},
+		{name: "wrong contract", txHash: wrongContract.Hex(), want: domain.ErrWrongPaymentContract},
+		{name: "wrong order", txHash: wrongOrder.Hex(), want: domain.ErrPaymentOrderMismatch},
+		{name: "underpaid", txHash: underpaid.Hex(), want: domain.ErrPaymentUnderpaid},
+		{name: "wrong chain", chainID: ct.chain.ID + 1, txHash: paid.Hex(), want: domain.ErrWrongChain},
+		{name: "paid", txHash: paid.Hex(), want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payment := ethPayment("order-1", 1e17)
+			payment.ChainID = tt.chainID
+
+			err := p.VerifyTransaction(context.Background(), payment, tt.txHash)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("VerifyTransaction: %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package blockchain
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hsibAD/payment-service/internal/domain"
+)
+
+// MultiChainProcessor serves crypto payments on several chains by handing
+// each payment to the processor of the chain it is assigned to.
+type MultiChainProcessor struct {
+	processors   map[uint64]*MetaMaskProcessor
+	defaultChain uint64
+}
+
+// NewMultiChainProcessor creates a processor over per-chain processors
+// keyed by chain ID. Payments without a chain go to defaultChain.
+func NewMultiChainProcessor(processors map[uint64]*MetaMaskProcessor, defaultChain uint64) (*MultiChainProcessor, error) {
+	if _, ok := processors[defaultChain]; !ok {
+		return nil, fmt.Errorf("default chain %d has no processor", defaultChain)
+	}
+
+	return &MultiChainProcessor{
+		processors:   processors,
+		defaultChain: defaultChain,
+	}, nil
+}
+
+func (m *MultiChainProcessor) Chain(chainID uint64) (*domain.ChainInfo, error) {
+	p, err := m.processor(chainID)
+	if err != nil {
+		return nil, err
+	}
+	return p.Chain(0)
+}
+
+func (m *MultiChainProcessor) InitiateTransaction(ctx context.Context, payment *domain.Payment, walletAddress string) (*domain.MetaMaskInfo, error) {
+	p, err := m.processor(payment.ChainID)
+	if err != nil {
+		return nil, err
+	}
+	return p.InitiateTransaction(ctx, payment, walletAddress)
+}
+
+func (m *MultiChainProcessor) VerifyTransaction(ctx context.Context, payment *domain.Payment, transactionHash string) error {
+	p, err := m.processor(payment.ChainID)
+	if err != nil {
+		return err
+	}
+	return p.VerifyTransaction(ctx, payment, transactionHash)
+}
+
+func (m *MultiChainProcessor) GetTransactionStatus(ctx context.Context, payment *domain.Payment, transactionHash string) (string, error) {
+	p, err := m.processor(payment.ChainID)
+	if err != nil {
+		return "", err
+	}
+	return p.GetTransactionStatus(ctx, payment, transactionHash)
+}
+
+func (m *MultiChainProcessor) RefundTransaction(ctx context.Context, payment *domain.Payment, refund *domain.Refund, record func(context.Context, *domain.Refund) error) error {
+	p, err := m.processor(payment.ChainID)
+	if err != nil {
+		return err
+	}
+	return p.RefundTransaction(ctx, payment, refund, record)
+}
+
+func (m *MultiChainProcessor) VerifyRefund(ctx context.Context, payment *domain.Payment, refund *domain.Refund) error {
+	p, err := m.processor(payment.ChainID)
+	if err != nil {
+		return err
+	}
+	return p.VerifyRefund(ctx, payment, refund)
+}
+
+func (m *MultiChainProcessor) processor(chainID uint64) (*MetaMaskProcessor, error) {
+	if chainID == 0 {
+		chainID = m.defaultChain
+	}
+
+	p, ok := m.processors[chainID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %d", domain.ErrUnsupportedChain, chainID)
+	}
+	return p, nil
+}
@@ -0,0 +1,121 @@
+package blockchain
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/hsibAD/payment-service/internal/domain"
+)
+
+const walletOwnershipType = "WalletOwnership"
+
+// OwnershipVerifier checks wallet ownership signatures. EIP-712 challenges
+// are signed as typed data bound to the chain; EIP-191 challenges as a
+// personal_sign text message with the same fields.
+type OwnershipVerifier struct {
+	domainName string
+}
+
+func NewOwnershipVerifier(domainName string) *OwnershipVerifier {
+	return &OwnershipVerifier{domainName: domainName}
+}
+
+func (v *OwnershipVerifier) SigningPayload(challenge *domain.WalletChallenge) (string, error) {
+	switch challenge.Scheme {
+	case domain.SignatureSchemeEIP712:
+		data, err := json.Marshal(v.typedData(challenge))
+		if err != nil {
+			return "", err
+		}
+		return string(data), nil
+	case domain.SignatureSchemeEIP191:
+		return v.message(challenge), nil
+	default:
+		return "", domain.ErrInvalidSignatureType
+	}
+}
+
+// RecoverSigner returns the checksummed address that produced signature, a
+// 65 byte hex encoded [R || S || V] signature as wallets return it.
+func (v *OwnershipVerifier) RecoverSigner(challenge *domain.WalletChallenge, signature string) (string, error) {
+	sig, err := hexutil.Decode(signature)
+	if err != nil || len(sig) != crypto.SignatureLength {
+		return "", domain.ErrInvalidSignature
+	}
+	// Wallets return V as 27 or 28; crypto.SigToPub expects 0 or 1.
+	if sig[crypto.RecoveryIDOffset] >= 27 {
+		sig[crypto.RecoveryIDOffset] -= 27
+	}
+
+	var hash []byte
+	switch challenge.Scheme {
+	case domain.SignatureSchemeEIP712:
+		hash, _, err = apitypes.TypedDataAndHash(v.typedData(challenge))
+		if err != nil {
+			return "", fmt.Errorf("failed to hash typed data: %w", err)
+		}
+	case domain.SignatureSchemeEIP191:
+		hash = accounts.TextHash([]byte(v.message(challenge)))
+	default:
+		return "", domain.ErrInvalidSignatureType
+	}
+
+	pub, err := crypto.SigToPub(hash, sig)
+	if err != nil {
+		return "", domain.ErrInvalidSignature
+	}
+	return crypto.PubkeyToAddress(*pub).Hex(), nil
+}
+
+func (v *OwnershipVerifier) typedData(challenge *domain.WalletChallenge) apitypes.TypedData {
+	return apitypes.TypedData{
+		Types: apitypes.Types{
+			"EIP712Domain": {
+				{Name: "name", Type: "string"},
+				{Name: "version", Type: "string"},
+				{Name: "chainId", Type: "uint256"},
+			},
+			walletOwnershipType: {
+				{Name: "paymentId", Type: "string"},
+				{Name: "wallet", Type: "address"},
+				{Name: "amount", Type: "string"},
+				{Name: "nonce", Type: "string"},
+				{Name: "expiresAt", Type: "uint256"},
+			},
+		},
+		PrimaryType: walletOwnershipType,
+		Domain: apitypes.TypedDataDomain{
+			Name:    v.domainName,
+			Version: "1",
+			ChainId: (*math.HexOrDecimal256)(new(big.Int).SetUint64(challenge.ChainID)),
+		},
+		Message: apitypes.TypedDataMessage{
+			"paymentId": challenge.PaymentID,
+			"wallet":    common.HexToAddress(challenge.WalletAddress).Hex(),
+			"amount":    challenge.Amount.String(),
+			"nonce":     challenge.Nonce,
+			"expiresAt": big.NewInt(challenge.ExpiresAt.Unix()).String(),
+		},
+	}
+}
+
+func (v *OwnershipVerifier) message(challenge *domain.WalletChallenge) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s: sign to prove you own this wallet.\n\n", v.domainName)
+	fmt.Fprintf(&b, "Payment: %s\n", challenge.PaymentID)
+	fmt.Fprintf(&b, "Amount: %s\n", challenge.Amount)
+	fmt.Fprintf(&b, "Wallet: %s\n", common.HexToAddress(challenge.WalletAddress).Hex())
+	fmt.Fprintf(&b, "Chain ID: %d\n", challenge.ChainID)
+	fmt.Fprintf(&b, "Nonce: %s\n", challenge.Nonce)
+	fmt.Fprintf(&b, "Expires: %s", challenge.ExpiresAt.UTC().Format(time.RFC3339))
+	return b.String()
+}
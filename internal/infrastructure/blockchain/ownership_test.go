@@ -0,0 +1,156 @@
+package blockchain_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hsibAD/payment-service/internal/domain"
+	"github.com/hsibAD/payment-service/internal/infrastructure/blockchain"
+)
+
+// ownerAddress is the address of the key that made the signatures below,
+// 4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318.
+const ownerAddress = "0x2c7536E3605D9C16a7a3D7b1898e529396a65c23"
+
+// Signatures the owner made over the payloads of ownershipChallenge, as
+// wallets return them but with V as the bare recovery ID.
+const (
+	eip712Sig0 = "0xb80460e1be54b908dd76d592a7ed447b51b12c97e8f3e6c85bf3049b10e2165777497619ad42125de8a5196048e56e9dbbb06d265e3a4c8551822bc32a48825200"
+	eip712Sig1 = "0x65bf4100cee99d8da0c0418d6669c2483507c6d721f7f50cb8a61fddc9ebc87b0cfbe540a3fec5f88cfe33ec249dfd6c09e3ed93bfeb727163626f38ea961af201"
+	eip191Sig0 = "0x77555bf68b295a45e46f6a56ab7040ed8a8d1f9710d0fd45b4f6d3e739c0b9f50956a21f720f43a88fbd63a599bc39af10af861aa777c19f6458cfbe63e3dbe100"
+	eip191Sig1 = "0x70058259eab1219c14f1609f8639c7b22c8f96b4c5562931c369cc126bd41d4d6f774df9583206f21bb40e3636e3befe6a241f60eb3349f28bb6a648cf2a86d001"
+)
+
+// ownershipChallenge is the challenge the signatures above answer.
+func ownershipChallenge(scheme domain.SignatureScheme, nonce string) *domain.WalletChallenge {
+	return &domain.WalletChallenge{
+		Nonce:         nonce,
+		Scheme:        scheme,
+		PaymentID:     "payment-1",
+		UserID:        "user-1",
+		WalletAddress: ownerAddress,
+		ChainID:       1,
+		Amount:        domain.Money{MinorUnits: 1e17, Currency: "ETH"},
+		ExpiresAt:     time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+// walletV rewrites the signature's V the way most wallets return it, as 27
+// or 28.
+func walletV(signature string) string {
+	switch signature[len(signature)-2:] {
+	case "00":
+		return signature[:len(signature)-2] + "1b"
+	default:
+		return signature[:len(signature)-2] + "1c"
+	}
+}
+
+func TestOwnershipVerifierRecoversSigner(t *testing.T) {
+	tests := []struct {
+		name      string
+		scheme    domain.SignatureScheme
+		nonce     string
+		signature string
+	}{
+		{name: "EIP-712 V=0", scheme: domain.SignatureSchemeEIP712, nonce: "nonce-0", signature: eip712Sig0},
+		{name: "EIP-712 V=1", scheme: domain.SignatureSchemeEIP712, nonce: "nonce-3", signature: eip712Sig1},
+		{name: "EIP-712 V=27", scheme: domain.SignatureSchemeEIP712, nonce: "nonce-0", signature: walletV(eip712Sig0)},
+		{name: "EIP-712 V=28", scheme: domain.SignatureSchemeEIP712, nonce: "nonce-3", signature: walletV(eip712Sig1)},
+		{name: "EIP-191 V=0", scheme: domain.SignatureSchemeEIP191, nonce: "nonce-0", signature: eip191Sig0},
+		{name: "EIP-191 V=1", scheme: domain.SignatureSchemeEIP191, nonce: "nonce-1", signature: eip191Sig1},
+		{name: "EIP-191 V=27", scheme: domain.SignatureSchemeEIP191, nonce: "nonce-0", signature: walletV(eip191Sig0)},
+		{name: "EIP-191 V=28", scheme: domain.SignatureSchemeEIP191, nonce: "nonce-1", signature: walletV(eip191Sig1)},
+	}
+
+	v := blockchain.NewOwnershipVerifier("PaymentService")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signer, err := v.RecoverSigner(ownershipChallenge(tt.scheme, tt.nonce), tt.signature)
+			if err != nil {
+				t.Fatalf("RecoverSigner: %v", err)
+			}
+			if signer != ownerAddress {
+				t.Errorf("signer = %s, want %s", signer, ownerAddress)
+			}
+		})
+	}
+}
+
+func TestOwnershipVerifierRejectsSignatureOfOtherChallenge(t *testing.T) {
+	tests := []struct {
+		name      string
+		scheme    domain.SignatureScheme
+		signature string
+		edit      func(*domain.WalletChallenge)
+	}{
+		{
+			name:      "EIP-712 other chain",
+			scheme:    domain.SignatureSchemeEIP712,
+			signature: eip712Sig0,
+			edit:      func(c *domain.WalletChallenge) { c.ChainID = 5 },
+		},
+		{
+			name:      "EIP-191 other chain",
+			scheme:    domain.SignatureSchemeEIP191,
+			signature: eip191Sig0,
+			edit:      func(c *domain.WalletChallenge) { c.ChainID = 5 },
+		},
+		{
+			name:      "EIP-712 other wallet",
+			scheme:    domain.SignatureSchemeEIP712,
+			signature: eip712Sig0,
+			edit:      func(c *domain.WalletChallenge) { c.WalletAddress = strangerAddr.Hex() },
+		},
+		{
+			name:      "EIP-191 other wallet",
+			scheme:    domain.SignatureSchemeEIP191,
+			signature: eip191Sig0,
+			edit:      func(c *domain.WalletChallenge) { c.WalletAddress = strangerAddr.Hex() },
+		},
+		{
+			name:      "EIP-712 signature made as EIP-191",
+			scheme:    domain.SignatureSchemeEIP712,
+			signature: eip191Sig0,
+			edit:      func(c *domain.WalletChallenge) {},
+		},
+	}
+
+	v := blockchain.NewOwnershipVerifier("PaymentService")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			challenge := ownershipChallenge(tt.scheme, "nonce-0")
+			tt.edit(challenge)
+
+			signer, err := v.RecoverSigner(challenge, tt.signature)
+			if err == nil && (signer == ownerAddress || signer == challenge.WalletAddress) {
+				t.Errorf("signature accepted as made by %s", signer)
+			}
+		})
+	}
+}
+
+func TestOwnershipVerifierRejectsMalformedSignatures(t *testing.T) {
+	tests := []struct {
+		name      string
+		scheme    domain.SignatureScheme
+		signature string
+		want      error
+	}{
+		{name: "not hex", scheme: domain.SignatureSchemeEIP191, signature: "signature", want: domain.ErrInvalidSignature},
+		{name: "short", scheme: domain.SignatureSchemeEIP191, signature: eip191Sig0[:len(eip191Sig0)-2], want: domain.ErrInvalidSignature},
+		{name: "invalid V", scheme: domain.SignatureSchemeEIP191, signature: eip191Sig0[:len(eip191Sig0)-2] + "05", want: domain.ErrInvalidSignature},
+		{name: "unknown scheme", scheme: "EIP1271", signature: eip191Sig0, want: domain.ErrInvalidSignatureType},
+	}
+
+	v := blockchain.NewOwnershipVerifier("PaymentService")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := v.RecoverSigner(ownershipChallenge(tt.scheme, "nonce-0"), tt.signature)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package blockchain
+
+import (
+	"fmt"
+	"math/big"
+	"net/url"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// PaymentURI builds an EIP-681 request that calls makePayment(orderID) on
+// the payment contract with value wei, e.g.
+//
+//	ethereum:0xAbC...@137/makePayment?string=order-1&value=2500000000000000
+//
+// A gas of zero is left out and estimated by the wallet.
+func PaymentURI(contract common.Address, chainID uint64, orderID string, value *big.Int, gas uint64) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "ethereum:%s", contract.Hex())
+	if chainID != 0 {
+		fmt.Fprintf(&b, "@%d", chainID)
+	}
+	fmt.Fprintf(&b, "/%s?string=%s&value=%s", makePaymentMethod, escapeURIValue(orderID), value)
+	if gas != 0 {
+		fmt.Fprintf(&b, "&gasLimit=%d", gas)
+	}
+	return b.String()
+}
+
+// escapeURIValue percent-encodes a query value; EIP-681 follows RFC 3986,
+// which has no "+" for spaces.
+func escapeURIValue(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
@@ -0,0 +1,79 @@
+package blockchain_test
+
+import (
+	"math/big"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/hsibAD/payment-service/internal/infrastructure/blockchain"
+)
+
+func TestPaymentURI(t *testing.T) {
+	contract := common.HexToAddress("0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed")
+
+	tests := []struct {
+		name    string
+		chainID uint64
+		orderID string
+		value   *big.Int
+		gas     uint64
+		want    string
+	}{
+		{
+			name:    "mainnet",
+			chainID: 1,
+			orderID: "order-1",
+			value:   big.NewInt(25e14),
+			want:    "ethereum:0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed@1/makePayment?string=order-1&value=2500000000000000",
+		},
+		{
+			name:    "gas limit",
+			chainID: 137,
+			orderID: "order-1",
+			value:   big.NewInt(25e14),
+			gas:     52000,
+			want:    "ethereum:0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed@137/makePayment?string=order-1&value=2500000000000000&gasLimit=52000",
+		},
+		{
+			name:    "no chain",
+			orderID: "order-1",
+			value:   big.NewInt(1),
+			want:    "ethereum:0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed/makePayment?string=order-1&value=1",
+		},
+		{
+			name:    "value beyond int64",
+			chainID: 1,
+			orderID: "order-1",
+			value:   new(big.Int).Mul(big.NewInt(50), big.NewInt(1e18)),
+			want:    "ethereum:0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed@1/makePayment?string=order-1&value=50000000000000000000",
+		},
+		{
+			name:    "escaped order ID",
+			chainID: 1,
+			orderID: "order 1&value=0/ä",
+			value:   big.NewInt(1),
+			want:    "ethereum:0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed@1/makePayment?string=order%201%26value%3D0%2F%C3%A4&value=1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := blockchain.PaymentURI(contract, tt.chainID, tt.orderID, tt.value, tt.gas)
+			if got != tt.want {
+				t.Errorf("PaymentURI =\n%s\nwant\n%s", got, tt.want)
+			}
+
+			// Wallets read the order ID and value back unchanged.
+			_, query, _ := strings.Cut(got, "?")
+			params, err := url.ParseQuery(query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if params.Get("string") != tt.orderID || params.Get("value") != tt.value.String() {
+				t.Errorf("URI carries order %q and value %s, want %q and %s", params.Get("string"), params.Get("value"), tt.orderID, tt.value)
+			}
+		})
+	}
+}
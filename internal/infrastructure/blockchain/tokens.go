@@ -0,0 +1,133 @@
+package blockchain
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/hsibAD/payment-service/internal/domain"
+)
+
+// Token is an ERC-20 token accepted for payments on one chain. Symbol is the
+// payment currency the token settles, e.g. USDC.
+type Token struct {
+	ChainID  uint64         `json:"chain_id"`
+	Symbol   string         `json:"symbol"`
+	Address  common.Address `json:"address"`
+	Decimals int            `json:"decimals"`
+}
+
+// TokenRegistry maps a chain ID and currency to the token contract that is
+// paid for it.
+type TokenRegistry struct {
+	tokens map[uint64]map[string]Token
+}
+
+func NewTokenRegistry(tokens ...Token) (*TokenRegistry, error) {
+	r := &TokenRegistry{tokens: make(map[uint64]map[string]Token)}
+	for _, token := range tokens {
+		token.Symbol = strings.ToUpper(token.Symbol)
+
+		exp, err := domain.CurrencyExponent(token.Symbol)
+		if err != nil {
+			return nil, fmt.Errorf("token %s on chain %d: %w", token.Symbol, token.ChainID, err)
+		}
+		// Payment amounts are scaled up to the token's decimals, so a
+		// token with fewer decimals than the currency would drop digits.
+		if token.Decimals < exp {
+			return nil, fmt.Errorf("token %s on chain %d has %d decimals, need at least %d",
+				token.Symbol, token.ChainID, token.Decimals, exp)
+		}
+		if token.Address == (common.Address{}) {
+			return nil, fmt.Errorf("token %s on chain %d has no address", token.Symbol, token.ChainID)
+		}
+
+		if r.tokens[token.ChainID] == nil {
+			r.tokens[token.ChainID] = make(map[string]Token)
+		}
+		r.tokens[token.ChainID][token.Symbol] = token
+	}
+	return r, nil
+}
+
+// DefaultTokenRegistry returns the stablecoins on Ethereum mainnet, Polygon,
+// Arbitrum One and the Sepolia testnet.
+func DefaultTokenRegistry() *TokenRegistry {
+	r, err := NewTokenRegistry(
+		Token{ChainID: 1, Symbol: "USDC", Address: common.HexToAddress("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"), Decimals: 6},
+		Token{ChainID: 1, Symbol: "USDT", Address: common.HexToAddress("0xdAC17F958D2ee523a2206206994597C13D831ec7"), Decimals: 6},
+		Token{ChainID: 1, Symbol: "DAI", Address: common.HexToAddress("0x6B175474E89094C44Da98b954EedeAC495271d0F"), Decimals: 18},
+		Token{ChainID: 137, Symbol: "USDC", Address: common.HexToAddress("0x3c499c542cEF5E3811e1192ce70d8cC03d5c3359"), Decimals: 6},
+		Token{ChainID: 137, Symbol: "USDT", Address: common.HexToAddress("0xc2132D05D31c914a87C6611C10748AEb04B58e8F"), Decimals: 6},
+		Token{ChainID: 137, Symbol: "DAI", Address: common.HexToAddress("0x8f3Cf7ad23Cd3CaDbD9735AFf958023239c6A063"), Decimals: 18},
+		Token{ChainID: 42161, Symbol: "USDC", Address: common.HexToAddress("0xaf88d065e77c8cC2239327C5EDb3A432268e5831"), Decimals: 6},
+		Token{ChainID: 42161, Symbol: "USDT", Address: common.HexToAddress("0xFd086bC7CD5C481DCC9C85ebE478A1C0b69FCbb9"), Decimals: 6},
+		Token{ChainID: 42161, Symbol: "DAI", Address: common.HexToAddress("0xDA10009cBd5D07dd0CeCc66161FC93D7c9000da1"), Decimals: 18},
+		Token{ChainID: 11155111, Symbol: "USDC", Address: common.HexToAddress("0x1c7D4B196Cb0C7B01d743Fbc6116a902379C7238"), Decimals: 6},
+	)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+// LoadTokenRegistry reads a JSON array of tokens, replacing the defaults.
+func LoadTokenRegistry(path string) (*TokenRegistry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token registry: %w", err)
+	}
+
+	var tokens []Token
+	if err := json.Unmarshal(data, &tokens); err != nil {
+		return nil, fmt.Errorf("failed to parse token registry: %w", err)
+	}
+
+	return NewTokenRegistry(tokens...)
+}
+
+// Lookup returns the token that settles currency on the chain.
+func (r *TokenRegistry) Lookup(chainID uint64, currency string) (Token, bool) {
+	if r == nil {
+		return Token{}, false
+	}
+	token, ok := r.tokens[chainID][strings.ToUpper(currency)]
+	return token, ok
+}
+
+// ERC20ABI is the subset of the ERC-20 interface used for token payments and
+// refunds.
+const ERC20ABI = `[
+	{
+		"anonymous": false,
+		"inputs": [
+			{"indexed": true, "internalType": "address", "name": "from", "type": "address"},
+			{"indexed": true, "internalType": "address", "name": "to", "type": "address"},
+			{"indexed": false, "internalType": "uint256", "name": "value", "type": "uint256"}
+		],
+		"name": "Transfer",
+		"type": "event"
+	},
+	{
+		"inputs": [
+			{"internalType": "address", "name": "spender", "type": "address"},
+			{"internalType": "uint256", "name": "amount", "type": "uint256"}
+		],
+		"name": "approve",
+		"outputs": [{"internalType": "bool", "name": "", "type": "bool"}],
+		"stateMutability": "nonpayable",
+		"type": "function"
+	},
+	{
+		"inputs": [
+			{"internalType": "address", "name": "to", "type": "address"},
+			{"internalType": "uint256", "name": "amount", "type": "uint256"}
+		],
+		"name": "transfer",
+		"outputs": [{"internalType": "bool", "name": "", "type": "bool"}],
+		"stateMutability": "nonpayable",
+		"type": "function"
+	}
+]`
@@ -0,0 +1,417 @@
+package blockchain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/hsibAD/payment-service/internal/domain"
+)
+
+// PaymentConfirmer is the part of the payment use cases the watcher drives.
+type PaymentConfirmer interface {
+	GetPendingPaymentsByOrderHashes(ctx context.Context, method domain.PaymentMethod, orderHashes []string) ([]*domain.Payment, error)
+	ConfirmMetaMaskPayment(ctx context.Context, paymentID, transactionHash string, chainID uint64) (*domain.Payment, error)
+}
+
+type WatcherConfig struct {
+	PollInterval time.Duration
+	// StartBlock is where a watcher without a checkpoint starts scanning.
+	// Zero starts at the current head.
+	StartBlock uint64
+	// MaxBlockRange bounds the blocks requested in a single log query.
+	MaxBlockRange uint64
+}
+
+// PaymentWatcher completes crypto payments from the payment contract's
+// PaymentReceived and TokenPaymentReceived events, so payments do not depend
+// on the client calling ConfirmMetaMaskPayment.
+//
+// An event is matched to a pending payment of its order that it pays in full
+// in the payment's currency. When an order has several such payments, the
+// event goes to the largest one it covers, and each payment takes at most one
+// transaction. Every match is recorded together with its block hash. A match
+// completes the payment only once it has minConfirmations blocks on top; if
+// its block is replaced by a chain reorganization before that, the match is
+// dropped and the payment stays pending.
+type PaymentWatcher struct {
+	client           Backend
+	chain            Chain
+	contractAddr     common.Address
+	events           map[common.Hash]abi.Event
+	eventIDs         []common.Hash
+	tokens           *TokenRegistry
+	minConfirmations uint64
+	payments         PaymentConfirmer
+	store            domain.ChainWatcherRepository
+	cfg              WatcherConfig
+	name             string
+}
+
+func NewPaymentWatcher(
+	client Backend,
+	chain Chain,
+	contractABI string,
+	tokens *TokenRegistry,
+	payments PaymentConfirmer,
+	store domain.ChainWatcherRepository,
+	cfg WatcherConfig,
+) (*PaymentWatcher, error) {
+	parsedABI, err := abi.JSON(strings.NewReader(contractABI))
+	if err != nil {
+		return nil, fmt.Errorf("invalid contract ABI: %w", err)
+	}
+	event, ok := parsedABI.Events[paymentReceivedEvent]
+	if !ok {
+		return nil, fmt.Errorf("contract ABI has no %s event", paymentReceivedEvent)
+	}
+	events := map[common.Hash]abi.Event{event.ID: event}
+	eventIDs := []common.Hash{event.ID}
+	if tokenEvent, ok := parsedABI.Events[tokenPaymentReceivedEvent]; ok {
+		events[tokenEvent.ID] = tokenEvent
+		eventIDs = append(eventIDs, tokenEvent.ID)
+	}
+
+	if cfg.MaxBlockRange == 0 {
+		cfg.MaxBlockRange = 1000
+	}
+
+	contractAddr := common.HexToAddress(chain.ContractAddress)
+	return &PaymentWatcher{
+		client:           client,
+		chain:            chain,
+		contractAddr:     contractAddr,
+		events:           events,
+		eventIDs:         eventIDs,
+		tokens:           tokens,
+		minConfirmations: chain.MinConfirmations,
+		payments:         payments,
+		store:            store,
+		cfg:              cfg,
+		name:             fmt.Sprintf("payment_received:%d:%s", chain.ID, strings.ToLower(contractAddr.Hex())),
+	}, nil
+}
+
+// Run polls until ctx is cancelled.
+func (w *PaymentWatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := w.Poll(ctx); err != nil {
+			log.Printf("payment watcher: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll scans the blocks added since the last checkpoint and settles matches
+// that are deep enough.
+func (w *PaymentWatcher) Poll(ctx context.Context) error {
+	head, err := w.client.BlockNumber(ctx)
+	if err != nil {
+		return err
+	}
+
+	from, err := w.nextBlock(ctx, head)
+	if err != nil {
+		return err
+	}
+
+	if from <= head {
+		to := head
+		if to-from+1 > w.cfg.MaxBlockRange {
+			to = from + w.cfg.MaxBlockRange - 1
+		}
+		if err := w.scan(ctx, from, to); err != nil {
+			return err
+		}
+	}
+
+	return w.settleMatches(ctx, head)
+}
+
+// nextBlock returns the first block to scan. If the checkpoint block is no
+// longer canonical, the watcher steps back minConfirmations blocks to pick up
+// events that moved to the new branch.
+func (w *PaymentWatcher) nextBlock(ctx context.Context, head uint64) (uint64, error) {
+	checkpoint, err := w.store.GetCheckpoint(ctx, w.name)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load checkpoint: %w", err)
+	}
+
+	if checkpoint == nil {
+		if w.cfg.StartBlock != 0 {
+			return w.cfg.StartBlock, nil
+		}
+		return head, nil
+	}
+
+	orphaned, err := w.isOrphaned(ctx, checkpoint.BlockNumber, checkpoint.BlockHash)
+	if err != nil {
+		return 0, err
+	}
+	if !orphaned {
+		return checkpoint.BlockNumber + 1, nil
+	}
+
+	log.Printf("payment watcher: block %d was reorganized, rescanning", checkpoint.BlockNumber)
+	if checkpoint.BlockNumber < w.minConfirmations {
+		return 0, nil
+	}
+	return checkpoint.BlockNumber - w.minConfirmations, nil
+}
+
+func (w *PaymentWatcher) scan(ctx context.Context, from, to uint64) error {
+	logs, err := w.client.FilterLogs(ctx, ethereum.FilterQuery{
+		FromBlock: new(big.Int).SetUint64(from),
+		ToBlock:   new(big.Int).SetUint64(to),
+		Addresses: []common.Address{w.contractAddr},
+		Topics:    [][]common.Hash{w.eventIDs},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to filter logs: %w", err)
+	}
+
+	if len(logs) > 0 {
+		pending, err := w.pendingByOrderTopic(ctx, logs)
+		if err != nil {
+			return err
+		}
+
+		matched, err := w.matchedPayments(ctx)
+		if err != nil {
+			return err
+		}
+
+		for _, vLog := range logs {
+			if vLog.Removed || len(vLog.Topics) < 3 {
+				continue
+			}
+			payment := w.paidPayment(vLog, pending[vLog.Topics[1]], matched)
+			if payment == nil {
+				continue
+			}
+			matched[payment.ID] = vLog.TxHash
+
+			match := &domain.ChainMatch{
+				TransactionHash: vLog.TxHash.Hex(),
+				BlockNumber:     vLog.BlockNumber,
+				BlockHash:       vLog.BlockHash.Hex(),
+				PaymentID:       payment.ID,
+				SeenAt:          time.Now(),
+			}
+			if err := w.store.SaveMatch(ctx, w.name, match); err != nil {
+				return fmt.Errorf("failed to save match: %w", err)
+			}
+		}
+	}
+
+	header, err := w.client.HeaderByNumber(ctx, new(big.Int).SetUint64(to))
+	if err != nil {
+		return err
+	}
+
+	return w.store.SaveCheckpoint(ctx, w.name, &domain.ChainCheckpoint{
+		BlockNumber: to,
+		BlockHash:   header.Hash().Hex(),
+		UpdatedAt:   time.Now(),
+	})
+}
+
+// pendingByOrderTopic loads the pending crypto payments on the watcher's
+// chain of the orders the logs pay for, indexed by the topic their order ID
+// has in a payment event.
+func (w *PaymentWatcher) pendingByOrderTopic(ctx context.Context, logs []types.Log) (map[common.Hash][]*domain.Payment, error) {
+	seen := make(map[common.Hash]bool)
+	var orderHashes []string
+	for _, vLog := range logs {
+		if vLog.Removed || len(vLog.Topics) < 3 || seen[vLog.Topics[1]] {
+			continue
+		}
+		seen[vLog.Topics[1]] = true
+		orderHashes = append(orderHashes, vLog.Topics[1].Hex())
+	}
+
+	payments, err := w.payments.GetPendingPaymentsByOrderHashes(ctx, domain.PaymentMethodMetaMask, orderHashes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load pending payments: %w", err)
+	}
+
+	index := make(map[common.Hash][]*domain.Payment, len(payments))
+	for _, payment := range payments {
+		if !w.onChain(payment) {
+			continue
+		}
+		topic := common.HexToHash(domain.OrderHash(payment.OrderID))
+		index[topic] = append(index[topic], payment)
+	}
+	return index, nil
+}
+
+// matchedPayments maps the payments that already have a match waiting for
+// confirmations to the matched transaction.
+func (w *PaymentWatcher) matchedPayments(ctx context.Context) (map[string]common.Hash, error) {
+	matches, err := w.store.GetMatches(ctx, w.name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load matches: %w", err)
+	}
+
+	matched := make(map[string]common.Hash, len(matches))
+	for _, match := range matches {
+		matched[match.PaymentID] = common.HexToHash(match.TransactionHash)
+	}
+	return matched, nil
+}
+
+// paidPayment returns the payment of the log's order that the log pays, or
+// nil. Of the payments the log covers that are not matched to another
+// transaction, the one with the largest expected amount wins, so a log is not
+// spent on a smaller payment of the same order. A transaction that moved to
+// another block in a reorganization keeps its payment.
+func (w *PaymentWatcher) paidPayment(vLog types.Log, candidates []*domain.Payment, matched map[string]common.Hash) *domain.Payment {
+	event, ok := w.events[vLog.Topics[0]]
+	if !ok {
+		return nil
+	}
+	values, err := event.Inputs.NonIndexed().Unpack(vLog.Data)
+	if err != nil || len(values) != 1 {
+		return nil
+	}
+	amount, ok := values[0].(*big.Int)
+	if !ok {
+		return nil
+	}
+
+	var best *domain.Payment
+	var bestExpected *big.Int
+	for _, payment := range candidates {
+		if txHash, ok := matched[payment.ID]; ok && txHash != vLog.TxHash {
+			continue
+		}
+		expected, ok := w.expectedAmount(payment, event, vLog)
+		if !ok || amount.Cmp(expected) < 0 {
+			continue
+		}
+		if best == nil || expected.Cmp(bestExpected) > 0 {
+			best, bestExpected = payment, expected
+		}
+	}
+	return best
+}
+
+// expectedAmount returns the least the payment accepts in the log's units.
+// It is false if the log pays in a different currency than the payment: the
+// native currency for PaymentReceived, or another token for
+// TokenPaymentReceived.
+func (w *PaymentWatcher) expectedAmount(payment *domain.Payment, event abi.Event, vLog types.Log) (*big.Int, bool) {
+	minimum := payment.MinimumPayment()
+	if strings.EqualFold(minimum.Currency, w.chain.NativeCurrency) {
+		if event.Name != paymentReceivedEvent {
+			return nil, false
+		}
+		return minimum.ScaledTo(18), true
+	}
+
+	token, ok := w.tokens.Lookup(w.chain.ID, minimum.Currency)
+	if !ok || event.Name != tokenPaymentReceivedEvent ||
+		len(vLog.Topics) != 4 || vLog.Topics[3] != common.BytesToHash(token.Address.Bytes()) {
+		return nil, false
+	}
+	return minimum.ScaledTo(token.Decimals), true
+}
+
+func (w *PaymentWatcher) settleMatches(ctx context.Context, head uint64) error {
+	matches, err := w.store.GetMatches(ctx, w.name)
+	if err != nil {
+		return fmt.Errorf("failed to load matches: %w", err)
+	}
+
+	for _, match := range matches {
+		if head < match.BlockNumber+w.minConfirmations {
+			continue
+		}
+
+		orphaned, err := w.isOrphaned(ctx, match.BlockNumber, match.BlockHash)
+		if err != nil {
+			return err
+		}
+		if orphaned {
+			log.Printf("payment watcher: dropping match %s for payment %s, block %d was reorganized",
+				match.TransactionHash, match.PaymentID, match.BlockNumber)
+			if err := w.store.DeleteMatch(ctx, w.name, match.TransactionHash); err != nil {
+				return err
+			}
+			continue
+		}
+
+		_, err = w.payments.ConfirmMetaMaskPayment(ctx, match.PaymentID, match.TransactionHash, w.chain.ID)
+		switch {
+		case errors.Is(err, domain.ErrPaymentConflict):
+			// Saved by someone else meanwhile. The match is confirmed
+			// again on the next poll, which sees what they saved.
+			continue
+		case err != nil && !isFinalConfirmationError(err):
+			log.Printf("payment watcher: failed to confirm payment %s: %v", match.PaymentID, err)
+			continue
+		case err != nil:
+			log.Printf("payment watcher: match %s rejected for payment %s: %v", match.TransactionHash, match.PaymentID, err)
+		}
+
+		if err := w.store.DeleteMatch(ctx, w.name, match.TransactionHash); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// onChain reports whether the payment is paid on the watcher's chain.
+// Payments without a chain belong to the default chain.
+func (w *PaymentWatcher) onChain(payment *domain.Payment) bool {
+	if payment.ChainID == 0 {
+		return w.chain.Default
+	}
+	return payment.ChainID == w.chain.ID
+}
+
+func (w *PaymentWatcher) isOrphaned(ctx context.Context, number uint64, hash string) (bool, error) {
+	header, err := w.client.HeaderByNumber(ctx, new(big.Int).SetUint64(number))
+	if err != nil {
+		if errors.Is(err, ethereum.NotFound) {
+			return true, nil
+		}
+		return false, err
+	}
+	return header.Hash() != common.HexToHash(hash), nil
+}
+
+// isFinalConfirmationError reports whether confirming again could never
+// succeed, so the match can be discarded.
+func isFinalConfirmationError(err error) bool {
+	var transitionErr domain.ErrInvalidTransition
+	return errors.As(err, &transitionErr) ||
+		errors.Is(err, domain.ErrInvalidPaymentID) ||
+		errors.Is(err, domain.ErrPaymentNotPending) ||
+		errors.Is(err, domain.ErrPaymentMethodMismatch) ||
+		errors.Is(err, domain.ErrTransactionAlreadyUsed) ||
+		errors.Is(err, domain.ErrWrongChain) ||
+		errors.Is(err, domain.ErrPaymentLogNotFound) ||
+		errors.Is(err, domain.ErrWrongPaymentContract) ||
+		errors.Is(err, domain.ErrPaymentOrderMismatch) ||
+		errors.Is(err, domain.ErrPaymentUnderpaid) ||
+		errors.Is(err, domain.ErrPaymentTokenMismatch)
+}
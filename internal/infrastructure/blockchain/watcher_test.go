@@ -0,0 +1,277 @@
+package blockchain_test
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/hsibAD/payment-service/internal/domain"
+	"github.com/hsibAD/payment-service/internal/infrastructure/blockchain"
+	"github.com/hsibAD/payment-service/internal/repository/memory"
+)
+
+// stubConfirmer hands out the pending payments of the orders asked for,
+// counting the payments handed out, and records which transaction
+// confirmed each of them. The first conflicts confirmations fail as if the
+// payment had been saved concurrently.
+type stubConfirmer struct {
+	mu        sync.Mutex
+	pending   []*domain.Payment
+	confirmed map[string]string
+	conflicts int
+	loaded    int
+}
+
+func newStubConfirmer(payments ...*domain.Payment) *stubConfirmer {
+	return &stubConfirmer{pending: payments, confirmed: make(map[string]string)}
+}
+
+func (c *stubConfirmer) GetPendingPaymentsByOrderHashes(ctx context.Context, method domain.PaymentMethod, orderHashes []string) ([]*domain.Payment, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var payments []*domain.Payment
+	for _, payment := range c.pending {
+		for _, orderHash := range orderHashes {
+			if domain.OrderHash(payment.OrderID) == orderHash {
+				payments = append(payments, payment)
+			}
+		}
+	}
+	c.loaded += len(payments)
+	return payments, nil
+}
+
+func (c *stubConfirmer) ConfirmMetaMaskPayment(ctx context.Context, paymentID, transactionHash string, chainID uint64) (*domain.Payment, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.conflicts > 0 {
+		c.conflicts--
+		return nil, domain.ErrPaymentConflict
+	}
+	for i, payment := range c.pending {
+		if payment.ID == paymentID {
+			c.pending = append(c.pending[:i], c.pending[i+1:]...)
+			c.confirmed[paymentID] = transactionHash
+			return payment, nil
+		}
+	}
+	return nil, domain.ErrPaymentNotPending
+}
+
+func (c *stubConfirmer) confirmations() map[string]string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	confirmed := make(map[string]string, len(c.confirmed))
+	for paymentID, txHash := range c.confirmed {
+		confirmed[paymentID] = txHash
+	}
+	return confirmed
+}
+
+type watcherTest struct {
+	*chainTest
+	watcher   *blockchain.PaymentWatcher
+	store     *memory.ChainWatcherRepository
+	confirmer *stubConfirmer
+}
+
+func newWatcherTest(t *testing.T, payments ...*domain.Payment) *watcherTest {
+	t.Helper()
+
+	wt := &watcherTest{
+		chainTest: newChainTest(t),
+		store:     memory.NewChainWatcherRepository(),
+		confirmer: newStubConfirmer(payments...),
+	}
+	watcher, err := blockchain.NewPaymentWatcher(
+		wt.client, wt.chain, blockchain.PaymentContractABI, nil, wt.confirmer, wt.store,
+		blockchain.WatcherConfig{PollInterval: time.Second, StartBlock: 1},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wt.watcher = watcher
+	return wt
+}
+
+func (wt *watcherTest) poll(t *testing.T) {
+	t.Helper()
+
+	if err := wt.watcher.Poll(context.Background()); err != nil {
+		t.Fatalf("Poll: %v", err)
+	}
+}
+
+// replace replaces the transaction with a plain transfer from the same
+// account, so a reorganization that drops it cannot mine it again.
+func (ct *chainTest) replace(t *testing.T, tx *types.Transaction) {
+	t.Helper()
+
+	replacement, err := types.SignNewTx(ct.payer, types.LatestSignerForChainID(tx.ChainId()), &types.DynamicFeeTx{
+		ChainID:   tx.ChainId(),
+		Nonce:     tx.Nonce(),
+		GasTipCap: new(big.Int).Mul(tx.GasTipCap(), big.NewInt(2)),
+		GasFeeCap: new(big.Int).Mul(tx.GasFeeCap(), big.NewInt(2)),
+		Gas:       21000,
+		To:        &strangerAddr,
+		Value:     big.NewInt(0),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The pool follows the fork in the background; until it does, it sees
+	// the nonce as used.
+	for attempt := 0; ; attempt++ {
+		err = ct.client.SendTransaction(context.Background(), replacement)
+		if err == nil {
+			return
+		}
+		if !strings.Contains(err.Error(), "nonce too low") || attempt == 100 {
+			t.Fatal(err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestWatcherConfirmsAfterConfirmations(t *testing.T) {
+	payment := ethPayment("order-1", 1e17)
+	wt := newWatcherTest(t, payment)
+
+	txHash := wt.pay(t, contractAddr, "order-1", big.NewInt(1e17))
+	wt.poll(t)
+	if confirmed := wt.confirmer.confirmations(); len(confirmed) != 0 {
+		t.Fatalf("confirmed %v before %d confirmations", confirmed, minConfirmations)
+	}
+
+	wt.confirm()
+	wt.poll(t)
+	if got := wt.confirmer.confirmations()[payment.ID]; got != txHash.Hex() {
+		t.Errorf("payment confirmed with %q, want %s", got, txHash.Hex())
+	}
+}
+
+func TestWatcherMatchesPaymentsOfAnOrderByAmount(t *testing.T) {
+	// The customer retried, so the order has a small and a large payment.
+	small := ethPayment("order-1", 1e17)
+	large := ethPayment("order-1", 2e17)
+	large.ID = "payment-order-1-retry"
+	wt := newWatcherTest(t, small, large)
+
+	underpaid := wt.pay(t, contractAddr, "order-1", big.NewInt(5e16))
+	largeTx := wt.pay(t, contractAddr, "order-1", big.NewInt(2e17))
+	smallTx := wt.pay(t, contractAddr, "order-1", big.NewInt(1e17))
+	// Pays the small payment again; it must not be matched to either.
+	wt.pay(t, contractAddr, "order-1", big.NewInt(1e17))
+	wt.confirm()
+	wt.poll(t)
+
+	confirmed := wt.confirmer.confirmations()
+	if confirmed[large.ID] != largeTx.Hex() {
+		t.Errorf("large payment confirmed with %q, want %s", confirmed[large.ID], largeTx.Hex())
+	}
+	if confirmed[small.ID] != smallTx.Hex() {
+		t.Errorf("small payment confirmed with %q, want %s", confirmed[small.ID], smallTx.Hex())
+	}
+	for paymentID, txHash := range confirmed {
+		if txHash == underpaid.Hex() {
+			t.Errorf("payment %s confirmed with an underpaying transaction", paymentID)
+		}
+	}
+}
+
+func TestWatcherDropsMatchOrphanedByReorg(t *testing.T) {
+	payment := ethPayment("order-1", 1e17)
+	wt := newWatcherTest(t, payment)
+
+	parent := wt.backend.Commit()
+	txHash := wt.pay(t, contractAddr, "order-1", big.NewInt(1e17))
+	tx, _, err := wt.client.TransactionByHash(context.Background(), txHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wt.poll(t)
+
+	// A longer branch without the payment replaces the block it was in.
+	if err := wt.backend.Fork(parent); err != nil {
+		t.Fatal(err)
+	}
+	wt.replace(t, tx)
+	for i := 0; i < minConfirmations+2; i++ {
+		wt.backend.Commit()
+	}
+	wt.poll(t)
+	wt.confirm()
+	wt.poll(t)
+
+	if confirmed := wt.confirmer.confirmations(); len(confirmed) != 0 {
+		t.Errorf("confirmed %v with an orphaned transaction", confirmed)
+	}
+}
+
+func TestWatcherFollowsTransactionMovedByReorg(t *testing.T) {
+	payment := ethPayment("order-1", 1e17)
+	wt := newWatcherTest(t, payment)
+
+	parent := wt.backend.Commit()
+	txHash := wt.pay(t, contractAddr, "order-1", big.NewInt(1e17))
+	wt.poll(t)
+
+	// The new branch mines the same transaction in a different block.
+	if err := wt.backend.Fork(parent); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < minConfirmations+2; i++ {
+		wt.backend.Commit()
+	}
+	wt.poll(t)
+
+	if got := wt.confirmer.confirmations()[payment.ID]; got != txHash.Hex() {
+		t.Errorf("payment confirmed with %q, want %s", got, txHash.Hex())
+	}
+}
+
+func TestWatcherKeepsMatchAfterConflict(t *testing.T) {
+	payment := ethPayment("order-1", 1e17)
+	wt := newWatcherTest(t, payment)
+	wt.confirmer.conflicts = 1
+
+	txHash := wt.pay(t, contractAddr, "order-1", big.NewInt(1e17))
+	wt.confirm()
+	wt.poll(t)
+	if confirmed := wt.confirmer.confirmations(); len(confirmed) != 0 {
+		t.Fatalf("confirmed %v despite the conflict", confirmed)
+	}
+
+	wt.poll(t)
+	if got := wt.confirmer.confirmations()[payment.ID]; got != txHash.Hex() {
+		t.Errorf("payment confirmed with %q, want %s", got, txHash.Hex())
+	}
+}
+
+func TestWatcherLoadsOnlyPaymentsOfPaidOrders(t *testing.T) {
+	paid := ethPayment("order-1", 1e17)
+	payments := []*domain.Payment{paid}
+	for i := 0; i < 1500; i++ {
+		payments = append(payments, ethPayment(fmt.Sprintf("order-other-%d", i), 1e17))
+	}
+	wt := newWatcherTest(t, payments...)
+
+	txHash := wt.pay(t, contractAddr, "order-1", big.NewInt(1e17))
+	wt.confirm()
+	wt.poll(t)
+
+	if got := wt.confirmer.confirmations()[paid.ID]; got != txHash.Hex() {
+		t.Errorf("payment confirmed with %q, want %s", got, txHash.Hex())
+	}
+	if wt.confirmer.loaded != 1 {
+		t.Errorf("loaded %d pending payments, want only the paid order's", wt.confirmer.loaded)
+	}
+}
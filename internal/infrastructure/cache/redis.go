@@ -1,168 +1,300 @@
-package cache
-
-import (
-	"context"
-	"encoding/json"
-	"time"
-
-	"github.com/go-redis/redis/v8"
-	"github.com/hsibAD/payment-service/internal/domain"
-)
-
-type RedisCache struct {
-	client *redis.Client
-}
-
-func NewRedisCache(addr string, password string, db int) *RedisCache {
-	client := redis.NewClient(&redis.Options{
-		Addr:     addr,
-		Password: password,
-		DB:       db,
-	})
-
-	return &RedisCache{
-		client: client,
-	}
-}
-
-func (c *RedisCache) Set(ctx context.Context, key string, value interface{}, ttl int) error {
-	data, err := json.Marshal(value)
-	if err != nil {
-		return err
-	}
-
-	return c.client.Set(ctx, key, data, time.Duration(ttl)*time.Second).Err()
-}
-
-func (c *RedisCache) Get(ctx context.Context, key string) (interface{}, error) {
-	data, err := c.client.Get(ctx, key).Bytes()
-	if err != nil {
-		if err == redis.Nil {
-			return nil, nil
-		}
-		return nil, err
-	}
-
-	var value interface{}
-	if err := json.Unmarshal(data, &value); err != nil {
-		return nil, err
-	}
-
-	return value, nil
-}
-
-func (c *RedisCache) Delete(ctx context.Context, key string) error {
-	return c.client.Del(ctx, key).Err()
-}
-
-// Payment-specific cache methods
-func (c *RedisCache) GetPayment(ctx context.Context, paymentID string) (*domain.Payment, error) {
-	key := "payment:" + paymentID
-	data, err := c.client.Get(ctx, key).Bytes()
-	if err != nil {
-		if err == redis.Nil {
-			return nil, nil
-		}
-		return nil, err
-	}
-
-	var payment domain.Payment
-	if err := json.Unmarshal(data, &payment); err != nil {
-		return nil, err
-	}
-
-	return &payment, nil
-}
-
-func (c *RedisCache) SetPayment(ctx context.Context, payment *domain.Payment, ttl int) error {
-	key := "payment:" + payment.ID
-	return c.Set(ctx, key, payment, ttl)
-}
-
-func (c *RedisCache) DeletePayment(ctx context.Context, paymentID string) error {
-	key := "payment:" + paymentID
-	return c.Delete(ctx, key)
-}
-
-// Order payments cache methods
-func (c *RedisCache) GetOrderPayments(ctx context.Context, orderID string) ([]*domain.Payment, error) {
-	key := "order_payments:" + orderID
-	data, err := c.client.Get(ctx, key).Bytes()
-	if err != nil {
-		if err == redis.Nil {
-			return nil, nil
-		}
-		return nil, err
-	}
-
-	var payments []*domain.Payment
-	if err := json.Unmarshal(data, &payments); err != nil {
-		return nil, err
-	}
-
-	return payments, nil
-}
-
-func (c *RedisCache) SetOrderPayments(ctx context.Context, orderID string, payments []*domain.Payment, ttl int) error {
-	key := "order_payments:" + orderID
-	return c.Set(ctx, key, payments, ttl)
-}
-
-func (c *RedisCache) DeleteOrderPayments(ctx context.Context, orderID string) error {
-	key := "order_payments:" + orderID
-	return c.Delete(ctx, key)
-}
-
-// User payments cache methods
-func (c *RedisCache) GetUserPayments(ctx context.Context, userID string, page, limit int) ([]*domain.Payment, error) {
-	key := "user_payments:" + userID + ":" + string(page) + ":" + string(limit)
-	data, err := c.client.Get(ctx, key).Bytes()
-	if err != nil {
-		if err == redis.Nil {
-			return nil, nil
-		}
-		return nil, err
-	}
-
-	var payments []*domain.Payment
-	if err := json.Unmarshal(data, &payments); err != nil {
-		return nil, err
-	}
-
-	return payments, nil
-}
-
-func (c *RedisCache) SetUserPayments(ctx context.Context, userID string, page, limit int, payments []*domain.Payment, ttl int) error {
-	key := "user_payments:" + userID + ":" + string(page) + ":" + string(limit)
-	return c.Set(ctx, key, payments, ttl)
-}
-
-func (c *RedisCache) DeleteUserPayments(ctx context.Context, userID string) error {
-	pattern := "user_payments:" + userID + ":*"
-	keys, err := c.client.Keys(ctx, pattern).Result()
-	if err != nil {
-		return err
-	}
-
-	if len(keys) > 0 {
-		return c.client.Del(ctx, keys...).Err()
-	}
-
-	return nil
-}
-
-// MetaMask transaction cache methods
-func (c *RedisCache) GetTransactionStatus(ctx context.Context, txHash string) (string, error) {
-	key := "tx_status:" + txHash
-	return c.client.Get(ctx, key).Result()
-}
-
-func (c *RedisCache) SetTransactionStatus(ctx context.Context, txHash string, status string, ttl int) error {
-	key := "tx_status:" + txHash
-	return c.client.Set(ctx, key, status, time.Duration(ttl)*time.Second).Err()
-}
-
-func (c *RedisCache) DeleteTransactionStatus(ctx context.Context, txHash string) error {
-	key := "tx_status:" + txHash
-	return c.Delete(ctx, key)
-} 
\ No newline at end of file
+package cache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/hsibAD/payment-service/internal/domain"
+)
+
+type RedisCache struct {
+	client *redis.Client
+}
+
+func NewRedisCache(addr string, password string, db int) *RedisCache {
+	client := redis.NewClient(&redis.Options{
+		Addr:     addr,
+		Password: password,
+		DB:       db,
+	})
+
+	return &RedisCache{
+		client: client,
+	}
+}
+
+func (c *RedisCache) Set(ctx context.Context, key string, value interface{}, ttl int) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	return c.client.Set(ctx, key, data, time.Duration(ttl)*time.Second).Err()
+}
+
+func (c *RedisCache) Get(ctx context.Context, key string) (interface{}, error) {
+	data, err := c.client.Get(ctx, key).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+
+	return value, nil
+}
+
+func (c *RedisCache) Delete(ctx context.Context, key string) error {
+	return c.client.Del(ctx, key).Err()
+}
+
+// Payment-specific cache methods
+func (c *RedisCache) GetPayment(ctx context.Context, paymentID string) (*domain.Payment, error) {
+	key := "payment:" + paymentID
+	data, err := c.client.Get(ctx, key).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var payment domain.Payment
+	if err := json.Unmarshal(data, &payment); err != nil {
+		return nil, err
+	}
+
+	return &payment, nil
+}
+
+func (c *RedisCache) SetPayment(ctx context.Context, payment *domain.Payment, ttl int) error {
+	key := "payment:" + payment.ID
+	return c.Set(ctx, key, payment, ttl)
+}
+
+func (c *RedisCache) DeletePayment(ctx context.Context, paymentID string) error {
+	key := "payment:" + paymentID
+	return c.Delete(ctx, key)
+}
+
+// Order payments cache methods
+func (c *RedisCache) GetOrderPayments(ctx context.Context, orderID string) ([]*domain.Payment, error) {
+	key := "order_payments:" + orderID
+	data, err := c.client.Get(ctx, key).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var payments []*domain.Payment
+	if err := json.Unmarshal(data, &payments); err != nil {
+		return nil, err
+	}
+
+	return payments, nil
+}
+
+func (c *RedisCache) SetOrderPayments(ctx context.Context, orderID string, payments []*domain.Payment, ttl int) error {
+	key := "order_payments:" + orderID
+	return c.Set(ctx, key, payments, ttl)
+}
+
+func (c *RedisCache) DeleteOrderPayments(ctx context.Context, orderID string) error {
+	key := "order_payments:" + orderID
+	return c.Delete(ctx, key)
+}
+
+// User payments cache methods
+func (c *RedisCache) GetUserPayments(ctx context.Context, userID string, page, limit int) ([]*domain.Payment, error) {
+	key := "user_payments:" + userID + ":" + strconv.Itoa(page) + ":" + strconv.Itoa(limit)
+	data, err := c.client.Get(ctx, key).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var payments []*domain.Payment
+	if err := json.Unmarshal(data, &payments); err != nil {
+		return nil, err
+	}
+
+	return payments, nil
+}
+
+func (c *RedisCache) SetUserPayments(ctx context.Context, userID string, page, limit int, payments []*domain.Payment, ttl int) error {
+	key := "user_payments:" + userID + ":" + strconv.Itoa(page) + ":" + strconv.Itoa(limit)
+	return c.Set(ctx, key, payments, ttl)
+}
+
+func (c *RedisCache) DeleteUserPayments(ctx context.Context, userID string) error {
+	pattern := "user_payments:" + userID + ":*"
+	keys, err := c.client.Keys(ctx, pattern).Result()
+	if err != nil {
+		return err
+	}
+
+	if len(keys) > 0 {
+		return c.client.Del(ctx, keys...).Err()
+	}
+
+	return nil
+}
+
+// MetaMask transaction cache methods
+func (c *RedisCache) GetTransactionStatus(ctx context.Context, txHash string) (string, error) {
+	key := "tx_status:" + txHash
+	return c.client.Get(ctx, key).Result()
+}
+
+func (c *RedisCache) SetTransactionStatus(ctx context.Context, txHash string, status string, ttl int) error {
+	key := "tx_status:" + txHash
+	return c.client.Set(ctx, key, status, time.Duration(ttl)*time.Second).Err()
+}
+
+func (c *RedisCache) DeleteTransactionStatus(ctx context.Context, txHash string) error {
+	key := "tx_status:" + txHash
+	return c.Delete(ctx, key)
+}
+
+// Idempotency methods implement domain.IdempotencyStore.
+func (c *RedisCache) Lock(ctx context.Context, key, fingerprint string, ttl int) (*domain.IdempotencyRecord, error) {
+	redisKey := "idempotency:" + key
+	data, err := json.Marshal(&domain.IdempotencyRecord{Fingerprint: fingerprint})
+	if err != nil {
+		return nil, err
+	}
+
+	// The key may expire between a failed SETNX and the GET that follows,
+	// in which case claiming it again is correct.
+	for attempt := 0; attempt < 2; attempt++ {
+		acquired, err := c.client.SetNX(ctx, redisKey, data, time.Duration(ttl)*time.Second).Result()
+		if err != nil {
+			return nil, err
+		}
+		if acquired {
+			return nil, nil
+		}
+
+		existing, err := c.client.Get(ctx, redisKey).Bytes()
+		if err == redis.Nil {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var record domain.IdempotencyRecord
+		if err := json.Unmarshal(existing, &record); err != nil {
+			return nil, err
+		}
+		return &record, nil
+	}
+
+	return nil, domain.ErrIdempotencyInProgress
+}
+
+func (c *RedisCache) Complete(ctx context.Context, key string, record *domain.IdempotencyRecord, ttl int) error {
+	return c.Set(ctx, "idempotency:"+key, record, ttl)
+}
+
+func (c *RedisCache) Release(ctx context.Context, key string) error {
+	return c.Delete(ctx, "idempotency:"+key)
+}
+
+// Wallet challenge methods implement domain.WalletChallengeStore.
+func (c *RedisCache) SaveChallenge(ctx context.Context, challenge *domain.WalletChallenge, ttl int) error {
+	return c.Set(ctx, "wallet_challenge:"+challenge.Nonce, challenge, ttl)
+}
+
+func (c *RedisCache) TakeChallenge(ctx context.Context, nonce string) (*domain.WalletChallenge, error) {
+	data, err := c.client.GetDel(ctx, "wallet_challenge:"+nonce).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, domain.ErrChallengeNotFound
+		}
+		return nil, err
+	}
+
+	var challenge domain.WalletChallenge
+	if err := json.Unmarshal(data, &challenge); err != nil {
+		return nil, err
+	}
+
+	return &challenge, nil
+}
+
+// Event counter methods implement domain.EventCounter. Each key is a sorted
+// set of events scored by the time they were recorded, in milliseconds.
+func (c *RedisCache) CountEvents(ctx context.Context, key string, window time.Duration) (int, error) {
+	since := time.Now().Add(-window).UnixMilli()
+	count, err := c.client.ZCount(ctx, "events:"+key, strconv.FormatInt(since, 10), "+inf").Result()
+	if err != nil {
+		return 0, err
+	}
+	return int(count), nil
+}
+
+func (c *RedisCache) RecordEvent(ctx context.Context, key string, retention time.Duration) error {
+	member := make([]byte, 8)
+	if _, err := rand.Read(member); err != nil {
+		return err
+	}
+	return c.RecordDistinctEvent(ctx, key, hex.EncodeToString(member), retention)
+}
+
+func (c *RedisCache) RecordDistinctEvent(ctx context.Context, key, member string, retention time.Duration) error {
+	now := time.Now()
+	redisKey := "events:" + key
+	_, err := c.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZAdd(ctx, redisKey, &redis.Z{Score: float64(now.UnixMilli()), Member: member})
+		pipe.ZRemRangeByScore(ctx, redisKey, "-inf", "("+strconv.FormatInt(now.Add(-retention).UnixMilli(), 10))
+		pipe.Expire(ctx, redisKey, retention)
+		return nil
+	})
+	return err
+}
+
+// Lockout methods implement domain.LockoutStore.
+func (c *RedisCache) Lockout(ctx context.Context, key string) (time.Duration, error) {
+	ttl, err := c.client.PTTL(ctx, "lockout:"+key).Result()
+	if err != nil {
+		return 0, err
+	}
+	// PTTL reports missing keys and keys without expiry as negative.
+	if ttl < 0 {
+		return 0, nil
+	}
+	return ttl, nil
+}
+
+func (c *RedisCache) LockOut(ctx context.Context, key string, d time.Duration) error {
+	return c.client.Set(ctx, "lockout:"+key, 1, d).Err()
+}
+
+func (c *RedisCache) AddStrike(ctx context.Context, key string, memory time.Duration) (int, error) {
+	redisKey := "lockout_strikes:" + key
+	var incr *redis.IntCmd
+	_, err := c.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		incr = pipe.Incr(ctx, redisKey)
+		pipe.Expire(ctx, redisKey, memory)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int(incr.Val()), nil
+}
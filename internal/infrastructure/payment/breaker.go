@@ -0,0 +1,59 @@
+package payment
+
+import (
+	"sync"
+	"time"
+)
+
+// BreakerConfig configures the circuit breaker kept for every card
+// provider.
+type BreakerConfig struct {
+	// FailureThreshold is the number of unanswered calls in a row that
+	// opens the circuit.
+	FailureThreshold int
+	// Cooldown is how long an open circuit keeps payments away from the
+	// provider.
+	Cooldown time.Duration
+}
+
+// breaker is a consecutive-failure circuit breaker. Once open it rejects
+// calls until the cooldown has passed and then lets them through again;
+// a success closes it, while another failure reopens it at once.
+type breaker struct {
+	cfg BreakerConfig
+	now func() time.Time
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+}
+
+func newBreaker(cfg BreakerConfig) *breaker {
+	if cfg.FailureThreshold <= 0 {
+		cfg.FailureThreshold = 1
+	}
+	return &breaker{cfg: cfg, now: time.Now}
+}
+
+// allow reports whether the circuit lets a call through.
+func (b *breaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return !b.now().Before(b.openUntil)
+}
+
+func (b *breaker) success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures = 0
+	b.openUntil = time.Time{}
+}
+
+func (b *breaker) failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures++
+	if b.failures >= b.cfg.FailureThreshold {
+		b.openUntil = b.now().Add(b.cfg.Cooldown)
+	}
+}
@@ -0,0 +1,49 @@
+package payment
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBreaker(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	b := newBreaker(BreakerConfig{FailureThreshold: 2, Cooldown: time.Minute})
+	b.now = func() time.Time { return now }
+
+	steps := []struct {
+		name    string
+		advance time.Duration
+		outcome func()
+		allow   bool
+	}{
+		{name: "closed", allow: true},
+		{name: "first failure", outcome: b.failure, allow: true},
+		{name: "success resets the count", outcome: b.success, allow: true},
+		{name: "failure after success", outcome: b.failure, allow: true},
+		{name: "threshold reached", outcome: b.failure, allow: false},
+		{name: "during cooldown", advance: 59 * time.Second, allow: false},
+		{name: "cooldown over", advance: time.Second, allow: true},
+		{name: "failure after cooldown reopens at once", outcome: b.failure, allow: false},
+		{name: "second cooldown over", advance: time.Minute, allow: true},
+		{name: "success closes", outcome: b.success, allow: true},
+		{name: "single failure once closed", outcome: b.failure, allow: true},
+	}
+
+	for _, step := range steps {
+		now = now.Add(step.advance)
+		if step.outcome != nil {
+			step.outcome()
+		}
+		if got := b.allow(); got != step.allow {
+			t.Fatalf("%s: allow = %v, want %v", step.name, got, step.allow)
+		}
+	}
+}
+
+func TestBreakerThresholdDefaultsToOne(t *testing.T) {
+	b := newBreaker(BreakerConfig{Cooldown: time.Minute})
+	b.failure()
+	if b.allow() {
+		t.Error("breaker without a threshold stayed closed after a failure")
+	}
+}
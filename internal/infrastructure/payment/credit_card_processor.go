@@ -1,187 +1,148 @@
-package payment
-
-import (
-	"context"
-	"errors"
-	"fmt"
-	"regexp"
-	"strconv"
-	"time"
-
-	"github.com/stripe/stripe-go/v74"
-	"github.com/stripe/stripe-go/v74/charge"
-	"github.com/stripe/stripe-go/v74/refund"
-	"github.com/hsibAD/payment-service/internal/domain"
-)
-
-var (
-	ErrInvalidCardNumber   = errors.New("invalid card number")
-	ErrInvalidExpiryMonth = errors.New("invalid expiry month")
-	ErrInvalidExpiryYear  = errors.New("invalid expiry year")
-	ErrInvalidCVV        = errors.New("invalid CVV")
-	ErrCardExpired       = errors.New("card has expired")
-	ErrPaymentFailed     = errors.New("payment failed")
-)
-
-type CreditCardProcessor struct {
-	stripeSecretKey string
-}
-
-func NewCreditCardProcessor(stripeSecretKey string) *CreditCardProcessor {
-	stripe.Key = stripeSecretKey
-	return &CreditCardProcessor{
-		stripeSecretKey: stripeSecretKey,
-	}
-}
-
-func (p *CreditCardProcessor) ProcessPayment(ctx context.Context, payment *domain.Payment, cardInfo *domain.CreditCardInfo) error {
-	if err := p.ValidateCard(ctx, cardInfo); err != nil {
-		return err
-	}
-
-	// Create Stripe token
-	token, err := p.createStripeToken(cardInfo)
-	if err != nil {
-		return fmt.Errorf("failed to create stripe token: %w", err)
-	}
-
-	// Create charge parameters
-	params := &stripe.ChargeParams{
-		Amount:      stripe.Int64(int64(payment.Amount * 100)), // Convert to cents
-		Currency:    stripe.String(string(payment.Currency)),
-		Source:      &token.ID,
-		Description: stripe.String(fmt.Sprintf("Payment for order %s", payment.OrderID)),
-		Metadata: map[string]string{
-			"order_id":    payment.OrderID,
-			"payment_id":  payment.ID,
-			"customer_id": payment.UserID,
-		},
-	}
-
-	// Create charge
-	ch, err := charge.New(params)
-	if err != nil {
-		return fmt.Errorf("failed to create charge: %w", err)
-	}
-
-	if !ch.Paid {
-		return ErrPaymentFailed
-	}
-
-	payment.TransactionID = ch.ID
-	return nil
-}
-
-func (p *CreditCardProcessor) RefundPayment(ctx context.Context, payment *domain.Payment) error {
-	if payment.TransactionID == "" {
-		return errors.New("no transaction ID found")
-	}
-
-	params := &stripe.RefundParams{
-		Charge: stripe.String(payment.TransactionID),
-		Metadata: map[string]string{
-			"order_id":    payment.OrderID,
-			"payment_id":  payment.ID,
-			"customer_id": payment.UserID,
-		},
-	}
-
-	_, err := refund.New(params)
-	if err != nil {
-		return fmt.Errorf("failed to create refund: %w", err)
-	}
-
-	return nil
-}
-
-func (p *CreditCardProcessor) ValidateCard(ctx context.Context, cardInfo *domain.CreditCardInfo) error {
-	// Validate card number (Luhn algorithm)
-	if !isValidCardNumber(cardInfo.CardNumber) {
-		return ErrInvalidCardNumber
-	}
-
-	// Validate expiry month
-	month, err := strconv.Atoi(cardInfo.ExpiryMonth)
-	if err != nil || month < 1 || month > 12 {
-		return ErrInvalidExpiryMonth
-	}
-
-	// Validate expiry year
-	year, err := strconv.Atoi(cardInfo.ExpiryYear)
-	if err != nil || year < time.Now().Year() {
-		return ErrInvalidExpiryYear
-	}
-
-	// Check if card is expired
-	currentTime := time.Now()
-	if year < currentTime.Year() || (year == currentTime.Year() && month < int(currentTime.Month())) {
-		return ErrCardExpired
-	}
-
-	// Validate CVV
-	if !isValidCVV(cardInfo.CVV) {
-		return ErrInvalidCVV
-	}
-
-	return nil
-}
-
-func (p *CreditCardProcessor) createStripeToken(cardInfo *domain.CreditCardInfo) (*stripe.Token, error) {
-	params := &stripe.TokenParams{
-		Card: &stripe.CardParams{
-			Number:   stripe.String(cardInfo.CardNumber),
-			ExpMonth: stripe.String(cardInfo.ExpiryMonth),
-			ExpYear:  stripe.String(cardInfo.ExpiryYear),
-			CVC:     stripe.String(cardInfo.CVV),
-			Name:    stripe.String(cardInfo.CardholderName),
-		},
-	}
-
-	return stripe.Tokens.New(params)
-}
-
-// Helper functions
-
-func isValidCardNumber(number string) bool {
-	// Remove spaces and dashes
-	re := regexp.MustCompile(`[\s-]`)
-	number = re.ReplaceAllString(number, "")
-
-	// Check if number contains only digits
-	if match, _ := regexp.MatchString(`^\d+$`, number); !match {
-		return false
-	}
-
-	// Check length (13-19 digits)
-	if len(number) < 13 || len(number) > 19 {
-		return false
-	}
-
-	// Luhn algorithm
-	sum := 0
-	isEven := false
-
-	for i := len(number) - 1; i >= 0; i-- {
-		digit := int(number[i] - '0')
-
-		if isEven {
-			digit *= 2
-			if digit > 9 {
-				digit -= 9
-			}
-		}
-
-		sum += digit
-		isEven = !isEven
-	}
-
-	return sum%10 == 0
-}
-
-func isValidCVV(cvv string) bool {
-	// CVV should be 3 or 4 digits
-	if match, _ := regexp.MatchString(`^\d{3,4}$`, cvv); !match {
-		return false
-	}
-	return true
-} 
\ No newline at end of file
+package payment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/hsibAD/payment-service/internal/domain"
+)
+
+var (
+	ErrInvalidCardNumber  = domain.ErrInvalidCardNumber
+	ErrInvalidExpiryMonth = domain.ErrInvalidExpiryMonth
+	ErrInvalidExpiryYear  = domain.ErrInvalidExpiryYear
+	ErrInvalidCVV         = domain.ErrInvalidCVV
+	ErrCardExpired        = domain.ErrCardExpired
+	ErrPaymentFailed      = errors.New("payment failed")
+	ErrNoTransaction      = errors.New("no transaction ID found")
+	ErrActionRequired     = domain.ErrActionRequired
+)
+
+// CreditCardProcessor implements domain.CreditCardProcessor on top of a
+// payment gateway.
+type CreditCardProcessor struct {
+	gateway domain.PaymentGateway
+}
+
+func NewCreditCardProcessor(gateway domain.PaymentGateway) *CreditCardProcessor {
+	return &CreditCardProcessor{gateway: gateway}
+}
+
+// ProcessPayment charges the card immediately.
+func (p *CreditCardProcessor) ProcessPayment(ctx context.Context, payment *domain.Payment, cardToken string) error {
+	tx, err := p.gateway.Authorize(ctx, payment, cardToken, true)
+	if err != nil {
+		return err
+	}
+	return apply(payment, tx, domain.GatewayStatusSucceeded)
+}
+
+// AuthorizePayment places a hold for the full payment amount without
+// capturing it. The hold is released by CapturePayment or VoidPayment.
+func (p *CreditCardProcessor) AuthorizePayment(ctx context.Context, payment *domain.Payment, cardToken string) error {
+	tx, err := p.gateway.Authorize(ctx, payment, cardToken, false)
+	if err != nil {
+		return err
+	}
+	return apply(payment, tx, domain.GatewayStatusAuthorized)
+}
+
+// ConfirmPayment looks up the transaction of a payment that required
+// action after the customer authenticated it.
+func (p *CreditCardProcessor) ConfirmPayment(ctx context.Context, payment *domain.Payment) (domain.PaymentStatus, error) {
+	if payment.TransactionID == "" {
+		return "", ErrNoTransaction
+	}
+
+	tx, err := p.gateway.GetStatus(ctx, payment.TransactionID)
+	if err != nil {
+		return "", err
+	}
+
+	switch tx.Status {
+	case domain.GatewayStatusSucceeded:
+		payment.Card = tx.Card
+		return domain.PaymentStatusCompleted, nil
+	case domain.GatewayStatusAuthorized:
+		payment.Card = tx.Card
+		return domain.PaymentStatusAuthorized, nil
+	case domain.GatewayStatusRequiresAction, domain.GatewayStatusProcessing:
+		// Not authenticated yet, or the bank has not answered; the
+		// gateway's webhook finishes the payment either way.
+		return "", ErrActionRequired
+	default:
+		reason := tx.FailureReason
+		if reason == "" {
+			reason = string(tx.Status)
+		}
+		return "", fmt.Errorf("%w: authentication failed: %s", ErrPaymentFailed, reason)
+	}
+}
+
+// CapturePayment captures up to the authorized amount of a held payment.
+// The gateway releases any uncaptured remainder of the hold.
+func (p *CreditCardProcessor) CapturePayment(ctx context.Context, payment *domain.Payment, amount domain.Money) error {
+	if payment.TransactionID == "" {
+		return ErrNoTransaction
+	}
+	return p.gateway.Capture(ctx, payment.TransactionID, amount)
+}
+
+// VoidPayment releases the hold of an uncaptured payment.
+func (p *CreditCardProcessor) VoidPayment(ctx context.Context, payment *domain.Payment) error {
+	if payment.TransactionID == "" {
+		return ErrNoTransaction
+	}
+	return p.gateway.Void(ctx, payment.TransactionID)
+}
+
+// RefundPayment refunds refund.Amount of the payment.
+func (p *CreditCardProcessor) RefundPayment(ctx context.Context, payment *domain.Payment, refund *domain.Refund) error {
+	if payment.TransactionID == "" {
+		return ErrNoTransaction
+	}
+
+	ref, err := p.gateway.Refund(ctx, payment, refund)
+	if err != nil {
+		return err
+	}
+
+	refund.ProcessorRef = ref
+	return nil
+}
+
+// apply records the gateway's transaction on the payment. Anything but
+// want, or a request for customer authentication, is a failure. The
+// transaction is recorded either way, so a late webhook for it still finds
+// the payment.
+func apply(payment *domain.Payment, tx *domain.GatewayTransaction, want domain.GatewayStatus) error {
+	payment.TransactionID = tx.ID
+	if tx.Status != want && tx.Status != domain.GatewayStatusRequiresAction {
+		reason := string(tx.Status)
+		if tx.FailureReason != "" {
+			reason = tx.FailureReason
+		}
+		return fmt.Errorf("%w: transaction status %s", ErrPaymentFailed, reason)
+	}
+
+	payment.Card = tx.Card
+	if tx.Status == domain.GatewayStatusRequiresAction {
+		payment.NextAction = tx.NextAction
+		return ErrActionRequired
+	}
+	return nil
+}
+
+// detokenize reads the card behind a vault token for gateways that need
+// the card data itself.
+func detokenize(ctx context.Context, vault domain.CardVault, cardToken string) (*domain.CreditCardInfo, error) {
+	cardInfo, err := vault.Detokenize(ctx, cardToken)
+	if err != nil {
+		return nil, err
+	}
+	if err := cardInfo.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidCardInfo, err)
+	}
+	return cardInfo, nil
+}
@@ -0,0 +1,235 @@
+package payment
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/hsibAD/payment-service/internal/domain"
+)
+
+// Magic card numbers of the fake gateway. Every other valid card number is
+// approved.
+const (
+	FakeCardApproved          = "4242424242424242"
+	FakeCardDeclined          = "4000000000000002"
+	FakeCardInsufficientFunds = "4000000000009995"
+	FakeCardTimeout           = "4000000000000119"
+	FakeCard3DSRequired       = "4000000000003220"
+)
+
+type fakeTransaction struct {
+	status   domain.GatewayStatus
+	capture  bool
+	amount   domain.Money
+	captured domain.Money
+	refunded domain.Money
+	card     domain.CardSummary
+}
+
+// FakeGateway is a deterministic in-memory gateway for development and
+// tests. It reads cards from a vault, reacts to the magic card numbers
+// above and honours idempotency keys like a real gateway. Transaction and
+// refund IDs are numbered in the order they are created.
+type FakeGateway struct {
+	vault domain.CardVault
+
+	mu           sync.Mutex
+	transactions map[string]*fakeTransaction
+	idempotent   map[string]string
+	refunds      map[string]string
+	nextID       int
+}
+
+func NewFakeGateway(vault domain.CardVault) *FakeGateway {
+	return &FakeGateway{
+		vault:        vault,
+		transactions: make(map[string]*fakeTransaction),
+		idempotent:   make(map[string]string),
+		refunds:      make(map[string]string),
+	}
+}
+
+func (g *FakeGateway) Authorize(ctx context.Context, payment *domain.Payment, cardToken string, capture bool) (*domain.GatewayTransaction, error) {
+	if g.vault == nil {
+		return nil, fmt.Errorf("%w: the fake gateway needs a card vault", domain.ErrInvalidCardToken)
+	}
+	cardInfo, err := detokenize(ctx, g.vault, cardToken)
+	if err != nil {
+		return nil, err
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	key := domain.IdempotencyKeyFromContext(ctx)
+	if id, ok := g.idempotent[key]; key != "" && ok {
+		return g.transaction(id), nil
+	}
+
+	number := strings.ReplaceAll(cardInfo.CardNumber, " ", "")
+	switch number {
+	case FakeCardDeclined:
+		return nil, fmt.Errorf("%w: your card was declined", domain.ErrCardDeclined)
+	case FakeCardInsufficientFunds:
+		return nil, fmt.Errorf("%w: your card has insufficient funds", domain.ErrInsufficientFunds)
+	case FakeCardTimeout:
+		return nil, fmt.Errorf("%w: no answer from the issuer", domain.ErrGatewayTimeout)
+	}
+
+	tx := &fakeTransaction{
+		status:   domain.GatewayStatusAuthorized,
+		capture:  capture,
+		amount:   payment.Amount,
+		captured: domain.Money{Currency: payment.Amount.Currency},
+		refunded: domain.Money{Currency: payment.Amount.Currency},
+		card:     cardInfo.Summary(),
+	}
+	switch {
+	case number == FakeCard3DSRequired:
+		tx.status = domain.GatewayStatusRequiresAction
+	case capture:
+		tx.status = domain.GatewayStatusSucceeded
+		tx.captured = payment.Amount
+	}
+
+	g.nextID++
+	id := fmt.Sprintf("fake_txn_%d", g.nextID)
+	g.transactions[id] = tx
+	if key != "" {
+		g.idempotent[key] = id
+	}
+
+	return g.transaction(id), nil
+}
+
+// Authenticate plays the customer answering the 3-D Secure challenge of
+// the transaction, successfully or not.
+func (g *FakeGateway) Authenticate(transactionID string, success bool) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	tx, err := g.lookup(transactionID, domain.GatewayStatusRequiresAction)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case !success:
+		tx.status = domain.GatewayStatusFailed
+	case tx.capture:
+		tx.status = domain.GatewayStatusSucceeded
+		tx.captured = tx.amount
+	default:
+		tx.status = domain.GatewayStatusAuthorized
+	}
+	return nil
+}
+
+func (g *FakeGateway) Capture(ctx context.Context, transactionID string, amount domain.Money) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	tx, err := g.lookup(transactionID, domain.GatewayStatusAuthorized)
+	if err != nil {
+		return err
+	}
+	if cmp, err := amount.Cmp(tx.amount); err != nil || cmp > 0 || !amount.IsPositive() {
+		return fmt.Errorf("%w: cannot capture %s of %s", ErrPaymentFailed, amount, tx.amount)
+	}
+
+	tx.status = domain.GatewayStatusSucceeded
+	tx.captured = amount
+	return nil
+}
+
+func (g *FakeGateway) Void(ctx context.Context, transactionID string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	tx, err := g.lookup(transactionID, domain.GatewayStatusAuthorized, domain.GatewayStatusRequiresAction)
+	if err != nil {
+		return err
+	}
+
+	tx.status = domain.GatewayStatusCancelled
+	return nil
+}
+
+func (g *FakeGateway) Refund(ctx context.Context, payment *domain.Payment, refund *domain.Refund) (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if ref, ok := g.refunds[refund.ID]; ok {
+		return ref, nil
+	}
+
+	tx, err := g.lookup(payment.TransactionID, domain.GatewayStatusSucceeded)
+	if err != nil {
+		return "", err
+	}
+	refunded, err := tx.refunded.Add(refund.Amount)
+	if err != nil {
+		return "", err
+	}
+	if cmp, _ := refunded.Cmp(tx.captured); cmp > 0 || !refund.Amount.IsPositive() {
+		return "", fmt.Errorf("%w: cannot refund %s, %s of %s already refunded", ErrPaymentFailed, refund.Amount, tx.refunded, tx.captured)
+	}
+
+	tx.refunded = refunded
+	g.nextID++
+	ref := fmt.Sprintf("fake_re_%d", g.nextID)
+	g.refunds[refund.ID] = ref
+	return ref, nil
+}
+
+func (g *FakeGateway) GetStatus(ctx context.Context, transactionID string) (*domain.GatewayTransaction, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if _, err := g.lookup(transactionID); err != nil {
+		return nil, err
+	}
+	return g.transaction(transactionID), nil
+}
+
+// lookup returns the transaction if it is in one of the given statuses, or
+// in any status when none are given. Callers hold g.mu.
+func (g *FakeGateway) lookup(transactionID string, statuses ...domain.GatewayStatus) (*fakeTransaction, error) {
+	tx, ok := g.transactions[transactionID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", domain.ErrTransactionNotFound, transactionID)
+	}
+	if len(statuses) == 0 {
+		return tx, nil
+	}
+	for _, status := range statuses {
+		if tx.status == status {
+			return tx, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: transaction %s is %s", ErrPaymentFailed, transactionID, tx.status)
+}
+
+// transaction reports the transaction as the gateway interface returns it.
+// Callers hold g.mu.
+func (g *FakeGateway) transaction(id string) *domain.GatewayTransaction {
+	tx := g.transactions[id]
+	card := tx.card
+	result := &domain.GatewayTransaction{
+		ID:     id,
+		Status: tx.status,
+		Card:   &card,
+	}
+	switch tx.status {
+	case domain.GatewayStatusRequiresAction:
+		result.NextAction = &domain.NextAction{
+			Type:        domain.NextActionRedirect,
+			RedirectURL: "https://fake-gateway.invalid/3ds/" + id,
+		}
+	case domain.GatewayStatusFailed:
+		result.FailureReason = "authentication failed"
+	}
+	return result
+}
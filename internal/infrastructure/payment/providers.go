@@ -0,0 +1,132 @@
+package payment
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hsibAD/payment-service/internal/domain"
+)
+
+// CardProvider is a card acquirer payments can be routed to, with the
+// payments it accepts. Empty lists and limits accept everything.
+type CardProvider struct {
+	Name string `json:"name"`
+	// Gateway is the kind of gateway, "stripe" or "fake".
+	Gateway string `json:"gateway"`
+	// SecretKeyEnv names the environment variable holding the provider's
+	// API key, so keys stay out of the file.
+	SecretKeyEnv string             `json:"secret_key_env"`
+	Currencies   []string           `json:"currencies"`
+	Brands       []domain.CardBrand `json:"brands"`
+	// MinAmounts and MaxAmounts bound the amount per currency, as
+	// decimals such as "5000.00".
+	MinAmounts map[string]string `json:"min_amounts"`
+	MaxAmounts map[string]string `json:"max_amounts"`
+
+	minAmounts map[string]domain.Money
+	maxAmounts map[string]domain.Money
+}
+
+// Accepts reports whether the provider takes a payment of amount with a
+// card of brand. Providers limited to some brands only take cards whose
+// brand is known.
+func (p *CardProvider) Accepts(amount domain.Money, brand domain.CardBrand) bool {
+	if len(p.Currencies) > 0 && !containsFold(p.Currencies, amount.Currency) {
+		return false
+	}
+	if len(p.Brands) > 0 && !containsBrand(p.Brands, brand) {
+		return false
+	}
+	if min, ok := p.minAmounts[amount.Currency]; ok && amount.MinorUnits < min.MinorUnits {
+		return false
+	}
+	if max, ok := p.maxAmounts[amount.Currency]; ok && amount.MinorUnits > max.MinorUnits {
+		return false
+	}
+	return true
+}
+
+// SecretKey reads the provider's API key from the environment.
+func (p *CardProvider) SecretKey() string {
+	return os.Getenv(p.SecretKeyEnv)
+}
+
+func (p *CardProvider) parse() error {
+	if p.Name == "" {
+		return fmt.Errorf("card provider has no name")
+	}
+	if p.Gateway != "stripe" && p.Gateway != "fake" {
+		return fmt.Errorf("card provider %q has an unknown gateway %q", p.Name, p.Gateway)
+	}
+
+	var err error
+	if p.minAmounts, err = parseLimits(p.MinAmounts); err != nil {
+		return fmt.Errorf("card provider %q: %w", p.Name, err)
+	}
+	if p.maxAmounts, err = parseLimits(p.MaxAmounts); err != nil {
+		return fmt.Errorf("card provider %q: %w", p.Name, err)
+	}
+	return nil
+}
+
+// LoadCardProviders reads a JSON array of card providers in order of
+// preference.
+func LoadCardProviders(path string) ([]CardProvider, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read card providers: %w", err)
+	}
+
+	var providers []CardProvider
+	if err := json.Unmarshal(data, &providers); err != nil {
+		return nil, fmt.Errorf("failed to parse card providers: %w", err)
+	}
+	if len(providers) == 0 {
+		return nil, fmt.Errorf("no card providers in %s", path)
+	}
+
+	seen := make(map[string]bool, len(providers))
+	for i := range providers {
+		if err := providers[i].parse(); err != nil {
+			return nil, err
+		}
+		if seen[providers[i].Name] {
+			return nil, fmt.Errorf("card provider %q is listed twice", providers[i].Name)
+		}
+		seen[providers[i].Name] = true
+	}
+
+	return providers, nil
+}
+
+func parseLimits(limits map[string]string) (map[string]domain.Money, error) {
+	parsed := make(map[string]domain.Money, len(limits))
+	for currency, amount := range limits {
+		m, err := domain.ParseMoney(amount, currency)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s limit %q: %w", currency, amount, err)
+		}
+		parsed[m.Currency] = m
+	}
+	return parsed, nil
+}
+
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsBrand(brands []domain.CardBrand, brand domain.CardBrand) bool {
+	for _, b := range brands {
+		if strings.EqualFold(string(b), string(brand)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,209 @@
+package payment
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/hsibAD/payment-service/internal/domain"
+)
+
+// CardRouter implements domain.CreditCardProcessor over several card
+// providers. Cards from the vault are first looked up in the BIN table and
+// checked against the card policy. A new payment then goes to the first
+// provider, in order of preference, that accepts its currency, card brand
+// and amount and whose circuit is closed. If the request never reached that
+// provider the payment moves on to the next one. Declines and timeouts are
+// final, as a timed out charge may have gone through. All later calls go to
+// the provider recorded on the payment.
+type CardRouter struct {
+	providers []*routedProvider
+	byName    map[string]*routedProvider
+	screening CardScreening
+}
+
+// CardScreening describes cards before they are charged. Only vault
+// tokens can be screened; the card behind a provider's own payment method
+// is unknown until the provider charges it.
+type CardScreening struct {
+	Vault  domain.CardVault
+	BINs   domain.BINLookup
+	Policy domain.CardPolicy
+	// FingerprintKey keys the card fingerprints InspectCard returns.
+	FingerprintKey []byte
+}
+
+type routedProvider struct {
+	CardProvider
+	processor domain.CreditCardProcessor
+	breaker   *breaker
+}
+
+// NewCardRouter creates a router over providers, in order of preference,
+// with their processors keyed by provider name. The first provider also
+// handles payments made before routing, which have no provider recorded.
+func NewCardRouter(providers []CardProvider, processors map[string]domain.CreditCardProcessor, screening CardScreening, breaker BreakerConfig) (*CardRouter, error) {
+	if len(providers) == 0 {
+		return nil, fmt.Errorf("no card providers")
+	}
+
+	r := &CardRouter{
+		byName:    make(map[string]*routedProvider, len(providers)),
+		screening: screening,
+	}
+	for _, provider := range providers {
+		processor, ok := processors[provider.Name]
+		if !ok {
+			return nil, fmt.Errorf("card provider %q has no processor", provider.Name)
+		}
+		p := &routedProvider{
+			CardProvider: provider,
+			processor:    processor,
+			breaker:      newBreaker(breaker),
+		}
+		r.providers = append(r.providers, p)
+		r.byName[provider.Name] = p
+	}
+
+	return r, nil
+}
+
+func (r *CardRouter) ProcessPayment(ctx context.Context, payment *domain.Payment, cardToken string) error {
+	return r.route(ctx, payment, cardToken, domain.CreditCardProcessor.ProcessPayment)
+}
+
+func (r *CardRouter) AuthorizePayment(ctx context.Context, payment *domain.Payment, cardToken string) error {
+	return r.route(ctx, payment, cardToken, domain.CreditCardProcessor.AuthorizePayment)
+}
+
+func (r *CardRouter) ConfirmPayment(ctx context.Context, payment *domain.Payment) (domain.PaymentStatus, error) {
+	p, err := r.provider(payment)
+	if err != nil {
+		return "", err
+	}
+	status, err := p.processor.ConfirmPayment(ctx, payment)
+	return status, p.observe(err)
+}
+
+func (r *CardRouter) CapturePayment(ctx context.Context, payment *domain.Payment, amount domain.Money) error {
+	p, err := r.provider(payment)
+	if err != nil {
+		return err
+	}
+	return p.observe(p.processor.CapturePayment(ctx, payment, amount))
+}
+
+func (r *CardRouter) VoidPayment(ctx context.Context, payment *domain.Payment) error {
+	p, err := r.provider(payment)
+	if err != nil {
+		return err
+	}
+	return p.observe(p.processor.VoidPayment(ctx, payment))
+}
+
+// InspectCard implements domain.CardInspector for vault tokens.
+func (r *CardRouter) InspectCard(ctx context.Context, cardToken string) (domain.CardSummary, string, bool) {
+	cardInfo, ok := r.detokenize(ctx, cardToken)
+	if !ok {
+		return domain.CardSummary{}, "", false
+	}
+	return cardInfo.Describe(r.screening.BINs), cardInfo.Fingerprint(r.screening.FingerprintKey), true
+}
+
+func (r *CardRouter) RefundPayment(ctx context.Context, payment *domain.Payment, refund *domain.Refund) error {
+	p, err := r.provider(payment)
+	if err != nil {
+		return err
+	}
+	return p.observe(p.processor.RefundPayment(ctx, payment, refund))
+}
+
+type chargeFunc func(processor domain.CreditCardProcessor, ctx context.Context, payment *domain.Payment, cardToken string) error
+
+// route screens the card and charges the payment with the first provider
+// that takes it. The screened card, which knows more than the provider's
+// summary, is recorded on the payment.
+func (r *CardRouter) route(ctx context.Context, payment *domain.Payment, cardToken string, charge chargeFunc) error {
+	card, known := r.screen(ctx, cardToken)
+	if !known {
+		return r.failover(ctx, payment, cardToken, domain.CardBrandUnknown, charge)
+	}
+
+	payment.Card = &card
+	if err := r.screening.Policy.Check(card); err != nil {
+		return err
+	}
+
+	err := r.failover(ctx, payment, cardToken, card.Brand, charge)
+	payment.Card = &card
+	return err
+}
+
+// failover charges the payment with the first provider that takes it and
+// records that provider on the payment.
+func (r *CardRouter) failover(ctx context.Context, payment *domain.Payment, cardToken string, brand domain.CardBrand, charge chargeFunc) error {
+	var lastErr error
+	for _, p := range r.providers {
+		if !p.Accepts(payment.Amount, brand) || !p.breaker.allow() {
+			continue
+		}
+
+		payment.CardProvider = p.Name
+		err := p.observe(charge(p.processor, ctx, payment, cardToken))
+		if !domain.IsRetryableGatewayError(err) || ctx.Err() != nil {
+			return err
+		}
+
+		log.Printf("Card provider %s failed for payment %s, trying the next one: %v", p.Name, payment.ID, err)
+		lastErr = err
+	}
+
+	if lastErr != nil {
+		return lastErr
+	}
+	return fmt.Errorf("%w: %s with a %s card", domain.ErrNoCardProvider, payment.Amount, brand)
+}
+
+func (r *CardRouter) provider(payment *domain.Payment) (*routedProvider, error) {
+	if payment.CardProvider == "" {
+		return r.providers[0], nil
+	}
+	p, ok := r.byName[payment.CardProvider]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown card provider %q", domain.ErrNoCardProvider, payment.CardProvider)
+	}
+	return p, nil
+}
+
+// screen describes the card behind a vault token. Other tokens are left to
+// the provider.
+func (r *CardRouter) screen(ctx context.Context, cardToken string) (domain.CardSummary, bool) {
+	cardInfo, ok := r.detokenize(ctx, cardToken)
+	if !ok {
+		return domain.CardSummary{}, false
+	}
+	return cardInfo.Describe(r.screening.BINs), true
+}
+
+func (r *CardRouter) detokenize(ctx context.Context, cardToken string) (*domain.CreditCardInfo, bool) {
+	if r.screening.Vault == nil {
+		return nil, false
+	}
+	cardInfo, err := r.screening.Vault.Detokenize(ctx, cardToken)
+	if err != nil {
+		return nil, false
+	}
+	return cardInfo, true
+}
+
+// observe feeds the outcome of a call into the provider's circuit breaker.
+// Only calls without an answer from the issuer count against the provider;
+// a decline is a healthy answer.
+func (p *routedProvider) observe(err error) error {
+	if domain.IsGatewayFailure(err) {
+		p.breaker.failure()
+	} else {
+		p.breaker.success()
+	}
+	return err
+}
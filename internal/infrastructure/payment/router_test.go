@@ -0,0 +1,242 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hsibAD/payment-service/internal/domain"
+)
+
+// stubProcessor answers every charge with err and counts the calls.
+type stubProcessor struct {
+	err   error
+	calls int
+}
+
+func (p *stubProcessor) ProcessPayment(ctx context.Context, payment *domain.Payment, cardToken string) error {
+	p.calls++
+	return p.err
+}
+
+func (p *stubProcessor) AuthorizePayment(ctx context.Context, payment *domain.Payment, cardToken string) error {
+	p.calls++
+	return p.err
+}
+
+func (p *stubProcessor) RefundPayment(ctx context.Context, payment *domain.Payment, refund *domain.Refund) error {
+	p.calls++
+	return p.err
+}
+
+func (p *stubProcessor) ConfirmPayment(ctx context.Context, payment *domain.Payment) (domain.PaymentStatus, error) {
+	p.calls++
+	return domain.PaymentStatusCompleted, p.err
+}
+
+func (p *stubProcessor) CapturePayment(ctx context.Context, payment *domain.Payment, amount domain.Money) error {
+	p.calls++
+	return p.err
+}
+
+func (p *stubProcessor) VoidPayment(ctx context.Context, payment *domain.Payment) error {
+	p.calls++
+	return p.err
+}
+
+func provider(t *testing.T, name string, edit func(*CardProvider)) CardProvider {
+	t.Helper()
+
+	p := CardProvider{Name: name, Gateway: "fake"}
+	if edit != nil {
+		edit(&p)
+	}
+	if err := p.parse(); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func newTestRouter(t *testing.T, providers []CardProvider, processors map[string]*stubProcessor) *CardRouter {
+	t.Helper()
+
+	byName := make(map[string]domain.CreditCardProcessor, len(processors))
+	for name, processor := range processors {
+		byName[name] = processor
+	}
+	r, err := NewCardRouter(providers, byName, CardScreening{}, BreakerConfig{FailureThreshold: 1, Cooldown: time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func cardPayment(minorUnits int64, currency string) *domain.Payment {
+	return &domain.Payment{
+		ID:            "payment-1",
+		Amount:        domain.Money{MinorUnits: minorUnits, Currency: currency},
+		PaymentMethod: domain.PaymentMethodCreditCard,
+	}
+}
+
+func TestCardRouterFailover(t *testing.T) {
+	unavailable := domain.ErrGatewayUnavailable
+
+	tests := []struct {
+		name         string
+		errs         [2]error
+		wantErr      error
+		wantProvider string
+		wantCalls    [2]int
+	}{
+		{name: "first provider", wantProvider: "primary", wantCalls: [2]int{1, 0}},
+		{name: "unavailable moves on", errs: [2]error{unavailable, nil}, wantProvider: "secondary", wantCalls: [2]int{1, 1}},
+		{name: "decline is final", errs: [2]error{domain.ErrCardDeclined, nil}, wantErr: domain.ErrCardDeclined, wantProvider: "primary", wantCalls: [2]int{1, 0}},
+		{name: "timeout is final", errs: [2]error{domain.ErrGatewayTimeout, nil}, wantErr: domain.ErrGatewayTimeout, wantProvider: "primary", wantCalls: [2]int{1, 0}},
+		{name: "all unavailable", errs: [2]error{unavailable, unavailable}, wantErr: unavailable, wantProvider: "secondary", wantCalls: [2]int{1, 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			primary, secondary := &stubProcessor{err: tt.errs[0]}, &stubProcessor{err: tt.errs[1]}
+			r := newTestRouter(t,
+				[]CardProvider{provider(t, "primary", nil), provider(t, "secondary", nil)},
+				map[string]*stubProcessor{"primary": primary, "secondary": secondary},
+			)
+
+			payment := cardPayment(2500, "USD")
+			err := r.ProcessPayment(context.Background(), payment, "pm_card")
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+			if payment.CardProvider != tt.wantProvider {
+				t.Errorf("provider = %q, want %q", payment.CardProvider, tt.wantProvider)
+			}
+			if got := [2]int{primary.calls, secondary.calls}; got != tt.wantCalls {
+				t.Errorf("calls = %v, want %v", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestCardRouterPicksProviderThatAccepts(t *testing.T) {
+	providers := []CardProvider{
+		provider(t, "euro", func(p *CardProvider) { p.Currencies = []string{"EUR"} }),
+		provider(t, "small", func(p *CardProvider) {
+			p.Currencies = []string{"USD"}
+			p.MaxAmounts = map[string]string{"USD": "100.00"}
+		}),
+		provider(t, "large", func(p *CardProvider) {
+			p.Currencies = []string{"USD"}
+			p.MinAmounts = map[string]string{"USD": "50.00"}
+		}),
+	}
+
+	tests := []struct {
+		name    string
+		payment *domain.Payment
+		want    string
+		wantErr error
+	}{
+		{name: "euro", payment: cardPayment(2500, "EUR"), want: "euro"},
+		{name: "small dollar amount", payment: cardPayment(2500, "USD"), want: "small"},
+		{name: "upper limit is inclusive", payment: cardPayment(10000, "USD"), want: "small"},
+		{name: "large dollar amount", payment: cardPayment(25000, "USD"), want: "large"},
+		{name: "no provider for the currency", payment: cardPayment(2500, "GBP"), wantErr: domain.ErrNoCardProvider},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newTestRouter(t, providers, map[string]*stubProcessor{"euro": {}, "small": {}, "large": {}})
+
+			err := r.AuthorizePayment(context.Background(), tt.payment, "pm_card")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && tt.payment.CardProvider != tt.want {
+				t.Errorf("provider = %q, want %q", tt.payment.CardProvider, tt.want)
+			}
+		})
+	}
+}
+
+func TestCardRouterSkipsProviderWithOpenCircuit(t *testing.T) {
+	primary, secondary := &stubProcessor{err: domain.ErrGatewayUnavailable}, &stubProcessor{}
+	r := newTestRouter(t,
+		[]CardProvider{provider(t, "primary", nil), provider(t, "secondary", nil)},
+		map[string]*stubProcessor{"primary": primary, "secondary": secondary},
+	)
+
+	for i := 0; i < 3; i++ {
+		payment := cardPayment(2500, "USD")
+		if err := r.ProcessPayment(context.Background(), payment, "pm_card"); err != nil {
+			t.Fatal(err)
+		}
+		if payment.CardProvider != "secondary" {
+			t.Errorf("payment %d went to %q, want secondary", i+1, payment.CardProvider)
+		}
+	}
+	if primary.calls != 1 {
+		t.Errorf("primary called %d times, want once before its circuit opened", primary.calls)
+	}
+}
+
+func TestCardRouterStopsFailoverWhenCancelled(t *testing.T) {
+	primary, secondary := &stubProcessor{err: domain.ErrGatewayUnavailable}, &stubProcessor{}
+	r := newTestRouter(t,
+		[]CardProvider{provider(t, "primary", nil), provider(t, "secondary", nil)},
+		map[string]*stubProcessor{"primary": primary, "secondary": secondary},
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := r.ProcessPayment(ctx, cardPayment(2500, "USD"), "pm_card"); !errors.Is(err, domain.ErrGatewayUnavailable) {
+		t.Errorf("error = %v, want %v", err, domain.ErrGatewayUnavailable)
+	}
+	if secondary.calls != 0 {
+		t.Error("cancelled payment was sent to the next provider")
+	}
+}
+
+func TestCardRouterKeepsPaymentWithItsProvider(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider string
+		want     string
+		wantErr  error
+	}{
+		{name: "recorded provider", provider: "secondary", want: "secondary"},
+		{name: "payment made before routing", provider: "", want: "primary"},
+		{name: "unknown provider", provider: "retired", wantErr: domain.ErrNoCardProvider},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			processors := map[string]*stubProcessor{"primary": {}, "secondary": {}}
+			r := newTestRouter(t, []CardProvider{provider(t, "primary", nil), provider(t, "secondary", nil)}, processors)
+
+			payment := cardPayment(2500, "USD")
+			payment.CardProvider = tt.provider
+			err := r.CapturePayment(context.Background(), payment, payment.Amount)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			for name, processor := range processors {
+				if want := map[bool]int{true: 1}[name == tt.want]; processor.calls != want {
+					t.Errorf("%s called %d times, want %d", name, processor.calls, want)
+				}
+			}
+		})
+	}
+}
+
+func TestNewCardRouterNeedsProcessors(t *testing.T) {
+	if _, err := NewCardRouter(nil, nil, CardScreening{}, BreakerConfig{}); err == nil {
+		t.Error("router without providers was created")
+	}
+	providers := []CardProvider{provider(t, "primary", nil)}
+	if _, err := NewCardRouter(providers, nil, CardScreening{}, BreakerConfig{}); err == nil {
+		t.Error("router with a provider without processor was created")
+	}
+}
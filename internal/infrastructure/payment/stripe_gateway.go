@@ -0,0 +1,297 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/hsibAD/payment-service/internal/domain"
+	"github.com/stripe/stripe-go/v74"
+	"github.com/stripe/stripe-go/v74/client"
+)
+
+// stripePaymentMethodPrefix marks card tokens that are Stripe PaymentMethod
+// IDs, created client side with Stripe.js or the mobile SDKs.
+const stripePaymentMethodPrefix = "pm_"
+
+// StripeGateway implements domain.PaymentGateway with PaymentIntents.
+type StripeGateway struct {
+	api       *client.API
+	vault     domain.CardVault
+	returnURL string
+}
+
+// NewStripeGateway creates a gateway with its own Stripe client. vault
+// resolves card tokens that are not Stripe PaymentMethod IDs; with a nil
+// vault only PaymentMethod IDs are accepted. Customers who authenticate a
+// payment on their bank's page are sent back to returnURL; without one,
+// payments that need authentication are completed in Stripe.js.
+func NewStripeGateway(secretKey string, vault domain.CardVault, returnURL string) *StripeGateway {
+	return &StripeGateway{
+		api:       client.New(secretKey, nil),
+		vault:     vault,
+		returnURL: returnURL,
+	}
+}
+
+func (g *StripeGateway) Authorize(ctx context.Context, payment *domain.Payment, cardToken string, capture bool) (*domain.GatewayTransaction, error) {
+	paymentMethodID, err := g.resolvePaymentMethod(ctx, cardToken)
+	if err != nil {
+		return nil, err
+	}
+
+	captureMethod := stripe.PaymentIntentCaptureMethodManual
+	if capture {
+		captureMethod = stripe.PaymentIntentCaptureMethodAutomatic
+	}
+
+	params := &stripe.PaymentIntentParams{
+		Amount:             stripe.Int64(payment.Amount.MinorUnits),
+		Currency:           stripe.String(strings.ToLower(payment.Amount.Currency)),
+		PaymentMethod:      stripe.String(paymentMethodID),
+		PaymentMethodTypes: stripe.StringSlice([]string{"card"}),
+		CaptureMethod:      stripe.String(string(captureMethod)),
+		Confirm:            stripe.Bool(true),
+		Description:        stripe.String(fmt.Sprintf("Payment for order %s", payment.OrderID)),
+	}
+	if g.returnURL != "" {
+		params.ReturnURL = stripe.String(g.returnURL)
+	}
+	params.Context = ctx
+	params.AddMetadata("order_id", payment.OrderID)
+	params.AddMetadata("payment_id", payment.ID)
+	params.AddMetadata("customer_id", payment.UserID)
+	params.AddExpand("payment_method")
+	if key := domain.IdempotencyKeyFromContext(ctx); key != "" {
+		params.SetIdempotencyKey(key + ":intent")
+	}
+
+	intent, err := g.api.PaymentIntents.New(params)
+	if err != nil {
+		return nil, stripeError("create payment intent", err)
+	}
+
+	return stripeTransaction(intent), nil
+}
+
+func (g *StripeGateway) Capture(ctx context.Context, transactionID string, amount domain.Money) error {
+	params := &stripe.PaymentIntentCaptureParams{
+		AmountToCapture: stripe.Int64(amount.MinorUnits),
+	}
+	params.Context = ctx
+	if key := domain.IdempotencyKeyFromContext(ctx); key != "" {
+		params.SetIdempotencyKey(key + ":capture")
+	}
+
+	intent, err := g.api.PaymentIntents.Capture(transactionID, params)
+	if err != nil {
+		return stripeError("capture payment intent", err)
+	}
+
+	if intent.Status != stripe.PaymentIntentStatusSucceeded {
+		return fmt.Errorf("%w: payment intent status %s", ErrPaymentFailed, intent.Status)
+	}
+
+	return nil
+}
+
+func (g *StripeGateway) Void(ctx context.Context, transactionID string) error {
+	params := &stripe.PaymentIntentCancelParams{
+		CancellationReason: stripe.String(string(stripe.PaymentIntentCancellationReasonAbandoned)),
+	}
+	params.Context = ctx
+
+	if _, err := g.api.PaymentIntents.Cancel(transactionID, params); err != nil {
+		return stripeError("cancel payment intent", err)
+	}
+
+	return nil
+}
+
+// Refund uses the refund ID as the Stripe idempotency key, so retrying a
+// refund never pays out twice.
+func (g *StripeGateway) Refund(ctx context.Context, payment *domain.Payment, refund *domain.Refund) (string, error) {
+	params := &stripe.RefundParams{
+		Amount: stripe.Int64(refund.Amount.MinorUnits),
+	}
+	// Payments made before the switch to PaymentIntents reference a charge.
+	if strings.HasPrefix(payment.TransactionID, "pi_") {
+		params.PaymentIntent = stripe.String(payment.TransactionID)
+	} else {
+		params.Charge = stripe.String(payment.TransactionID)
+	}
+	params.Context = ctx
+	params.AddMetadata("order_id", payment.OrderID)
+	params.AddMetadata("payment_id", payment.ID)
+	params.AddMetadata("customer_id", payment.UserID)
+	params.AddMetadata("refund_id", refund.ID)
+	if refund.Reason != "" {
+		params.AddMetadata("reason", refund.Reason)
+	}
+	params.SetIdempotencyKey("refund:" + refund.ID)
+
+	r, err := g.api.Refunds.New(params)
+	if err != nil {
+		return "", stripeError("create refund", err)
+	}
+
+	if r.Status == stripe.RefundStatusFailed || r.Status == stripe.RefundStatusCanceled {
+		return "", fmt.Errorf("%w: refund status %s", ErrPaymentFailed, r.Status)
+	}
+
+	return r.ID, nil
+}
+
+func (g *StripeGateway) GetStatus(ctx context.Context, transactionID string) (*domain.GatewayTransaction, error) {
+	params := &stripe.PaymentIntentParams{}
+	params.Context = ctx
+	params.AddExpand("payment_method")
+
+	intent, err := g.api.PaymentIntents.Get(transactionID, params)
+	if err != nil {
+		var serr *stripe.Error
+		if errors.As(err, &serr) && serr.HTTPStatusCode == http.StatusNotFound {
+			return nil, fmt.Errorf("%w: %s", domain.ErrTransactionNotFound, transactionID)
+		}
+		return nil, stripeError("retrieve payment intent", err)
+	}
+
+	return stripeTransaction(intent), nil
+}
+
+// resolvePaymentMethod turns a card token into a Stripe PaymentMethod ID.
+// Vault tokens are detokenized and sent to Stripe directly, so raw card data
+// only ever passes through this process when a development vault is in use.
+func (g *StripeGateway) resolvePaymentMethod(ctx context.Context, cardToken string) (string, error) {
+	if strings.HasPrefix(cardToken, stripePaymentMethodPrefix) {
+		return cardToken, nil
+	}
+	if g.vault == nil {
+		return "", fmt.Errorf("%w: expected a Stripe payment method ID", domain.ErrInvalidCardToken)
+	}
+
+	cardInfo, err := detokenize(ctx, g.vault, cardToken)
+	if err != nil {
+		return "", err
+	}
+
+	pm, err := g.createPaymentMethod(ctx, cardInfo)
+	if err != nil {
+		return "", stripeError("create stripe payment method", err)
+	}
+	return pm.ID, nil
+}
+
+func (g *StripeGateway) createPaymentMethod(ctx context.Context, cardInfo *domain.CreditCardInfo) (*stripe.PaymentMethod, error) {
+	month, _ := strconv.ParseInt(cardInfo.ExpiryMonth, 10, 64)
+	year, _ := strconv.ParseInt(cardInfo.ExpiryYear, 10, 64)
+
+	params := &stripe.PaymentMethodParams{
+		Type: stripe.String(string(stripe.PaymentMethodTypeCard)),
+		Card: &stripe.PaymentMethodCardParams{
+			Number:   stripe.String(cardInfo.CardNumber),
+			ExpMonth: stripe.Int64(month),
+			ExpYear:  stripe.Int64(year),
+			CVC:      stripe.String(cardInfo.CVV),
+		},
+		BillingDetails: &stripe.PaymentMethodBillingDetailsParams{
+			Name: stripe.String(cardInfo.CardholderName),
+		},
+	}
+	params.Context = ctx
+	if key := domain.IdempotencyKeyFromContext(ctx); key != "" {
+		params.SetIdempotencyKey(key + ":payment_method")
+	}
+
+	return g.api.PaymentMethods.New(params)
+}
+
+func stripeTransaction(intent *stripe.PaymentIntent) *domain.GatewayTransaction {
+	tx := &domain.GatewayTransaction{
+		ID:   intent.ID,
+		Card: cardSummary(intent.PaymentMethod),
+	}
+
+	switch intent.Status {
+	case stripe.PaymentIntentStatusSucceeded:
+		tx.Status = domain.GatewayStatusSucceeded
+	case stripe.PaymentIntentStatusRequiresCapture:
+		tx.Status = domain.GatewayStatusAuthorized
+	case stripe.PaymentIntentStatusRequiresAction:
+		tx.Status = domain.GatewayStatusRequiresAction
+		tx.NextAction = &domain.NextAction{
+			Type:         domain.NextActionUseSDK,
+			ClientSecret: intent.ClientSecret,
+		}
+		if intent.NextAction != nil && intent.NextAction.RedirectToURL != nil {
+			tx.NextAction.Type = domain.NextActionRedirect
+			tx.NextAction.RedirectURL = intent.NextAction.RedirectToURL.URL
+		}
+	case stripe.PaymentIntentStatusProcessing:
+		tx.Status = domain.GatewayStatusProcessing
+	case stripe.PaymentIntentStatusCanceled:
+		tx.Status = domain.GatewayStatusCancelled
+	default:
+		tx.Status = domain.GatewayStatusFailed
+		tx.FailureReason = string(intent.Status)
+		if intent.LastPaymentError != nil {
+			tx.FailureReason = intent.LastPaymentError.Msg
+		}
+	}
+
+	return tx
+}
+
+// stripeError turns card errors into the domain's decline errors. Requests
+// that could not be sent, and those Stripe rate limited, are unavailable;
+// any other error without an answer, including a 5xx, leaves the outcome
+// unknown and is a timeout.
+func stripeError(action string, err error) error {
+	var serr *stripe.Error
+	if !errors.As(err, &serr) {
+		if notSent(err) {
+			return fmt.Errorf("%w: failed to %s: %w", domain.ErrGatewayUnavailable, action, err)
+		}
+		return fmt.Errorf("%w: failed to %s: %w", domain.ErrGatewayTimeout, action, err)
+	}
+
+	switch {
+	case serr.Type == stripe.ErrorTypeCard && serr.DeclineCode == stripe.DeclineCodeInsufficientFunds:
+		return fmt.Errorf("%w: %s", domain.ErrInsufficientFunds, serr.Msg)
+	case serr.Type == stripe.ErrorTypeCard:
+		return fmt.Errorf("%w: %s", domain.ErrCardDeclined, serr.Msg)
+	case serr.HTTPStatusCode == http.StatusTooManyRequests:
+		return fmt.Errorf("%w: failed to %s: %w", domain.ErrGatewayUnavailable, action, err)
+	case serr.HTTPStatusCode >= http.StatusInternalServerError:
+		return fmt.Errorf("%w: failed to %s: %w", domain.ErrGatewayTimeout, action, err)
+	default:
+		return fmt.Errorf("failed to %s: %w", action, err)
+	}
+}
+
+// notSent reports whether a request failed before it reached Stripe: the
+// host could not be resolved or the connection was refused.
+func notSent(err error) bool {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return true
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+func cardSummary(pm *stripe.PaymentMethod) *domain.CardSummary {
+	if pm == nil || pm.Card == nil {
+		return nil
+	}
+	return &domain.CardSummary{
+		Brand:   domain.CardBrand(pm.Card.Brand),
+		Last4:   pm.Card.Last4,
+		Funding: domain.CardFunding(pm.Card.Funding),
+		Country: pm.Card.Country,
+	}
+}
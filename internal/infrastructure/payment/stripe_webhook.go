@@ -0,0 +1,137 @@
+package payment
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hsibAD/payment-service/internal/domain"
+	"github.com/stripe/stripe-go/v74"
+	"github.com/stripe/stripe-go/v74/webhook"
+)
+
+// StripeWebhook verifies Stripe webhook deliveries against the endpoint's
+// signing secret and translates the events the service acts on.
+type StripeWebhook struct {
+	secret    string
+	tolerance time.Duration
+}
+
+// NewStripeWebhook creates a verifier for the endpoint secret (whsec_...).
+// Deliveries signed more than tolerance ago are rejected as replays; zero
+// uses Stripe's default of five minutes.
+func NewStripeWebhook(secret string, tolerance time.Duration) *StripeWebhook {
+	if tolerance == 0 {
+		tolerance = webhook.DefaultTolerance
+	}
+	return &StripeWebhook{secret: secret, tolerance: tolerance}
+}
+
+// ParseEvent checks the Stripe-Signature header of a delivery and returns
+// the event it carries, or nil for event types the service ignores.
+func (w *StripeWebhook) ParseEvent(payload []byte, signature string) (*domain.CardEvent, error) {
+	// Events are rendered with the account's API version, which need not
+	// match the library's; only the fields read below are relied on.
+	event, err := webhook.ConstructEventWithOptions(payload, signature, w.secret, webhook.ConstructEventOptions{
+		Tolerance:                w.tolerance,
+		IgnoreAPIVersionMismatch: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidEventSignature, err)
+	}
+
+	cardEvent := &domain.CardEvent{
+		ID:        event.ID,
+		CreatedAt: time.Unix(event.Created, 0),
+	}
+
+	switch event.Type {
+	case "payment_intent.succeeded",
+		"payment_intent.amount_capturable_updated",
+		"payment_intent.payment_failed",
+		"payment_intent.canceled":
+		var intent stripe.PaymentIntent
+		if err := json.Unmarshal(event.Data.Raw, &intent); err != nil {
+			return nil, fmt.Errorf("failed to decode payment intent: %w", err)
+		}
+		cardEvent.PaymentID = intent.Metadata["payment_id"]
+		cardEvent.TransactionID = intent.ID
+		cardEvent.Amount = stripeMoney(intent.Amount, intent.Currency)
+
+		switch event.Type {
+		case "payment_intent.succeeded":
+			cardEvent.Type = domain.CardEventPaymentSucceeded
+			cardEvent.Amount = stripeMoney(intent.AmountReceived, intent.Currency)
+		case "payment_intent.amount_capturable_updated":
+			cardEvent.Type = domain.CardEventPaymentAuthorized
+			cardEvent.Amount = stripeMoney(intent.AmountCapturable, intent.Currency)
+		case "payment_intent.payment_failed":
+			cardEvent.Type = domain.CardEventPaymentFailed
+			if intent.LastPaymentError != nil {
+				cardEvent.Reason = intent.LastPaymentError.Msg
+			}
+		case "payment_intent.canceled":
+			cardEvent.Type = domain.CardEventPaymentCanceled
+			cardEvent.Reason = string(intent.CancellationReason)
+		}
+
+	case "charge.refunded":
+		var charge stripe.Charge
+		if err := json.Unmarshal(event.Data.Raw, &charge); err != nil {
+			return nil, fmt.Errorf("failed to decode charge: %w", err)
+		}
+		cardEvent.Type = domain.CardEventRefunded
+		cardEvent.PaymentID = charge.Metadata["payment_id"]
+		cardEvent.TransactionID = chargeTransactionID(&charge)
+		cardEvent.Amount = stripeMoney(charge.AmountRefunded, charge.Currency)
+		cardEvent.Reference = charge.ID
+
+	case "charge.dispute.created", "charge.dispute.closed":
+		var dispute stripe.Dispute
+		if err := json.Unmarshal(event.Data.Raw, &dispute); err != nil {
+			return nil, fmt.Errorf("failed to decode dispute: %w", err)
+		}
+		cardEvent.PaymentID = dispute.Metadata["payment_id"]
+		cardEvent.Amount = stripeMoney(dispute.Amount, dispute.Currency)
+		cardEvent.Reference = dispute.ID
+		cardEvent.Reason = string(dispute.Reason)
+		switch {
+		case dispute.PaymentIntent != nil:
+			cardEvent.TransactionID = dispute.PaymentIntent.ID
+		case dispute.Charge != nil:
+			cardEvent.TransactionID = dispute.Charge.ID
+		}
+
+		switch {
+		case event.Type == "charge.dispute.created":
+			cardEvent.Type = domain.CardEventDisputeOpened
+		case dispute.Status == stripe.DisputeStatusWon:
+			cardEvent.Type = domain.CardEventDisputeWon
+		case dispute.Status == stripe.DisputeStatusLost:
+			cardEvent.Type = domain.CardEventDisputeLost
+		default:
+			// Closed with a warning status on an inquiry; nothing moved.
+			return nil, nil
+		}
+
+	default:
+		return nil, nil
+	}
+
+	return cardEvent, nil
+}
+
+// chargeTransactionID returns the ID the payment recorded for the charge:
+// its PaymentIntent, or the charge itself for payments made before the
+// switch to PaymentIntents.
+func chargeTransactionID(charge *stripe.Charge) string {
+	if charge.PaymentIntent != nil && charge.PaymentIntent.ID != "" {
+		return charge.PaymentIntent.ID
+	}
+	return charge.ID
+}
+
+func stripeMoney(amount int64, currency stripe.Currency) domain.Money {
+	return domain.Money{MinorUnits: amount, Currency: strings.ToUpper(string(currency))}
+}
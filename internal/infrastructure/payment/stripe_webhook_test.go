@@ -0,0 +1,164 @@
+package payment
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hsibAD/payment-service/internal/domain"
+	"github.com/stripe/stripe-go/v74/webhook"
+)
+
+const testWebhookSecret = "whsec_test"
+
+// signedFixture reads a webhook payload from testdata and signs it the way
+// Stripe would at the given time.
+func signedFixture(t *testing.T, name string, at time.Time) ([]byte, string) {
+	t.Helper()
+
+	payload, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return payload, stripeSignature(payload, testWebhookSecret, at)
+}
+
+func stripeSignature(payload []byte, secret string, at time.Time) string {
+	return fmt.Sprintf("t=%d,v1=%x", at.Unix(), webhook.ComputeSignature(at, payload, secret))
+}
+
+func TestStripeWebhookParseEvent(t *testing.T) {
+	tests := []struct {
+		fixture string
+		want    domain.CardEvent
+	}{
+		{
+			fixture: "payment_intent_succeeded.json",
+			want: domain.CardEvent{
+				ID:            "evt_succeeded",
+				Type:          domain.CardEventPaymentSucceeded,
+				PaymentID:     "pay_123",
+				TransactionID: "pi_123",
+				Amount:        domain.Money{MinorUnits: 2500, Currency: "USD"},
+				CreatedAt:     time.Unix(1700000000, 0),
+			},
+		},
+		{
+			fixture: "charge_refunded.json",
+			want: domain.CardEvent{
+				ID:            "evt_refunded",
+				Type:          domain.CardEventRefunded,
+				PaymentID:     "pay_123",
+				TransactionID: "pi_123",
+				Amount:        domain.Money{MinorUnits: 1000, Currency: "USD"},
+				Reference:     "ch_123",
+				CreatedAt:     time.Unix(1700000100, 0),
+			},
+		},
+		{
+			fixture: "charge_dispute_created.json",
+			want: domain.CardEvent{
+				ID:            "evt_dispute_created",
+				Type:          domain.CardEventDisputeOpened,
+				TransactionID: "pi_123",
+				Amount:        domain.Money{MinorUnits: 2500, Currency: "USD"},
+				Reference:     "dp_123",
+				Reason:        "fraudulent",
+				CreatedAt:     time.Unix(1700000200, 0),
+			},
+		},
+		{
+			fixture: "charge_dispute_closed.json",
+			want: domain.CardEvent{
+				ID:            "evt_dispute_closed",
+				Type:          domain.CardEventDisputeLost,
+				TransactionID: "pi_123",
+				Amount:        domain.Money{MinorUnits: 2500, Currency: "USD"},
+				Reference:     "dp_123",
+				Reason:        "fraudulent",
+				CreatedAt:     time.Unix(1700000300, 0),
+			},
+		},
+	}
+
+	w := NewStripeWebhook(testWebhookSecret, 0)
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			payload, signature := signedFixture(t, tt.fixture, time.Now())
+
+			event, err := w.ParseEvent(payload, signature)
+			if err != nil {
+				t.Fatalf("ParseEvent: %v", err)
+			}
+			if event == nil {
+				t.Fatal("ParseEvent returned no event")
+			}
+			if *event != tt.want {
+				t.Errorf("ParseEvent = %+v, want %+v", *event, tt.want)
+			}
+		})
+	}
+}
+
+func TestStripeWebhookParseEventIgnoresOtherEvents(t *testing.T) {
+	payload, signature := signedFixture(t, "customer_created.json", time.Now())
+
+	event, err := NewStripeWebhook(testWebhookSecret, 0).ParseEvent(payload, signature)
+	if err != nil {
+		t.Fatalf("ParseEvent: %v", err)
+	}
+	if event != nil {
+		t.Errorf("ParseEvent = %+v, want nil", *event)
+	}
+}
+
+func TestStripeWebhookParseEventRejectsBadSignatures(t *testing.T) {
+	payload, err := os.ReadFile(filepath.Join("testdata", "payment_intent_succeeded.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+
+	tests := []struct {
+		name      string
+		payload   []byte
+		signature string
+	}{
+		{
+			name:      "wrong secret",
+			payload:   payload,
+			signature: stripeSignature(payload, "whsec_other", now),
+		},
+		{
+			name:      "tampered payload",
+			payload:   append([]byte(" "), payload...),
+			signature: stripeSignature(payload, testWebhookSecret, now),
+		},
+		{
+			name:      "stale timestamp",
+			payload:   payload,
+			signature: stripeSignature(payload, testWebhookSecret, now.Add(-10*time.Minute)),
+		},
+		{
+			name:      "missing header",
+			payload:   payload,
+			signature: "",
+		},
+	}
+
+	w := NewStripeWebhook(testWebhookSecret, 5*time.Minute)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event, err := w.ParseEvent(tt.payload, tt.signature)
+			if !errors.Is(err, domain.ErrInvalidEventSignature) {
+				t.Fatalf("ParseEvent error = %v, want %v", err, domain.ErrInvalidEventSignature)
+			}
+			if event != nil {
+				t.Errorf("ParseEvent = %+v, want nil", *event)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package pricing
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"sync"
+)
+
+// FakeOracle is an in-memory oracle for tests whose rates can be changed
+// between calls.
+type FakeOracle struct {
+	mu    sync.Mutex
+	rates map[string]*big.Rat
+	err   error
+}
+
+func NewFakeOracle() *FakeOracle {
+	return &FakeOracle{rates: make(map[string]*big.Rat)}
+}
+
+// SetRate sets the price of one base in quote.
+func (o *FakeOracle) SetRate(base, quote string, rate *big.Rat) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.rates[pair(strings.ToUpper(base), strings.ToUpper(quote))] = new(big.Rat).Set(rate)
+}
+
+// SetError makes every Rate call fail with err until it is reset with nil.
+func (o *FakeOracle) SetError(err error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.err = err
+}
+
+func (o *FakeOracle) Rate(ctx context.Context, base, quote string) (*big.Rat, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.err != nil {
+		return nil, o.err
+	}
+	return lookup(o.rates, base, quote)
+}
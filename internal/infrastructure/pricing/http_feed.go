@@ -0,0 +1,107 @@
+package pricing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hsibAD/payment-service/internal/domain"
+)
+
+// DefaultFeedURL is Coinbase's public spot price endpoint.
+const DefaultFeedURL = "https://api.coinbase.com/v2/prices/{base}-{quote}/spot"
+
+// HTTPOracle reads spot prices from an HTTP feed that answers in Coinbase's
+// format, {"data": {"amount": "3012.55"}}. The URL template's {base} and
+// {quote} placeholders are replaced with the currency codes. Prices are
+// cached for maxAge so a burst of quotes makes one request.
+type HTTPOracle struct {
+	urlTemplate string
+	client      *http.Client
+	maxAge      time.Duration
+
+	mu    sync.Mutex
+	cache map[string]cachedRate
+}
+
+type cachedRate struct {
+	rate      *big.Rat
+	fetchedAt time.Time
+}
+
+type feedResponse struct {
+	Data struct {
+		Amount string `json:"amount"`
+	} `json:"data"`
+}
+
+func NewHTTPOracle(urlTemplate string, timeout, maxAge time.Duration) *HTTPOracle {
+	return &HTTPOracle{
+		urlTemplate: urlTemplate,
+		client:      &http.Client{Timeout: timeout},
+		maxAge:      maxAge,
+		cache:       make(map[string]cachedRate),
+	}
+}
+
+func (o *HTTPOracle) Rate(ctx context.Context, base, quote string) (*big.Rat, error) {
+	base, quote = strings.ToUpper(base), strings.ToUpper(quote)
+	if base == quote {
+		return big.NewRat(1, 1), nil
+	}
+
+	key := pair(base, quote)
+
+	o.mu.Lock()
+	cached, ok := o.cache[key]
+	o.mu.Unlock()
+	if ok && time.Since(cached.fetchedAt) < o.maxAge {
+		return new(big.Rat).Set(cached.rate), nil
+	}
+
+	rate, err := o.fetch(ctx, base, quote)
+	if err != nil {
+		return nil, err
+	}
+
+	o.mu.Lock()
+	o.cache[key] = cachedRate{rate: rate, fetchedAt: time.Now()}
+	o.mu.Unlock()
+
+	return new(big.Rat).Set(rate), nil
+}
+
+func (o *HTTPOracle) fetch(ctx context.Context, base, quote string) (*big.Rat, error) {
+	url := strings.NewReplacer("{base}", base, "{quote}", quote).Replace(o.urlTemplate)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", domain.ErrPriceUnavailable, pair(base, quote), err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: %s: feed returned %s", domain.ErrPriceUnavailable, pair(base, quote), resp.Status)
+	}
+
+	var body feedResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", domain.ErrPriceUnavailable, pair(base, quote), err)
+	}
+
+	rate, ok := new(big.Rat).SetString(body.Data.Amount)
+	if !ok || rate.Sign() <= 0 {
+		return nil, fmt.Errorf("%w: %s = %q", domain.ErrInvalidRate, pair(base, quote), body.Data.Amount)
+	}
+	return rate, nil
+}
@@ -0,0 +1,71 @@
+package pricing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/hsibAD/payment-service/internal/domain"
+)
+
+// StaticOracle serves fixed rates loaded from a JSON file such as
+//
+//	{"ETH/USD": "3012.55", "USDC/USD": "1"}
+//
+// It is meant for development and for markets without a live feed.
+type StaticOracle struct {
+	rates map[string]*big.Rat
+}
+
+func NewStaticOracle(rates map[string]string) (*StaticOracle, error) {
+	o := &StaticOracle{rates: make(map[string]*big.Rat, len(rates))}
+	for pair, value := range rates {
+		rate, ok := new(big.Rat).SetString(value)
+		if !ok || rate.Sign() <= 0 {
+			return nil, fmt.Errorf("%w: %s = %q", domain.ErrInvalidRate, pair, value)
+		}
+		o.rates[strings.ToUpper(pair)] = rate
+	}
+	return o, nil
+}
+
+func LoadStaticOracle(path string) (*StaticOracle, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read price file: %w", err)
+	}
+
+	var rates map[string]string
+	if err := json.Unmarshal(data, &rates); err != nil {
+		return nil, fmt.Errorf("failed to parse price file: %w", err)
+	}
+
+	return NewStaticOracle(rates)
+}
+
+// Rate returns the configured rate for base/quote, or the inverse of
+// quote/base.
+func (o *StaticOracle) Rate(ctx context.Context, base, quote string) (*big.Rat, error) {
+	return lookup(o.rates, base, quote)
+}
+
+func lookup(rates map[string]*big.Rat, base, quote string) (*big.Rat, error) {
+	base, quote = strings.ToUpper(base), strings.ToUpper(quote)
+	if base == quote {
+		return big.NewRat(1, 1), nil
+	}
+	if rate, ok := rates[pair(base, quote)]; ok {
+		return new(big.Rat).Set(rate), nil
+	}
+	if rate, ok := rates[pair(quote, base)]; ok {
+		return new(big.Rat).Inv(rate), nil
+	}
+	return nil, fmt.Errorf("%w: %s", domain.ErrPriceUnavailable, pair(base, quote))
+}
+
+func pair(base, quote string) string {
+	return base + "/" + quote
+}
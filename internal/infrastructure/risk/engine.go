@@ -0,0 +1,150 @@
+package risk
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/hsibAD/payment-service/internal/domain"
+)
+
+// Engine implements domain.RiskEngine with a rule set. Velocity and failed
+// attempt rules count in counters; a rule whose counter cannot be read is
+// skipped so that an unavailable counter store does not stop payments.
+type Engine struct {
+	rules    *Rules
+	counters domain.EventCounter
+
+	// attempts and failures are how long attempts and failures are kept
+	// per key: the longest window of the rules counting them.
+	attempts map[CounterKey]time.Duration
+	failures map[CounterKey]time.Duration
+}
+
+func NewEngine(rules *Rules, counters domain.EventCounter) *Engine {
+	e := &Engine{
+		rules:    rules,
+		counters: counters,
+		attempts: make(map[CounterKey]time.Duration),
+		failures: make(map[CounterKey]time.Duration),
+	}
+	for _, rule := range rules.Rules {
+		switch rule.Type {
+		case RuleVelocity:
+			e.attempts[rule.Key] = maxDuration(e.attempts[rule.Key], rule.Window)
+		case RuleFailedAttempts:
+			e.failures[rule.Key] = maxDuration(e.failures[rule.Key], rule.Window)
+		}
+	}
+	return e
+}
+
+func (e *Engine) Assess(ctx context.Context, signals domain.RiskSignals) (*domain.RiskAssessment, error) {
+	assessment := &domain.RiskAssessment{
+		Decision:   domain.RiskDecisionAllow,
+		AssessedAt: time.Now(),
+	}
+
+	for i := range e.rules.Rules {
+		rule := &e.rules.Rules[i]
+		matched, err := e.matches(ctx, rule, signals)
+		if err != nil {
+			log.Printf("Skipping risk rule %s for payment %s: %v", rule.Name, signals.Payment.ID, err)
+			continue
+		}
+		if !matched {
+			continue
+		}
+
+		assessment.Score += rule.Score
+		assessment.Rules = append(assessment.Rules, rule.Name)
+		if rule.Decision.Severity() > assessment.Decision.Severity() {
+			assessment.Decision = rule.Decision
+		}
+	}
+
+	if decision := e.rules.decide(assessment.Score); decision.Severity() > assessment.Decision.Severity() {
+		assessment.Decision = decision
+	}
+	return assessment, nil
+}
+
+// Record counts the attempt, and its failure, under every key a rule counts
+// by.
+func (e *Engine) Record(ctx context.Context, signals domain.RiskSignals, failed bool) error {
+	var errs []error
+	for key, retention := range e.attempts {
+		if value := keyValue(key, signals); value != "" {
+			errs = append(errs, e.counters.RecordEvent(ctx, counterName("attempts", key, value), retention))
+		}
+	}
+	if failed {
+		for key, retention := range e.failures {
+			if value := keyValue(key, signals); value != "" {
+				errs = append(errs, e.counters.RecordEvent(ctx, counterName("failures", key, value), retention))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (e *Engine) matches(ctx context.Context, rule *Rule, signals domain.RiskSignals) (bool, error) {
+	payment := signals.Payment
+
+	switch rule.Type {
+	case RuleVelocity, RuleFailedAttempts:
+		value := keyValue(rule.Key, signals)
+		if value == "" {
+			return false, nil
+		}
+		kind := "attempts"
+		if rule.Type == RuleFailedAttempts {
+			kind = "failures"
+		}
+		count, err := e.counters.CountEvents(ctx, counterName(kind, rule.Key, value), rule.Window)
+		if err != nil {
+			return false, err
+		}
+		// Velocity counts earlier attempts, so the one being scored is
+		// the one over the limit.
+		return count >= rule.Max, nil
+
+	case RuleAmount:
+		return rule.exceeds(payment.Amount), nil
+
+	case RuleFirstOrder:
+		return signals.FirstOrder && rule.exceeds(payment.Amount), nil
+
+	case RuleCountryMismatch:
+		if signals.Card == nil || signals.Card.Country == "" || signals.Client.Country == "" {
+			return false, nil
+		}
+		return !strings.EqualFold(signals.Card.Country, signals.Client.Country), nil
+	}
+	return false, nil
+}
+
+func keyValue(key CounterKey, signals domain.RiskSignals) string {
+	switch key {
+	case CounterUser:
+		return signals.Payment.UserID
+	case CounterCard:
+		return signals.CardKey
+	case CounterIP:
+		return signals.Client.IP
+	}
+	return ""
+}
+
+func counterName(kind string, key CounterKey, value string) string {
+	return "risk:" + kind + ":" + string(key) + ":" + value
+}
+
+func maxDuration(a, b time.Duration) time.Duration {
+	if a > b {
+		return a
+	}
+	return b
+}
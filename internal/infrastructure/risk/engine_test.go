@@ -0,0 +1,285 @@
+package risk_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/hsibAD/payment-service/internal/domain"
+	"github.com/hsibAD/payment-service/internal/infrastructure/risk"
+)
+
+// memoryCounters counts events per key and ignores windows.
+type memoryCounters struct {
+	events map[string]int
+	err    error
+}
+
+func newMemoryCounters() *memoryCounters {
+	return &memoryCounters{events: make(map[string]int)}
+}
+
+func (c *memoryCounters) CountEvents(ctx context.Context, key string, window time.Duration) (int, error) {
+	return c.events[key], c.err
+}
+
+func (c *memoryCounters) RecordEvent(ctx context.Context, key string, retention time.Duration) error {
+	c.events[key]++
+	return c.err
+}
+
+func (c *memoryCounters) RecordDistinctEvent(ctx context.Context, key, member string, retention time.Duration) error {
+	return errors.New("not used by the risk engine")
+}
+
+const testRules = `
+review_score: 50
+deny_score: 100
+rules:
+  - name: user_velocity
+    type: velocity
+    key: user
+    window: 1h
+    max: 2
+    score: 40
+  - name: large_amount
+    type: amount
+    amounts:
+      USD: "2000.00"
+    score: 30
+  - name: country_mismatch
+    type: country_mismatch
+    score: 30
+  - name: first_order_high_value
+    type: first_order
+    amounts:
+      usd: "500.00"
+    score: 40
+  - name: repeated_card_failures
+    type: failed_attempts
+    key: card
+    window: 24h
+    max: 2
+    decision: deny
+`
+
+func newEngine(t *testing.T, counters domain.EventCounter) *risk.Engine {
+	t.Helper()
+
+	rules, err := risk.ParseRules([]byte(testRules))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return risk.NewEngine(rules, counters)
+}
+
+// signals is a 10.00 USD payment by a returning user from a US client.
+func signals() domain.RiskSignals {
+	return domain.RiskSignals{
+		Payment: &domain.Payment{
+			ID:     "payment-1",
+			UserID: "user-1",
+			Amount: domain.Money{MinorUnits: 1000, Currency: "USD"},
+		},
+		Card:    &domain.CardSummary{Country: "US"},
+		CardKey: "card-1",
+		Client:  domain.ClientInfo{IP: "203.0.113.7", Country: "US"},
+	}
+}
+
+func TestEngineAssess(t *testing.T) {
+	tests := []struct {
+		name         string
+		edit         func(*domain.RiskSignals)
+		wantRules    []string
+		wantScore    int
+		wantDecision domain.RiskDecision
+	}{
+		{name: "nothing matches", wantDecision: domain.RiskDecisionAllow},
+		{
+			name:         "amount at the limit",
+			edit:         func(s *domain.RiskSignals) { s.Payment.Amount.MinorUnits = 200000 },
+			wantRules:    []string{"large_amount"},
+			wantScore:    30,
+			wantDecision: domain.RiskDecisionAllow,
+		},
+		{
+			name:         "amount in a currency without limit",
+			edit:         func(s *domain.RiskSignals) { s.Payment.Amount = domain.Money{MinorUnits: 900000, Currency: "EUR"} },
+			wantDecision: domain.RiskDecisionAllow,
+		},
+		{
+			name:         "returning user with a high value order",
+			edit:         func(s *domain.RiskSignals) { s.Payment.Amount.MinorUnits = 60000 },
+			wantDecision: domain.RiskDecisionAllow,
+		},
+		{
+			name: "first order with a high value",
+			edit: func(s *domain.RiskSignals) {
+				s.Payment.Amount.MinorUnits = 60000
+				s.FirstOrder = true
+			},
+			wantRules:    []string{"first_order_high_value"},
+			wantScore:    40,
+			wantDecision: domain.RiskDecisionAllow,
+		},
+		{
+			name:         "card from another country",
+			edit:         func(s *domain.RiskSignals) { s.Card.Country = "gb" },
+			wantRules:    []string{"country_mismatch"},
+			wantScore:    30,
+			wantDecision: domain.RiskDecisionAllow,
+		},
+		{
+			name:         "country compared case-insensitively",
+			edit:         func(s *domain.RiskSignals) { s.Card.Country = "us" },
+			wantDecision: domain.RiskDecisionAllow,
+		},
+		{
+			name:         "card that cannot be inspected",
+			edit:         func(s *domain.RiskSignals) { s.Card = nil },
+			wantDecision: domain.RiskDecisionAllow,
+		},
+		{
+			name:         "client without country",
+			edit:         func(s *domain.RiskSignals) { s.Client.Country = "" },
+			wantDecision: domain.RiskDecisionAllow,
+		},
+		{
+			name: "review score reached",
+			edit: func(s *domain.RiskSignals) {
+				s.Payment.Amount.MinorUnits = 200000
+				s.Card.Country = "GB"
+			},
+			wantRules:    []string{"large_amount", "country_mismatch"},
+			wantScore:    60,
+			wantDecision: domain.RiskDecisionReview,
+		},
+		{
+			name: "deny score reached",
+			edit: func(s *domain.RiskSignals) {
+				s.Payment.Amount.MinorUnits = 200000
+				s.Card.Country = "GB"
+				s.FirstOrder = true
+			},
+			wantRules:    []string{"large_amount", "country_mismatch", "first_order_high_value"},
+			wantScore:    100,
+			wantDecision: domain.RiskDecisionDeny,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newEngine(t, newMemoryCounters())
+			s := signals()
+			if tt.edit != nil {
+				tt.edit(&s)
+			}
+
+			assessment, err := e.Assess(context.Background(), s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(assessment.Rules, tt.wantRules) {
+				t.Errorf("rules = %v, want %v", assessment.Rules, tt.wantRules)
+			}
+			if assessment.Score != tt.wantScore {
+				t.Errorf("score = %d, want %d", assessment.Score, tt.wantScore)
+			}
+			if assessment.Decision != tt.wantDecision {
+				t.Errorf("decision = %s, want %s", assessment.Decision, tt.wantDecision)
+			}
+		})
+	}
+}
+
+func TestEngineCountsRecordedAttempts(t *testing.T) {
+	ctx := context.Background()
+	e := newEngine(t, newMemoryCounters())
+
+	assess := func() *domain.RiskAssessment {
+		t.Helper()
+		assessment, err := e.Assess(ctx, signals())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return assessment
+	}
+
+	// Two attempts are within the limit; the third is over it.
+	for i := 0; i < 2; i++ {
+		if assessment := assess(); len(assessment.Rules) != 0 {
+			t.Fatalf("attempt %d matched %v", i+1, assessment.Rules)
+		}
+		if err := e.Record(ctx, signals(), false); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if assessment := assess(); !reflect.DeepEqual(assessment.Rules, []string{"user_velocity"}) {
+		t.Fatalf("third attempt matched %v, want user_velocity", assessment.Rules)
+	}
+
+	// Another user is counted apart.
+	other := signals()
+	other.Payment.UserID = "user-2"
+	if assessment, _ := e.Assess(ctx, other); len(assessment.Rules) != 0 {
+		t.Errorf("other user matched %v", assessment.Rules)
+	}
+}
+
+func TestEngineDeniesRepeatedFailures(t *testing.T) {
+	ctx := context.Background()
+	e := newEngine(t, newMemoryCounters())
+
+	// Failures are counted by card, so a new user does not reset them.
+	for i, user := range []string{"user-1", "user-2"} {
+		s := signals()
+		s.Payment.UserID = user
+		if err := e.Record(ctx, s, true); err != nil {
+			t.Fatal(err)
+		}
+		if i == 0 {
+			if assessment, _ := e.Assess(ctx, s); assessment.Decision != domain.RiskDecisionAllow {
+				t.Fatalf("decision after one failure = %s, want allow", assessment.Decision)
+			}
+		}
+	}
+
+	s := signals()
+	s.Payment.UserID = "user-3"
+	assessment, err := e.Assess(ctx, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The rule scores nothing but its decision overrides the score.
+	if assessment.Decision != domain.RiskDecisionDeny || assessment.Score != 0 {
+		t.Errorf("assessment = %+v, want a deny scoring 0", assessment)
+	}
+
+	s.CardKey = "card-2"
+	if assessment, _ := e.Assess(ctx, s); assessment.Decision != domain.RiskDecisionAllow {
+		t.Errorf("decision for another card = %s, want allow", assessment.Decision)
+	}
+}
+
+func TestEngineSkipsRulesWhenCountersFail(t *testing.T) {
+	counters := newMemoryCounters()
+	counters.err = errors.New("redis unavailable")
+	e := newEngine(t, counters)
+
+	s := signals()
+	s.Payment.Amount.MinorUnits = 200000
+	assessment, err := e.Assess(context.Background(), s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(assessment.Rules, []string{"large_amount"}) {
+		t.Errorf("rules = %v, want only large_amount", assessment.Rules)
+	}
+
+	if err := e.Record(context.Background(), s, true); err == nil {
+		t.Error("Record hid the counter error")
+	}
+}
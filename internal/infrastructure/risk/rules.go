@@ -0,0 +1,178 @@
+package risk
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/hsibAD/payment-service/internal/domain"
+	"gopkg.in/yaml.v3"
+)
+
+// RuleType is the kind of check a rule makes.
+type RuleType string
+
+const (
+	// RuleVelocity matches when more than Max attempts were made with the
+	// same Key within Window.
+	RuleVelocity RuleType = "velocity"
+	// RuleFailedAttempts matches when Max attempts with the same Key failed
+	// within Window.
+	RuleFailedAttempts RuleType = "failed_attempts"
+	// RuleAmount matches payments of at least the amount for their currency.
+	RuleAmount RuleType = "amount"
+	// RuleFirstOrder is RuleAmount for users who never paid before.
+	RuleFirstOrder RuleType = "first_order"
+	// RuleCountryMismatch matches when the card was issued in a different
+	// country than the one the client connects from.
+	RuleCountryMismatch RuleType = "country_mismatch"
+)
+
+// CounterKey is what velocity and failed attempt rules count attempts by.
+type CounterKey string
+
+const (
+	CounterUser CounterKey = "user"
+	CounterCard CounterKey = "card"
+	CounterIP   CounterKey = "ip"
+)
+
+// Rule is a single check. A payment's score is the sum of the scores of the
+// rules it matches.
+type Rule struct {
+	Name  string   `yaml:"name"`
+	Type  RuleType `yaml:"type"`
+	Score int      `yaml:"score"`
+	// Decision, when set, is the least a payment matching the rule gets,
+	// whatever its score, e.g. deny to block outright.
+	Decision domain.RiskDecision `yaml:"decision"`
+
+	// Key, Window and Max configure velocity and failed_attempts rules.
+	Key    CounterKey    `yaml:"key"`
+	Window time.Duration `yaml:"window"`
+	Max    int           `yaml:"max"`
+
+	// Amounts configures amount and first_order rules per currency, as
+	// decimals such as "1000.00". Other currencies never match.
+	Amounts map[string]string `yaml:"amounts"`
+
+	amounts map[string]domain.Money
+}
+
+// Rules is a rule set with the scores at which payments are held for review
+// and denied. A zero threshold is never reached.
+type Rules struct {
+	ReviewScore int    `yaml:"review_score"`
+	DenyScore   int    `yaml:"deny_score"`
+	Rules       []Rule `yaml:"rules"`
+}
+
+// LoadRules reads a YAML rule set, e.g.
+//
+//	review_score: 50
+//	deny_score: 90
+//	rules:
+//	  - name: user_velocity
+//	    type: velocity
+//	    key: user
+//	    window: 1h
+//	    max: 5
+//	    score: 40
+func LoadRules(path string) (*Rules, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read risk rules: %w", err)
+	}
+
+	return ParseRules(data)
+}
+
+// ParseRules reads a rule set in the format LoadRules expects.
+func ParseRules(data []byte) (*Rules, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	var rules Rules
+	if err := decoder.Decode(&rules); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse risk rules: %w", err)
+	}
+	if rules.ReviewScore < 0 || rules.DenyScore < 0 {
+		return nil, fmt.Errorf("risk score thresholds must not be negative")
+	}
+
+	seen := make(map[string]bool, len(rules.Rules))
+	for i := range rules.Rules {
+		rule := &rules.Rules[i]
+		if err := rule.parse(); err != nil {
+			return nil, err
+		}
+		if seen[rule.Name] {
+			return nil, fmt.Errorf("risk rule %q is listed twice", rule.Name)
+		}
+		seen[rule.Name] = true
+	}
+
+	return &rules, nil
+}
+
+// decide returns the decision for a score.
+func (r *Rules) decide(score int) domain.RiskDecision {
+	switch {
+	case r.DenyScore > 0 && score >= r.DenyScore:
+		return domain.RiskDecisionDeny
+	case r.ReviewScore > 0 && score >= r.ReviewScore:
+		return domain.RiskDecisionReview
+	default:
+		return domain.RiskDecisionAllow
+	}
+}
+
+func (r *Rule) parse() error {
+	if r.Name == "" {
+		return fmt.Errorf("risk rule has no name")
+	}
+	if r.Score < 0 {
+		return fmt.Errorf("risk rule %q has a negative score", r.Name)
+	}
+	switch r.Decision {
+	case "", domain.RiskDecisionAllow, domain.RiskDecisionReview, domain.RiskDecisionDeny:
+	default:
+		return fmt.Errorf("risk rule %q has an unknown decision %q", r.Name, r.Decision)
+	}
+
+	switch r.Type {
+	case RuleVelocity, RuleFailedAttempts:
+		if r.Key != CounterUser && r.Key != CounterCard && r.Key != CounterIP {
+			return fmt.Errorf("risk rule %q has an unknown key %q", r.Name, r.Key)
+		}
+		if r.Window <= 0 || r.Max <= 0 {
+			return fmt.Errorf("risk rule %q needs a window and a max", r.Name)
+		}
+	case RuleAmount, RuleFirstOrder:
+		if len(r.Amounts) == 0 {
+			return fmt.Errorf("risk rule %q has no amounts", r.Name)
+		}
+		r.amounts = make(map[string]domain.Money, len(r.Amounts))
+		for currency, amount := range r.Amounts {
+			m, err := domain.ParseMoney(amount, currency)
+			if err != nil {
+				return fmt.Errorf("risk rule %q: invalid %s amount %q: %w", r.Name, currency, amount, err)
+			}
+			r.amounts[m.Currency] = m
+		}
+	case RuleCountryMismatch:
+	default:
+		return fmt.Errorf("risk rule %q has an unknown type %q", r.Name, r.Type)
+	}
+	return nil
+}
+
+// exceeds reports whether amount is at least the rule's amount for its
+// currency.
+func (r *Rule) exceeds(amount domain.Money) bool {
+	limit, ok := r.amounts[amount.Currency]
+	return ok && amount.MinorUnits >= limit.MinorUnits
+}
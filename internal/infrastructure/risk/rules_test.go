@@ -0,0 +1,61 @@
+package risk_test
+
+import (
+	"testing"
+
+	"github.com/hsibAD/payment-service/internal/infrastructure/risk"
+)
+
+func TestLoadRulesExample(t *testing.T) {
+	rules, err := risk.LoadRules("../../../risk_rules.example.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rules.ReviewScore != 50 || rules.DenyScore != 100 || len(rules.Rules) != 7 {
+		t.Errorf("rules = %+v", rules)
+	}
+	if _, err := risk.LoadRules("missing.yaml"); err == nil {
+		t.Error("LoadRules read a missing file")
+	}
+}
+
+func TestParseRulesRejects(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+	}{
+		{name: "unknown field", yaml: "review_scroe: 50\n"},
+		{name: "negative threshold", yaml: "deny_score: -1\n"},
+		{name: "no name", yaml: "rules:\n  - type: country_mismatch\n"},
+		{name: "negative score", yaml: "rules:\n  - name: a\n    type: country_mismatch\n    score: -5\n"},
+		{name: "unknown decision", yaml: "rules:\n  - name: a\n    type: country_mismatch\n    decision: block\n"},
+		{name: "unknown type", yaml: "rules:\n  - name: a\n    type: weather\n"},
+		{name: "unknown key", yaml: "rules:\n  - name: a\n    type: velocity\n    key: device\n    window: 1h\n    max: 5\n"},
+		{name: "velocity without window", yaml: "rules:\n  - name: a\n    type: velocity\n    key: user\n    max: 5\n"},
+		{name: "velocity without max", yaml: "rules:\n  - name: a\n    type: failed_attempts\n    key: card\n    window: 1h\n"},
+		{name: "amount without amounts", yaml: "rules:\n  - name: a\n    type: amount\n"},
+		{name: "invalid amount", yaml: "rules:\n  - name: a\n    type: first_order\n    amounts:\n      USD: lots\n"},
+		{
+			name: "duplicate name",
+			yaml: "rules:\n  - name: a\n    type: country_mismatch\n  - name: a\n    type: country_mismatch\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := risk.ParseRules([]byte(tt.yaml)); err == nil {
+				t.Error("ParseRules accepted the rules")
+			}
+		})
+	}
+}
+
+func TestParseRulesEmpty(t *testing.T) {
+	rules, err := risk.ParseRules(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rules.Rules) != 0 {
+		t.Errorf("empty rule set has %d rules", len(rules.Rules))
+	}
+}
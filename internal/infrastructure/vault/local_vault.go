@@ -0,0 +1,189 @@
+// Package vault holds card vault implementations.
+package vault
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/hsibAD/payment-service/internal/domain"
+)
+
+const tokenPrefix = "vault_"
+
+// LocalVault stores AES-GCM encrypted cards in a JSON file. It is meant for
+// development and tests, where a hosted vault or client side Stripe
+// tokenization is not available; production deployments should leave it
+// disabled and accept provider payment method IDs only.
+type LocalVault struct {
+	path string
+	aead cipher.AEAD
+	mu   sync.Mutex
+}
+
+// NewLocalVault opens the vault file at path, which is created on first
+// write. key must be 16, 24 or 32 bytes.
+func NewLocalVault(path string, key []byte) (*LocalVault, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid vault key: %w", err)
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &LocalVault{
+		path: path,
+		aead: aead,
+	}, nil
+}
+
+// NewLocalVaultFromHexKey is NewLocalVault with a hex encoded key, as kept in
+// configuration.
+func NewLocalVaultFromHexKey(path, hexKey string) (*LocalVault, error) {
+	key, err := hex.DecodeString(hexKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid vault key: %w", err)
+	}
+	return NewLocalVault(path, key)
+}
+
+func (v *LocalVault) Tokenize(ctx context.Context, card *domain.CreditCardInfo) (string, error) {
+	if err := card.Validate(); err != nil {
+		return "", fmt.Errorf("%w: %v", domain.ErrInvalidCardInfo, err)
+	}
+
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	token := tokenPrefix + hex.EncodeToString(id)
+
+	plaintext, err := json.Marshal(card)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, v.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	// The token is authenticated with the ciphertext, so an entry copied
+	// under another token fails to decrypt.
+	sealed := v.aead.Seal(nonce, nonce, plaintext, []byte(token))
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	entries, err := v.load()
+	if err != nil {
+		return "", err
+	}
+	entries[token] = sealed
+
+	if err := v.save(entries); err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+func (v *LocalVault) Detokenize(ctx context.Context, token string) (*domain.CreditCardInfo, error) {
+	v.mu.Lock()
+	entries, err := v.load()
+	v.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	sealed, ok := entries[token]
+	if !ok {
+		return nil, domain.ErrCardTokenNotFound
+	}
+
+	nonceSize := v.aead.NonceSize()
+	if len(sealed) < nonceSize {
+		return nil, fmt.Errorf("%w: corrupt vault entry", domain.ErrInvalidCardToken)
+	}
+
+	plaintext, err := v.aead.Open(nil, sealed[:nonceSize], sealed[nonceSize:], []byte(token))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidCardToken, err)
+	}
+
+	var card domain.CreditCardInfo
+	if err := json.Unmarshal(plaintext, &card); err != nil {
+		return nil, err
+	}
+
+	return &card, nil
+}
+
+func (v *LocalVault) Delete(ctx context.Context, token string) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	entries, err := v.load()
+	if err != nil {
+		return err
+	}
+	if _, ok := entries[token]; !ok {
+		return domain.ErrCardTokenNotFound
+	}
+	delete(entries, token)
+
+	return v.save(entries)
+}
+
+// load reads the vault file on every call so tokens added by another process,
+// such as the cardvault command, are visible without a restart.
+func (v *LocalVault) load() (map[string][]byte, error) {
+	entries := make(map[string][]byte)
+
+	data, err := os.ReadFile(v.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return entries, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read vault: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("failed to decode vault: %w", err)
+	}
+
+	return entries, nil
+}
+
+func (v *LocalVault) save(entries map[string][]byte) error {
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(v.path), ".vault-*")
+	if err != nil {
+		return fmt.Errorf("failed to write vault: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write vault: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write vault: %w", err)
+	}
+
+	return os.Rename(tmp.Name(), v.path)
+}
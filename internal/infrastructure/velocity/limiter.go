@@ -0,0 +1,173 @@
+package velocity
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/hsibAD/payment-service/internal/domain"
+)
+
+// Limit allows up to Max events within Window. A zero Max disables it.
+type Limit struct {
+	Max    int
+	Window time.Duration
+}
+
+func (l Limit) enabled() bool {
+	return l.Max > 0 && l.Window > 0
+}
+
+// Config holds the velocity limits. Tripping a limit locks out the user,
+// client IP or card it counts for Lockout; every further lockout doubles,
+// up to MaxLockout, until LockoutMemory passes without one.
+type Config struct {
+	UserAttempts Limit
+	UserCards    Limit
+	IPDeclines   Limit
+	CardDeclines Limit
+
+	Lockout       time.Duration
+	MaxLockout    time.Duration
+	LockoutMemory time.Duration
+}
+
+// Limiter implements domain.VelocityLimiter. Counters and lockouts that
+// cannot be read are skipped so that an unavailable store does not stop
+// payments.
+type Limiter struct {
+	cfg      Config
+	counters domain.EventCounter
+	lockouts domain.LockoutStore
+}
+
+func NewLimiter(cfg Config, counters domain.EventCounter, lockouts domain.LockoutStore) *Limiter {
+	return &Limiter{
+		cfg:      cfg,
+		counters: counters,
+		lockouts: lockouts,
+	}
+}
+
+// subject is what a limit counts for and what gets locked out.
+type subject struct {
+	kind  string
+	value string
+}
+
+func (s subject) key(prefix string) string {
+	return "velocity:" + prefix + ":" + s.kind + ":" + s.value
+}
+
+// Check rejects attempts by a locked out user, IP or card, and trips the
+// limits the attempt would exceed. A card is counted towards the user's
+// cards as soon as it is tried.
+func (l *Limiter) Check(ctx context.Context, signals domain.RiskSignals) error {
+	user := subject{"user", signals.Payment.UserID}
+	ip := subject{"ip", signals.Client.IP}
+	card := subject{"card", signals.CardKey}
+
+	for _, s := range []subject{user, ip, card} {
+		if s.value == "" {
+			continue
+		}
+		remaining, err := l.lockouts.Lockout(ctx, s.key("lockout"))
+		if err != nil {
+			log.Printf("failed to read %s lockout: %v", s.kind, err)
+			continue
+		}
+		if remaining > 0 {
+			return domain.ErrRateLimited{RetryAfter: remaining}
+		}
+	}
+
+	if card.value != "" && l.cfg.UserCards.enabled() {
+		err := l.counters.RecordDistinctEvent(ctx, user.key("cards"), card.value, l.cfg.UserCards.Window)
+		if err != nil {
+			log.Printf("failed to record card for user %s: %v", user.value, err)
+		}
+	}
+
+	checks := []struct {
+		subject subject
+		counter string
+		limit   Limit
+		// distinct counters already include this attempt.
+		distinct bool
+	}{
+		{user, "attempts", l.cfg.UserAttempts, false},
+		{user, "cards", l.cfg.UserCards, true},
+		{ip, "declines", l.cfg.IPDeclines, false},
+		{card, "declines", l.cfg.CardDeclines, false},
+	}
+	for _, check := range checks {
+		if check.subject.value == "" || !check.limit.enabled() {
+			continue
+		}
+		count, err := l.counters.CountEvents(ctx, check.subject.key(check.counter), check.limit.Window)
+		if err != nil {
+			log.Printf("failed to count %s %s: %v", check.subject.kind, check.counter, err)
+			continue
+		}
+		if check.distinct {
+			count--
+		}
+		if count >= check.limit.Max {
+			return l.trip(ctx, check.subject, check.counter)
+		}
+	}
+	return nil
+}
+
+// Record counts the attempt against the user and, if the card was
+// declined, the decline against the client IP and card.
+func (l *Limiter) Record(ctx context.Context, signals domain.RiskSignals, declined bool) error {
+	var errs []error
+	if l.cfg.UserAttempts.enabled() {
+		user := subject{"user", signals.Payment.UserID}
+		errs = append(errs, l.counters.RecordEvent(ctx, user.key("attempts"), l.cfg.UserAttempts.Window))
+	}
+	if !declined {
+		return errors.Join(errs...)
+	}
+
+	if ip := (subject{"ip", signals.Client.IP}); ip.value != "" && l.cfg.IPDeclines.enabled() {
+		errs = append(errs, l.counters.RecordEvent(ctx, ip.key("declines"), l.cfg.IPDeclines.Window))
+	}
+	if card := (subject{"card", signals.CardKey}); card.value != "" && l.cfg.CardDeclines.enabled() {
+		errs = append(errs, l.counters.RecordEvent(ctx, card.key("declines"), l.cfg.CardDeclines.Window))
+	}
+	return errors.Join(errs...)
+}
+
+// trip locks the subject out, for longer with every strike.
+func (l *Limiter) trip(ctx context.Context, s subject, counter string) error {
+	strikes, err := l.lockouts.AddStrike(ctx, s.key("strikes"), l.cfg.LockoutMemory)
+	if err != nil {
+		log.Printf("failed to count %s lockout: %v", s.kind, err)
+		strikes = 1
+	}
+
+	d := l.lockoutFor(strikes)
+	if d <= 0 {
+		return domain.ErrRateLimited{}
+	}
+	if err := l.lockouts.LockOut(ctx, s.key("lockout"), d); err != nil {
+		log.Printf("failed to lock out %s: %v", s.kind, err)
+	}
+
+	log.Printf("Velocity limit on %s %s tripped; locked out for %s (strike %d)", s.kind, counter, d, strikes)
+	return domain.ErrRateLimited{RetryAfter: d}
+}
+
+func (l *Limiter) lockoutFor(strikes int) time.Duration {
+	d := l.cfg.Lockout
+	for i := 1; i < strikes && d < l.cfg.MaxLockout; i++ {
+		d *= 2
+	}
+	if l.cfg.MaxLockout > 0 && d > l.cfg.MaxLockout {
+		d = l.cfg.MaxLockout
+	}
+	return d
+}
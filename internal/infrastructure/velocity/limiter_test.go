@@ -0,0 +1,270 @@
+package velocity_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/hsibAD/payment-service/internal/domain"
+	"github.com/hsibAD/payment-service/internal/infrastructure/velocity"
+)
+
+// memoryCounters counts events per key and ignores windows.
+type memoryCounters struct {
+	events  map[string]int
+	members map[string]map[string]bool
+	err     error
+}
+
+func newMemoryCounters() *memoryCounters {
+	return &memoryCounters{events: make(map[string]int), members: make(map[string]map[string]bool)}
+}
+
+func (c *memoryCounters) CountEvents(ctx context.Context, key string, window time.Duration) (int, error) {
+	return c.events[key] + len(c.members[key]), c.err
+}
+
+func (c *memoryCounters) RecordEvent(ctx context.Context, key string, retention time.Duration) error {
+	c.events[key]++
+	return c.err
+}
+
+func (c *memoryCounters) RecordDistinctEvent(ctx context.Context, key, member string, retention time.Duration) error {
+	if c.members[key] == nil {
+		c.members[key] = make(map[string]bool)
+	}
+	c.members[key][member] = true
+	return c.err
+}
+
+// memoryLockouts keeps lockouts until they are lifted; time does not pass.
+type memoryLockouts struct {
+	lockouts map[string]time.Duration
+	strikes  map[string]int
+	err      error
+}
+
+func newMemoryLockouts() *memoryLockouts {
+	return &memoryLockouts{lockouts: make(map[string]time.Duration), strikes: make(map[string]int)}
+}
+
+func (l *memoryLockouts) Lockout(ctx context.Context, key string) (time.Duration, error) {
+	return l.lockouts[key], l.err
+}
+
+func (l *memoryLockouts) LockOut(ctx context.Context, key string, d time.Duration) error {
+	l.lockouts[key] = d
+	return l.err
+}
+
+func (l *memoryLockouts) AddStrike(ctx context.Context, key string, memory time.Duration) (int, error) {
+	l.strikes[key]++
+	return l.strikes[key], l.err
+}
+
+func (l *memoryLockouts) lift() {
+	l.lockouts = make(map[string]time.Duration)
+}
+
+var testConfig = velocity.Config{
+	UserAttempts:  velocity.Limit{Max: 3, Window: time.Hour},
+	UserCards:     velocity.Limit{Max: 2, Window: 24 * time.Hour},
+	IPDeclines:    velocity.Limit{Max: 2, Window: time.Hour},
+	CardDeclines:  velocity.Limit{Max: 2, Window: time.Hour},
+	Lockout:       time.Minute,
+	MaxLockout:    5 * time.Minute,
+	LockoutMemory: 24 * time.Hour,
+}
+
+// signals is an attempt by user-1 from one IP with card-1.
+func signals() domain.RiskSignals {
+	return domain.RiskSignals{
+		Payment: &domain.Payment{ID: "payment-1", UserID: "user-1"},
+		CardKey: "card-1",
+		Client:  domain.ClientInfo{IP: "203.0.113.7"},
+	}
+}
+
+// retryAfter returns how long err asks to wait, failing the test unless err
+// is an ErrRateLimited.
+func retryAfter(t *testing.T, err error) time.Duration {
+	t.Helper()
+
+	var limited domain.ErrRateLimited
+	if !errors.As(err, &limited) {
+		t.Fatalf("error = %v, want %T", err, limited)
+	}
+	return limited.RetryAfter
+}
+
+func TestLimiterTripsLimits(t *testing.T) {
+	tests := []struct {
+		name string
+		// attempts are made in order; all but the last must pass.
+		attempts []func(*domain.RiskSignals)
+		declined bool
+	}{
+		{
+			name:     "user attempts",
+			attempts: []func(*domain.RiskSignals){nil, nil, nil, nil},
+		},
+		{
+			name: "user cards",
+			attempts: []func(*domain.RiskSignals){
+				func(s *domain.RiskSignals) { s.CardKey = "card-1" },
+				func(s *domain.RiskSignals) { s.CardKey = "card-2" },
+				func(s *domain.RiskSignals) { s.CardKey = "card-3" },
+			},
+		},
+		{
+			name: "IP declines",
+			attempts: []func(*domain.RiskSignals){
+				func(s *domain.RiskSignals) { s.Payment.UserID, s.CardKey = "user-1", "card-1" },
+				func(s *domain.RiskSignals) { s.Payment.UserID, s.CardKey = "user-2", "card-2" },
+				func(s *domain.RiskSignals) { s.Payment.UserID, s.CardKey = "user-3", "card-3" },
+			},
+			declined: true,
+		},
+		{
+			name: "card declines",
+			attempts: []func(*domain.RiskSignals){
+				func(s *domain.RiskSignals) { s.Payment.UserID, s.Client.IP = "user-1", "203.0.113.1" },
+				func(s *domain.RiskSignals) { s.Payment.UserID, s.Client.IP = "user-2", "203.0.113.2" },
+				func(s *domain.RiskSignals) { s.Payment.UserID, s.Client.IP = "user-3", "203.0.113.3" },
+			},
+			declined: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			l := velocity.NewLimiter(testConfig, newMemoryCounters(), newMemoryLockouts())
+
+			last := len(tt.attempts) - 1
+			for i, edit := range tt.attempts {
+				s := signals()
+				if edit != nil {
+					edit(&s)
+				}
+				err := l.Check(ctx, s)
+				if i == last {
+					if d := retryAfter(t, err); d != testConfig.Lockout {
+						t.Errorf("retry after %s, want %s", d, testConfig.Lockout)
+					}
+					break
+				}
+				if err != nil {
+					t.Fatalf("attempt %d: %v", i+1, err)
+				}
+				if err := l.Record(ctx, s, tt.declined); err != nil {
+					t.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestLimiterIgnoresSuccessfulAttemptsForDeclines(t *testing.T) {
+	ctx := context.Background()
+	cfg := testConfig
+	cfg.UserAttempts = velocity.Limit{}
+	l := velocity.NewLimiter(cfg, newMemoryCounters(), newMemoryLockouts())
+
+	for i := 0; i < 5; i++ {
+		if err := l.Check(ctx, signals()); err != nil {
+			t.Fatalf("attempt %d: %v", i+1, err)
+		}
+		if err := l.Record(ctx, signals(), false); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestLimiterLocksOut(t *testing.T) {
+	ctx := context.Background()
+	lockouts := newMemoryLockouts()
+	cfg := testConfig
+	cfg.UserAttempts.Max = 1
+	l := velocity.NewLimiter(cfg, newMemoryCounters(), lockouts)
+
+	if err := l.Record(ctx, signals(), false); err != nil {
+		t.Fatal(err)
+	}
+
+	// Every lockout doubles the next one, up to the maximum.
+	for i, want := range []time.Duration{time.Minute, 2 * time.Minute, 4 * time.Minute, 5 * time.Minute, 5 * time.Minute} {
+		if d := retryAfter(t, l.Check(ctx, signals())); d != want {
+			t.Errorf("lockout %d = %s, want %s", i+1, d, want)
+		}
+
+		// A locked out user is turned away by another card and IP too.
+		other := signals()
+		other.CardKey, other.Client.IP = "card-2", "198.51.100.1"
+		if d := retryAfter(t, l.Check(ctx, other)); d != want {
+			t.Errorf("lockout %d with another card = %s, want %s", i+1, d, want)
+		}
+
+		lockouts.lift()
+	}
+}
+
+func TestLimiterWithoutLockout(t *testing.T) {
+	ctx := context.Background()
+	lockouts := newMemoryLockouts()
+	cfg := testConfig
+	cfg.UserAttempts.Max = 1
+	cfg.Lockout = 0
+	l := velocity.NewLimiter(cfg, newMemoryCounters(), lockouts)
+
+	if err := l.Record(ctx, signals(), false); err != nil {
+		t.Fatal(err)
+	}
+	if d := retryAfter(t, l.Check(ctx, signals())); d != 0 {
+		t.Errorf("retry after %s, want 0", d)
+	}
+	if len(lockouts.lockouts) != 0 {
+		t.Errorf("lockouts = %v, want none", lockouts.lockouts)
+	}
+}
+
+func TestLimiterDisabled(t *testing.T) {
+	ctx := context.Background()
+	l := velocity.NewLimiter(velocity.Config{Lockout: time.Minute}, newMemoryCounters(), newMemoryLockouts())
+
+	for i := 0; i < 10; i++ {
+		s := signals()
+		s.CardKey = "card-" + strconv.Itoa(i)
+		if err := l.Check(ctx, s); err != nil {
+			t.Fatalf("attempt %d: %v", i+1, err)
+		}
+		if err := l.Record(ctx, s, true); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestLimiterSkipsUnavailableStores(t *testing.T) {
+	ctx := context.Background()
+	counters, lockouts := newMemoryCounters(), newMemoryLockouts()
+	cfg := testConfig
+	cfg.UserAttempts.Max = 1
+	l := velocity.NewLimiter(cfg, counters, lockouts)
+
+	if err := l.Record(ctx, signals(), false); err != nil {
+		t.Fatal(err)
+	}
+	retryAfter(t, l.Check(ctx, signals()))
+
+	lockouts.err = errors.New("redis unavailable")
+	counters.err = errors.New("redis unavailable")
+
+	if err := l.Check(ctx, signals()); err != nil {
+		t.Errorf("Check = %v, want the attempt let through", err)
+	}
+	if err := l.Record(ctx, signals(), true); err == nil {
+		t.Error("Record hid the counter error")
+	}
+}
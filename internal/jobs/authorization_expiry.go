@@ -0,0 +1,48 @@
+// Package jobs contains background work that runs alongside the gRPC server.
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/hsibAD/payment-service/internal/usecase"
+)
+
+// AuthorizationExpiryJob periodically voids card holds that were never
+// captured.
+type AuthorizationExpiryJob struct {
+	service  *usecase.PaymentService
+	interval time.Duration
+	maxAge   time.Duration
+}
+
+func NewAuthorizationExpiryJob(service *usecase.PaymentService, interval, maxAge time.Duration) *AuthorizationExpiryJob {
+	return &AuthorizationExpiryJob{
+		service:  service,
+		interval: interval,
+		maxAge:   maxAge,
+	}
+}
+
+// Run blocks until ctx is cancelled.
+func (j *AuthorizationExpiryJob) Run(ctx context.Context) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			expired, err := j.service.ExpireAuthorizations(ctx, j.maxAge)
+			if err != nil {
+				log.Printf("failed to expire authorizations: %v", err)
+				continue
+			}
+			if expired > 0 {
+				log.Printf("voided %d expired authorizations", expired)
+			}
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/hsibAD/payment-service/internal/usecase"
+)
+
+// RefundConfirmationJob periodically settles on-chain refunds once their
+// transactions have enough confirmations.
+type RefundConfirmationJob struct {
+	service  *usecase.PaymentService
+	interval time.Duration
+}
+
+func NewRefundConfirmationJob(service *usecase.PaymentService, interval time.Duration) *RefundConfirmationJob {
+	return &RefundConfirmationJob{
+		service:  service,
+		interval: interval,
+	}
+}
+
+// Run blocks until ctx is cancelled.
+func (j *RefundConfirmationJob) Run(ctx context.Context) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			settled, err := j.service.ConfirmRefunds(ctx)
+			if err != nil {
+				log.Printf("failed to confirm refunds: %v", err)
+				continue
+			}
+			if settled > 0 {
+				log.Printf("settled %d on-chain refunds", settled)
+			}
+		}
+	}
+}
@@ -0,0 +1,291 @@
+// Package mapper converts between domain types and their protobuf
+// representations. It is the only place that knows both, so unknown enum
+// values are rejected here instead of leaking into the domain or onto the
+// wire.
+package mapper
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/hsibAD/payment-service/internal/domain"
+	pb "github.com/hsibAD/payment-service/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var statusToProto = map[domain.PaymentStatus]pb.PaymentStatus{
+	domain.PaymentStatusPending:           pb.PaymentStatus_PAYMENT_STATUS_PENDING,
+	domain.PaymentStatusProcessing:        pb.PaymentStatus_PAYMENT_STATUS_PROCESSING,
+	domain.PaymentStatusCompleted:         pb.PaymentStatus_PAYMENT_STATUS_COMPLETED,
+	domain.PaymentStatusFailed:            pb.PaymentStatus_PAYMENT_STATUS_FAILED,
+	domain.PaymentStatusCancelled:         pb.PaymentStatus_PAYMENT_STATUS_CANCELLED,
+	domain.PaymentStatusRefunded:          pb.PaymentStatus_PAYMENT_STATUS_REFUNDED,
+	domain.PaymentStatusPartiallyRefunded: pb.PaymentStatus_PAYMENT_STATUS_PARTIALLY_REFUNDED,
+	domain.PaymentStatusAuthorized:        pb.PaymentStatus_PAYMENT_STATUS_AUTHORIZED,
+	domain.PaymentStatusCaptured:          pb.PaymentStatus_PAYMENT_STATUS_CAPTURED,
+	domain.PaymentStatusVoided:            pb.PaymentStatus_PAYMENT_STATUS_VOIDED,
+	domain.PaymentStatusDisputed:          pb.PaymentStatus_PAYMENT_STATUS_DISPUTED,
+	domain.PaymentStatusChargedBack:       pb.PaymentStatus_PAYMENT_STATUS_CHARGED_BACK,
+	domain.PaymentStatusRequiresAction:    pb.PaymentStatus_PAYMENT_STATUS_REQUIRES_ACTION,
+	domain.PaymentStatusManualReview:      pb.PaymentStatus_PAYMENT_STATUS_MANUAL_REVIEW,
+}
+
+var methodToProto = map[domain.PaymentMethod]pb.PaymentMethod{
+	domain.PaymentMethodCreditCard: pb.PaymentMethod_PAYMENT_METHOD_CREDIT_CARD,
+	domain.PaymentMethodMetaMask:   pb.PaymentMethod_PAYMENT_METHOD_METAMASK,
+}
+
+var (
+	statusFromProto = invert(statusToProto)
+	methodFromProto = invert(methodToProto)
+)
+
+func invert[K, V comparable](m map[K]V) map[V]K {
+	inverted := make(map[V]K, len(m))
+	for k, v := range m {
+		inverted[v] = k
+	}
+	return inverted
+}
+
+func PaymentStatusToProto(s domain.PaymentStatus) (pb.PaymentStatus, error) {
+	if v, ok := statusToProto[s]; ok {
+		return v, nil
+	}
+	return pb.PaymentStatus_PAYMENT_STATUS_UNSPECIFIED, fmt.Errorf("%w: %q", domain.ErrInvalidStatus, s)
+}
+
+func PaymentStatusFromProto(s pb.PaymentStatus) (domain.PaymentStatus, error) {
+	if v, ok := statusFromProto[s]; ok {
+		return v, nil
+	}
+	return "", fmt.Errorf("%w: %s", domain.ErrInvalidStatus, s)
+}
+
+func PaymentMethodToProto(m domain.PaymentMethod) (pb.PaymentMethod, error) {
+	if v, ok := methodToProto[m]; ok {
+		return v, nil
+	}
+	return pb.PaymentMethod_PAYMENT_METHOD_UNSPECIFIED, fmt.Errorf("%w: %q", domain.ErrInvalidPaymentMethod, m)
+}
+
+func PaymentMethodFromProto(m pb.PaymentMethod) (domain.PaymentMethod, error) {
+	if v, ok := methodFromProto[m]; ok {
+		return v, nil
+	}
+	return "", fmt.Errorf("%w: %s", domain.ErrInvalidPaymentMethod, m)
+}
+
+func MoneyToProto(m domain.Money) *pb.Money {
+	return &pb.Money{
+		MinorUnits: m.MinorUnits,
+		Currency:   m.Currency,
+		Exponent:   int32(m.Exponent()),
+	}
+}
+
+func MoneyFromProto(m *pb.Money) (domain.Money, error) {
+	if m == nil {
+		return domain.Money{}, domain.ErrInvalidAmount
+	}
+	return domain.NewMoney(m.GetMinorUnits(), m.GetCurrency())
+}
+
+func PaymentToProto(payment *domain.Payment) (*pb.Payment, error) {
+	status, err := PaymentStatusToProto(payment.Status)
+	if err != nil {
+		return nil, err
+	}
+
+	method, err := PaymentMethodToProto(payment.PaymentMethod)
+	if err != nil {
+		return nil, err
+	}
+
+	history, err := StatusHistoryToProto(payment.History)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.Payment{
+		Id:               payment.ID,
+		OrderId:          payment.OrderID,
+		UserId:           payment.UserID,
+		Amount:           payment.Amount.Float64(),
+		Currency:         payment.Amount.Currency,
+		Money:            MoneyToProto(payment.Amount),
+		Status:           status,
+		PaymentMethod:    method,
+		TransactionId:    payment.TransactionID,
+		ErrorMessage:     payment.ErrorMessage,
+		CreatedAt:        timestamppb.New(payment.CreatedAt),
+		UpdatedAt:        timestamppb.New(payment.UpdatedAt),
+		History:          history,
+		AuthorizedAmount: MoneyToProto(payment.AuthorizedAmount),
+		CapturedAmount:   MoneyToProto(payment.CapturedAmount),
+		Card:             CardSummaryToProto(payment.Card),
+		RefundedAmount:   MoneyToProto(payment.RefundedAmount),
+		Quote:            PriceQuoteToProto(payment.Quote),
+		ChainId:          payment.ChainID,
+		WalletAddress:    payment.WalletAddress,
+		NetworkFee:       NetworkFeeToProto(payment.NetworkFee),
+		NextAction:       NextActionToProto(payment.NextAction),
+		CardProvider:     payment.CardProvider,
+	}, nil
+}
+
+var nextActionTypeToProto = map[domain.NextActionType]pb.NextActionType{
+	domain.NextActionRedirect: pb.NextActionType_NEXT_ACTION_TYPE_REDIRECT_TO_URL,
+	domain.NextActionUseSDK:   pb.NextActionType_NEXT_ACTION_TYPE_USE_SDK,
+}
+
+func NextActionToProto(next *domain.NextAction) *pb.NextAction {
+	if next == nil {
+		return nil
+	}
+	return &pb.NextAction{
+		Type:         nextActionTypeToProto[next.Type],
+		RedirectUrl:  next.RedirectURL,
+		ClientSecret: next.ClientSecret,
+	}
+}
+
+func CardSummaryToProto(card *domain.CardSummary) *pb.CardSummary {
+	if card == nil {
+		return nil
+	}
+	return &pb.CardSummary{
+		Brand:   string(card.Brand),
+		Last4:   card.Last4,
+		Funding: string(card.Funding),
+		Country: card.Country,
+	}
+}
+
+func PriceQuoteToProto(quote *domain.PriceQuote) *pb.PriceQuote {
+	if quote == nil {
+		return nil
+	}
+	return &pb.PriceQuote{
+		Currency:    quote.Currency,
+		Rate:        quote.Rate,
+		Amount:      MoneyToProto(quote.Amount),
+		SlippageBps: int32(quote.SlippageBps),
+		ExpiresAt:   timestamppb.New(quote.ExpiresAt),
+		CreatedAt:   timestamppb.New(quote.CreatedAt),
+	}
+}
+
+func TransactionRequestToProto(tx *domain.TransactionRequest) *pb.TransactionRequest {
+	if tx == nil {
+		return nil
+	}
+
+	value, ok := new(big.Int).SetString(tx.Value, 10)
+	if !ok {
+		value = new(big.Int)
+	}
+	result := &pb.TransactionRequest{
+		From:    tx.From,
+		To:      tx.To,
+		Value:   hexutil.EncodeBig(value),
+		Data:    tx.Data,
+		ChainId: hexutil.EncodeUint64(tx.ChainID),
+	}
+	if tx.Gas != 0 {
+		result.Gas = hexutil.EncodeUint64(tx.Gas)
+	}
+	if fee, ok := new(big.Int).SetString(tx.MaxPriorityFeePerGas, 10); ok {
+		result.MaxPriorityFeePerGas = hexutil.EncodeBig(fee)
+	}
+	if fee, ok := new(big.Int).SetString(tx.MaxFeePerGas, 10); ok {
+		result.MaxFeePerGas = hexutil.EncodeBig(fee)
+	}
+	return result
+}
+
+func FeeEstimateToProto(fee *domain.FeeEstimate) *pb.FeeEstimate {
+	if fee == nil {
+		return nil
+	}
+	result := &pb.FeeEstimate{
+		GasLimit:             fee.GasLimit,
+		BaseFeePerGas:        fee.BaseFeePerGas,
+		MaxPriorityFeePerGas: fee.MaxPriorityFeePerGas,
+		MaxFeePerGas:         fee.MaxFeePerGas,
+	}
+	if fee.GasLimit != 0 {
+		result.MaxCost = MoneyToProto(fee.MaxCost)
+	}
+	return result
+}
+
+func NetworkFeeToProto(fee *domain.NetworkFee) *pb.NetworkFee {
+	if fee == nil {
+		return nil
+	}
+	return &pb.NetworkFee{
+		ChainId:           fee.ChainID,
+		GasUsed:           fee.GasUsed,
+		EffectiveGasPrice: fee.EffectiveGasPrice,
+		Amount:            MoneyToProto(fee.Amount),
+	}
+}
+
+func NetworkFeeTotalsToProto(totals []*domain.NetworkFeeTotal) []*pb.NetworkFeeTotal {
+	result := make([]*pb.NetworkFeeTotal, len(totals))
+	for i, total := range totals {
+		result[i] = &pb.NetworkFeeTotal{
+			ChainId:      total.ChainID,
+			Total:        MoneyToProto(total.Total),
+			GasUsed:      total.GasUsed,
+			PaymentCount: total.Payments,
+		}
+	}
+	return result
+}
+
+func TokenToProto(token *domain.TokenInfo) *pb.Token {
+	if token == nil {
+		return nil
+	}
+	return &pb.Token{
+		Symbol:   token.Symbol,
+		Address:  token.Address,
+		Decimals: int32(token.Decimals),
+	}
+}
+
+func PaymentsToProto(payments []*domain.Payment) ([]*pb.Payment, error) {
+	result := make([]*pb.Payment, len(payments))
+	for i, payment := range payments {
+		p, err := PaymentToProto(payment)
+		if err != nil {
+			return nil, err
+		}
+		result[i] = p
+	}
+	return result, nil
+}
+
+func StatusHistoryToProto(history []domain.StatusChange) ([]*pb.StatusChange, error) {
+	result := make([]*pb.StatusChange, len(history))
+	for i, change := range history {
+		from, err := PaymentStatusToProto(change.From)
+		if err != nil {
+			return nil, err
+		}
+		to, err := PaymentStatusToProto(change.To)
+		if err != nil {
+			return nil, err
+		}
+		result[i] = &pb.StatusChange{
+			From:   from,
+			To:     to,
+			Reason: change.Reason,
+			At:     timestamppb.New(change.At),
+		}
+	}
+	return result, nil
+}
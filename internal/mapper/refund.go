@@ -0,0 +1,53 @@
+package mapper
+
+import (
+	"fmt"
+
+	"github.com/hsibAD/payment-service/internal/domain"
+	pb "github.com/hsibAD/payment-service/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var refundStatusToProto = map[domain.RefundStatus]pb.RefundStatus{
+	domain.RefundStatusPending:   pb.RefundStatus_REFUND_STATUS_PENDING,
+	domain.RefundStatusSucceeded: pb.RefundStatus_REFUND_STATUS_SUCCEEDED,
+	domain.RefundStatusFailed:    pb.RefundStatus_REFUND_STATUS_FAILED,
+}
+
+func RefundStatusToProto(s domain.RefundStatus) (pb.RefundStatus, error) {
+	if v, ok := refundStatusToProto[s]; ok {
+		return v, nil
+	}
+	return pb.RefundStatus_REFUND_STATUS_UNSPECIFIED, fmt.Errorf("%w: %q", domain.ErrInvalidRefundStatus, s)
+}
+
+func RefundToProto(refund *domain.Refund) (*pb.Refund, error) {
+	status, err := RefundStatusToProto(refund.Status)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.Refund{
+		Id:                 refund.ID,
+		PaymentId:          refund.PaymentID,
+		Amount:             MoneyToProto(refund.Amount),
+		Reason:             refund.Reason,
+		Status:             status,
+		ProcessorReference: refund.ProcessorRef,
+		ErrorMessage:       refund.ErrorMessage,
+		CreatedAt:          timestamppb.New(refund.CreatedAt),
+		UpdatedAt:          timestamppb.New(refund.UpdatedAt),
+	}, nil
+}
+
+func RefundsToProto(refunds []*domain.Refund) ([]*pb.Refund, error) {
+	result := make([]*pb.Refund, len(refunds))
+	for i, refund := range refunds {
+		r, err := RefundToProto(refund)
+		if err != nil {
+			return nil, err
+		}
+		result[i] = r
+	}
+	return result, nil
+}
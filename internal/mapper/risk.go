@@ -0,0 +1,34 @@
+package mapper
+
+import (
+	"github.com/hsibAD/payment-service/internal/domain"
+	pb "github.com/hsibAD/payment-service/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var riskDecisionToProto = map[domain.RiskDecision]pb.RiskDecision{
+	domain.RiskDecisionAllow:  pb.RiskDecision_RISK_DECISION_ALLOW,
+	domain.RiskDecisionReview: pb.RiskDecision_RISK_DECISION_REVIEW,
+	domain.RiskDecisionDeny:   pb.RiskDecision_RISK_DECISION_DENY,
+}
+
+func RiskDecisionRecordToProto(record *domain.RiskDecisionRecord) *pb.RiskDecisionRecord {
+	return &pb.RiskDecisionRecord{
+		Id:        record.ID,
+		PaymentId: record.PaymentID,
+		Decision:  riskDecisionToProto[record.Decision],
+		Score:     int32(record.Score),
+		Rules:     record.Rules,
+		Reviewer:  record.Reviewer,
+		Note:      record.Note,
+		CreatedAt: timestamppb.New(record.CreatedAt),
+	}
+}
+
+func RiskDecisionRecordsToProto(records []*domain.RiskDecisionRecord) []*pb.RiskDecisionRecord {
+	result := make([]*pb.RiskDecisionRecord, len(records))
+	for i, record := range records {
+		result[i] = RiskDecisionRecordToProto(record)
+	}
+	return result
+}
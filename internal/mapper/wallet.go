@@ -0,0 +1,36 @@
+package mapper
+
+import (
+	"fmt"
+
+	"github.com/hsibAD/payment-service/internal/domain"
+	pb "github.com/hsibAD/payment-service/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var signatureSchemeToProto = map[domain.SignatureScheme]pb.SignatureScheme{
+	domain.SignatureSchemeEIP712: pb.SignatureScheme_SIGNATURE_SCHEME_EIP712,
+	domain.SignatureSchemeEIP191: pb.SignatureScheme_SIGNATURE_SCHEME_EIP191,
+}
+
+var signatureSchemeFromProto = invert(signatureSchemeToProto)
+
+// SignatureSchemeFromProto defaults an unset scheme to EIP-712.
+func SignatureSchemeFromProto(s pb.SignatureScheme) (domain.SignatureScheme, error) {
+	if s == pb.SignatureScheme_SIGNATURE_SCHEME_UNSPECIFIED {
+		return domain.SignatureSchemeEIP712, nil
+	}
+	if v, ok := signatureSchemeFromProto[s]; ok {
+		return v, nil
+	}
+	return "", fmt.Errorf("%w: %s", domain.ErrInvalidSignatureType, s)
+}
+
+func WalletChallengeToProto(challenge *domain.WalletChallenge, payload string) *pb.WalletChallenge {
+	return &pb.WalletChallenge{
+		Nonce:     challenge.Nonce,
+		Scheme:    signatureSchemeToProto[challenge.Scheme],
+		Payload:   payload,
+		ExpiresAt: timestamppb.New(challenge.ExpiresAt),
+	}
+}
@@ -0,0 +1,74 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/hsibAD/payment-service/internal/domain"
+)
+
+type ChainWatcherRepository struct {
+	mu          sync.Mutex
+	checkpoints map[string]domain.ChainCheckpoint
+	matches     map[string]map[string]domain.ChainMatch
+}
+
+func NewChainWatcherRepository() *ChainWatcherRepository {
+	return &ChainWatcherRepository{
+		checkpoints: make(map[string]domain.ChainCheckpoint),
+		matches:     make(map[string]map[string]domain.ChainMatch),
+	}
+}
+
+func (r *ChainWatcherRepository) GetCheckpoint(ctx context.Context, watcher string) (*domain.ChainCheckpoint, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	checkpoint, ok := r.checkpoints[watcher]
+	if !ok {
+		return nil, nil
+	}
+	return &checkpoint, nil
+}
+
+func (r *ChainWatcherRepository) SaveCheckpoint(ctx context.Context, watcher string, checkpoint *domain.ChainCheckpoint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.checkpoints[watcher] = *checkpoint
+	return nil
+}
+
+func (r *ChainWatcherRepository) SaveMatch(ctx context.Context, watcher string, match *domain.ChainMatch) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.matches[watcher] == nil {
+		r.matches[watcher] = make(map[string]domain.ChainMatch)
+	}
+	r.matches[watcher][match.TransactionHash] = *match
+	return nil
+}
+
+// GetMatches returns the watcher's matches, oldest block first.
+func (r *ChainWatcherRepository) GetMatches(ctx context.Context, watcher string) ([]*domain.ChainMatch, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	matches := make([]*domain.ChainMatch, 0, len(r.matches[watcher]))
+	for _, match := range r.matches[watcher] {
+		match := match
+		matches = append(matches, &match)
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i].BlockNumber < matches[j].BlockNumber })
+	return matches, nil
+}
+
+func (r *ChainWatcherRepository) DeleteMatch(ctx context.Context, watcher string, transactionHash string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.matches[watcher], transactionHash)
+	return nil
+}
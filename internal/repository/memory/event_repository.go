@@ -0,0 +1,56 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/hsibAD/payment-service/internal/domain"
+)
+
+type processedEvent struct {
+	eventType    domain.CardEventType
+	applied      bool
+	claimedUntil time.Time
+}
+
+type ProcessedEventRepository struct {
+	mu     sync.Mutex
+	events map[string]*processedEvent
+}
+
+func NewProcessedEventRepository() *ProcessedEventRepository {
+	return &ProcessedEventRepository{events: make(map[string]*processedEvent)}
+}
+
+func (r *ProcessedEventRepository) Claim(ctx context.Context, eventID string, eventType domain.CardEventType, lease time.Duration) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	if event, ok := r.events[eventID]; ok && (event.applied || now.Before(event.claimedUntil)) {
+		return false, nil
+	}
+	r.events[eventID] = &processedEvent{eventType: eventType, claimedUntil: now.Add(lease)}
+	return true, nil
+}
+
+func (r *ProcessedEventRepository) Complete(ctx context.Context, eventID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if event, ok := r.events[eventID]; ok {
+		event.applied = true
+	}
+	return nil
+}
+
+func (r *ProcessedEventRepository) Release(ctx context.Context, eventID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if event, ok := r.events[eventID]; ok && !event.applied {
+		delete(r.events, eventID)
+	}
+	return nil
+}
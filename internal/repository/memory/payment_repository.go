@@ -0,0 +1,275 @@
+// Package memory keeps payments, refunds, processed events and chain watcher
+// progress in memory for tests. It behaves like the mongodb repositories:
+// stored payments are copies, saves are guarded by the payment's version and
+// a crypto transaction pays for at most one payment.
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hsibAD/payment-service/internal/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type PaymentRepository struct {
+	mu       sync.Mutex
+	payments map[string]*domain.Payment
+}
+
+func NewPaymentRepository() *PaymentRepository {
+	return &PaymentRepository{payments: make(map[string]*domain.Payment)}
+}
+
+func (r *PaymentRepository) Create(ctx context.Context, payment *domain.Payment) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	payment.ID = primitive.NewObjectID().Hex()
+	r.payments[payment.ID] = clonePayment(payment)
+	return nil
+}
+
+func (r *PaymentRepository) GetByID(ctx context.Context, id string) (*domain.Payment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	payment, ok := r.payments[id]
+	if !ok {
+		return nil, domain.ErrInvalidPaymentID
+	}
+	return clonePayment(payment), nil
+}
+
+func (r *PaymentRepository) GetByOrderID(ctx context.Context, orderID string) ([]*domain.Payment, error) {
+	return r.find(func(p *domain.Payment) bool { return p.OrderID == orderID }, byCreatedAt, 0), nil
+}
+
+func (r *PaymentRepository) GetByTransactionID(ctx context.Context, transactionID string) (*domain.Payment, error) {
+	payments := r.find(func(p *domain.Payment) bool { return p.TransactionID == transactionID }, byCreatedAt, 1)
+	if len(payments) == 0 {
+		return nil, domain.ErrInvalidPaymentID
+	}
+	return payments[0], nil
+}
+
+func (r *PaymentRepository) GetByUserID(ctx context.Context, userID string, page, limit int) ([]*domain.Payment, int, error) {
+	payments := r.find(func(p *domain.Payment) bool { return p.UserID == userID }, newestFirst, 0)
+	return paginate(payments, page, limit), len(payments), nil
+}
+
+func (r *PaymentRepository) GetPendingByUserID(ctx context.Context, userID string, page, limit int) ([]*domain.Payment, int, error) {
+	payments := r.find(func(p *domain.Payment) bool {
+		if userID != "" && p.UserID != userID {
+			return false
+		}
+		switch p.Status {
+		case domain.PaymentStatusPending,
+			domain.PaymentStatusProcessing,
+			domain.PaymentStatusRequiresAction,
+			domain.PaymentStatusManualReview:
+			return true
+		}
+		return false
+	}, byCreatedAt, 0)
+	return paginate(payments, page, limit), len(payments), nil
+}
+
+func (r *PaymentRepository) GetPendingByOrderHashes(ctx context.Context, method domain.PaymentMethod, orderHashes []string) ([]*domain.Payment, error) {
+	wanted := make(map[string]bool, len(orderHashes))
+	for _, orderHash := range orderHashes {
+		wanted[orderHash] = true
+	}
+	return r.find(func(p *domain.Payment) bool {
+		return p.PaymentMethod == method &&
+			(p.Status == domain.PaymentStatusPending || p.Status == domain.PaymentStatusProcessing) &&
+			wanted[domain.OrderHash(p.OrderID)]
+	}, byCreatedAt, 0), nil
+}
+
+func (r *PaymentRepository) GetAuthorizedBefore(ctx context.Context, before time.Time, limit int) ([]*domain.Payment, error) {
+	payments := r.find(func(p *domain.Payment) bool {
+		return (p.Status == domain.PaymentStatusAuthorized || p.Status == domain.PaymentStatusManualReview) &&
+			p.AuthorizedAt.Before(before)
+	}, func(a, b *domain.Payment) bool { return a.AuthorizedAt.Before(b.AuthorizedAt) }, limit)
+	return payments, nil
+}
+
+func (r *PaymentRepository) HasPaid(ctx context.Context, userID string) (bool, error) {
+	payments := r.find(func(p *domain.Payment) bool {
+		if p.UserID != userID {
+			return false
+		}
+		switch p.Status {
+		case domain.PaymentStatusCompleted,
+			domain.PaymentStatusCaptured,
+			domain.PaymentStatusPartiallyRefunded,
+			domain.PaymentStatusRefunded,
+			domain.PaymentStatusDisputed,
+			domain.PaymentStatusChargedBack:
+			return true
+		}
+		return false
+	}, byCreatedAt, 1)
+	return len(payments) > 0, nil
+}
+
+func (r *PaymentRepository) Update(ctx context.Context, payment *domain.Payment) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, ok := r.payments[payment.ID]
+	if !ok {
+		return domain.ErrInvalidPaymentID
+	}
+	if current.Version != payment.Version {
+		return domain.ErrPaymentConflict
+	}
+
+	if payment.PaymentMethod == domain.PaymentMethodMetaMask && payment.TransactionID != "" {
+		for id, other := range r.payments {
+			if id != payment.ID && other.PaymentMethod == domain.PaymentMethodMetaMask &&
+				other.TransactionID == payment.TransactionID {
+				return domain.ErrTransactionAlreadyUsed
+			}
+		}
+	}
+
+	payment.Version++
+	r.payments[payment.ID] = clonePayment(payment)
+	return nil
+}
+
+func (r *PaymentRepository) UpdateStatus(ctx context.Context, paymentID string, from, to domain.PaymentStatus) error {
+	if !domain.CanTransition(from, to) {
+		return domain.ErrInvalidTransition{From: from, To: to}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	payment, ok := r.payments[paymentID]
+	if !ok {
+		return domain.ErrInvalidPaymentID
+	}
+	if payment.Status != from {
+		return domain.ErrInvalidTransition{From: payment.Status, To: to}
+	}
+
+	now := time.Now()
+	payment.Status = to
+	payment.UpdatedAt = now
+	payment.History = append(payment.History, domain.StatusChange{From: from, To: to, At: now})
+	payment.Version++
+	return nil
+}
+
+func (r *PaymentRepository) SumNetworkFees(ctx context.Context, from, to time.Time) ([]*domain.NetworkFeeTotal, error) {
+	type group struct {
+		chainID  uint64
+		currency string
+	}
+
+	totals := make(map[group]*domain.NetworkFeeTotal)
+	payments := r.find(func(p *domain.Payment) bool {
+		return p.NetworkFee != nil && !p.NetworkFee.RecordedAt.Before(from) && p.NetworkFee.RecordedAt.Before(to)
+	}, byCreatedAt, 0)
+	for _, payment := range payments {
+		fee := payment.NetworkFee
+		key := group{chainID: fee.ChainID, currency: fee.Amount.Currency}
+		total, ok := totals[key]
+		if !ok {
+			total = &domain.NetworkFeeTotal{ChainID: fee.ChainID, Total: domain.Money{Currency: fee.Amount.Currency}}
+			totals[key] = total
+		}
+		sum, err := total.Total.Add(fee.Amount)
+		if err != nil {
+			return nil, err
+		}
+		total.Total = sum
+		total.GasUsed += fee.GasUsed
+		total.Payments++
+	}
+
+	result := make([]*domain.NetworkFeeTotal, 0, len(totals))
+	for _, total := range totals {
+		result = append(result, total)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].ChainID != result[j].ChainID {
+			return result[i].ChainID < result[j].ChainID
+		}
+		return result[i].Total.Currency < result[j].Total.Currency
+	})
+	return result, nil
+}
+
+// find returns copies of up to limit payments that match, in the given
+// order. A zero limit returns all of them.
+func (r *PaymentRepository) find(match func(*domain.Payment) bool, less func(a, b *domain.Payment) bool, limit int) []*domain.Payment {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var payments []*domain.Payment
+	for _, payment := range r.payments {
+		if match(payment) {
+			payments = append(payments, clonePayment(payment))
+		}
+	}
+	sort.Slice(payments, func(i, j int) bool { return less(payments[i], payments[j]) })
+	if limit > 0 && len(payments) > limit {
+		payments = payments[:limit]
+	}
+	return payments
+}
+
+func byCreatedAt(a, b *domain.Payment) bool {
+	return a.CreatedAt.Before(b.CreatedAt)
+}
+
+func newestFirst(a, b *domain.Payment) bool {
+	return a.CreatedAt.After(b.CreatedAt)
+}
+
+func paginate(payments []*domain.Payment, page, limit int) []*domain.Payment {
+	skip := (page - 1) * limit
+	if skip < 0 || skip >= len(payments) {
+		return nil
+	}
+	payments = payments[skip:]
+	if len(payments) > limit {
+		payments = payments[:limit]
+	}
+	return payments
+}
+
+// clonePayment copies a payment deeply enough that neither the caller nor
+// the repository sees the other's later changes.
+func clonePayment(payment *domain.Payment) *domain.Payment {
+	clone := *payment
+	clone.History = append([]domain.StatusChange(nil), payment.History...)
+	if payment.NextAction != nil {
+		next := *payment.NextAction
+		clone.NextAction = &next
+	}
+	if payment.Card != nil {
+		card := *payment.Card
+		clone.Card = &card
+	}
+	if payment.NetworkFee != nil {
+		fee := *payment.NetworkFee
+		clone.NetworkFee = &fee
+	}
+	if payment.Quote != nil {
+		quote := *payment.Quote
+		clone.Quote = &quote
+	}
+	if payment.Risk != nil {
+		risk := *payment.Risk
+		risk.Rules = append([]string(nil), payment.Risk.Rules...)
+		clone.Risk = &risk
+	}
+	return &clone
+}
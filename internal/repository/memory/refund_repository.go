@@ -0,0 +1,80 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/hsibAD/payment-service/internal/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type RefundRepository struct {
+	mu      sync.Mutex
+	refunds map[string]domain.Refund
+}
+
+func NewRefundRepository() *RefundRepository {
+	return &RefundRepository{refunds: make(map[string]domain.Refund)}
+}
+
+func (r *RefundRepository) Create(ctx context.Context, refund *domain.Refund) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	refund.ID = primitive.NewObjectID().Hex()
+	r.refunds[refund.ID] = *refund
+	return nil
+}
+
+func (r *RefundRepository) GetByID(ctx context.Context, id string) (*domain.Refund, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	refund, ok := r.refunds[id]
+	if !ok {
+		return nil, domain.ErrInvalidRefundID
+	}
+	return &refund, nil
+}
+
+func (r *RefundRepository) GetByPaymentID(ctx context.Context, paymentID string) ([]*domain.Refund, error) {
+	return r.find(func(refund *domain.Refund) bool { return refund.PaymentID == paymentID }, 0), nil
+}
+
+func (r *RefundRepository) GetUnconfirmed(ctx context.Context, limit int) ([]*domain.Refund, error) {
+	return r.find(func(refund *domain.Refund) bool {
+		return refund.Status == domain.RefundStatusPending && refund.ProcessorRef != ""
+	}, limit), nil
+}
+
+func (r *RefundRepository) Update(ctx context.Context, refund *domain.Refund) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.refunds[refund.ID]; !ok {
+		return domain.ErrInvalidRefundID
+	}
+	r.refunds[refund.ID] = *refund
+	return nil
+}
+
+// find returns copies of up to limit matching refunds, oldest first. A zero
+// limit returns all of them.
+func (r *RefundRepository) find(match func(*domain.Refund) bool, limit int) []*domain.Refund {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var refunds []*domain.Refund
+	for _, refund := range r.refunds {
+		refund := refund
+		if match(&refund) {
+			refunds = append(refunds, &refund)
+		}
+	}
+	sort.Slice(refunds, func(i, j int) bool { return refunds[i].CreatedAt.Before(refunds[j].CreatedAt) })
+	if limit > 0 && len(refunds) > limit {
+		refunds = refunds[:limit]
+	}
+	return refunds
+}
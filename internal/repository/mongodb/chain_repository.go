@@ -0,0 +1,119 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/hsibAD/payment-service/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type ChainWatcherRepository struct {
+	db          *mongo.Database
+	checkpoints *mongo.Collection
+	matches     *mongo.Collection
+}
+
+type mongoCheckpoint struct {
+	Watcher     string    `bson:"_id"`
+	BlockNumber int64     `bson:"block_number"`
+	BlockHash   string    `bson:"block_hash"`
+	UpdatedAt   time.Time `bson:"updated_at"`
+}
+
+type mongoChainMatch struct {
+	Watcher         string    `bson:"watcher"`
+	TransactionHash string    `bson:"transaction_hash"`
+	BlockNumber     int64     `bson:"block_number"`
+	BlockHash       string    `bson:"block_hash"`
+	PaymentID       string    `bson:"payment_id"`
+	SeenAt          time.Time `bson:"seen_at"`
+}
+
+func NewChainWatcherRepository(db *mongo.Database) *ChainWatcherRepository {
+	return &ChainWatcherRepository{
+		db:          db,
+		checkpoints: db.Collection("chain_checkpoints"),
+		matches:     db.Collection("chain_matches"),
+	}
+}
+
+func (r *ChainWatcherRepository) GetCheckpoint(ctx context.Context, watcher string) (*domain.ChainCheckpoint, error) {
+	var mCheckpoint mongoCheckpoint
+	err := r.checkpoints.FindOne(ctx, bson.M{"_id": watcher}).Decode(&mCheckpoint)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &domain.ChainCheckpoint{
+		BlockNumber: uint64(mCheckpoint.BlockNumber),
+		BlockHash:   mCheckpoint.BlockHash,
+		UpdatedAt:   mCheckpoint.UpdatedAt,
+	}, nil
+}
+
+func (r *ChainWatcherRepository) SaveCheckpoint(ctx context.Context, watcher string, checkpoint *domain.ChainCheckpoint) error {
+	mCheckpoint := mongoCheckpoint{
+		Watcher:     watcher,
+		BlockNumber: int64(checkpoint.BlockNumber),
+		BlockHash:   checkpoint.BlockHash,
+		UpdatedAt:   checkpoint.UpdatedAt,
+	}
+
+	_, err := r.checkpoints.ReplaceOne(ctx, bson.M{"_id": watcher}, mCheckpoint, options.Replace().SetUpsert(true))
+	return err
+}
+
+func (r *ChainWatcherRepository) SaveMatch(ctx context.Context, watcher string, match *domain.ChainMatch) error {
+	mMatch := mongoChainMatch{
+		Watcher:         watcher,
+		TransactionHash: match.TransactionHash,
+		BlockNumber:     int64(match.BlockNumber),
+		BlockHash:       match.BlockHash,
+		PaymentID:       match.PaymentID,
+		SeenAt:          match.SeenAt,
+	}
+
+	filter := bson.M{"watcher": watcher, "transaction_hash": match.TransactionHash}
+	_, err := r.matches.ReplaceOne(ctx, filter, mMatch, options.Replace().SetUpsert(true))
+	return err
+}
+
+// GetMatches returns the watcher's matches, oldest block first.
+func (r *ChainWatcherRepository) GetMatches(ctx context.Context, watcher string) ([]*domain.ChainMatch, error) {
+	opts := options.Find().SetSort(bson.M{"block_number": 1})
+
+	cursor, err := r.matches.Find(ctx, bson.M{"watcher": watcher}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var mMatches []mongoChainMatch
+	if err = cursor.All(ctx, &mMatches); err != nil {
+		return nil, err
+	}
+
+	matches := make([]*domain.ChainMatch, len(mMatches))
+	for i, m := range mMatches {
+		matches[i] = &domain.ChainMatch{
+			TransactionHash: m.TransactionHash,
+			BlockNumber:     uint64(m.BlockNumber),
+			BlockHash:       m.BlockHash,
+			PaymentID:       m.PaymentID,
+			SeenAt:          m.SeenAt,
+		}
+	}
+	return matches, nil
+}
+
+func (r *ChainWatcherRepository) DeleteMatch(ctx context.Context, watcher string, transactionHash string) error {
+	_, err := r.matches.DeleteOne(ctx, bson.M{"watcher": watcher, "transaction_hash": transactionHash})
+	return err
+}
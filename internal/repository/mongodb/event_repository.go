@@ -0,0 +1,72 @@
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"github.com/hsibAD/payment-service/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ProcessedEventRepository deduplicates processor webhook events. The event
+// ID is the document ID, so two deliveries of an event racing each other
+// cannot both claim it.
+type ProcessedEventRepository struct {
+	db         *mongo.Database
+	collection *mongo.Collection
+}
+
+// mongoProcessedEvent is an applied event, or one claimed until
+// ClaimedUntil. Events recorded before claims expired have neither field
+// and count as applied.
+type mongoProcessedEvent struct {
+	ID           string    `bson:"_id"`
+	Type         string    `bson:"type"`
+	ReceivedAt   time.Time `bson:"received_at"`
+	Applied      bool      `bson:"applied"`
+	ClaimedUntil time.Time `bson:"claimed_until"`
+}
+
+func NewProcessedEventRepository(db *mongo.Database) *ProcessedEventRepository {
+	return &ProcessedEventRepository{
+		db:         db,
+		collection: db.Collection("processed_events"),
+	}
+}
+
+func (r *ProcessedEventRepository) Claim(ctx context.Context, eventID string, eventType domain.CardEventType, lease time.Duration) (bool, error) {
+	now := time.Now()
+	// A new event is inserted. An existing one is only matched while it is
+	// unapplied and its lease ran out; otherwise the upsert collides with
+	// it on the ID.
+	filter := bson.M{
+		"_id":           eventID,
+		"applied":       false,
+		"claimed_until": bson.M{"$lt": now},
+	}
+	update := bson.M{
+		"$set":         bson.M{"type": string(eventType), "claimed_until": now.Add(lease)},
+		"$setOnInsert": bson.M{"received_at": now},
+	}
+
+	_, err := r.collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *ProcessedEventRepository) Complete(ctx context.Context, eventID string) error {
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": eventID}, bson.M{"$set": bson.M{"applied": true}})
+	return err
+}
+
+func (r *ProcessedEventRepository) Release(ctx context.Context, eventID string) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": eventID, "applied": false})
+	return err
+}
@@ -1,198 +1,854 @@
-package mongodb
-
-import (
-	"context"
-	"errors"
-	"time"
-
-	"go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/mongo"
-	"github.com/hsibAD/payment-service/internal/domain"
-	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo/options"
-)
-
-type PaymentRepository struct {
-	db         *mongo.Database
-	collection *mongo.Collection
-}
-
-type mongoPayment struct {
-	ID            primitive.ObjectID `bson:"_id,omitempty"`
-	OrderID       string            `bson:"order_id"`
-	UserID        string            `bson:"user_id"`
-	Amount        float64           `bson:"amount"`
-	Currency      string            `bson:"currency"`
-	Status        string            `bson:"status"`
-	PaymentMethod string            `bson:"payment_method"`
-	TransactionID string            `bson:"transaction_id,omitempty"`
-	ErrorMessage  string            `bson:"error_message,omitempty"`
-	CreatedAt     time.Time         `bson:"created_at"`
-	UpdatedAt     time.Time         `bson:"updated_at"`
-}
-
-func NewPaymentRepository(db *mongo.Database) *PaymentRepository {
-	return &PaymentRepository{
-		db:         db,
-		collection: db.Collection("payments"),
-	}
-}
-
-func (r *PaymentRepository) Create(ctx context.Context, payment *domain.Payment) error {
-	mPayment := toMongoPayment(payment)
-	result, err := r.collection.InsertOne(ctx, mPayment)
-	if err != nil {
-		return err
-	}
-
-	payment.ID = result.InsertedID.(primitive.ObjectID).Hex()
-	return nil
-}
-
-func (r *PaymentRepository) GetByID(ctx context.Context, id string) (*domain.Payment, error) {
-	objectID, err := primitive.ObjectIDFromHex(id)
-	if err != nil {
-		return nil, domain.ErrInvalidPaymentID
-	}
-
-	var mPayment mongoPayment
-	err = r.collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&mPayment)
-	if err != nil {
-		if errors.Is(err, mongo.ErrNoDocuments) {
-			return nil, domain.ErrInvalidPaymentID
-		}
-		return nil, err
-	}
-
-	return fromMongoPayment(&mPayment), nil
-}
-
-func (r *PaymentRepository) GetByOrderID(ctx context.Context, orderID string) ([]*domain.Payment, error) {
-	cursor, err := r.collection.Find(ctx, bson.M{"order_id": orderID})
-	if err != nil {
-		return nil, err
-	}
-	defer cursor.Close(ctx)
-
-	var mPayments []mongoPayment
-	if err = cursor.All(ctx, &mPayments); err != nil {
-		return nil, err
-	}
-
-	payments := make([]*domain.Payment, len(mPayments))
-	for i, mPayment := range mPayments {
-		payments[i] = fromMongoPayment(&mPayment)
-	}
-
-	return payments, nil
-}
-
-func (r *PaymentRepository) GetByUserID(ctx context.Context, userID string, page, limit int) ([]*domain.Payment, int, error) {
-	skip := (page - 1) * limit
-
-	opts := options.Find().
-		SetSkip(int64(skip)).
-		SetLimit(int64(limit)).
-		SetSort(bson.M{"created_at": -1})
-
-	cursor, err := r.collection.Find(ctx, bson.M{"user_id": userID}, opts)
-	if err != nil {
-		return nil, 0, err
-	}
-	defer cursor.Close(ctx)
-
-	var mPayments []mongoPayment
-	if err = cursor.All(ctx, &mPayments); err != nil {
-		return nil, 0, err
-	}
-
-	payments := make([]*domain.Payment, len(mPayments))
-	for i, mPayment := range mPayments {
-		payments[i] = fromMongoPayment(&mPayment)
-	}
-
-	// Get total count
-	total, err := r.collection.CountDocuments(ctx, bson.M{"user_id": userID})
-	if err != nil {
-		return nil, 0, err
-	}
-
-	return payments, int(total), nil
-}
-
-func (r *PaymentRepository) Update(ctx context.Context, payment *domain.Payment) error {
-	objectID, err := primitive.ObjectIDFromHex(payment.ID)
-	if err != nil {
-		return domain.ErrInvalidPaymentID
-	}
-
-	mPayment := toMongoPayment(payment)
-	mPayment.ID = objectID
-
-	result, err := r.collection.ReplaceOne(ctx, bson.M{"_id": objectID}, mPayment)
-	if err != nil {
-		return err
-	}
-
-	if result.MatchedCount == 0 {
-		return domain.ErrInvalidPaymentID
-	}
-
-	return nil
-}
-
-func (r *PaymentRepository) UpdateStatus(ctx context.Context, paymentID string, status domain.PaymentStatus) error {
-	objectID, err := primitive.ObjectIDFromHex(paymentID)
-	if err != nil {
-		return domain.ErrInvalidPaymentID
-	}
-
-	update := bson.M{
-		"$set": bson.M{
-			"status":     string(status),
-			"updated_at": time.Now(),
-		},
-	}
-
-	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": objectID}, update)
-	if err != nil {
-		return err
-	}
-
-	if result.MatchedCount == 0 {
-		return domain.ErrInvalidPaymentID
-	}
-
-	return nil
-}
-
-func toMongoPayment(payment *domain.Payment) *mongoPayment {
-	return &mongoPayment{
-		OrderID:       payment.OrderID,
-		UserID:        payment.UserID,
-		Amount:        payment.Amount,
-		Currency:      payment.Currency,
-		Status:        string(payment.Status),
-		PaymentMethod: string(payment.PaymentMethod),
-		TransactionID: payment.TransactionID,
-		ErrorMessage:  payment.ErrorMessage,
-		CreatedAt:     payment.CreatedAt,
-		UpdatedAt:     payment.UpdatedAt,
-	}
-}
-
-func fromMongoPayment(mPayment *mongoPayment) *domain.Payment {
-	return &domain.Payment{
-		ID:            mPayment.ID.Hex(),
-		OrderID:       mPayment.OrderID,
-		UserID:        mPayment.UserID,
-		Amount:        mPayment.Amount,
-		Currency:      mPayment.Currency,
-		Status:        domain.PaymentStatus(mPayment.Status),
-		PaymentMethod: domain.PaymentMethod(mPayment.PaymentMethod),
-		TransactionID: mPayment.TransactionID,
-		ErrorMessage:  mPayment.ErrorMessage,
-		CreatedAt:     mPayment.CreatedAt,
-		UpdatedAt:     mPayment.UpdatedAt,
-	}
-} 
\ No newline at end of file
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/hsibAD/payment-service/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type PaymentRepository struct {
+	db         *mongo.Database
+	collection *mongo.Collection
+}
+
+type mongoPayment struct {
+	ID            primitive.ObjectID  `bson:"_id,omitempty"`
+	OrderID       string              `bson:"order_id"`
+	OrderHash     string              `bson:"order_hash,omitempty"`
+	UserID        string              `bson:"user_id"`
+	AmountMinor   int64               `bson:"amount_minor"`
+	Currency      string              `bson:"currency"`
+	Status        string              `bson:"status"`
+	PaymentMethod string              `bson:"payment_method"`
+	TransactionID string              `bson:"transaction_id,omitempty"`
+	ErrorMessage  string              `bson:"error_message,omitempty"`
+	CustomerEmail string              `bson:"customer_email,omitempty"`
+	History       []mongoStatusChange `bson:"status_history,omitempty"`
+	CreatedAt     time.Time           `bson:"created_at"`
+	UpdatedAt     time.Time           `bson:"updated_at"`
+
+	AuthorizedAmountMinor int64     `bson:"authorized_amount_minor,omitempty"`
+	CapturedAmountMinor   int64     `bson:"captured_amount_minor,omitempty"`
+	AuthorizedAt          time.Time `bson:"authorized_at,omitempty"`
+	RefundedAmountMinor   int64     `bson:"refunded_amount_minor,omitempty"`
+
+	Card       *mongoCard       `bson:"card,omitempty"`
+	Quote      *mongoQuote      `bson:"quote,omitempty"`
+	NextAction *mongoNextAction `bson:"next_action,omitempty"`
+
+	CardProvider string `bson:"card_provider,omitempty"`
+
+	Risk              *mongoRisk `bson:"risk,omitempty"`
+	CaptureOnApproval bool       `bson:"capture_on_approval,omitempty"`
+
+	ChainID       int64            `bson:"chain_id,omitempty"`
+	WalletAddress string           `bson:"wallet_address,omitempty"`
+	NetworkFee    *mongoNetworkFee `bson:"network_fee,omitempty"`
+
+	// Version is missing on documents written before payments were
+	// versioned, which reads as zero.
+	Version int64 `bson:"version"`
+
+	// LegacyAmount is only present on documents written before amounts
+	// were stored in minor units; see MigrateLegacyAmounts.
+	LegacyAmount float64 `bson:"amount,omitempty"`
+}
+
+// mongoCard is the masked card. Card numbers and CVVs are never stored.
+type mongoCard struct {
+	Brand   string `bson:"brand"`
+	Last4   string `bson:"last4"`
+	Funding string `bson:"funding,omitempty"`
+	Country string `bson:"country,omitempty"`
+}
+
+type mongoRisk struct {
+	Score      int       `bson:"score"`
+	Decision   string    `bson:"decision"`
+	Rules      []string  `bson:"rules,omitempty"`
+	AssessedAt time.Time `bson:"assessed_at"`
+}
+
+type mongoNextAction struct {
+	Type         string `bson:"type"`
+	RedirectURL  string `bson:"redirect_url,omitempty"`
+	ClientSecret string `bson:"client_secret,omitempty"`
+}
+
+type mongoQuote struct {
+	Currency    string    `bson:"currency"`
+	Rate        string    `bson:"rate"`
+	AmountMinor int64     `bson:"amount_minor"`
+	SlippageBps int       `bson:"slippage_bps"`
+	ExpiresAt   time.Time `bson:"expires_at"`
+	CreatedAt   time.Time `bson:"created_at"`
+}
+
+type mongoNetworkFee struct {
+	ChainID           int64     `bson:"chain_id"`
+	GasUsed           int64     `bson:"gas_used"`
+	EffectiveGasPrice string    `bson:"effective_gas_price"`
+	AmountMinor       int64     `bson:"amount_minor"`
+	Currency          string    `bson:"currency"`
+	RecordedAt        time.Time `bson:"recorded_at"`
+}
+
+type mongoStatusChange struct {
+	From   string    `bson:"from"`
+	To     string    `bson:"to"`
+	Reason string    `bson:"reason,omitempty"`
+	At     time.Time `bson:"at"`
+}
+
+func NewPaymentRepository(db *mongo.Database) *PaymentRepository {
+	return &PaymentRepository{
+		db:         db,
+		collection: db.Collection("payments"),
+	}
+}
+
+func (r *PaymentRepository) Create(ctx context.Context, payment *domain.Payment) error {
+	mPayment := toMongoPayment(payment)
+	result, err := r.collection.InsertOne(ctx, mPayment)
+	if err != nil {
+		return err
+	}
+
+	payment.ID = result.InsertedID.(primitive.ObjectID).Hex()
+	return nil
+}
+
+func (r *PaymentRepository) GetByID(ctx context.Context, id string) (*domain.Payment, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, domain.ErrInvalidPaymentID
+	}
+
+	var mPayment mongoPayment
+	err = r.collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&mPayment)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, domain.ErrInvalidPaymentID
+		}
+		return nil, err
+	}
+
+	return fromMongoPayment(&mPayment)
+}
+
+func (r *PaymentRepository) GetByOrderID(ctx context.Context, orderID string) ([]*domain.Payment, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{"order_id": orderID})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var mPayments []mongoPayment
+	if err = cursor.All(ctx, &mPayments); err != nil {
+		return nil, err
+	}
+
+	return fromMongoPayments(mPayments)
+}
+
+// GetByTransactionID returns the payment a processor transaction was
+// recorded on.
+func (r *PaymentRepository) GetByTransactionID(ctx context.Context, transactionID string) (*domain.Payment, error) {
+	var mPayment mongoPayment
+	err := r.collection.FindOne(ctx, bson.M{"transaction_id": transactionID}).Decode(&mPayment)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, domain.ErrInvalidPaymentID
+		}
+		return nil, err
+	}
+
+	return fromMongoPayment(&mPayment)
+}
+
+func (r *PaymentRepository) GetByUserID(ctx context.Context, userID string, page, limit int) ([]*domain.Payment, int, error) {
+	skip := (page - 1) * limit
+
+	opts := options.Find().
+		SetSkip(int64(skip)).
+		SetLimit(int64(limit)).
+		SetSort(bson.M{"created_at": -1})
+
+	cursor, err := r.collection.Find(ctx, bson.M{"user_id": userID}, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var mPayments []mongoPayment
+	if err = cursor.All(ctx, &mPayments); err != nil {
+		return nil, 0, err
+	}
+
+	payments, err := fromMongoPayments(mPayments)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Get total count
+	total, err := r.collection.CountDocuments(ctx, bson.M{"user_id": userID})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return payments, int(total), nil
+}
+
+func (r *PaymentRepository) GetPendingByUserID(ctx context.Context, userID string, page, limit int) ([]*domain.Payment, int, error) {
+	skip := (page - 1) * limit
+
+	filter := bson.M{
+		"status": bson.M{"$in": []string{
+			string(domain.PaymentStatusPending),
+			string(domain.PaymentStatusProcessing),
+			string(domain.PaymentStatusRequiresAction),
+			string(domain.PaymentStatusManualReview),
+		}},
+	}
+	if userID != "" {
+		filter["user_id"] = userID
+	}
+
+	opts := options.Find().
+		SetSkip(int64(skip)).
+		SetLimit(int64(limit)).
+		SetSort(bson.M{"created_at": 1})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var mPayments []mongoPayment
+	if err = cursor.All(ctx, &mPayments); err != nil {
+		return nil, 0, err
+	}
+
+	payments, err := fromMongoPayments(mPayments)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return payments, int(total), nil
+}
+
+// GetPendingByOrderHashes returns the pending or processing payments made
+// with method whose order has one of orderHashes, oldest first.
+func (r *PaymentRepository) GetPendingByOrderHashes(ctx context.Context, method domain.PaymentMethod, orderHashes []string) ([]*domain.Payment, error) {
+	filter := bson.M{
+		"order_hash":     bson.M{"$in": orderHashes},
+		"payment_method": string(method),
+		"status": bson.M{"$in": []string{
+			string(domain.PaymentStatusPending),
+			string(domain.PaymentStatusProcessing),
+		}},
+	}
+
+	opts := options.Find().SetSort(bson.M{"created_at": 1})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var mPayments []mongoPayment
+	if err = cursor.All(ctx, &mPayments); err != nil {
+		return nil, err
+	}
+
+	return fromMongoPayments(mPayments)
+}
+
+func (r *PaymentRepository) Update(ctx context.Context, payment *domain.Payment) error {
+	objectID, err := primitive.ObjectIDFromHex(payment.ID)
+	if err != nil {
+		return domain.ErrInvalidPaymentID
+	}
+
+	mPayment := toMongoPayment(payment)
+	mPayment.ID = objectID
+	mPayment.Version = payment.Version + 1
+
+	filter := bson.M{"_id": objectID, "version": versionFilter(payment.Version)}
+	result, err := r.collection.ReplaceOne(ctx, filter, mPayment)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return domain.ErrTransactionAlreadyUsed
+		}
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		// Gone, or changed since it was loaded.
+		if _, err := r.GetByID(ctx, payment.ID); err != nil {
+			return err
+		}
+		return domain.ErrPaymentConflict
+	}
+
+	payment.Version = mPayment.Version
+	return nil
+}
+
+// versionFilter matches documents at version, treating unversioned
+// documents as version zero.
+func versionFilter(version int64) interface{} {
+	if version == 0 {
+		return bson.M{"$in": bson.A{0, nil}}
+	}
+	return version
+}
+
+// GetAuthorizedBefore returns payments whose card hold was placed before the
+// given time and has been neither captured nor voided, oldest first. Holds
+// of payments still waiting for manual review are included.
+func (r *PaymentRepository) GetAuthorizedBefore(ctx context.Context, before time.Time, limit int) ([]*domain.Payment, error) {
+	filter := bson.M{
+		"status": bson.M{"$in": []string{
+			string(domain.PaymentStatusAuthorized),
+			string(domain.PaymentStatusManualReview),
+		}},
+		"authorized_at": bson.M{"$lt": before},
+	}
+
+	opts := options.Find().
+		SetLimit(int64(limit)).
+		SetSort(bson.M{"authorized_at": 1})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var mPayments []mongoPayment
+	if err = cursor.All(ctx, &mPayments); err != nil {
+		return nil, err
+	}
+
+	return fromMongoPayments(mPayments)
+}
+
+func (r *PaymentRepository) HasPaid(ctx context.Context, userID string) (bool, error) {
+	count, err := r.collection.CountDocuments(ctx, bson.M{
+		"user_id": userID,
+		"status": bson.M{"$in": []string{
+			string(domain.PaymentStatusCompleted),
+			string(domain.PaymentStatusCaptured),
+			string(domain.PaymentStatusPartiallyRefunded),
+			string(domain.PaymentStatusRefunded),
+			string(domain.PaymentStatusDisputed),
+			string(domain.PaymentStatusChargedBack),
+		}},
+	}, options.Count().SetLimit(1))
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// UpdateStatus atomically moves a payment from one status to another. The
+// transition must be allowed by the domain transition table and the stored
+// status must still be from, so concurrent writers cannot skip states.
+func (r *PaymentRepository) UpdateStatus(ctx context.Context, paymentID string, from, to domain.PaymentStatus) error {
+	if !domain.CanTransition(from, to) {
+		return domain.ErrInvalidTransition{From: from, To: to}
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(paymentID)
+	if err != nil {
+		return domain.ErrInvalidPaymentID
+	}
+
+	now := time.Now()
+	update := bson.M{
+		"$set": bson.M{
+			"status":     string(to),
+			"updated_at": now,
+		},
+		"$inc": bson.M{"version": 1},
+		"$push": bson.M{
+			"status_history": mongoStatusChange{
+				From: string(from),
+				To:   string(to),
+				At:   now,
+			},
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": objectID, "status": string(from)}, update)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		current, err := r.GetByID(ctx, paymentID)
+		if err != nil {
+			return err
+		}
+		return domain.ErrInvalidTransition{From: current.Status, To: to}
+	}
+
+	return nil
+}
+
+// SumNetworkFees groups the network fees recorded in [from, to) by chain
+// and currency.
+func (r *PaymentRepository) SumNetworkFees(ctx context.Context, from, to time.Time) ([]*domain.NetworkFeeTotal, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"network_fee.recorded_at": bson.M{"$gte": from, "$lt": to},
+		}}},
+		{{Key: "$group", Value: bson.M{
+			"_id": bson.M{
+				"chain_id": "$network_fee.chain_id",
+				"currency": "$network_fee.currency",
+			},
+			"total":    bson.M{"$sum": "$network_fee.amount_minor"},
+			"gas_used": bson.M{"$sum": "$network_fee.gas_used"},
+			"payments": bson.M{"$sum": 1},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "_id.chain_id", Value: 1}, {Key: "_id.currency", Value: 1}}}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var rows []struct {
+		ID struct {
+			ChainID  int64  `bson:"chain_id"`
+			Currency string `bson:"currency"`
+		} `bson:"_id"`
+		Total    int64 `bson:"total"`
+		GasUsed  int64 `bson:"gas_used"`
+		Payments int64 `bson:"payments"`
+	}
+	if err := cursor.All(ctx, &rows); err != nil {
+		return nil, err
+	}
+
+	totals := make([]*domain.NetworkFeeTotal, len(rows))
+	for i, row := range rows {
+		totals[i] = &domain.NetworkFeeTotal{
+			ChainID:  uint64(row.ID.ChainID),
+			Total:    domain.Money{MinorUnits: row.Total, Currency: row.ID.Currency},
+			GasUsed:  uint64(row.GasUsed),
+			Payments: row.Payments,
+		}
+	}
+	return totals, nil
+}
+
+// EnsureIndexes creates the indexes the repository relies on. A crypto
+// transaction can pay for at most one payment, which the unique index on
+// transaction_id enforces even when two confirmations race. The chain
+// watchers look crypto payments up by order_hash.
+func (r *PaymentRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "transaction_id", Value: 1}},
+			Options: options.Index().
+				SetName("unique_crypto_transaction").
+				SetUnique(true).
+				SetPartialFilterExpression(bson.M{
+					"payment_method": string(domain.PaymentMethodMetaMask),
+					"transaction_id": bson.M{"$exists": true},
+				}),
+		},
+		{
+			Keys: bson.D{{Key: "order_hash", Value: 1}, {Key: "status", Value: 1}},
+			Options: options.Index().
+				SetName("crypto_order_hash").
+				SetPartialFilterExpression(bson.M{
+					"payment_method": string(domain.PaymentMethodMetaMask),
+				}),
+		},
+	})
+	return err
+}
+
+// MigrateOrderHashes stores the order hash on pending crypto payments written
+// before payments carried one, so the chain watchers find them. It is safe to
+// run on every start.
+func (r *PaymentRepository) MigrateOrderHashes(ctx context.Context) (int, error) {
+	filter := bson.M{
+		"payment_method": string(domain.PaymentMethodMetaMask),
+		"order_hash":     bson.M{"$exists": false},
+		"status": bson.M{"$in": []string{
+			string(domain.PaymentStatusPending),
+			string(domain.PaymentStatusProcessing),
+		}},
+	}
+
+	cursor, err := r.collection.Find(ctx, filter, options.Find().SetProjection(bson.M{"order_id": 1}))
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	migrated := 0
+	for cursor.Next(ctx) {
+		var mPayment mongoPayment
+		if err := cursor.Decode(&mPayment); err != nil {
+			return migrated, err
+		}
+
+		update := bson.M{"$set": bson.M{"order_hash": domain.OrderHash(mPayment.OrderID)}}
+		if _, err := r.collection.UpdateOne(ctx, bson.M{"_id": mPayment.ID}, update); err != nil {
+			return migrated, err
+		}
+		migrated++
+	}
+
+	return migrated, cursor.Err()
+}
+
+// MigrateLegacyAmounts rewrites documents that still store the amount as a
+// double into integer minor units. It is safe to run on every start.
+func (r *PaymentRepository) MigrateLegacyAmounts(ctx context.Context) (int, error) {
+	filter := bson.M{
+		"amount":       bson.M{"$type": "double"},
+		"amount_minor": bson.M{"$exists": false},
+	}
+
+	cursor, err := r.collection.Find(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	migrated := 0
+	for cursor.Next(ctx) {
+		var mPayment mongoPayment
+		if err := cursor.Decode(&mPayment); err != nil {
+			return migrated, err
+		}
+
+		amount := mPayment.money()
+		update := bson.M{
+			"$set": bson.M{
+				"amount_minor": amount.MinorUnits,
+				"currency":     amount.Currency,
+			},
+			"$unset": bson.M{"amount": ""},
+			"$inc":   bson.M{"version": 1},
+		}
+		if _, err := r.collection.UpdateOne(ctx, bson.M{"_id": mPayment.ID}, update); err != nil {
+			return migrated, err
+		}
+		migrated++
+	}
+
+	return migrated, cursor.Err()
+}
+
+// money returns the document's amount, converting legacy double amounts to
+// minor units.
+func (m *mongoPayment) money() domain.Money {
+	currency := strings.ToUpper(m.Currency)
+	if m.AmountMinor != 0 || m.LegacyAmount == 0 {
+		return domain.Money{MinorUnits: m.AmountMinor, Currency: currency}
+	}
+
+	if amount, err := domain.MoneyFromFloat(m.LegacyAmount, currency); err == nil {
+		return amount
+	}
+
+	// The stored double carries more decimals than the currency allows or
+	// the currency is unknown; round to the nearest minor unit.
+	exp, err := domain.CurrencyExponent(currency)
+	if err != nil {
+		exp = 2
+	}
+	units := math.Round(m.LegacyAmount * math.Pow10(exp))
+	return domain.Money{MinorUnits: int64(units), Currency: currency}
+}
+
+func toMongoPayment(payment *domain.Payment) *mongoPayment {
+	return &mongoPayment{
+		OrderID:       payment.OrderID,
+		OrderHash:     domain.OrderHash(payment.OrderID),
+		UserID:        payment.UserID,
+		AmountMinor:   payment.Amount.MinorUnits,
+		Currency:      payment.Amount.Currency,
+		Status:        string(payment.Status),
+		PaymentMethod: string(payment.PaymentMethod),
+		TransactionID: payment.TransactionID,
+		ErrorMessage:  payment.ErrorMessage,
+		CustomerEmail: payment.CustomerEmail,
+		History:       toMongoHistory(payment.History),
+		CreatedAt:     payment.CreatedAt,
+		UpdatedAt:     payment.UpdatedAt,
+
+		AuthorizedAmountMinor: payment.AuthorizedAmount.MinorUnits,
+		CapturedAmountMinor:   payment.CapturedAmount.MinorUnits,
+		AuthorizedAt:          payment.AuthorizedAt,
+		RefundedAmountMinor:   payment.RefundedAmount.MinorUnits,
+		Card:                  toMongoCard(payment.Card),
+		Quote:                 toMongoQuote(payment.Quote),
+		NextAction:            toMongoNextAction(payment.NextAction),
+		CardProvider:          payment.CardProvider,
+		Risk:                  toMongoRisk(payment.Risk),
+		CaptureOnApproval:     payment.CaptureOnApproval,
+		ChainID:               int64(payment.ChainID),
+		WalletAddress:         payment.WalletAddress,
+		NetworkFee:            toMongoNetworkFee(payment.NetworkFee),
+		Version:               payment.Version,
+	}
+}
+
+// fromMongoPayment converts a stored document back into a payment, rejecting
+// documents whose status or payment method the domain does not know.
+func fromMongoPayment(mPayment *mongoPayment) (*domain.Payment, error) {
+	status, err := domain.ParsePaymentStatus(mPayment.Status)
+	if err != nil {
+		return nil, err
+	}
+
+	method, err := domain.ParsePaymentMethod(mPayment.PaymentMethod)
+	if err != nil {
+		return nil, err
+	}
+
+	history, err := fromMongoHistory(mPayment.History)
+	if err != nil {
+		return nil, err
+	}
+
+	amount := mPayment.money()
+
+	captured := mPayment.CapturedAmountMinor
+	if captured == 0 && status == domain.PaymentStatusCompleted {
+		// Completed before captured amounts were recorded; immediate
+		// charges always captured the full amount.
+		captured = amount.MinorUnits
+	}
+
+	return &domain.Payment{
+		ID:            mPayment.ID.Hex(),
+		OrderID:       mPayment.OrderID,
+		UserID:        mPayment.UserID,
+		Amount:        amount,
+		Status:        status,
+		PaymentMethod: method,
+		TransactionID: mPayment.TransactionID,
+		ErrorMessage:  mPayment.ErrorMessage,
+		CustomerEmail: mPayment.CustomerEmail,
+		History:       history,
+		CreatedAt:     mPayment.CreatedAt,
+		UpdatedAt:     mPayment.UpdatedAt,
+
+		AuthorizedAmount:  domain.Money{MinorUnits: mPayment.AuthorizedAmountMinor, Currency: amount.Currency},
+		CapturedAmount:    domain.Money{MinorUnits: captured, Currency: amount.Currency},
+		AuthorizedAt:      mPayment.AuthorizedAt,
+		RefundedAmount:    domain.Money{MinorUnits: mPayment.RefundedAmountMinor, Currency: amount.Currency},
+		Card:              fromMongoCard(mPayment.Card),
+		Quote:             fromMongoQuote(mPayment.Quote),
+		NextAction:        fromMongoNextAction(mPayment.NextAction),
+		CardProvider:      mPayment.CardProvider,
+		Risk:              fromMongoRisk(mPayment.Risk),
+		CaptureOnApproval: mPayment.CaptureOnApproval,
+		ChainID:           uint64(mPayment.ChainID),
+		WalletAddress:     mPayment.WalletAddress,
+		NetworkFee:        fromMongoNetworkFee(mPayment.NetworkFee),
+		Version:           mPayment.Version,
+	}, nil
+}
+
+func fromMongoPayments(mPayments []mongoPayment) ([]*domain.Payment, error) {
+	payments := make([]*domain.Payment, len(mPayments))
+	for i := range mPayments {
+		payment, err := fromMongoPayment(&mPayments[i])
+		if err != nil {
+			return nil, err
+		}
+		payments[i] = payment
+	}
+	return payments, nil
+}
+
+func toMongoCard(card *domain.CardSummary) *mongoCard {
+	if card == nil {
+		return nil
+	}
+	return &mongoCard{
+		Brand:   string(card.Brand),
+		Last4:   card.Last4,
+		Funding: string(card.Funding),
+		Country: card.Country,
+	}
+}
+
+func fromMongoCard(card *mongoCard) *domain.CardSummary {
+	if card == nil {
+		return nil
+	}
+	funding := domain.CardFunding(card.Funding)
+	if funding == "" {
+		funding = domain.CardFundingUnknown
+	}
+	return &domain.CardSummary{
+		Brand:   domain.CardBrand(card.Brand),
+		Last4:   card.Last4,
+		Funding: funding,
+		Country: card.Country,
+	}
+}
+
+func toMongoRisk(risk *domain.RiskAssessment) *mongoRisk {
+	if risk == nil {
+		return nil
+	}
+	return &mongoRisk{
+		Score:      risk.Score,
+		Decision:   string(risk.Decision),
+		Rules:      risk.Rules,
+		AssessedAt: risk.AssessedAt,
+	}
+}
+
+func fromMongoRisk(risk *mongoRisk) *domain.RiskAssessment {
+	if risk == nil {
+		return nil
+	}
+	return &domain.RiskAssessment{
+		Score:      risk.Score,
+		Decision:   domain.RiskDecision(risk.Decision),
+		Rules:      risk.Rules,
+		AssessedAt: risk.AssessedAt,
+	}
+}
+
+func toMongoHistory(history []domain.StatusChange) []mongoStatusChange {
+	result := make([]mongoStatusChange, len(history))
+	for i, change := range history {
+		result[i] = mongoStatusChange{
+			From:   string(change.From),
+			To:     string(change.To),
+			Reason: change.Reason,
+			At:     change.At,
+		}
+	}
+	return result
+}
+
+func fromMongoHistory(history []mongoStatusChange) ([]domain.StatusChange, error) {
+	result := make([]domain.StatusChange, len(history))
+	for i, change := range history {
+		from, err := domain.ParsePaymentStatus(change.From)
+		if err != nil {
+			return nil, err
+		}
+		to, err := domain.ParsePaymentStatus(change.To)
+		if err != nil {
+			return nil, err
+		}
+		result[i] = domain.StatusChange{
+			From:   from,
+			To:     to,
+			Reason: change.Reason,
+			At:     change.At,
+		}
+	}
+	return result, nil
+}
+
+func toMongoQuote(quote *domain.PriceQuote) *mongoQuote {
+	if quote == nil {
+		return nil
+	}
+	return &mongoQuote{
+		Currency:    quote.Currency,
+		Rate:        quote.Rate,
+		AmountMinor: quote.Amount.MinorUnits,
+		SlippageBps: quote.SlippageBps,
+		ExpiresAt:   quote.ExpiresAt,
+		CreatedAt:   quote.CreatedAt,
+	}
+}
+
+func fromMongoQuote(quote *mongoQuote) *domain.PriceQuote {
+	if quote == nil {
+		return nil
+	}
+	return &domain.PriceQuote{
+		Currency:    quote.Currency,
+		Rate:        quote.Rate,
+		Amount:      domain.Money{MinorUnits: quote.AmountMinor, Currency: quote.Currency},
+		SlippageBps: quote.SlippageBps,
+		ExpiresAt:   quote.ExpiresAt,
+		CreatedAt:   quote.CreatedAt,
+	}
+}
+
+func toMongoNetworkFee(fee *domain.NetworkFee) *mongoNetworkFee {
+	if fee == nil {
+		return nil
+	}
+	return &mongoNetworkFee{
+		ChainID:           int64(fee.ChainID),
+		GasUsed:           int64(fee.GasUsed),
+		EffectiveGasPrice: fee.EffectiveGasPrice,
+		AmountMinor:       fee.Amount.MinorUnits,
+		Currency:          fee.Amount.Currency,
+		RecordedAt:        fee.RecordedAt,
+	}
+}
+
+func fromMongoNetworkFee(fee *mongoNetworkFee) *domain.NetworkFee {
+	if fee == nil {
+		return nil
+	}
+	return &domain.NetworkFee{
+		ChainID:           uint64(fee.ChainID),
+		GasUsed:           uint64(fee.GasUsed),
+		EffectiveGasPrice: fee.EffectiveGasPrice,
+		Amount:            domain.Money{MinorUnits: fee.AmountMinor, Currency: fee.Currency},
+		RecordedAt:        fee.RecordedAt,
+	}
+}
+
+func toMongoNextAction(next *domain.NextAction) *mongoNextAction {
+	if next == nil {
+		return nil
+	}
+	return &mongoNextAction{
+		Type:         string(next.Type),
+		RedirectURL:  next.RedirectURL,
+		ClientSecret: next.ClientSecret,
+	}
+}
+
+func fromMongoNextAction(next *mongoNextAction) *domain.NextAction {
+	if next == nil {
+		return nil
+	}
+	return &domain.NextAction{
+		Type:         domain.NextActionType(next.Type),
+		RedirectURL:  next.RedirectURL,
+		ClientSecret: next.ClientSecret,
+	}
+}
@@ -0,0 +1,175 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/hsibAD/payment-service/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type RefundRepository struct {
+	db         *mongo.Database
+	collection *mongo.Collection
+}
+
+type mongoRefund struct {
+	ID           primitive.ObjectID `bson:"_id,omitempty"`
+	PaymentID    string             `bson:"payment_id"`
+	AmountMinor  int64              `bson:"amount_minor"`
+	Currency     string             `bson:"currency"`
+	Reason       string             `bson:"reason,omitempty"`
+	Status       string             `bson:"status"`
+	ProcessorRef string             `bson:"processor_ref,omitempty"`
+	ErrorMessage string             `bson:"error_message,omitempty"`
+	CreatedAt    time.Time          `bson:"created_at"`
+	UpdatedAt    time.Time          `bson:"updated_at"`
+}
+
+func NewRefundRepository(db *mongo.Database) *RefundRepository {
+	return &RefundRepository{
+		db:         db,
+		collection: db.Collection("refunds"),
+	}
+}
+
+func (r *RefundRepository) Create(ctx context.Context, refund *domain.Refund) error {
+	result, err := r.collection.InsertOne(ctx, toMongoRefund(refund))
+	if err != nil {
+		return err
+	}
+
+	refund.ID = result.InsertedID.(primitive.ObjectID).Hex()
+	return nil
+}
+
+func (r *RefundRepository) GetByID(ctx context.Context, id string) (*domain.Refund, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, domain.ErrInvalidRefundID
+	}
+
+	var mRefund mongoRefund
+	err = r.collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&mRefund)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, domain.ErrInvalidRefundID
+		}
+		return nil, err
+	}
+
+	return fromMongoRefund(&mRefund)
+}
+
+// GetByPaymentID returns every refund of a payment, oldest first.
+func (r *RefundRepository) GetByPaymentID(ctx context.Context, paymentID string) ([]*domain.Refund, error) {
+	opts := options.Find().SetSort(bson.M{"created_at": 1})
+
+	cursor, err := r.collection.Find(ctx, bson.M{"payment_id": paymentID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var mRefunds []mongoRefund
+	if err = cursor.All(ctx, &mRefunds); err != nil {
+		return nil, err
+	}
+
+	return fromMongoRefunds(mRefunds)
+}
+
+func (r *RefundRepository) GetUnconfirmed(ctx context.Context, limit int) ([]*domain.Refund, error) {
+	filter := bson.M{
+		"status":        string(domain.RefundStatusPending),
+		"processor_ref": bson.M{"$exists": true, "$ne": ""},
+	}
+
+	opts := options.Find().
+		SetLimit(int64(limit)).
+		SetSort(bson.M{"created_at": 1})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var mRefunds []mongoRefund
+	if err = cursor.All(ctx, &mRefunds); err != nil {
+		return nil, err
+	}
+
+	return fromMongoRefunds(mRefunds)
+}
+
+func (r *RefundRepository) Update(ctx context.Context, refund *domain.Refund) error {
+	objectID, err := primitive.ObjectIDFromHex(refund.ID)
+	if err != nil {
+		return domain.ErrInvalidRefundID
+	}
+
+	mRefund := toMongoRefund(refund)
+	mRefund.ID = objectID
+
+	result, err := r.collection.ReplaceOne(ctx, bson.M{"_id": objectID}, mRefund)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return domain.ErrInvalidRefundID
+	}
+
+	return nil
+}
+
+func toMongoRefund(refund *domain.Refund) *mongoRefund {
+	return &mongoRefund{
+		PaymentID:    refund.PaymentID,
+		AmountMinor:  refund.Amount.MinorUnits,
+		Currency:     refund.Amount.Currency,
+		Reason:       refund.Reason,
+		Status:       string(refund.Status),
+		ProcessorRef: refund.ProcessorRef,
+		ErrorMessage: refund.ErrorMessage,
+		CreatedAt:    refund.CreatedAt,
+		UpdatedAt:    refund.UpdatedAt,
+	}
+}
+
+func fromMongoRefund(mRefund *mongoRefund) (*domain.Refund, error) {
+	status, err := domain.ParseRefundStatus(mRefund.Status)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.Refund{
+		ID:           mRefund.ID.Hex(),
+		PaymentID:    mRefund.PaymentID,
+		Amount:       domain.Money{MinorUnits: mRefund.AmountMinor, Currency: strings.ToUpper(mRefund.Currency)},
+		Reason:       mRefund.Reason,
+		Status:       status,
+		ProcessorRef: mRefund.ProcessorRef,
+		ErrorMessage: mRefund.ErrorMessage,
+		CreatedAt:    mRefund.CreatedAt,
+		UpdatedAt:    mRefund.UpdatedAt,
+	}, nil
+}
+
+func fromMongoRefunds(mRefunds []mongoRefund) ([]*domain.Refund, error) {
+	refunds := make([]*domain.Refund, len(mRefunds))
+	for i := range mRefunds {
+		refund, err := fromMongoRefund(&mRefunds[i])
+		if err != nil {
+			return nil, err
+		}
+		refunds[i] = refund
+	}
+	return refunds, nil
+}
@@ -0,0 +1,98 @@
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"github.com/hsibAD/payment-service/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// RiskDecisionRepository is the audit trail of risk decisions. Records are
+// only ever inserted.
+type RiskDecisionRepository struct {
+	db         *mongo.Database
+	collection *mongo.Collection
+}
+
+type mongoRiskDecision struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	PaymentID string             `bson:"payment_id"`
+	UserID    string             `bson:"user_id"`
+	Decision  string             `bson:"decision"`
+	Score     int                `bson:"score"`
+	Rules     []string           `bson:"rules,omitempty"`
+	Reviewer  string             `bson:"reviewer,omitempty"`
+	Note      string             `bson:"note,omitempty"`
+	CreatedAt time.Time          `bson:"created_at"`
+}
+
+func NewRiskDecisionRepository(db *mongo.Database) *RiskDecisionRepository {
+	return &RiskDecisionRepository{
+		db:         db,
+		collection: db.Collection("risk_decisions"),
+	}
+}
+
+func (r *RiskDecisionRepository) Save(ctx context.Context, record *domain.RiskDecisionRecord) error {
+	result, err := r.collection.InsertOne(ctx, mongoRiskDecision{
+		PaymentID: record.PaymentID,
+		UserID:    record.UserID,
+		Decision:  string(record.Decision),
+		Score:     record.Score,
+		Rules:     record.Rules,
+		Reviewer:  record.Reviewer,
+		Note:      record.Note,
+		CreatedAt: record.CreatedAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	record.ID = result.InsertedID.(primitive.ObjectID).Hex()
+	return nil
+}
+
+// GetByPaymentID returns the decisions on a payment, oldest first.
+func (r *RiskDecisionRepository) GetByPaymentID(ctx context.Context, paymentID string) ([]*domain.RiskDecisionRecord, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}})
+
+	cursor, err := r.collection.Find(ctx, bson.M{"payment_id": paymentID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var mRecords []mongoRiskDecision
+	if err = cursor.All(ctx, &mRecords); err != nil {
+		return nil, err
+	}
+
+	records := make([]*domain.RiskDecisionRecord, len(mRecords))
+	for i, m := range mRecords {
+		records[i] = &domain.RiskDecisionRecord{
+			ID:        m.ID.Hex(),
+			PaymentID: m.PaymentID,
+			UserID:    m.UserID,
+			Decision:  domain.RiskDecision(m.Decision),
+			Score:     m.Score,
+			Rules:     m.Rules,
+			Reviewer:  m.Reviewer,
+			Note:      m.Note,
+			CreatedAt: m.CreatedAt,
+		}
+	}
+	return records, nil
+}
+
+// EnsureIndexes indexes decisions by payment.
+func (r *RiskDecisionRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "payment_id", Value: 1}, {Key: "created_at", Value: 1}},
+		Options: options.Index().SetName("payment_decisions"),
+	})
+	return err
+}
@@ -0,0 +1,95 @@
+package mongodb
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/hsibAD/payment-service/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type WalletRepository struct {
+	db         *mongo.Database
+	collection *mongo.Collection
+}
+
+type mongoWallet struct {
+	UserID     string    `bson:"user_id"`
+	Address    string    `bson:"address"`
+	PaymentID  string    `bson:"payment_id"`
+	Scheme     string    `bson:"scheme"`
+	VerifiedAt time.Time `bson:"verified_at"`
+}
+
+func NewWalletRepository(db *mongo.Database) *WalletRepository {
+	return &WalletRepository{
+		db:         db,
+		collection: db.Collection("wallets"),
+	}
+}
+
+// Addresses are stored lower case so lookups do not depend on the checksum
+// casing the client used.
+func (r *WalletRepository) Save(ctx context.Context, wallet *domain.VerifiedWallet) error {
+	mWallet := mongoWallet{
+		UserID:     wallet.UserID,
+		Address:    strings.ToLower(wallet.Address),
+		PaymentID:  wallet.PaymentID,
+		Scheme:     string(wallet.Scheme),
+		VerifiedAt: wallet.VerifiedAt,
+	}
+
+	filter := bson.M{"user_id": mWallet.UserID, "address": mWallet.Address}
+	_, err := r.collection.ReplaceOne(ctx, filter, mWallet, options.Replace().SetUpsert(true))
+	return err
+}
+
+func (r *WalletRepository) IsVerified(ctx context.Context, userID, address string) (bool, error) {
+	count, err := r.collection.CountDocuments(ctx, bson.M{
+		"user_id": userID,
+		"address": strings.ToLower(address),
+	}, options.Count().SetLimit(1))
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *WalletRepository) GetByUserID(ctx context.Context, userID string) ([]*domain.VerifiedWallet, error) {
+	opts := options.Find().SetSort(bson.M{"verified_at": -1})
+
+	cursor, err := r.collection.Find(ctx, bson.M{"user_id": userID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var mWallets []mongoWallet
+	if err = cursor.All(ctx, &mWallets); err != nil {
+		return nil, err
+	}
+
+	wallets := make([]*domain.VerifiedWallet, len(mWallets))
+	for i, m := range mWallets {
+		wallets[i] = &domain.VerifiedWallet{
+			UserID:     m.UserID,
+			Address:    m.Address,
+			PaymentID:  m.PaymentID,
+			Scheme:     domain.SignatureScheme(m.Scheme),
+			VerifiedAt: m.VerifiedAt,
+		}
+	}
+	return wallets, nil
+}
+
+// EnsureIndexes makes a wallet linkable to a user only once.
+func (r *WalletRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "address", Value: 1}},
+		Options: options.Index().SetName("unique_user_wallet").SetUnique(true),
+	})
+	return err
+}
@@ -1,36 +1,83 @@
-package server
-
-import (
-	"fmt"
-	"net"
-
-	"github.com/hsibAD/payment-service/internal/config"
-	"github.com/hsibAD/payment-service/internal/handler"
-	"google.golang.org/grpc"
-)
-
-type Server struct {
-	cfg    *config.Config
-	server *grpc.Server
-}
-
-func NewServer(cfg *config.Config) (*Server, error) {
-	server := grpc.NewServer()
-	
-	// Register services
-	handler.RegisterServices(server, cfg)
-
-	return &Server{
-		cfg:    cfg,
-		server: server,
-	}, nil
-}
-
-func (s *Server) Run() error {
-	lis, err := net.Listen("tcp", fmt.Sprintf(":%s", s.cfg.Port))
-	if err != nil {
-		return fmt.Errorf("failed to listen: %v", err)
-	}
-
-	return s.server.Serve(lis)
-} 
\ No newline at end of file
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/hsibAD/payment-service/internal/config"
+	"github.com/hsibAD/payment-service/internal/domain"
+	"github.com/hsibAD/payment-service/internal/handler"
+	"google.golang.org/grpc"
+)
+
+// stripeWebhookPath is where Stripe delivers webhook events.
+const stripeWebhookPath = "/webhooks/stripe"
+
+type Server struct {
+	cfg    *config.Config
+	server *grpc.Server
+	http   *http.Server
+}
+
+// NewServer creates the gRPC server and, when webhooks is not nil, the
+// HTTP server that receives processor webhooks. Calls are authenticated
+// with verifier unless it is nil.
+func NewServer(cfg *config.Config, paymentHandler *handler.PaymentHandler, webhooks *handler.StripeWebhookHandler, verifier domain.TokenVerifier) (*Server, error) {
+	unary := []grpc.UnaryServerInterceptor{handler.ClientInfoInterceptor(cfg.TrustForwardedFor)}
+	var stream []grpc.StreamServerInterceptor
+	if verifier != nil {
+		unary = append(unary, handler.AuthInterceptor(verifier))
+		stream = append(stream, handler.StreamAuthInterceptor(verifier))
+	}
+
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(unary...),
+		grpc.ChainStreamInterceptor(stream...),
+	)
+
+	// Register services
+	handler.RegisterServices(server, paymentHandler)
+
+	s := &Server{
+		cfg:    cfg,
+		server: server,
+	}
+
+	if webhooks != nil {
+		mux := http.NewServeMux()
+		mux.Handle(stripeWebhookPath, webhooks)
+		s.http = &http.Server{
+			Addr:              fmt.Sprintf(":%s", cfg.WebhookPort),
+			Handler:           mux,
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+	}
+
+	return s, nil
+}
+
+// Run serves until either server fails.
+func (s *Server) Run() error {
+	lis, err := net.Listen("tcp", fmt.Sprintf(":%s", s.cfg.Port))
+	if err != nil {
+		return fmt.Errorf("failed to listen: %v", err)
+	}
+
+	errs := make(chan error, 2)
+	go func() {
+		errs <- s.server.Serve(lis)
+	}()
+
+	if s.http != nil {
+		go func() {
+			if err := s.http.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+				errs <- fmt.Errorf("webhook server: %w", err)
+			}
+		}()
+	}
+
+	return <-errs
+}
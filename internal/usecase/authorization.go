@@ -0,0 +1,122 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/hsibAD/payment-service/internal/domain"
+)
+
+const expireBatchSize = 100
+
+// AuthorizeCreditCardPayment places a hold on the card for the payment amount.
+// The final amount is collected later with CapturePayment.
+func (s *PaymentService) AuthorizeCreditCardPayment(ctx context.Context, paymentID string, cardToken string) (*domain.Payment, error) {
+	return s.chargeCard(ctx, paymentID, cardToken, false)
+}
+
+// authorized records the hold the processor placed on the card. Payments
+// the risk engine flagged are held for review instead.
+func (s *PaymentService) authorized(ctx context.Context, payment *domain.Payment) (*domain.Payment, error) {
+	if payment.FlaggedForReview() {
+		return s.holdForReview(ctx, payment)
+	}
+	if err := payment.MarkAsAuthorized(payment.TransactionID); err != nil {
+		return nil, err
+	}
+	if err := s.save(ctx, payment); err != nil {
+		return nil, err
+	}
+
+	s.publish(ctx, "status updated", s.publisher.PublishPaymentStatusUpdated, payment)
+	return payment, nil
+}
+
+// CapturePayment collects amount from an authorized payment. A nil amount
+// captures the full authorization; a smaller amount releases the rest of the
+// hold, e.g. after items were substituted or dropped from an order.
+func (s *PaymentService) CapturePayment(ctx context.Context, paymentID string, amount *domain.Money) (*domain.Payment, error) {
+	payment, err := s.userPayment(ctx, paymentID)
+	if err != nil {
+		return nil, err
+	}
+
+	captureAmount := payment.AuthorizedAmount
+	if amount != nil {
+		captureAmount = *amount
+	}
+
+	if err := payment.ValidateCapture(captureAmount); err != nil {
+		return nil, err
+	}
+
+	if err := s.cardProc.CapturePayment(ctx, payment, captureAmount); err != nil {
+		return nil, fmt.Errorf("failed to capture payment: %w", err)
+	}
+
+	if err := payment.Capture(captureAmount); err != nil {
+		return nil, err
+	}
+	if err := s.save(ctx, payment); err != nil {
+		return nil, err
+	}
+
+	s.publish(ctx, "completed", s.publisher.PublishPaymentCompleted, payment)
+	s.notify(ctx, "confirmation", s.notifier.SendPaymentConfirmation, payment)
+
+	return payment, nil
+}
+
+// VoidPayment releases the hold of an authorized payment without collecting
+// anything.
+func (s *PaymentService) VoidPayment(ctx context.Context, paymentID, reason string) (*domain.Payment, error) {
+	payment, err := s.userPayment(ctx, paymentID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.void(ctx, payment, reason)
+}
+
+// ExpireAuthorizations voids holds placed more than maxAge ago that were never
+// captured. Card networks drop such holds after about a week anyway; voiding
+// them first releases the customer's funds and keeps our records in step.
+func (s *PaymentService) ExpireAuthorizations(ctx context.Context, maxAge time.Duration) (int, error) {
+	payments, err := s.repo.GetAuthorizedBefore(ctx, time.Now().Add(-maxAge), expireBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	expired := 0
+	for _, payment := range payments {
+		if _, err := s.void(ctx, payment, "authorization expired"); err != nil {
+			log.Printf("failed to expire authorization for payment %s: %v", payment.ID, err)
+			continue
+		}
+		expired++
+	}
+
+	return expired, nil
+}
+
+func (s *PaymentService) void(ctx context.Context, payment *domain.Payment, reason string) (*domain.Payment, error) {
+	if !domain.CanTransition(payment.Status, domain.PaymentStatusVoided) {
+		return nil, domain.ErrInvalidTransition{From: payment.Status, To: domain.PaymentStatusVoided}
+	}
+
+	if err := s.cardProc.VoidPayment(ctx, payment); err != nil {
+		return nil, fmt.Errorf("failed to void payment: %w", err)
+	}
+
+	if err := payment.Void(reason); err != nil {
+		return nil, err
+	}
+	if err := s.save(ctx, payment); err != nil {
+		return nil, err
+	}
+
+	s.publish(ctx, "status updated", s.publisher.PublishPaymentStatusUpdated, payment)
+	return payment, nil
+}
@@ -0,0 +1,139 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/hsibAD/payment-service/internal/domain"
+	"github.com/hsibAD/payment-service/internal/infrastructure/payment"
+)
+
+// authorizedPayment places a hold for a new 25.00 USD payment.
+func (ct *cardTest) authorizedPayment(t *testing.T) *domain.Payment {
+	t.Helper()
+
+	p := ct.newPayment(t)
+	got, err := ct.service.AuthorizeCreditCardPayment(context.Background(), p.ID, ct.cardToken(t, payment.FakeCardApproved))
+	if err != nil {
+		t.Fatalf("AuthorizeCreditCardPayment: %v", err)
+	}
+	if got.Status != domain.PaymentStatusAuthorized {
+		t.Fatalf("status = %s, want %s", got.Status, domain.PaymentStatusAuthorized)
+	}
+	return got
+}
+
+func TestAuthorizeCreditCardPayment(t *testing.T) {
+	tests := []struct {
+		name string
+		card string
+		want domain.PaymentStatus
+	}{
+		{name: "approved", card: payment.FakeCardApproved, want: domain.PaymentStatusAuthorized},
+		{name: "declined", card: payment.FakeCardDeclined, want: domain.PaymentStatusFailed},
+		{name: "insufficient funds", card: payment.FakeCardInsufficientFunds, want: domain.PaymentStatusFailed},
+		{name: "timeout", card: payment.FakeCardTimeout, want: domain.PaymentStatusProcessing},
+		{name: "3DS required", card: payment.FakeCard3DSRequired, want: domain.PaymentStatusRequiresAction},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ct := newCardTest(t)
+			p := ct.newPayment(t)
+
+			got, err := ct.service.AuthorizeCreditCardPayment(context.Background(), p.ID, ct.cardToken(t, tt.card))
+			if err != nil {
+				t.Fatalf("AuthorizeCreditCardPayment: %v", err)
+			}
+			if got.Status != tt.want {
+				t.Errorf("status = %s, want %s", got.Status, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuthorizeAfter3DSHoldsTheCard(t *testing.T) {
+	ct := newCardTest(t)
+	p := ct.newPayment(t)
+	ctx := context.Background()
+
+	pending, err := ct.service.AuthorizeCreditCardPayment(ctx, p.ID, ct.cardToken(t, payment.FakeCard3DSRequired))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ct.gateway.Authenticate(pending.TransactionID, true); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ct.service.ConfirmCreditCardPayment(ctx, p.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != domain.PaymentStatusAuthorized {
+		t.Errorf("status = %s, want %s", got.Status, domain.PaymentStatusAuthorized)
+	}
+}
+
+func TestCapturePayment(t *testing.T) {
+	tests := []struct {
+		name   string
+		amount *domain.Money
+		want   domain.Money
+	}{
+		{name: "full", amount: nil, want: usd(2500)},
+		{name: "partial", amount: &domain.Money{MinorUnits: 1800, Currency: "USD"}, want: usd(1800)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ct := newCardTest(t)
+			p := ct.authorizedPayment(t)
+
+			got, err := ct.service.CapturePayment(context.Background(), p.ID, tt.amount)
+			if err != nil {
+				t.Fatalf("CapturePayment: %v", err)
+			}
+			if got.Status != domain.PaymentStatusCaptured {
+				t.Errorf("status = %s, want %s", got.Status, domain.PaymentStatusCaptured)
+			}
+			if got.CapturedAmount != tt.want {
+				t.Errorf("captured amount = %v, want %v", got.CapturedAmount, tt.want)
+			}
+		})
+	}
+}
+
+func TestCapturePaymentRejectsMoreThanAuthorized(t *testing.T) {
+	ct := newCardTest(t)
+	p := ct.authorizedPayment(t)
+
+	_, err := ct.service.CapturePayment(context.Background(), p.ID, &domain.Money{MinorUnits: 2600, Currency: "USD"})
+	if !errors.Is(err, domain.ErrCaptureExceedsAuthorization) {
+		t.Fatalf("error = %v, want %v", err, domain.ErrCaptureExceedsAuthorization)
+	}
+	if stored := ct.stored(t, p.ID); stored.Status != domain.PaymentStatusAuthorized {
+		t.Errorf("status = %s, want %s", stored.Status, domain.PaymentStatusAuthorized)
+	}
+}
+
+func TestVoidPayment(t *testing.T) {
+	ct := newCardTest(t)
+	p := ct.authorizedPayment(t)
+	ctx := context.Background()
+
+	got, err := ct.service.VoidPayment(ctx, p.ID, "order cancelled")
+	if err != nil {
+		t.Fatalf("VoidPayment: %v", err)
+	}
+	if got.Status != domain.PaymentStatusVoided {
+		t.Errorf("status = %s, want %s", got.Status, domain.PaymentStatusVoided)
+	}
+
+	// The hold is gone, so it cannot be captured any more.
+	_, err = ct.service.CapturePayment(ctx, p.ID, nil)
+	var transitionErr domain.ErrInvalidTransition
+	if !errors.As(err, &transitionErr) {
+		t.Errorf("CapturePayment after void: error = %v, want an invalid transition", err)
+	}
+}
@@ -0,0 +1,232 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/hsibAD/payment-service/internal/domain"
+)
+
+const (
+	// externalRefundReason is recorded on refunds the service learns about
+	// from the processor, e.g. ones issued from the Stripe dashboard.
+	externalRefundReason = "refunded at processor"
+
+	// cardEventLease is how long a delivery has to apply the event it
+	// claimed before a retry of the event may claim it.
+	cardEventLease = 5 * time.Minute
+)
+
+// HandleCardEvent applies a processor event to its card payment. Every
+// event is applied at most once; an event that fails to apply is released
+// so that the processor's retry can apply it, and one whose delivery died
+// while applying it is claimable again once the claim's lease runs out.
+// Events for payments this service does not know, or that describe a state
+// the payment has already reached or cannot reach, are ignored.
+func (s *PaymentService) HandleCardEvent(ctx context.Context, event *domain.CardEvent) error {
+	claimed, err := s.processedEvents.Claim(ctx, event.ID, event.Type, cardEventLease)
+	if err != nil {
+		return fmt.Errorf("failed to record event: %w", err)
+	}
+	if !claimed {
+		return nil
+	}
+
+	if err := s.applyCardEvent(ctx, event); err != nil {
+		if releaseErr := s.processedEvents.Release(ctx, event.ID); releaseErr != nil {
+			log.Printf("failed to release card event %s: %v", event.ID, releaseErr)
+		}
+		return err
+	}
+
+	if err := s.processedEvents.Complete(ctx, event.ID); err != nil {
+		// The event is applied; a retry after the lease finds nothing
+		// left to change.
+		log.Printf("failed to record card event %s as applied: %v", event.ID, err)
+	}
+	return nil
+}
+
+func (s *PaymentService) applyCardEvent(ctx context.Context, event *domain.CardEvent) error {
+	payment, err := s.cardEventPayment(ctx, event)
+	if errors.Is(err, domain.ErrInvalidPaymentID) {
+		log.Printf("ignoring card event %s: no payment for transaction %s", event.ID, event.TransactionID)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if payment.PaymentMethod != domain.PaymentMethodCreditCard {
+		log.Printf("ignoring card event %s: payment %s is not a card payment", event.ID, payment.ID)
+		return nil
+	}
+
+	// Payments whose customer never came back from authenticating are
+	// finished here as well.
+	inFlight := payment.IsPending() || payment.Status == domain.PaymentStatusRequiresAction
+
+	switch event.Type {
+	case domain.CardEventPaymentSucceeded:
+		// The customer was charged even if the payment was failed
+		// meanwhile, e.g. on an error after the processor took the charge.
+		lateSuccess := payment.Status == domain.PaymentStatusFailed &&
+			payment.TransactionID != "" && payment.TransactionID == event.TransactionID
+		if !inFlight && !lateSuccess {
+			return nil
+		}
+		if err := startProcessing(payment); err != nil {
+			return err
+		}
+		_, err := s.complete(ctx, payment, event.TransactionID)
+		return err
+
+	case domain.CardEventPaymentAuthorized:
+		if !inFlight {
+			return nil
+		}
+		if err := startProcessing(payment); err != nil {
+			return err
+		}
+		if payment.FlaggedForReview() {
+			err = payment.HoldForReview(event.TransactionID)
+		} else {
+			err = payment.MarkAsAuthorized(event.TransactionID)
+		}
+		if err != nil {
+			return err
+		}
+		return s.saveAndAnnounce(ctx, payment)
+
+	case domain.CardEventPaymentFailed:
+		if !inFlight {
+			return nil
+		}
+		reason := event.Reason
+		if reason == "" {
+			reason = "payment failed at processor"
+		}
+		_, err := s.fail(ctx, payment, errors.New(reason))
+		return err
+
+	case domain.CardEventPaymentCanceled:
+		switch {
+		case payment.Status == domain.PaymentStatusAuthorized,
+			payment.Status == domain.PaymentStatusManualReview:
+			err = payment.Void(event.Reason)
+		case inFlight:
+			err = payment.Cancel(event.Reason)
+		default:
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		return s.saveAndAnnounce(ctx, payment)
+
+	case domain.CardEventRefunded:
+		return s.applyProcessorRefund(ctx, payment, event)
+
+	case domain.CardEventDisputeOpened:
+		if payment.Status == domain.PaymentStatusDisputed {
+			return nil
+		}
+		if err := payment.OpenDispute(disputeReason(event)); err != nil {
+			// Retrying could not change the payment's status either.
+			var transitionErr domain.ErrInvalidTransition
+			if errors.As(err, &transitionErr) {
+				log.Printf("ignoring card event %s for payment %s: %v", event.ID, payment.ID, err)
+				return nil
+			}
+			return err
+		}
+		return s.saveAndAnnounce(ctx, payment)
+
+	case domain.CardEventDisputeWon, domain.CardEventDisputeLost:
+		if payment.Status != domain.PaymentStatusDisputed {
+			return nil
+		}
+		if err := payment.CloseDispute(event.Type == domain.CardEventDisputeWon, disputeReason(event)); err != nil {
+			return err
+		}
+		return s.saveAndAnnounce(ctx, payment)
+	}
+
+	return nil
+}
+
+// cardEventPayment finds the payment an event is about, by the payment ID
+// the processor echoes back or else by the processor's transaction ID.
+func (s *PaymentService) cardEventPayment(ctx context.Context, event *domain.CardEvent) (*domain.Payment, error) {
+	if event.PaymentID != "" {
+		payment, err := s.repo.GetByID(ctx, event.PaymentID)
+		if !errors.Is(err, domain.ErrInvalidPaymentID) {
+			return payment, err
+		}
+	}
+	if event.TransactionID == "" {
+		return nil, domain.ErrInvalidPaymentID
+	}
+	return s.repo.GetByTransactionID(ctx, event.TransactionID)
+}
+
+// applyProcessorRefund records the part of the processor's refunded total
+// that no refund of this service accounts for, such as a refund issued
+// from the processor's dashboard.
+func (s *PaymentService) applyProcessorRefund(ctx context.Context, payment *domain.Payment, event *domain.CardEvent) error {
+	existing, err := s.refunds.GetByPaymentID(ctx, payment.ID)
+	if err != nil {
+		return fmt.Errorf("failed to load refunds: %w", err)
+	}
+
+	missing := event.Amount
+	for _, refund := range existing {
+		if refund.Status == domain.RefundStatusFailed {
+			continue
+		}
+		if missing, err = missing.Sub(refund.Amount); err != nil {
+			return err
+		}
+	}
+	if !missing.IsPositive() {
+		return nil
+	}
+
+	refund, err := domain.NewRefund(payment.ID, missing, externalRefundReason)
+	if err != nil {
+		return err
+	}
+	refund.ProcessorRef = event.Reference
+	if err := s.refunds.Create(ctx, refund); err != nil {
+		return fmt.Errorf("failed to create refund: %w", err)
+	}
+
+	_, err = s.settleRefund(ctx, payment, refund)
+	return err
+}
+
+func (s *PaymentService) saveAndAnnounce(ctx context.Context, payment *domain.Payment) error {
+	if err := s.save(ctx, payment); err != nil {
+		return err
+	}
+	s.publish(ctx, "status updated", s.publisher.PublishPaymentStatusUpdated, payment)
+	return nil
+}
+
+// startProcessing moves a payment the RPC never got to process into
+// processing; payments past that point are left as they are.
+func startProcessing(payment *domain.Payment) error {
+	if payment.Status != domain.PaymentStatusPending {
+		return nil
+	}
+	return payment.MarkAsProcessing()
+}
+
+func disputeReason(event *domain.CardEvent) string {
+	if event.Reason == "" {
+		return "dispute " + event.Reference
+	}
+	return fmt.Sprintf("dispute %s: %s", event.Reference, event.Reason)
+}
@@ -0,0 +1,554 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/hsibAD/payment-service/internal/domain"
+)
+
+const (
+	paymentCacheTTL = 300 // seconds
+
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// PaymentService coordinates the payment repository, the payment processors
+// and the notification side effects for every payment RPC.
+type PaymentService struct {
+	repo      domain.PaymentRepository
+	refunds   domain.RefundRepository
+	cardProc  domain.CreditCardProcessor
+	metaMask  domain.MetaMaskProcessor
+	cache     domain.Cache
+	publisher domain.EventPublisher
+	notifier  domain.EmailNotifier
+	oracle    domain.PriceOracle
+	quotes    QuotePolicy
+
+	walletProof     WalletProof
+	processedEvents domain.ProcessedEventStore
+	risk            RiskControls
+}
+
+func NewPaymentService(
+	repo domain.PaymentRepository,
+	refunds domain.RefundRepository,
+	cardProc domain.CreditCardProcessor,
+	metaMask domain.MetaMaskProcessor,
+	cache domain.Cache,
+	publisher domain.EventPublisher,
+	notifier domain.EmailNotifier,
+	oracle domain.PriceOracle,
+	quotes QuotePolicy,
+	walletProof WalletProof,
+	processedEvents domain.ProcessedEventStore,
+	risk RiskControls,
+) *PaymentService {
+	return &PaymentService{
+		repo:      repo,
+		refunds:   refunds,
+		cardProc:  cardProc,
+		metaMask:  metaMask,
+		cache:     cache,
+		publisher: publisher,
+		notifier:  notifier,
+		oracle:    oracle,
+		quotes:    quotes,
+
+		walletProof:     walletProof,
+		processedEvents: processedEvents,
+		risk:            risk,
+	}
+}
+
+type InitiatePaymentInput struct {
+	OrderID       string
+	UserID        string
+	Amount        domain.Money
+	Method        domain.PaymentMethod
+	CustomerEmail string
+}
+
+type StatusUpdate struct {
+	Status       domain.PaymentStatus
+	ErrorMessage string
+}
+
+func (s *PaymentService) InitiatePayment(ctx context.Context, in InitiatePaymentInput) (*domain.Payment, error) {
+	payment, err := domain.NewPayment(in.OrderID, in.UserID, in.Amount, in.Method)
+	if err != nil {
+		return nil, err
+	}
+	payment.CustomerEmail = in.CustomerEmail
+
+	if err := s.repo.Create(ctx, payment); err != nil {
+		return nil, fmt.Errorf("failed to create payment: %w", err)
+	}
+
+	s.cachePayment(ctx, payment)
+	s.publish(ctx, "created", s.publisher.PublishPaymentCreated, payment)
+
+	return payment, nil
+}
+
+func (s *PaymentService) ProcessCreditCardPayment(ctx context.Context, paymentID string, cardToken string) (*domain.Payment, error) {
+	return s.chargeCard(ctx, paymentID, cardToken, true)
+}
+
+// chargeCard screens a card payment and charges it, capturing it right away
+// when capture is set. Attempts over the velocity limits are rejected and
+// leave the payment pending. Denied payments fail without reaching the
+// processor; flagged ones are only authorized and held for review. A charge
+// the processor did not answer may still go through, so it leaves the
+// payment processing until the processor's webhook settles it.
+func (s *PaymentService) chargeCard(ctx context.Context, paymentID, cardToken string, capture bool) (*domain.Payment, error) {
+	if cardToken == "" {
+		return nil, domain.ErrInvalidCardToken
+	}
+
+	payment, err := s.pendingPayment(ctx, paymentID, domain.PaymentMethodCreditCard)
+	if err != nil {
+		return nil, err
+	}
+	// A processing card payment was already sent to the processor;
+	// charging it again could charge the customer twice.
+	if payment.Status != domain.PaymentStatusPending {
+		return nil, domain.ErrPaymentNotPending
+	}
+
+	signals, err := s.screenAttempt(ctx, payment, cardToken)
+	if err != nil {
+		return nil, err
+	}
+	if payment.Risk != nil && payment.Risk.Decision == domain.RiskDecisionDeny {
+		s.recordAttempt(ctx, signals, domain.ErrPaymentDeclinedByRisk)
+		return s.fail(ctx, payment, domain.ErrPaymentDeclinedByRisk)
+	}
+	review := payment.FlaggedForReview()
+	payment.CaptureOnApproval = capture && review
+
+	if err := payment.MarkAsProcessing(); err != nil {
+		return nil, err
+	}
+	if err := s.save(ctx, payment); err != nil {
+		return nil, err
+	}
+
+	charge := s.cardProc.AuthorizePayment
+	if capture && !review {
+		charge = s.cardProc.ProcessPayment
+	}
+	err = charge(ctx, payment, cardToken)
+	s.recordAttempt(ctx, signals, err)
+	switch {
+	case errors.Is(err, domain.ErrActionRequired):
+		return s.requireAction(ctx, payment)
+	case errors.Is(err, domain.ErrGatewayTimeout):
+		log.Printf("card charge of payment %s timed out, waiting for the processor: %v", payment.ID, err)
+		return payment, nil
+	case err != nil:
+		return s.fail(ctx, payment, err)
+	}
+
+	if capture && !review {
+		return s.complete(ctx, payment, payment.TransactionID)
+	}
+	return s.authorized(ctx, payment)
+}
+
+// ConfirmCreditCardPayment finishes a card payment after the customer
+// authenticated it. A payment whose authentication is still outstanding is
+// returned unchanged, still requiring action.
+func (s *PaymentService) ConfirmCreditCardPayment(ctx context.Context, paymentID string) (*domain.Payment, error) {
+	if paymentID == "" {
+		return nil, domain.ErrInvalidPaymentID
+	}
+
+	payment, err := s.userPayment(ctx, paymentID)
+	if err != nil {
+		return nil, err
+	}
+	if payment.PaymentMethod != domain.PaymentMethodCreditCard {
+		return nil, domain.ErrPaymentMethodMismatch
+	}
+	if payment.Status != domain.PaymentStatusRequiresAction {
+		return nil, domain.ErrPaymentNotAwaitingAction
+	}
+
+	status, err := s.cardProc.ConfirmPayment(ctx, payment)
+	switch {
+	case errors.Is(err, domain.ErrActionRequired):
+		return payment, nil
+	case err != nil:
+		return s.fail(ctx, payment, err)
+	case status == domain.PaymentStatusAuthorized:
+		return s.authorized(ctx, payment)
+	default:
+		return s.complete(ctx, payment, payment.TransactionID)
+	}
+}
+
+// InitiateMetaMaskPayment returns what the wallet needs to pay the payment
+// in currency on the chain. Fiat payments are quoted in currency first; an
+// empty currency pays in the chain's native currency and chain ID 0 picks
+// the default chain.
+func (s *PaymentService) InitiateMetaMaskPayment(ctx context.Context, paymentID, walletAddress, currency string, chainID uint64) (*domain.MetaMaskInfo, error) {
+	payment, err := s.pendingPayment(ctx, paymentID, domain.PaymentMethodMetaMask)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkWalletOwnership(ctx, payment, walletAddress); err != nil {
+		return nil, err
+	}
+
+	chain, err := s.metaMask.Chain(chainID)
+	if err != nil {
+		return nil, err
+	}
+
+	changed, err := s.lockQuote(ctx, payment, currency, chain)
+	if err != nil {
+		return nil, err
+	}
+	if changed {
+		payment.UpdatedAt = time.Now()
+		if err := s.save(ctx, payment); err != nil {
+			return nil, err
+		}
+	}
+
+	info, err := s.metaMask.InitiateTransaction(ctx, payment, walletAddress)
+	if err != nil {
+		return nil, err
+	}
+	info.Quote = payment.Quote
+
+	return info, nil
+}
+
+// ConfirmMetaMaskPayment verifies the transaction that paid the payment. A
+// non-zero chainID names the chain the transaction was sent on and must be
+// the chain the payment was initiated on.
+func (s *PaymentService) ConfirmMetaMaskPayment(ctx context.Context, paymentID, transactionHash string, chainID uint64) (*domain.Payment, error) {
+	payment, err := s.pendingPayment(ctx, paymentID, domain.PaymentMethodMetaMask)
+	if err != nil {
+		return nil, err
+	}
+
+	if chainID != 0 {
+		chain, err := s.metaMask.Chain(payment.ChainID)
+		if err != nil {
+			return nil, err
+		}
+		if chain.ID != chainID {
+			return nil, fmt.Errorf("%w: payment is on chain %d", domain.ErrWrongChain, chain.ID)
+		}
+	}
+
+	if used, err := s.repo.GetByTransactionID(ctx, transactionHash); err == nil && used.ID != payment.ID {
+		return nil, domain.ErrTransactionAlreadyUsed
+	} else if err != nil && !errors.Is(err, domain.ErrInvalidPaymentID) {
+		return nil, err
+	}
+
+	// The payment is bound to the transaction from here on, so its quote is
+	// not replaced while the transaction is pending.
+	payment.TransactionID = transactionHash
+	if err := payment.MarkAsProcessing(); err != nil {
+		return nil, err
+	}
+	if err := s.save(ctx, payment); err != nil {
+		return nil, err
+	}
+
+	if err := s.metaMask.VerifyTransaction(ctx, payment, transactionHash); err != nil {
+		// Only a reverted transaction or an expired quote settles the
+		// payment. A transaction that is not mined or confirmed yet, one
+		// that does not pay for this payment and an unreachable node all
+		// leave it processing, so it can be confirmed again.
+		if isFinalVerificationError(err) {
+			return s.fail(ctx, payment, err)
+		}
+		return nil, err
+	}
+
+	return s.complete(ctx, payment, transactionHash)
+}
+
+func isFinalVerificationError(err error) bool {
+	return errors.Is(err, domain.ErrTransactionFailed) ||
+		errors.Is(err, domain.ErrQuoteExpired)
+}
+
+func (s *PaymentService) GetPayment(ctx context.Context, paymentID string) (*domain.Payment, error) {
+	if paymentID == "" {
+		return nil, domain.ErrInvalidPaymentID
+	}
+
+	payment := s.cachedPayment(ctx, paymentID)
+	if payment == nil {
+		var err error
+		if payment, err = s.repo.GetByID(ctx, paymentID); err != nil {
+			return nil, err
+		}
+		s.cachePayment(ctx, payment)
+	}
+
+	// Customers are not told that other users' payments exist.
+	if !canAccess(ctx, payment.UserID) {
+		return nil, domain.ErrInvalidPaymentID
+	}
+	return payment, nil
+}
+
+func (s *PaymentService) GetPaymentsByOrder(ctx context.Context, orderID string) ([]*domain.Payment, error) {
+	if orderID == "" {
+		return nil, domain.ErrInvalidOrderID
+	}
+
+	payments, err := s.repo.GetByOrderID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	visible := payments[:0]
+	for _, payment := range payments {
+		if canAccess(ctx, payment.UserID) {
+			visible = append(visible, payment)
+		}
+	}
+	return visible, nil
+}
+
+// UpdatePaymentStatus fails or cancels a payment on behalf of another
+// service. Every other status is rejected; payments complete, are held,
+// captured, voided and refunded only through the operations that move the
+// money.
+func (s *PaymentService) UpdatePaymentStatus(ctx context.Context, paymentID string, update StatusUpdate) (*domain.Payment, error) {
+	if !update.Status.Settable() {
+		return nil, fmt.Errorf("%w: %s", domain.ErrStatusNotSettable, update.Status)
+	}
+
+	payment, err := s.repo.GetByID(ctx, paymentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if update.Status == domain.PaymentStatusFailed {
+		err = payment.SetError(update.ErrorMessage)
+	} else {
+		err = payment.TransitionTo(update.Status, update.ErrorMessage)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.save(ctx, payment); err != nil {
+		return nil, err
+	}
+
+	s.publish(ctx, "status updated", s.publisher.PublishPaymentStatusUpdated, payment)
+	if update.Status == domain.PaymentStatusFailed {
+		s.publish(ctx, "failed", s.publisher.PublishPaymentFailed, payment)
+		s.notify(ctx, "failure", s.notifier.SendPaymentFailure, payment)
+	}
+
+	return payment, nil
+}
+
+// GetPendingPayments lists payments that never reached a final state. An
+// empty userID lists pending payments across all users, except for
+// customers, who only ever see their own.
+func (s *PaymentService) GetPendingPayments(ctx context.Context, userID string, page, limit int) ([]*domain.Payment, int, error) {
+	if principal := domain.PrincipalFromContext(ctx); principal != nil && principal.IsCustomer() {
+		if userID != "" && userID != principal.UserID {
+			return nil, 0, domain.ErrPermissionDenied
+		}
+		userID = principal.UserID
+	}
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = defaultPageSize
+	}
+	if limit > maxPageSize {
+		limit = maxPageSize
+	}
+
+	return s.repo.GetPendingByUserID(ctx, userID, page, limit)
+}
+
+// GetPendingPaymentsByOrderHashes lists the payments made with method that
+// are still waiting to be paid and belong to an order with one of the
+// hashes, oldest first.
+func (s *PaymentService) GetPendingPaymentsByOrderHashes(ctx context.Context, method domain.PaymentMethod, orderHashes []string) ([]*domain.Payment, error) {
+	if len(orderHashes) == 0 {
+		return nil, nil
+	}
+	return s.repo.GetPendingByOrderHashes(ctx, method, orderHashes)
+}
+
+func (s *PaymentService) RetryPayment(ctx context.Context, paymentID string, method domain.PaymentMethod) (*domain.Payment, error) {
+	payment, err := s.userPayment(ctx, paymentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := payment.Retry(method); err != nil {
+		return nil, err
+	}
+
+	if err := s.save(ctx, payment); err != nil {
+		return nil, err
+	}
+
+	s.publish(ctx, "status updated", s.publisher.PublishPaymentStatusUpdated, payment)
+	return payment, nil
+}
+
+// canAccess reports whether the caller may see payments of userID. Calls
+// that were not authenticated are the service's own and see everything.
+func canAccess(ctx context.Context, userID string) bool {
+	principal := domain.PrincipalFromContext(ctx)
+	return principal == nil || principal.CanAccess(userID)
+}
+
+// userPayment loads a payment the caller may act on. Like GetPayment, it
+// does not tell customers that other users' payments exist.
+func (s *PaymentService) userPayment(ctx context.Context, paymentID string) (*domain.Payment, error) {
+	payment, err := s.repo.GetByID(ctx, paymentID)
+	if err != nil {
+		return nil, err
+	}
+	if !canAccess(ctx, payment.UserID) {
+		return nil, domain.ErrInvalidPaymentID
+	}
+	return payment, nil
+}
+
+// pendingPayment loads a payment of the caller that is still waiting to be
+// paid with the given method.
+func (s *PaymentService) pendingPayment(ctx context.Context, paymentID string, method domain.PaymentMethod) (*domain.Payment, error) {
+	if paymentID == "" {
+		return nil, domain.ErrInvalidPaymentID
+	}
+
+	payment, err := s.userPayment(ctx, paymentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if payment.PaymentMethod != method {
+		return nil, domain.ErrPaymentMethodMismatch
+	}
+
+	if !payment.IsPending() {
+		return nil, domain.ErrPaymentNotPending
+	}
+
+	return payment, nil
+}
+
+func (s *PaymentService) complete(ctx context.Context, payment *domain.Payment, transactionID string) (*domain.Payment, error) {
+	if err := payment.MarkAsCompleted(transactionID); err != nil {
+		return nil, err
+	}
+	if err := s.save(ctx, payment); err != nil {
+		return nil, err
+	}
+
+	s.publish(ctx, "completed", s.publisher.PublishPaymentCompleted, payment)
+	s.notify(ctx, "confirmation", s.notifier.SendPaymentConfirmation, payment)
+
+	return payment, nil
+}
+
+// requireAction parks a card payment until the customer authenticates it.
+func (s *PaymentService) requireAction(ctx context.Context, payment *domain.Payment) (*domain.Payment, error) {
+	if err := payment.RequireAction(); err != nil {
+		return nil, err
+	}
+	if err := s.save(ctx, payment); err != nil {
+		return nil, err
+	}
+
+	s.publish(ctx, "status updated", s.publisher.PublishPaymentStatusUpdated, payment)
+	return payment, nil
+}
+
+// fail records a processor error on the payment. The failed payment is
+// returned to the caller rather than an error so that clients can inspect the
+// failure reason and decide whether to retry.
+func (s *PaymentService) fail(ctx context.Context, payment *domain.Payment, cause error) (*domain.Payment, error) {
+	if err := payment.SetError(cause.Error()); err != nil {
+		return nil, err
+	}
+	if err := s.save(ctx, payment); err != nil {
+		return nil, err
+	}
+
+	s.publish(ctx, "failed", s.publisher.PublishPaymentFailed, payment)
+	s.notify(ctx, "failure", s.notifier.SendPaymentFailure, payment)
+
+	return payment, nil
+}
+
+func (s *PaymentService) save(ctx context.Context, payment *domain.Payment) error {
+	if err := s.repo.Update(ctx, payment); err != nil {
+		return fmt.Errorf("failed to update payment: %w", err)
+	}
+
+	s.cachePayment(ctx, payment)
+	return nil
+}
+
+func (s *PaymentService) publish(ctx context.Context, event string, fn func(context.Context, *domain.Payment) error, payment *domain.Payment) {
+	if err := fn(ctx, payment); err != nil {
+		log.Printf("failed to publish payment %s event for %s: %v", event, payment.ID, err)
+	}
+}
+
+func (s *PaymentService) notify(ctx context.Context, kind string, fn func(context.Context, *domain.Payment) error, payment *domain.Payment) {
+	if err := fn(ctx, payment); err != nil {
+		log.Printf("failed to send payment %s email for %s: %v", kind, payment.ID, err)
+	}
+}
+
+func paymentCacheKey(paymentID string) string {
+	return "payment:" + paymentID
+}
+
+func (s *PaymentService) cachePayment(ctx context.Context, payment *domain.Payment) {
+	if err := s.cache.Set(ctx, paymentCacheKey(payment.ID), payment, paymentCacheTTL); err != nil {
+		log.Printf("failed to cache payment %s: %v", payment.ID, err)
+	}
+}
+
+func (s *PaymentService) cachedPayment(ctx context.Context, paymentID string) *domain.Payment {
+	value, err := s.cache.Get(ctx, paymentCacheKey(paymentID))
+	if err != nil || value == nil {
+		return nil
+	}
+
+	// The cache hands back generic JSON values, so round-trip them into
+	// the domain type.
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil
+	}
+
+	var payment domain.Payment
+	if err := json.Unmarshal(data, &payment); err != nil {
+		return nil
+	}
+
+	return &payment
+}
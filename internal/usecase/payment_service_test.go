@@ -0,0 +1,446 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hsibAD/payment-service/internal/domain"
+	"github.com/hsibAD/payment-service/internal/infrastructure/payment"
+	"github.com/hsibAD/payment-service/internal/infrastructure/vault"
+	"github.com/hsibAD/payment-service/internal/repository/memory"
+	"github.com/hsibAD/payment-service/internal/usecase"
+)
+
+// cardTest runs the payment use cases against in-memory repositories and the
+// fake gateway.
+type cardTest struct {
+	service  *usecase.PaymentService
+	gateway  *payment.FakeGateway
+	vault    *vault.LocalVault
+	payments *memory.PaymentRepository
+	refunds  *memory.RefundRepository
+}
+
+func newCardTest(t *testing.T) *cardTest {
+	t.Helper()
+
+	v, err := vault.NewLocalVault(filepath.Join(t.TempDir(), "vault.json"), make([]byte, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	gateway := payment.NewFakeGateway(v)
+
+	ct := &cardTest{
+		gateway:  gateway,
+		vault:    v,
+		payments: memory.NewPaymentRepository(),
+		refunds:  memory.NewRefundRepository(),
+	}
+	ct.service = usecase.NewPaymentService(
+		ct.payments, ct.refunds, payment.NewCreditCardProcessor(gateway), nil,
+		noCache{}, noPublisher{}, noNotifier{}, nil,
+		usecase.QuotePolicy{}, usecase.WalletProof{},
+		memory.NewProcessedEventRepository(), usecase.RiskControls{},
+	)
+	return ct
+}
+
+// newPayment initiates a 25.00 USD card payment.
+func (ct *cardTest) newPayment(t *testing.T) *domain.Payment {
+	t.Helper()
+
+	p, err := ct.service.InitiatePayment(context.Background(), usecase.InitiatePaymentInput{
+		OrderID: "order-1",
+		UserID:  "user-1",
+		Amount:  usd(2500),
+		Method:  domain.PaymentMethodCreditCard,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+// cardToken stores the card number in the vault.
+func (ct *cardTest) cardToken(t *testing.T, number string) string {
+	t.Helper()
+
+	token, err := ct.vault.Tokenize(context.Background(), &domain.CreditCardInfo{
+		CardNumber:     number,
+		ExpiryMonth:    "12",
+		ExpiryYear:     fmt.Sprint(time.Now().Year() + 1),
+		CVV:            "123",
+		CardholderName: "Test Customer",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+// stored returns the payment as it was saved.
+func (ct *cardTest) stored(t *testing.T, paymentID string) *domain.Payment {
+	t.Helper()
+
+	p, err := ct.payments.GetByID(context.Background(), paymentID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func usd(minorUnits int64) domain.Money {
+	return domain.Money{MinorUnits: minorUnits, Currency: "USD"}
+}
+
+func TestProcessCreditCardPayment(t *testing.T) {
+	tests := []struct {
+		name        string
+		card        string
+		wantStatus  domain.PaymentStatus
+		wantMessage string
+	}{
+		{name: "approved", card: payment.FakeCardApproved, wantStatus: domain.PaymentStatusCompleted},
+		{name: "declined", card: payment.FakeCardDeclined, wantStatus: domain.PaymentStatusFailed, wantMessage: "declined"},
+		{name: "insufficient funds", card: payment.FakeCardInsufficientFunds, wantStatus: domain.PaymentStatusFailed, wantMessage: "insufficient funds"},
+		// The charge may still go through, so the webhook settles it.
+		{name: "timeout", card: payment.FakeCardTimeout, wantStatus: domain.PaymentStatusProcessing},
+		{name: "3DS required", card: payment.FakeCard3DSRequired, wantStatus: domain.PaymentStatusRequiresAction},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ct := newCardTest(t)
+			p := ct.newPayment(t)
+
+			got, err := ct.service.ProcessCreditCardPayment(context.Background(), p.ID, ct.cardToken(t, tt.card))
+			if err != nil {
+				t.Fatalf("ProcessCreditCardPayment: %v", err)
+			}
+			if got.Status != tt.wantStatus {
+				t.Errorf("status = %s, want %s", got.Status, tt.wantStatus)
+			}
+			if !strings.Contains(got.ErrorMessage, tt.wantMessage) {
+				t.Errorf("error message = %q, want it to mention %q", got.ErrorMessage, tt.wantMessage)
+			}
+			if stored := ct.stored(t, p.ID); stored.Status != tt.wantStatus {
+				t.Errorf("stored status = %s, want %s", stored.Status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestProcessCreditCardPaymentDoesNotChargeTimedOutPaymentAgain(t *testing.T) {
+	ct := newCardTest(t)
+	p := ct.newPayment(t)
+	ctx := context.Background()
+
+	if _, err := ct.service.ProcessCreditCardPayment(ctx, p.ID, ct.cardToken(t, payment.FakeCardTimeout)); err != nil {
+		t.Fatalf("ProcessCreditCardPayment: %v", err)
+	}
+
+	_, err := ct.service.ProcessCreditCardPayment(ctx, p.ID, ct.cardToken(t, payment.FakeCardApproved))
+	if !errors.Is(err, domain.ErrPaymentNotPending) {
+		t.Fatalf("second ProcessCreditCardPayment: %v, want %v", err, domain.ErrPaymentNotPending)
+	}
+	if _, err := ct.service.RetryPayment(ctx, p.ID, ""); !errors.Is(err, domain.ErrPaymentNotRetryable) {
+		t.Fatalf("RetryPayment: %v, want %v", err, domain.ErrPaymentNotRetryable)
+	}
+}
+
+func TestProcessCreditCardPaymentRecordsCard(t *testing.T) {
+	ct := newCardTest(t)
+	p := ct.newPayment(t)
+
+	got, err := ct.service.ProcessCreditCardPayment(context.Background(), p.ID, ct.cardToken(t, payment.FakeCardApproved))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.TransactionID == "" {
+		t.Error("completed payment has no transaction ID")
+	}
+	if got.Card == nil || got.Card.Last4 != "4242" {
+		t.Errorf("card = %+v, want last 4 digits 4242", got.Card)
+	}
+	if got.CapturedAmount != usd(2500) {
+		t.Errorf("captured amount = %v, want %v", got.CapturedAmount, usd(2500))
+	}
+}
+
+func TestConfirmCreditCardPaymentAfter3DS(t *testing.T) {
+	tests := []struct {
+		name          string
+		authenticated bool
+		want          domain.PaymentStatus
+	}{
+		{name: "authenticated", authenticated: true, want: domain.PaymentStatusCompleted},
+		{name: "authentication failed", authenticated: false, want: domain.PaymentStatusFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ct := newCardTest(t)
+			p := ct.newPayment(t)
+			ctx := context.Background()
+
+			pending, err := ct.service.ProcessCreditCardPayment(ctx, p.ID, ct.cardToken(t, payment.FakeCard3DSRequired))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if pending.NextAction == nil || pending.NextAction.RedirectURL == "" {
+				t.Fatalf("next action = %+v, want a redirect", pending.NextAction)
+			}
+
+			// Until the customer answers the challenge the payment waits.
+			got, err := ct.service.ConfirmCreditCardPayment(ctx, p.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Status != domain.PaymentStatusRequiresAction {
+				t.Fatalf("status before authentication = %s, want %s", got.Status, domain.PaymentStatusRequiresAction)
+			}
+
+			if err := ct.gateway.Authenticate(pending.TransactionID, tt.authenticated); err != nil {
+				t.Fatal(err)
+			}
+			got, err = ct.service.ConfirmCreditCardPayment(ctx, p.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Status != tt.want {
+				t.Errorf("status = %s, want %s", got.Status, tt.want)
+			}
+		})
+	}
+}
+
+func TestProcessCreditCardPaymentRejectsPaymentOfOtherUser(t *testing.T) {
+	ct := newCardTest(t)
+	p := ct.newPayment(t)
+
+	ctx := domain.WithPrincipal(context.Background(), &domain.Principal{UserID: "user-2", Roles: []domain.Role{domain.RoleCustomer}})
+	_, err := ct.service.ProcessCreditCardPayment(ctx, p.ID, ct.cardToken(t, payment.FakeCardApproved))
+	if !errors.Is(err, domain.ErrInvalidPaymentID) {
+		t.Fatalf("error = %v, want %v", err, domain.ErrInvalidPaymentID)
+	}
+	if stored := ct.stored(t, p.ID); stored.Status != domain.PaymentStatusPending {
+		t.Errorf("status = %s, want %s", stored.Status, domain.PaymentStatusPending)
+	}
+}
+
+func TestUpdatePaymentStatus(t *testing.T) {
+	tests := []struct {
+		status domain.PaymentStatus
+		want   error
+	}{
+		{status: domain.PaymentStatusFailed},
+		{status: domain.PaymentStatusCancelled},
+		// Only the operations that move the money reach these.
+		{status: domain.PaymentStatusCompleted, want: domain.ErrStatusNotSettable},
+		{status: domain.PaymentStatusAuthorized, want: domain.ErrStatusNotSettable},
+		{status: domain.PaymentStatusRefunded, want: domain.ErrStatusNotSettable},
+		{status: domain.PaymentStatusVoided, want: domain.ErrStatusNotSettable},
+		{status: domain.PaymentStatusDisputed, want: domain.ErrStatusNotSettable},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.status), func(t *testing.T) {
+			ct := newCardTest(t)
+			p := ct.newPayment(t)
+
+			_, err := ct.service.UpdatePaymentStatus(context.Background(), p.ID, usecase.StatusUpdate{Status: tt.status})
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("UpdatePaymentStatus: %v, want %v", err, tt.want)
+			}
+
+			want := tt.status
+			if tt.want != nil {
+				want = domain.PaymentStatusPending
+			}
+			if stored := ct.stored(t, p.ID); stored.Status != want {
+				t.Errorf("stored status = %s, want %s", stored.Status, want)
+			}
+		})
+	}
+}
+
+// stubChain is a MetaMask processor whose verification and refund outcomes
+// are set by the test.
+type stubChain struct {
+	verifyErr error
+	refundErr error
+}
+
+func (c *stubChain) Chain(chainID uint64) (*domain.ChainInfo, error) {
+	return &domain.ChainInfo{ID: 1337, Name: "stub", NativeCurrency: "ETH"}, nil
+}
+
+func (c *stubChain) InitiateTransaction(ctx context.Context, payment *domain.Payment, walletAddress string) (*domain.MetaMaskInfo, error) {
+	return &domain.MetaMaskInfo{WalletAddress: walletAddress}, nil
+}
+
+func (c *stubChain) VerifyTransaction(ctx context.Context, payment *domain.Payment, transactionHash string) error {
+	return c.verifyErr
+}
+
+func (c *stubChain) GetTransactionStatus(ctx context.Context, payment *domain.Payment, transactionHash string) (string, error) {
+	return "CONFIRMED", nil
+}
+
+func (c *stubChain) RefundTransaction(ctx context.Context, payment *domain.Payment, refund *domain.Refund, record func(context.Context, *domain.Refund) error) error {
+	refund.ProcessorRef = testRefundHash
+	if err := record(ctx, refund); err != nil {
+		return err
+	}
+	return c.refundErr
+}
+
+func (c *stubChain) VerifyRefund(ctx context.Context, payment *domain.Payment, refund *domain.Refund) error {
+	return nil
+}
+
+func newChainService(chain domain.MetaMaskProcessor) (*usecase.PaymentService, *memory.PaymentRepository) {
+	payments := memory.NewPaymentRepository()
+	service := usecase.NewPaymentService(
+		payments, memory.NewRefundRepository(), nil, chain,
+		noCache{}, noPublisher{}, noNotifier{}, nil,
+		usecase.QuotePolicy{}, usecase.WalletProof{},
+		memory.NewProcessedEventRepository(), usecase.RiskControls{},
+	)
+	return service, payments
+}
+
+func newEthPayment(t *testing.T, service *usecase.PaymentService, orderID string) *domain.Payment {
+	t.Helper()
+
+	p, err := service.InitiatePayment(context.Background(), usecase.InitiatePaymentInput{
+		OrderID: orderID,
+		UserID:  "user-1",
+		Amount:  domain.Money{MinorUnits: 1e17, Currency: "ETH"},
+		Method:  domain.PaymentMethodMetaMask,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+const (
+	testTxHash     = "0x1111111111111111111111111111111111111111111111111111111111111111"
+	testRefundHash = "0x2222222222222222222222222222222222222222222222222222222222222222"
+)
+
+func TestConfirmMetaMaskPaymentRejectsUsedTransaction(t *testing.T) {
+	service, _ := newChainService(&stubChain{})
+	ctx := context.Background()
+	first := newEthPayment(t, service, "order-1")
+	second := newEthPayment(t, service, "order-2")
+
+	got, err := service.ConfirmMetaMaskPayment(ctx, first.ID, testTxHash, 0)
+	if err != nil {
+		t.Fatalf("ConfirmMetaMaskPayment: %v", err)
+	}
+	if got.Status != domain.PaymentStatusCompleted {
+		t.Fatalf("status = %s, want %s", got.Status, domain.PaymentStatusCompleted)
+	}
+
+	_, err = service.ConfirmMetaMaskPayment(ctx, second.ID, testTxHash, 0)
+	if !errors.Is(err, domain.ErrTransactionAlreadyUsed) {
+		t.Fatalf("error = %v, want %v", err, domain.ErrTransactionAlreadyUsed)
+	}
+}
+
+func TestConfirmMetaMaskPaymentVerificationErrors(t *testing.T) {
+	tests := []struct {
+		verifyErr error
+		want      domain.PaymentStatus
+	}{
+		// The transaction may still be mined, or the client may have sent
+		// the wrong hash, so the payment can be confirmed again.
+		{verifyErr: domain.ErrTransactionNotMined, want: domain.PaymentStatusProcessing},
+		{verifyErr: domain.ErrInsufficientConfirmations, want: domain.PaymentStatusProcessing},
+		{verifyErr: domain.ErrPaymentLogNotFound, want: domain.PaymentStatusProcessing},
+		{verifyErr: domain.ErrWrongPaymentContract, want: domain.PaymentStatusProcessing},
+		{verifyErr: domain.ErrPaymentOrderMismatch, want: domain.PaymentStatusProcessing},
+		{verifyErr: domain.ErrPaymentUnderpaid, want: domain.PaymentStatusProcessing},
+		{verifyErr: errors.New("node unavailable"), want: domain.PaymentStatusProcessing},
+		{verifyErr: domain.ErrTransactionFailed, want: domain.PaymentStatusFailed},
+		{verifyErr: domain.ErrQuoteExpired, want: domain.PaymentStatusFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.verifyErr.Error(), func(t *testing.T) {
+			service, payments := newChainService(&stubChain{verifyErr: tt.verifyErr})
+			p := newEthPayment(t, service, "order-1")
+
+			_, err := service.ConfirmMetaMaskPayment(context.Background(), p.ID, testTxHash, 0)
+			if tt.want == domain.PaymentStatusProcessing && !errors.Is(err, tt.verifyErr) {
+				t.Errorf("error = %v, want %v", err, tt.verifyErr)
+			}
+
+			stored, err := payments.GetByID(context.Background(), p.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if stored.Status != tt.want {
+				t.Errorf("status = %s, want %s", stored.Status, tt.want)
+			}
+		})
+	}
+}
+
+type noPublisher struct{}
+
+func (noPublisher) PublishPaymentCreated(ctx context.Context, payment *domain.Payment) error {
+	return nil
+}
+
+func (noPublisher) PublishPaymentStatusUpdated(ctx context.Context, payment *domain.Payment) error {
+	return nil
+}
+
+func (noPublisher) PublishPaymentCompleted(ctx context.Context, payment *domain.Payment) error {
+	return nil
+}
+
+func (noPublisher) PublishPaymentFailed(ctx context.Context, payment *domain.Payment) error {
+	return nil
+}
+
+func (noPublisher) PublishPaymentRefunded(ctx context.Context, payment *domain.Payment, refund *domain.Refund) error {
+	return nil
+}
+
+type noNotifier struct{}
+
+func (noNotifier) SendPaymentConfirmation(ctx context.Context, payment *domain.Payment) error {
+	return nil
+}
+
+func (noNotifier) SendPaymentFailure(ctx context.Context, payment *domain.Payment) error {
+	return nil
+}
+
+func (noNotifier) SendRefundConfirmation(ctx context.Context, payment *domain.Payment, refund *domain.Refund) error {
+	return nil
+}
+
+// noCache never holds anything, so every read goes to the repository.
+type noCache struct{}
+
+func (noCache) Set(ctx context.Context, key string, value interface{}, ttl int) error {
+	return nil
+}
+
+func (noCache) Get(ctx context.Context, key string) (interface{}, error) {
+	return nil, nil
+}
+
+func (noCache) Delete(ctx context.Context, key string) error {
+	return nil
+}
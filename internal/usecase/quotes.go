@@ -0,0 +1,70 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hsibAD/payment-service/internal/domain"
+)
+
+// QuotePolicy controls how fiat payments paid in crypto are quoted.
+type QuotePolicy struct {
+	TTL         time.Duration
+	SlippageBps int
+}
+
+// lockQuote assigns the payment to chain and, for fiat payments, prices it
+// in the crypto currency it is paid with. An unexpired quote for the same
+// chain and currency is kept, so initiating again does not move the price.
+// Once a transaction was submitted for the payment, neither the chain nor
+// the quote change any more, even after the quote expired. It reports
+// whether the payment changed and needs saving.
+func (s *PaymentService) lockQuote(ctx context.Context, payment *domain.Payment, currency string, chain *domain.ChainInfo) (bool, error) {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+
+	chainChanged := payment.ChainID != chain.ID
+	if chainChanged && payment.TransactionID != "" {
+		return false, domain.ErrTransactionSubmitted
+	}
+	payment.ChainID = chain.ID
+
+	if domain.IsCryptoCurrency(payment.Amount.Currency) {
+		if currency != "" && currency != payment.Amount.Currency {
+			return false, fmt.Errorf("%w: payment is priced in %s", domain.ErrCurrencyMismatch, payment.Amount.Currency)
+		}
+		return chainChanged, nil
+	}
+
+	if currency == "" {
+		currency = chain.NativeCurrency
+	}
+	if !domain.IsCryptoCurrency(currency) {
+		return false, fmt.Errorf("%w: %s", domain.ErrCurrencyNotPayable, currency)
+	}
+
+	if q := payment.Quote; q != nil && !chainChanged && q.Currency == currency && !q.Expired(time.Now()) {
+		return false, nil
+	}
+	if payment.TransactionID != "" {
+		return false, domain.ErrTransactionSubmitted
+	}
+
+	if s.oracle == nil {
+		return false, fmt.Errorf("%w: no price oracle configured", domain.ErrPriceUnavailable)
+	}
+
+	rate, err := s.oracle.Rate(ctx, currency, payment.Amount.Currency)
+	if err != nil {
+		return false, err
+	}
+
+	quote, err := domain.NewPriceQuote(payment.Amount, currency, rate, s.quotes.TTL, s.quotes.SlippageBps)
+	if err != nil {
+		return false, err
+	}
+
+	payment.Quote = quote
+	return true, nil
+}
@@ -0,0 +1,90 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/hsibAD/payment-service/internal/domain"
+	"github.com/hsibAD/payment-service/internal/infrastructure/pricing"
+	"github.com/hsibAD/payment-service/internal/repository/memory"
+	"github.com/hsibAD/payment-service/internal/usecase"
+)
+
+func newQuoteService(chain domain.MetaMaskProcessor, ethUSD int64) (*usecase.PaymentService, *memory.PaymentRepository) {
+	oracle := pricing.NewFakeOracle()
+	oracle.SetRate("ETH", "USD", big.NewRat(ethUSD, 1))
+
+	payments := memory.NewPaymentRepository()
+	service := usecase.NewPaymentService(
+		payments, memory.NewRefundRepository(), nil, chain,
+		noCache{}, noPublisher{}, noNotifier{}, oracle,
+		usecase.QuotePolicy{TTL: time.Minute, SlippageBps: 100},
+		usecase.WalletProof{},
+		memory.NewProcessedEventRepository(), usecase.RiskControls{},
+	)
+	return service, payments
+}
+
+func newUSDCryptoPayment(t *testing.T, service *usecase.PaymentService, minorUnits int64) *domain.Payment {
+	t.Helper()
+
+	p, err := service.InitiatePayment(context.Background(), usecase.InitiatePaymentInput{
+		OrderID: "order-1",
+		UserID:  "user-1",
+		Amount:  usd(minorUnits),
+		Method:  domain.PaymentMethodMetaMask,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestInitiateMetaMaskPaymentKeepsQuoteOfSubmittedTransaction(t *testing.T) {
+	service, payments := newQuoteService(&stubChain{verifyErr: domain.ErrTransactionNotMined}, 2000)
+	ctx := context.Background()
+	p := newUSDCryptoPayment(t, service, 100000)
+
+	info, err := service.InitiateMetaMaskPayment(ctx, p.ID, testWallet, "ETH", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.ConfirmMetaMaskPayment(ctx, p.ID, testTxHash, 0); !errors.Is(err, domain.ErrTransactionNotMined) {
+		t.Fatalf("ConfirmMetaMaskPayment error = %v, want %v", err, domain.ErrTransactionNotMined)
+	}
+
+	// The quote expires while the transaction is pending.
+	stored, err := payments.GetByID(ctx, p.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored.Quote.ExpiresAt = time.Now().Add(-time.Second)
+	if err := payments.Update(ctx, stored); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := service.InitiateMetaMaskPayment(ctx, p.ID, testWallet, "ETH", 0); !errors.Is(err, domain.ErrTransactionSubmitted) {
+		t.Fatalf("InitiateMetaMaskPayment error = %v, want %v", err, domain.ErrTransactionSubmitted)
+	}
+	stored, err = payments.GetByID(ctx, p.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Quote.Amount != info.Quote.Amount || stored.TransactionID != testTxHash {
+		t.Errorf("quote %v for transaction %q, want %v for %s", stored.Quote.Amount, stored.TransactionID, info.Quote.Amount, testTxHash)
+	}
+}
+
+func TestInitiateMetaMaskPaymentRejectsQuoteOverMinorUnits(t *testing.T) {
+	// 100,000.00 USD at 2,000 USD per ETH is 50 ETH, more wei than minor
+	// units hold.
+	service, _ := newQuoteService(&stubChain{}, 2000)
+	p := newUSDCryptoPayment(t, service, 10000000)
+
+	if _, err := service.InitiateMetaMaskPayment(context.Background(), p.ID, testWallet, "ETH", 0); !errors.Is(err, domain.ErrAmountOverflow) {
+		t.Errorf("error = %v, want %v", err, domain.ErrAmountOverflow)
+	}
+}
@@ -0,0 +1,228 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/hsibAD/payment-service/internal/domain"
+)
+
+const (
+	confirmRefundsBatchSize = 100
+
+	// settleRefundAttempts bounds how often a settled refund is applied to
+	// a payment that keeps changing underneath it.
+	settleRefundAttempts = 3
+)
+
+// RefundPayment refunds amount of a captured payment, or everything not yet
+// refunded when amount is nil. A refund the processor rejects is recorded as
+// failed and returned without an error, the same way failed payments are.
+// On-chain refunds stay pending until ConfirmRefunds sees them confirmed.
+// Concurrent refunds of a payment are serialized: of two refunds checked
+// against the same refunds, only one is sent.
+func (s *PaymentService) RefundPayment(ctx context.Context, paymentID string, amount *domain.Money, reason string) (*domain.Refund, error) {
+	if paymentID == "" {
+		return nil, domain.ErrInvalidPaymentID
+	}
+
+	payment, err := s.repo.GetByID(ctx, paymentID)
+	if err != nil {
+		return nil, err
+	}
+
+	refundFn, err := s.refundProcessor(payment.PaymentMethod)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.refunds.GetByPaymentID(ctx, payment.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load refunds: %w", err)
+	}
+
+	var refundAmount domain.Money
+	if amount != nil {
+		refundAmount = *amount
+	} else if refundAmount, err = payment.RemainingRefundable(existing); err != nil {
+		return nil, err
+	}
+
+	if err := payment.ValidateRefund(refundAmount, existing); err != nil {
+		return nil, err
+	}
+
+	refund, err := domain.NewRefund(payment.ID, refundAmount, reason)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.refunds.Create(ctx, refund); err != nil {
+		return nil, fmt.Errorf("failed to create refund: %w", err)
+	}
+
+	// Saving the payment fails if it was saved since it was loaded. A
+	// refund that saved it first had created its refund by then, and a
+	// refund that loads it afterwards sees this one among the existing
+	// refunds, so no two refunds pass the check above on the same totals.
+	payment.UpdatedAt = time.Now()
+	if err := s.save(ctx, payment); err != nil {
+		refund.Fail("payment changed while the refund was checked")
+		if updateErr := s.refunds.Update(ctx, refund); updateErr != nil {
+			log.Printf("failed to update refund %s: %v", refund.ID, updateErr)
+		}
+		return nil, err
+	}
+
+	if err := refundFn(ctx, payment, refund); err != nil {
+		if errors.Is(err, domain.ErrRefundSendUnknown) {
+			// The refund may be on its way. Its hash is saved, so
+			// ConfirmRefunds settles it once it is mined.
+			log.Printf("refund %s of payment %s may have been sent: %v", refund.ID, payment.ID, err)
+			return refund, nil
+		}
+		refund.Fail(err.Error())
+		if err := s.refunds.Update(ctx, refund); err != nil {
+			return nil, fmt.Errorf("failed to update refund: %w", err)
+		}
+		if errors.Is(err, domain.ErrRefundNotSupported) {
+			return nil, err
+		}
+		return refund, nil
+	}
+
+	// On-chain refunds were saved with their transaction before it was
+	// sent, and settle once it is confirmed.
+	if payment.PaymentMethod == domain.PaymentMethodMetaMask {
+		return refund, nil
+	}
+
+	return s.settleRefund(ctx, payment, refund)
+}
+
+// ConfirmRefunds settles on-chain refunds that have enough confirmations and
+// fails those whose transaction reverted. It returns how many refunds were
+// settled either way.
+func (s *PaymentService) ConfirmRefunds(ctx context.Context) (int, error) {
+	refunds, err := s.refunds.GetUnconfirmed(ctx, confirmRefundsBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	settled := 0
+	for _, refund := range refunds {
+		done, err := s.confirmRefund(ctx, refund)
+		if err != nil {
+			log.Printf("failed to confirm refund %s: %v", refund.ID, err)
+			continue
+		}
+		if done {
+			settled++
+		}
+	}
+
+	return settled, nil
+}
+
+func (s *PaymentService) confirmRefund(ctx context.Context, refund *domain.Refund) (bool, error) {
+	payment, err := s.repo.GetByID(ctx, refund.PaymentID)
+	if err != nil {
+		return false, err
+	}
+	if payment.PaymentMethod != domain.PaymentMethodMetaMask {
+		return false, nil
+	}
+
+	err = s.metaMask.VerifyRefund(ctx, payment, refund)
+	switch {
+	case errors.Is(err, domain.ErrInsufficientConfirmations):
+		return false, nil
+	case err != nil:
+		refund.Fail(err.Error())
+		if err := s.refunds.Update(ctx, refund); err != nil {
+			return false, fmt.Errorf("failed to update refund: %w", err)
+		}
+		return true, nil
+	}
+
+	if _, err := s.settleRefund(ctx, payment, refund); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// settleRefund marks a refund the processor completed as succeeded and
+// applies it to the payment. The money has moved by then, so a payment
+// that was changed concurrently is reloaded and the refund applied again.
+func (s *PaymentService) settleRefund(ctx context.Context, payment *domain.Payment, refund *domain.Refund) (*domain.Refund, error) {
+	refund.Succeed()
+	if err := s.refunds.Update(ctx, refund); err != nil {
+		return nil, fmt.Errorf("failed to update refund: %w", err)
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := payment.ApplyRefund(refund.Amount, refund.Reason)
+		if err == nil {
+			err = s.save(ctx, payment)
+		}
+		if err == nil {
+			break
+		}
+
+		if !errors.Is(err, domain.ErrPaymentConflict) || attempt == settleRefundAttempts {
+			return nil, err
+		}
+		if payment, err = s.repo.GetByID(ctx, payment.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	s.publish(ctx, "status updated", s.publisher.PublishPaymentStatusUpdated, payment)
+	s.announceRefund(ctx, payment, refund)
+
+	return refund, nil
+}
+
+// ListRefunds returns every refund of a payment, oldest first.
+func (s *PaymentService) ListRefunds(ctx context.Context, paymentID string) ([]*domain.Refund, error) {
+	if paymentID == "" {
+		return nil, domain.ErrInvalidPaymentID
+	}
+
+	if _, err := s.repo.GetByID(ctx, paymentID); err != nil {
+		return nil, err
+	}
+
+	return s.refunds.GetByPaymentID(ctx, paymentID)
+}
+
+// refundProcessor returns the function that refunds payments made with
+// method.
+func (s *PaymentService) refundProcessor(method domain.PaymentMethod) (func(context.Context, *domain.Payment, *domain.Refund) error, error) {
+	switch method {
+	case domain.PaymentMethodCreditCard:
+		return s.cardProc.RefundPayment, nil
+	case domain.PaymentMethodMetaMask:
+		return s.refundOnChain, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", domain.ErrRefundNotSupported, method)
+	}
+}
+
+// refundOnChain sends an on-chain refund, saving the refund with its
+// transaction hash before the transaction is broadcast.
+func (s *PaymentService) refundOnChain(ctx context.Context, payment *domain.Payment, refund *domain.Refund) error {
+	return s.metaMask.RefundTransaction(ctx, payment, refund, s.refunds.Update)
+}
+
+// announceRefund publishes the refund event and emails the customer.
+func (s *PaymentService) announceRefund(ctx context.Context, payment *domain.Payment, refund *domain.Refund) {
+	s.publish(ctx, "refunded", func(ctx context.Context, payment *domain.Payment) error {
+		return s.publisher.PublishPaymentRefunded(ctx, payment, refund)
+	}, payment)
+	s.notify(ctx, "refund", func(ctx context.Context, payment *domain.Payment) error {
+		return s.notifier.SendRefundConfirmation(ctx, payment, refund)
+	}, payment)
+}
@@ -0,0 +1,160 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/hsibAD/payment-service/internal/domain"
+	"github.com/hsibAD/payment-service/internal/infrastructure/payment"
+)
+
+// chargedPayment charges a new 25.00 USD payment.
+func (ct *cardTest) chargedPayment(t *testing.T) *domain.Payment {
+	t.Helper()
+
+	p := ct.newPayment(t)
+	got, err := ct.service.ProcessCreditCardPayment(context.Background(), p.ID, ct.cardToken(t, payment.FakeCardApproved))
+	if err != nil {
+		t.Fatalf("ProcessCreditCardPayment: %v", err)
+	}
+	if got.Status != domain.PaymentStatusCompleted {
+		t.Fatalf("status = %s, want %s", got.Status, domain.PaymentStatusCompleted)
+	}
+	return got
+}
+
+func TestRefundPayment(t *testing.T) {
+	ct := newCardTest(t)
+	p := ct.chargedPayment(t)
+	ctx := context.Background()
+
+	partial := usd(1000)
+	refund, err := ct.service.RefundPayment(ctx, p.ID, &partial, "damaged item")
+	if err != nil {
+		t.Fatalf("RefundPayment: %v", err)
+	}
+	if refund.Status != domain.RefundStatusSucceeded || refund.ProcessorRef == "" {
+		t.Errorf("refund = %+v, want a succeeded refund with a processor reference", refund)
+	}
+	stored := ct.stored(t, p.ID)
+	if stored.Status != domain.PaymentStatusPartiallyRefunded || stored.RefundedAmount != usd(1000) {
+		t.Errorf("payment is %s with %v refunded, want %s with %v", stored.Status, stored.RefundedAmount,
+			domain.PaymentStatusPartiallyRefunded, usd(1000))
+	}
+
+	// Without an amount the rest is refunded.
+	if _, err := ct.service.RefundPayment(ctx, p.ID, nil, "order cancelled"); err != nil {
+		t.Fatalf("RefundPayment: %v", err)
+	}
+	stored = ct.stored(t, p.ID)
+	if stored.Status != domain.PaymentStatusRefunded || stored.RefundedAmount != usd(2500) {
+		t.Errorf("payment is %s with %v refunded, want %s with %v", stored.Status, stored.RefundedAmount,
+			domain.PaymentStatusRefunded, usd(2500))
+	}
+
+	refunds, err := ct.service.ListRefunds(ctx, p.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(refunds) != 2 {
+		t.Errorf("got %d refunds, want 2", len(refunds))
+	}
+}
+
+func TestRefundPaymentRejectsMoreThanCaptured(t *testing.T) {
+	ct := newCardTest(t)
+	p := ct.chargedPayment(t)
+	ctx := context.Background()
+
+	first := usd(2000)
+	if _, err := ct.service.RefundPayment(ctx, p.ID, &first, "damaged item"); err != nil {
+		t.Fatal(err)
+	}
+
+	second := usd(1000)
+	_, err := ct.service.RefundPayment(ctx, p.ID, &second, "damaged item")
+	if !errors.Is(err, domain.ErrRefundExceedsCaptured) {
+		t.Fatalf("error = %v, want %v", err, domain.ErrRefundExceedsCaptured)
+	}
+	if stored := ct.stored(t, p.ID); stored.RefundedAmount != usd(2000) {
+		t.Errorf("refunded amount = %v, want %v", stored.RefundedAmount, usd(2000))
+	}
+}
+
+func TestRefundPaymentOfCapturedHold(t *testing.T) {
+	ct := newCardTest(t)
+	p := ct.authorizedPayment(t)
+	ctx := context.Background()
+
+	captured := usd(1800)
+	if _, err := ct.service.CapturePayment(ctx, p.ID, &captured); err != nil {
+		t.Fatal(err)
+	}
+
+	// Only what was captured can be refunded.
+	if _, err := ct.service.RefundPayment(ctx, p.ID, nil, "order cancelled"); err != nil {
+		t.Fatalf("RefundPayment: %v", err)
+	}
+	stored := ct.stored(t, p.ID)
+	if stored.Status != domain.PaymentStatusRefunded || stored.RefundedAmount != captured {
+		t.Errorf("payment is %s with %v refunded, want %s with %v", stored.Status, stored.RefundedAmount,
+			domain.PaymentStatusRefunded, captured)
+	}
+}
+
+func TestRefundPaymentRejectsUncapturedHold(t *testing.T) {
+	ct := newCardTest(t)
+	p := ct.authorizedPayment(t)
+
+	_, err := ct.service.RefundPayment(context.Background(), p.ID, nil, "order cancelled")
+	if !errors.Is(err, domain.ErrPaymentNotRefundable) {
+		t.Fatalf("error = %v, want %v", err, domain.ErrPaymentNotRefundable)
+	}
+}
+
+func TestRefundPaymentOnChain(t *testing.T) {
+	tests := []struct {
+		name      string
+		refundErr error
+		want      domain.RefundStatus
+	}{
+		{name: "sent", want: domain.RefundStatusPending},
+		// The refund may be on its way, so it must not be sent again.
+		{name: "send unknown", refundErr: domain.ErrRefundSendUnknown, want: domain.RefundStatusPending},
+		{name: "rejected", refundErr: errors.New("nonce too low"), want: domain.RefundStatusFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, _ := newChainService(&stubChain{refundErr: tt.refundErr})
+			p := newEthPayment(t, service, "order-1")
+			ctx := context.Background()
+			if _, err := service.ConfirmMetaMaskPayment(ctx, p.ID, testTxHash, 0); err != nil {
+				t.Fatal(err)
+			}
+
+			refund, err := service.RefundPayment(ctx, p.ID, nil, "damaged item")
+			if err != nil {
+				t.Fatalf("RefundPayment: %v", err)
+			}
+			if refund.Status != tt.want {
+				t.Errorf("refund status = %s, want %s", refund.Status, tt.want)
+			}
+
+			refunds, err := service.ListRefunds(ctx, p.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(refunds) != 1 || refunds[0].ProcessorRef != testRefundHash {
+				t.Fatalf("refunds = %+v, want one saved with its transaction hash", refunds)
+			}
+
+			again := domain.Money{MinorUnits: 1, Currency: "ETH"}
+			_, err = service.RefundPayment(ctx, p.ID, &again, "damaged item")
+			if tt.want == domain.RefundStatusPending && !errors.Is(err, domain.ErrRefundExceedsCaptured) {
+				t.Errorf("second RefundPayment: %v, want %v", err, domain.ErrRefundExceedsCaptured)
+			}
+		})
+	}
+}
@@ -0,0 +1,21 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/hsibAD/payment-service/internal/domain"
+)
+
+// NetworkFeeReport totals the network fees customers paid for crypto
+// payments verified in [from, to). A zero to means now.
+func (s *PaymentService) NetworkFeeReport(ctx context.Context, from, to time.Time) ([]*domain.NetworkFeeTotal, error) {
+	if to.IsZero() {
+		to = time.Now()
+	}
+	if !from.Before(to) {
+		return nil, domain.ErrInvalidReportPeriod
+	}
+
+	return s.repo.SumNetworkFees(ctx, from, to)
+}
@@ -0,0 +1,190 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/hsibAD/payment-service/internal/domain"
+)
+
+// RiskControls holds what risk checks on card payments need. Without an
+// Engine every card payment is allowed and without Velocity attempts are
+// not limited. Without Cards the checks only know the card token.
+type RiskControls struct {
+	Engine    domain.RiskEngine
+	Velocity  domain.VelocityLimiter
+	Cards     domain.CardInspector
+	Decisions domain.RiskDecisionRepository
+}
+
+// ReviewPayment approves or rejects a card payment held for manual review.
+// An approved payment is captured if it was made with
+// ProcessCreditCardPayment and stays authorized otherwise; a rejected one is
+// voided. The decision is recorded with the reviewer and note.
+func (s *PaymentService) ReviewPayment(ctx context.Context, paymentID string, approve bool, reviewer, note string) (*domain.Payment, error) {
+	if reviewer == "" {
+		return nil, domain.ErrInvalidReviewer
+	}
+
+	payment, err := s.repo.GetByID(ctx, paymentID)
+	if err != nil {
+		return nil, err
+	}
+	if payment.Status != domain.PaymentStatusManualReview {
+		return nil, domain.ErrPaymentNotInReview
+	}
+
+	record := &domain.RiskDecisionRecord{
+		PaymentID: payment.ID,
+		UserID:    payment.UserID,
+		Decision:  domain.RiskDecisionDeny,
+		Reviewer:  reviewer,
+		Note:      note,
+	}
+	if payment.Risk != nil {
+		record.Score = payment.Risk.Score
+		record.Rules = payment.Risk.Rules
+	}
+
+	switch {
+	case !approve:
+		payment, err = s.void(ctx, payment, "rejected in manual review")
+	case payment.CaptureOnApproval:
+		if err := s.cardProc.CapturePayment(ctx, payment, payment.AuthorizedAmount); err != nil {
+			return nil, fmt.Errorf("failed to capture payment: %w", err)
+		}
+		payment, err = s.complete(ctx, payment, "")
+	default:
+		if err := payment.TransitionTo(domain.PaymentStatusAuthorized, "approved in manual review"); err != nil {
+			return nil, err
+		}
+		err = s.saveAndAnnounce(ctx, payment)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if approve {
+		record.Decision = domain.RiskDecisionAllow
+	}
+	s.recordDecision(ctx, record)
+
+	return payment, nil
+}
+
+// GetRiskDecisions lists the risk decisions made on a payment, oldest
+// first.
+func (s *PaymentService) GetRiskDecisions(ctx context.Context, paymentID string) ([]*domain.RiskDecisionRecord, error) {
+	if paymentID == "" {
+		return nil, domain.ErrInvalidPaymentID
+	}
+	if _, err := s.repo.GetByID(ctx, paymentID); err != nil {
+		return nil, err
+	}
+	if s.risk.Decisions == nil {
+		return nil, nil
+	}
+
+	return s.risk.Decisions.GetByPaymentID(ctx, paymentID)
+}
+
+// screenAttempt applies the velocity limits to a card payment about to be
+// charged with cardToken, then scores it and records the assessment on the
+// payment. It returns the signals the attempt was screened on, or nil when
+// risk checks are off.
+func (s *PaymentService) screenAttempt(ctx context.Context, payment *domain.Payment, cardToken string) (*domain.RiskSignals, error) {
+	if s.risk.Engine == nil && s.risk.Velocity == nil {
+		return nil, nil
+	}
+
+	signals := &domain.RiskSignals{
+		Payment: payment,
+		CardKey: cardToken,
+		Client:  domain.ClientInfoFromContext(ctx),
+	}
+	if s.risk.Cards != nil {
+		if card, fingerprint, ok := s.risk.Cards.InspectCard(ctx, cardToken); ok {
+			signals.Card = &card
+			signals.CardKey = fingerprint
+		}
+	}
+
+	if s.risk.Velocity != nil {
+		if err := s.risk.Velocity.Check(ctx, *signals); err != nil {
+			return nil, err
+		}
+	}
+	if s.risk.Engine == nil {
+		return signals, nil
+	}
+
+	paid, err := s.repo.HasPaid(ctx, payment.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up payment history: %w", err)
+	}
+	signals.FirstOrder = !paid
+
+	assessment, err := s.risk.Engine.Assess(ctx, *signals)
+	if err != nil {
+		return nil, fmt.Errorf("failed to assess payment risk: %w", err)
+	}
+	payment.Risk = assessment
+
+	s.recordDecision(ctx, &domain.RiskDecisionRecord{
+		PaymentID: payment.ID,
+		UserID:    payment.UserID,
+		Decision:  assessment.Decision,
+		Score:     assessment.Score,
+		Rules:     assessment.Rules,
+		CreatedAt: assessment.AssessedAt,
+	})
+
+	return signals, nil
+}
+
+// recordAttempt feeds the outcome of a screened attempt, the error the
+// charge failed with, back to the risk checks.
+func (s *PaymentService) recordAttempt(ctx context.Context, signals *domain.RiskSignals, chargeErr error) {
+	if signals == nil {
+		return
+	}
+
+	failed := chargeErr != nil && !errors.Is(chargeErr, domain.ErrActionRequired)
+	if s.risk.Engine != nil {
+		if err := s.risk.Engine.Record(ctx, *signals, failed); err != nil {
+			log.Printf("failed to record risk attempt for payment %s: %v", signals.Payment.ID, err)
+		}
+	}
+	if s.risk.Velocity != nil {
+		if err := s.risk.Velocity.Record(ctx, *signals, domain.IsCardDecline(chargeErr)); err != nil {
+			log.Printf("failed to record velocity attempt for payment %s: %v", signals.Payment.ID, err)
+		}
+	}
+}
+
+func (s *PaymentService) recordDecision(ctx context.Context, record *domain.RiskDecisionRecord) {
+	if s.risk.Decisions == nil {
+		return
+	}
+	if record.CreatedAt.IsZero() {
+		record.CreatedAt = time.Now()
+	}
+	if err := s.risk.Decisions.Save(ctx, record); err != nil {
+		log.Printf("failed to record %s risk decision for payment %s: %v", record.Decision, record.PaymentID, err)
+	}
+}
+
+// holdForReview records the hold the processor placed on the card of a
+// flagged payment and parks the payment for a reviewer.
+func (s *PaymentService) holdForReview(ctx context.Context, payment *domain.Payment) (*domain.Payment, error) {
+	if err := payment.HoldForReview(payment.TransactionID); err != nil {
+		return nil, err
+	}
+	if err := s.saveAndAnnounce(ctx, payment); err != nil {
+		return nil, err
+	}
+	return payment, nil
+}
@@ -0,0 +1,189 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hsibAD/payment-service/internal/domain"
+)
+
+// walletChallengeWindow is the window MaxChallenges applies to.
+const walletChallengeWindow = time.Hour
+
+// WalletProof holds what the wallet ownership flow needs. With Required
+// set, crypto payments can only be initiated from a wallet the paying user
+// has verified.
+type WalletProof struct {
+	Challenges   domain.WalletChallengeStore
+	Wallets      domain.WalletRepository
+	Verifier     domain.OwnershipVerifier
+	ChallengeTTL time.Duration
+	Required     bool
+	// Issued counts the challenges issued per payment, of which a payment
+	// gets at most MaxChallenges an hour. Zero or a nil Issued leaves
+	// challenges unlimited.
+	Issued        domain.EventCounter
+	MaxChallenges int
+}
+
+// RequestWalletChallenge issues a nonce for proving control of
+// walletAddress and returns it with the payload the wallet signs. Payments
+// over their challenge limit get ErrTooManyChallenges.
+func (s *PaymentService) RequestWalletChallenge(ctx context.Context, paymentID, walletAddress string, scheme domain.SignatureScheme, chainID uint64) (*domain.WalletChallenge, string, error) {
+	if !scheme.IsValid() {
+		return nil, "", domain.ErrInvalidSignatureType
+	}
+	if !isHexAddress(walletAddress) {
+		return nil, "", domain.ErrInvalidWalletAddress
+	}
+
+	payment, err := s.pendingPayment(ctx, paymentID, domain.PaymentMethodMetaMask)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if chainID == 0 {
+		chainID = payment.ChainID
+	}
+	chain, err := s.metaMask.Chain(chainID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if err := s.limitChallenges(ctx, payment.ID); err != nil {
+		return nil, "", err
+	}
+
+	nonce, err := newNonce()
+	if err != nil {
+		return nil, "", err
+	}
+
+	challenge := &domain.WalletChallenge{
+		Nonce:         nonce,
+		Scheme:        scheme,
+		PaymentID:     payment.ID,
+		UserID:        payment.UserID,
+		WalletAddress: walletAddress,
+		ChainID:       chain.ID,
+		Amount:        payment.Amount,
+		ExpiresAt:     time.Now().Add(s.walletProof.ChallengeTTL),
+	}
+
+	payload, err := s.walletProof.Verifier.SigningPayload(challenge)
+	if err != nil {
+		return nil, "", err
+	}
+
+	ttl := int(s.walletProof.ChallengeTTL / time.Second)
+	if err := s.walletProof.Challenges.SaveChallenge(ctx, challenge, ttl); err != nil {
+		return nil, "", fmt.Errorf("failed to save wallet challenge: %w", err)
+	}
+
+	return challenge, payload, nil
+}
+
+// VerifyWalletOwnership checks the signature over a challenge, links the
+// wallet to the payment's user and records it on the payment. Only the
+// payment's user can answer its challenges, each once; a wrong signature
+// uses the challenge up.
+func (s *PaymentService) VerifyWalletOwnership(ctx context.Context, paymentID, nonce, signature string) (*domain.Payment, error) {
+	payment, err := s.pendingPayment(ctx, paymentID, domain.PaymentMethodMetaMask)
+	if err != nil {
+		return nil, err
+	}
+
+	challenge, err := s.walletProof.Challenges.TakeChallenge(ctx, nonce)
+	if err != nil {
+		return nil, err
+	}
+	if challenge.PaymentID != payment.ID || challenge.UserID != payment.UserID || time.Now().After(challenge.ExpiresAt) {
+		return nil, domain.ErrChallengeNotFound
+	}
+
+	signer, err := s.walletProof.Verifier.RecoverSigner(challenge, signature)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(signer, challenge.WalletAddress) {
+		return nil, domain.ErrInvalidSignature
+	}
+
+	err = s.walletProof.Wallets.Save(ctx, &domain.VerifiedWallet{
+		UserID:     payment.UserID,
+		Address:    signer,
+		PaymentID:  payment.ID,
+		Scheme:     challenge.Scheme,
+		VerifiedAt: time.Now(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to link wallet: %w", err)
+	}
+
+	payment.WalletAddress = signer
+	payment.UpdatedAt = time.Now()
+	if err := s.save(ctx, payment); err != nil {
+		return nil, err
+	}
+
+	return payment, nil
+}
+
+// limitChallenges counts a challenge against the payment, rejecting it once
+// the payment used up its challenges for the hour.
+func (s *PaymentService) limitChallenges(ctx context.Context, paymentID string) error {
+	if s.walletProof.Issued == nil || s.walletProof.MaxChallenges <= 0 {
+		return nil
+	}
+
+	key := "wallet_challenges:" + paymentID
+	issued, err := s.walletProof.Issued.CountEvents(ctx, key, walletChallengeWindow)
+	if err != nil {
+		return fmt.Errorf("failed to count wallet challenges: %w", err)
+	}
+	if issued >= s.walletProof.MaxChallenges {
+		return domain.ErrTooManyChallenges
+	}
+	if err := s.walletProof.Issued.RecordEvent(ctx, key, walletChallengeWindow); err != nil {
+		return fmt.Errorf("failed to count wallet challenges: %w", err)
+	}
+	return nil
+}
+
+// checkWalletOwnership rejects initiating a crypto payment from a wallet
+// the user has not verified, when proof is required.
+func (s *PaymentService) checkWalletOwnership(ctx context.Context, payment *domain.Payment, walletAddress string) error {
+	if !s.walletProof.Required || strings.EqualFold(payment.WalletAddress, walletAddress) {
+		return nil
+	}
+
+	verified, err := s.walletProof.Wallets.IsVerified(ctx, payment.UserID, walletAddress)
+	if err != nil {
+		return err
+	}
+	if !verified {
+		return domain.ErrWalletNotVerified
+	}
+	return nil
+}
+
+func newNonce() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func isHexAddress(s string) bool {
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "0x"), "0X")
+	if len(s) != 40 {
+		return false
+	}
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
@@ -0,0 +1,198 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hsibAD/payment-service/internal/domain"
+	"github.com/hsibAD/payment-service/internal/repository/memory"
+	"github.com/hsibAD/payment-service/internal/usecase"
+)
+
+const testWallet = "0x00000000000000000000000000000000000000a1"
+
+// memoryChallenges is an in-memory domain.WalletChallengeStore that ignores
+// TTLs.
+type memoryChallenges struct {
+	mu         sync.Mutex
+	challenges map[string]*domain.WalletChallenge
+}
+
+func (s *memoryChallenges) SaveChallenge(ctx context.Context, challenge *domain.WalletChallenge, ttl int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.challenges == nil {
+		s.challenges = make(map[string]*domain.WalletChallenge)
+	}
+	s.challenges[challenge.Nonce] = challenge
+	return nil
+}
+
+func (s *memoryChallenges) TakeChallenge(ctx context.Context, nonce string) (*domain.WalletChallenge, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	challenge, ok := s.challenges[nonce]
+	if !ok {
+		return nil, domain.ErrChallengeNotFound
+	}
+	delete(s.challenges, nonce)
+	return challenge, nil
+}
+
+// memoryWallets is an in-memory domain.WalletRepository.
+type memoryWallets struct {
+	mu      sync.Mutex
+	wallets []*domain.VerifiedWallet
+}
+
+func (r *memoryWallets) Save(ctx context.Context, wallet *domain.VerifiedWallet) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.wallets = append(r.wallets, wallet)
+	return nil
+}
+
+func (r *memoryWallets) IsVerified(ctx context.Context, userID, address string) (bool, error) {
+	wallets, _ := r.GetByUserID(ctx, userID)
+	for _, wallet := range wallets {
+		if wallet.Address == address {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *memoryWallets) GetByUserID(ctx context.Context, userID string) ([]*domain.VerifiedWallet, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var wallets []*domain.VerifiedWallet
+	for _, wallet := range r.wallets {
+		if wallet.UserID == userID {
+			wallets = append(wallets, wallet)
+		}
+	}
+	return wallets, nil
+}
+
+// echoVerifier treats a signature as made by the address it spells.
+type echoVerifier struct{}
+
+func (echoVerifier) SigningPayload(challenge *domain.WalletChallenge) (string, error) {
+	return "sign " + challenge.Nonce, nil
+}
+
+func (echoVerifier) RecoverSigner(challenge *domain.WalletChallenge, signature string) (string, error) {
+	return signature, nil
+}
+
+// eventCount counts events per key, never forgetting them.
+type eventCount struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func (c *eventCount) CountEvents(ctx context.Context, key string, window time.Duration) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.counts[key], nil
+}
+
+func (c *eventCount) RecordEvent(ctx context.Context, key string, retention time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.counts == nil {
+		c.counts = make(map[string]int)
+	}
+	c.counts[key]++
+	return nil
+}
+
+func (c *eventCount) RecordDistinctEvent(ctx context.Context, key, member string, retention time.Duration) error {
+	return c.RecordEvent(ctx, key, retention)
+}
+
+func newWalletService(maxChallenges int) *usecase.PaymentService {
+	return usecase.NewPaymentService(
+		memory.NewPaymentRepository(), memory.NewRefundRepository(), nil, &stubChain{},
+		noCache{}, noPublisher{}, noNotifier{}, nil,
+		usecase.QuotePolicy{},
+		usecase.WalletProof{
+			Challenges:    &memoryChallenges{},
+			Wallets:       &memoryWallets{},
+			Verifier:      echoVerifier{},
+			ChallengeTTL:  time.Minute,
+			Issued:        &eventCount{},
+			MaxChallenges: maxChallenges,
+		},
+		memory.NewProcessedEventRepository(), usecase.RiskControls{},
+	)
+}
+
+func customer(userID string) context.Context {
+	return domain.WithPrincipal(context.Background(), &domain.Principal{UserID: userID})
+}
+
+func TestVerifyWalletOwnershipOnlyByPaymentUser(t *testing.T) {
+	service := newWalletService(0)
+	p := newEthPayment(t, service, "order-1")
+
+	challenge, _, err := service.RequestWalletChallenge(customer("user-1"), p.ID, testWallet, domain.SignatureSchemeEIP191, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Another customer who learned the nonce can neither answer the
+	// challenge nor use it up.
+	if _, err := service.VerifyWalletOwnership(customer("user-2"), p.ID, challenge.Nonce, testWallet); !errors.Is(err, domain.ErrInvalidPaymentID) {
+		t.Fatalf("error = %v, want %v", err, domain.ErrInvalidPaymentID)
+	}
+
+	verified, err := service.VerifyWalletOwnership(customer("user-1"), p.ID, challenge.Nonce, testWallet)
+	if err != nil {
+		t.Fatalf("VerifyWalletOwnership: %v", err)
+	}
+	if verified.WalletAddress != testWallet {
+		t.Errorf("wallet = %q, want %s", verified.WalletAddress, testWallet)
+	}
+}
+
+func TestVerifyWalletOwnershipUsesChallengeUpOnWrongSignature(t *testing.T) {
+	service := newWalletService(0)
+	p := newEthPayment(t, service, "order-1")
+	ctx := customer("user-1")
+
+	challenge, _, err := service.RequestWalletChallenge(ctx, p.ID, testWallet, domain.SignatureSchemeEIP191, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	other := "0x00000000000000000000000000000000000000b2"
+	if _, err := service.VerifyWalletOwnership(ctx, p.ID, challenge.Nonce, other); !errors.Is(err, domain.ErrInvalidSignature) {
+		t.Fatalf("error = %v, want %v", err, domain.ErrInvalidSignature)
+	}
+	if _, err := service.VerifyWalletOwnership(ctx, p.ID, challenge.Nonce, testWallet); !errors.Is(err, domain.ErrChallengeNotFound) {
+		t.Errorf("error = %v, want %v", err, domain.ErrChallengeNotFound)
+	}
+}
+
+func TestRequestWalletChallengeIsLimitedPerPayment(t *testing.T) {
+	service := newWalletService(2)
+	p := newEthPayment(t, service, "order-1")
+	other := newEthPayment(t, service, "order-2")
+	ctx := customer("user-1")
+
+	for i := 0; i < 2; i++ {
+		if _, _, err := service.RequestWalletChallenge(ctx, p.ID, testWallet, domain.SignatureSchemeEIP712, 0); err != nil {
+			t.Fatalf("challenge %d: %v", i+1, err)
+		}
+	}
+	if _, _, err := service.RequestWalletChallenge(ctx, p.ID, testWallet, domain.SignatureSchemeEIP712, 0); !errors.Is(err, domain.ErrTooManyChallenges) {
+		t.Errorf("error = %v, want %v", err, domain.ErrTooManyChallenges)
+	}
+	if _, _, err := service.RequestWalletChallenge(ctx, other.ID, testWallet, domain.SignatureSchemeEIP712, 0); err != nil {
+		t.Errorf("challenge for another payment: %v", err)
+	}
+}
@@ -26,34 +26,58 @@ const (
 type PaymentStatus int32
 
 const (
-	PaymentStatus_PAYMENT_STATUS_UNSPECIFIED PaymentStatus = 0
-	PaymentStatus_PAYMENT_STATUS_PENDING     PaymentStatus = 1
-	PaymentStatus_PAYMENT_STATUS_PROCESSING  PaymentStatus = 2
-	PaymentStatus_PAYMENT_STATUS_COMPLETED   PaymentStatus = 3
-	PaymentStatus_PAYMENT_STATUS_FAILED      PaymentStatus = 4
-	PaymentStatus_PAYMENT_STATUS_CANCELLED   PaymentStatus = 5
-	PaymentStatus_PAYMENT_STATUS_REFUNDED    PaymentStatus = 6
+	PaymentStatus_PAYMENT_STATUS_UNSPECIFIED        PaymentStatus = 0
+	PaymentStatus_PAYMENT_STATUS_PENDING            PaymentStatus = 1
+	PaymentStatus_PAYMENT_STATUS_PROCESSING         PaymentStatus = 2
+	PaymentStatus_PAYMENT_STATUS_COMPLETED          PaymentStatus = 3
+	PaymentStatus_PAYMENT_STATUS_FAILED             PaymentStatus = 4
+	PaymentStatus_PAYMENT_STATUS_CANCELLED          PaymentStatus = 5
+	PaymentStatus_PAYMENT_STATUS_REFUNDED           PaymentStatus = 6
+	PaymentStatus_PAYMENT_STATUS_PARTIALLY_REFUNDED PaymentStatus = 7
+	PaymentStatus_PAYMENT_STATUS_AUTHORIZED         PaymentStatus = 8
+	PaymentStatus_PAYMENT_STATUS_CAPTURED           PaymentStatus = 9
+	PaymentStatus_PAYMENT_STATUS_VOIDED             PaymentStatus = 10
+	PaymentStatus_PAYMENT_STATUS_DISPUTED           PaymentStatus = 11
+	PaymentStatus_PAYMENT_STATUS_CHARGED_BACK       PaymentStatus = 12
+	PaymentStatus_PAYMENT_STATUS_REQUIRES_ACTION    PaymentStatus = 13
+	PaymentStatus_PAYMENT_STATUS_MANUAL_REVIEW      PaymentStatus = 14
 )
 
 // Enum value maps for PaymentStatus.
 var (
 	PaymentStatus_name = map[int32]string{
-		0: "PAYMENT_STATUS_UNSPECIFIED",
-		1: "PAYMENT_STATUS_PENDING",
-		2: "PAYMENT_STATUS_PROCESSING",
-		3: "PAYMENT_STATUS_COMPLETED",
-		4: "PAYMENT_STATUS_FAILED",
-		5: "PAYMENT_STATUS_CANCELLED",
-		6: "PAYMENT_STATUS_REFUNDED",
+		0:  "PAYMENT_STATUS_UNSPECIFIED",
+		1:  "PAYMENT_STATUS_PENDING",
+		2:  "PAYMENT_STATUS_PROCESSING",
+		3:  "PAYMENT_STATUS_COMPLETED",
+		4:  "PAYMENT_STATUS_FAILED",
+		5:  "PAYMENT_STATUS_CANCELLED",
+		6:  "PAYMENT_STATUS_REFUNDED",
+		7:  "PAYMENT_STATUS_PARTIALLY_REFUNDED",
+		8:  "PAYMENT_STATUS_AUTHORIZED",
+		9:  "PAYMENT_STATUS_CAPTURED",
+		10: "PAYMENT_STATUS_VOIDED",
+		11: "PAYMENT_STATUS_DISPUTED",
+		12: "PAYMENT_STATUS_CHARGED_BACK",
+		13: "PAYMENT_STATUS_REQUIRES_ACTION",
+		14: "PAYMENT_STATUS_MANUAL_REVIEW",
 	}
 	PaymentStatus_value = map[string]int32{
-		"PAYMENT_STATUS_UNSPECIFIED": 0,
-		"PAYMENT_STATUS_PENDING":     1,
-		"PAYMENT_STATUS_PROCESSING":  2,
-		"PAYMENT_STATUS_COMPLETED":   3,
-		"PAYMENT_STATUS_FAILED":      4,
-		"PAYMENT_STATUS_CANCELLED":   5,
-		"PAYMENT_STATUS_REFUNDED":    6,
+		"PAYMENT_STATUS_UNSPECIFIED":        0,
+		"PAYMENT_STATUS_PENDING":            1,
+		"PAYMENT_STATUS_PROCESSING":         2,
+		"PAYMENT_STATUS_COMPLETED":          3,
+		"PAYMENT_STATUS_FAILED":             4,
+		"PAYMENT_STATUS_CANCELLED":          5,
+		"PAYMENT_STATUS_REFUNDED":           6,
+		"PAYMENT_STATUS_PARTIALLY_REFUNDED": 7,
+		"PAYMENT_STATUS_AUTHORIZED":         8,
+		"PAYMENT_STATUS_CAPTURED":           9,
+		"PAYMENT_STATUS_VOIDED":             10,
+		"PAYMENT_STATUS_DISPUTED":           11,
+		"PAYMENT_STATUS_CHARGED_BACK":       12,
+		"PAYMENT_STATUS_REQUIRES_ACTION":    13,
+		"PAYMENT_STATUS_MANUAL_REVIEW":      14,
 	}
 )
 
@@ -133,38 +157,1774 @@ func (PaymentMethod) EnumDescriptor() ([]byte, []int) {
 	return file_payment_service_proto_payment_proto_rawDescGZIP(), []int{1}
 }
 
+type NextActionType int32
+
+const (
+	NextActionType_NEXT_ACTION_TYPE_UNSPECIFIED     NextActionType = 0
+	NextActionType_NEXT_ACTION_TYPE_REDIRECT_TO_URL NextActionType = 1
+	NextActionType_NEXT_ACTION_TYPE_USE_SDK         NextActionType = 2
+)
+
+// Enum value maps for NextActionType.
+var (
+	NextActionType_name = map[int32]string{
+		0: "NEXT_ACTION_TYPE_UNSPECIFIED",
+		1: "NEXT_ACTION_TYPE_REDIRECT_TO_URL",
+		2: "NEXT_ACTION_TYPE_USE_SDK",
+	}
+	NextActionType_value = map[string]int32{
+		"NEXT_ACTION_TYPE_UNSPECIFIED":     0,
+		"NEXT_ACTION_TYPE_REDIRECT_TO_URL": 1,
+		"NEXT_ACTION_TYPE_USE_SDK":         2,
+	}
+)
+
+func (x NextActionType) Enum() *NextActionType {
+	p := new(NextActionType)
+	*p = x
+	return p
+}
+
+func (x NextActionType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (NextActionType) Descriptor() protoreflect.EnumDescriptor {
+	return file_payment_service_proto_payment_proto_enumTypes[2].Descriptor()
+}
+
+func (NextActionType) Type() protoreflect.EnumType {
+	return &file_payment_service_proto_payment_proto_enumTypes[2]
+}
+
+func (x NextActionType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use NextActionType.Descriptor instead.
+func (NextActionType) EnumDescriptor() ([]byte, []int) {
+	return file_payment_service_proto_payment_proto_rawDescGZIP(), []int{2}
+}
+
+type QRCodeFormat int32
+
+const (
+	QRCodeFormat_QR_CODE_FORMAT_UNSPECIFIED QRCodeFormat = 0
+	QRCodeFormat_QR_CODE_FORMAT_PNG         QRCodeFormat = 1
+	QRCodeFormat_QR_CODE_FORMAT_SVG         QRCodeFormat = 2
+)
+
+// Enum value maps for QRCodeFormat.
+var (
+	QRCodeFormat_name = map[int32]string{
+		0: "QR_CODE_FORMAT_UNSPECIFIED",
+		1: "QR_CODE_FORMAT_PNG",
+		2: "QR_CODE_FORMAT_SVG",
+	}
+	QRCodeFormat_value = map[string]int32{
+		"QR_CODE_FORMAT_UNSPECIFIED": 0,
+		"QR_CODE_FORMAT_PNG":         1,
+		"QR_CODE_FORMAT_SVG":         2,
+	}
+)
+
+func (x QRCodeFormat) Enum() *QRCodeFormat {
+	p := new(QRCodeFormat)
+	*p = x
+	return p
+}
+
+func (x QRCodeFormat) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (QRCodeFormat) Descriptor() protoreflect.EnumDescriptor {
+	return file_payment_service_proto_payment_proto_enumTypes[3].Descriptor()
+}
+
+func (QRCodeFormat) Type() protoreflect.EnumType {
+	return &file_payment_service_proto_payment_proto_enumTypes[3]
+}
+
+func (x QRCodeFormat) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use QRCodeFormat.Descriptor instead.
+func (QRCodeFormat) EnumDescriptor() ([]byte, []int) {
+	return file_payment_service_proto_payment_proto_rawDescGZIP(), []int{3}
+}
+
+type SignatureScheme int32
+
+const (
+	SignatureScheme_SIGNATURE_SCHEME_UNSPECIFIED SignatureScheme = 0
+	SignatureScheme_SIGNATURE_SCHEME_EIP712      SignatureScheme = 1
+	SignatureScheme_SIGNATURE_SCHEME_EIP191      SignatureScheme = 2
+)
+
+// Enum value maps for SignatureScheme.
+var (
+	SignatureScheme_name = map[int32]string{
+		0: "SIGNATURE_SCHEME_UNSPECIFIED",
+		1: "SIGNATURE_SCHEME_EIP712",
+		2: "SIGNATURE_SCHEME_EIP191",
+	}
+	SignatureScheme_value = map[string]int32{
+		"SIGNATURE_SCHEME_UNSPECIFIED": 0,
+		"SIGNATURE_SCHEME_EIP712":      1,
+		"SIGNATURE_SCHEME_EIP191":      2,
+	}
+)
+
+func (x SignatureScheme) Enum() *SignatureScheme {
+	p := new(SignatureScheme)
+	*p = x
+	return p
+}
+
+func (x SignatureScheme) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (SignatureScheme) Descriptor() protoreflect.EnumDescriptor {
+	return file_payment_service_proto_payment_proto_enumTypes[4].Descriptor()
+}
+
+func (SignatureScheme) Type() protoreflect.EnumType {
+	return &file_payment_service_proto_payment_proto_enumTypes[4]
+}
+
+func (x SignatureScheme) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use SignatureScheme.Descriptor instead.
+func (SignatureScheme) EnumDescriptor() ([]byte, []int) {
+	return file_payment_service_proto_payment_proto_rawDescGZIP(), []int{4}
+}
+
+type RiskDecision int32
+
+const (
+	RiskDecision_RISK_DECISION_UNSPECIFIED RiskDecision = 0
+	RiskDecision_RISK_DECISION_ALLOW       RiskDecision = 1
+	RiskDecision_RISK_DECISION_REVIEW      RiskDecision = 2
+	RiskDecision_RISK_DECISION_DENY        RiskDecision = 3
+)
+
+// Enum value maps for RiskDecision.
+var (
+	RiskDecision_name = map[int32]string{
+		0: "RISK_DECISION_UNSPECIFIED",
+		1: "RISK_DECISION_ALLOW",
+		2: "RISK_DECISION_REVIEW",
+		3: "RISK_DECISION_DENY",
+	}
+	RiskDecision_value = map[string]int32{
+		"RISK_DECISION_UNSPECIFIED": 0,
+		"RISK_DECISION_ALLOW":       1,
+		"RISK_DECISION_REVIEW":      2,
+		"RISK_DECISION_DENY":        3,
+	}
+)
+
+func (x RiskDecision) Enum() *RiskDecision {
+	p := new(RiskDecision)
+	*p = x
+	return p
+}
+
+func (x RiskDecision) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (RiskDecision) Descriptor() protoreflect.EnumDescriptor {
+	return file_payment_service_proto_payment_proto_enumTypes[5].Descriptor()
+}
+
+func (RiskDecision) Type() protoreflect.EnumType {
+	return &file_payment_service_proto_payment_proto_enumTypes[5]
+}
+
+func (x RiskDecision) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use RiskDecision.Descriptor instead.
+func (RiskDecision) EnumDescriptor() ([]byte, []int) {
+	return file_payment_service_proto_payment_proto_rawDescGZIP(), []int{5}
+}
+
+type RefundStatus int32
+
+const (
+	RefundStatus_REFUND_STATUS_UNSPECIFIED RefundStatus = 0
+	RefundStatus_REFUND_STATUS_PENDING     RefundStatus = 1
+	RefundStatus_REFUND_STATUS_SUCCEEDED   RefundStatus = 2
+	RefundStatus_REFUND_STATUS_FAILED      RefundStatus = 3
+)
+
+// Enum value maps for RefundStatus.
+var (
+	RefundStatus_name = map[int32]string{
+		0: "REFUND_STATUS_UNSPECIFIED",
+		1: "REFUND_STATUS_PENDING",
+		2: "REFUND_STATUS_SUCCEEDED",
+		3: "REFUND_STATUS_FAILED",
+	}
+	RefundStatus_value = map[string]int32{
+		"REFUND_STATUS_UNSPECIFIED": 0,
+		"REFUND_STATUS_PENDING":     1,
+		"REFUND_STATUS_SUCCEEDED":   2,
+		"REFUND_STATUS_FAILED":      3,
+	}
+)
+
+func (x RefundStatus) Enum() *RefundStatus {
+	p := new(RefundStatus)
+	*p = x
+	return p
+}
+
+func (x RefundStatus) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (RefundStatus) Descriptor() protoreflect.EnumDescriptor {
+	return file_payment_service_proto_payment_proto_enumTypes[6].Descriptor()
+}
+
+func (RefundStatus) Type() protoreflect.EnumType {
+	return &file_payment_service_proto_payment_proto_enumTypes[6]
+}
+
+func (x RefundStatus) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use RefundStatus.Descriptor instead.
+func (RefundStatus) EnumDescriptor() ([]byte, []int) {
+	return file_payment_service_proto_payment_proto_rawDescGZIP(), []int{6}
+}
+
+// Money is an exact amount in the currency's minor unit (cents, wei, ...).
+type Money struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	MinorUnits int64                  `protobuf:"varint,1,opt,name=minor_units,json=minorUnits,proto3" json:"minor_units,omitempty"`
+	// ISO 4217 code, or a crypto ticker such as ETH.
+	Currency string `protobuf:"bytes,2,opt,name=currency,proto3" json:"currency,omitempty"`
+	// Number of decimals of the minor unit. Output only.
+	Exponent      int32 `protobuf:"varint,3,opt,name=exponent,proto3" json:"exponent,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Money) Reset() {
+	*x = Money{}
+	mi := &file_payment_service_proto_payment_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Money) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Money) ProtoMessage() {}
+
+func (x *Money) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_service_proto_payment_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Money.ProtoReflect.Descriptor instead.
+func (*Money) Descriptor() ([]byte, []int) {
+	return file_payment_service_proto_payment_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Money) GetMinorUnits() int64 {
+	if x != nil {
+		return x.MinorUnits
+	}
+	return 0
+}
+
+func (x *Money) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *Money) GetExponent() int32 {
+	if x != nil {
+		return x.Exponent
+	}
+	return 0
+}
+
 type Payment struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Id      string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	OrderId string                 `protobuf:"bytes,2,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	UserId  string                 `protobuf:"bytes,3,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	// Deprecated: use money.
+	//
+	// Deprecated: Marked as deprecated in payment-service/proto/payment.proto.
+	Amount           float64                `protobuf:"fixed64,4,opt,name=amount,proto3" json:"amount,omitempty"`
+	Currency         string                 `protobuf:"bytes,5,opt,name=currency,proto3" json:"currency,omitempty"`
+	Status           PaymentStatus          `protobuf:"varint,6,opt,name=status,proto3,enum=payment.PaymentStatus" json:"status,omitempty"`
+	PaymentMethod    PaymentMethod          `protobuf:"varint,7,opt,name=payment_method,json=paymentMethod,proto3,enum=payment.PaymentMethod" json:"payment_method,omitempty"`
+	TransactionId    string                 `protobuf:"bytes,8,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
+	ErrorMessage     string                 `protobuf:"bytes,9,opt,name=error_message,json=errorMessage,proto3" json:"error_message,omitempty"`
+	CreatedAt        *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt        *timestamppb.Timestamp `protobuf:"bytes,11,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	Money            *Money                 `protobuf:"bytes,12,opt,name=money,proto3" json:"money,omitempty"`
+	History          []*StatusChange        `protobuf:"bytes,13,rep,name=history,proto3" json:"history,omitempty"`
+	AuthorizedAmount *Money                 `protobuf:"bytes,14,opt,name=authorized_amount,json=authorizedAmount,proto3" json:"authorized_amount,omitempty"`
+	CapturedAmount   *Money                 `protobuf:"bytes,15,opt,name=captured_amount,json=capturedAmount,proto3" json:"captured_amount,omitempty"`
+	Card             *CardSummary           `protobuf:"bytes,16,opt,name=card,proto3" json:"card,omitempty"`
+	// Sum of successful refunds.
+	RefundedAmount *Money `protobuf:"bytes,17,opt,name=refunded_amount,json=refundedAmount,proto3" json:"refunded_amount,omitempty"`
+	// Set on fiat payments paid in crypto.
+	Quote *PriceQuote `protobuf:"bytes,18,opt,name=quote,proto3" json:"quote,omitempty"`
+	// Chain a crypto payment is paid on.
+	ChainId uint64 `protobuf:"varint,19,opt,name=chain_id,json=chainId,proto3" json:"chain_id,omitempty"`
+	// Wallet the customer proved they control.
+	WalletAddress string `protobuf:"bytes,20,opt,name=wallet_address,json=walletAddress,proto3" json:"wallet_address,omitempty"`
+	// Gas paid for the transaction of a crypto payment.
+	NetworkFee *NetworkFee `protobuf:"bytes,21,opt,name=network_fee,json=networkFee,proto3" json:"network_fee,omitempty"`
+	// How the customer authenticates a payment that requires action.
+	NextAction *NextAction `protobuf:"bytes,22,opt,name=next_action,json=nextAction,proto3" json:"next_action,omitempty"`
+	// Card provider that handled a card payment.
+	CardProvider  string `protobuf:"bytes,23,opt,name=card_provider,json=cardProvider,proto3" json:"card_provider,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Payment) Reset() {
+	*x = Payment{}
+	mi := &file_payment_service_proto_payment_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Payment) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Payment) ProtoMessage() {}
+
+func (x *Payment) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_service_proto_payment_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Payment.ProtoReflect.Descriptor instead.
+func (*Payment) Descriptor() ([]byte, []int) {
+	return file_payment_service_proto_payment_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Payment) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Payment) GetOrderId() string {
+	if x != nil {
+		return x.OrderId
+	}
+	return ""
+}
+
+func (x *Payment) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+// Deprecated: Marked as deprecated in payment-service/proto/payment.proto.
+func (x *Payment) GetAmount() float64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *Payment) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *Payment) GetStatus() PaymentStatus {
+	if x != nil {
+		return x.Status
+	}
+	return PaymentStatus_PAYMENT_STATUS_UNSPECIFIED
+}
+
+func (x *Payment) GetPaymentMethod() PaymentMethod {
+	if x != nil {
+		return x.PaymentMethod
+	}
+	return PaymentMethod_PAYMENT_METHOD_UNSPECIFIED
+}
+
+func (x *Payment) GetTransactionId() string {
+	if x != nil {
+		return x.TransactionId
+	}
+	return ""
+}
+
+func (x *Payment) GetErrorMessage() string {
+	if x != nil {
+		return x.ErrorMessage
+	}
+	return ""
+}
+
+func (x *Payment) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Payment) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+func (x *Payment) GetMoney() *Money {
+	if x != nil {
+		return x.Money
+	}
+	return nil
+}
+
+func (x *Payment) GetHistory() []*StatusChange {
+	if x != nil {
+		return x.History
+	}
+	return nil
+}
+
+func (x *Payment) GetAuthorizedAmount() *Money {
+	if x != nil {
+		return x.AuthorizedAmount
+	}
+	return nil
+}
+
+func (x *Payment) GetCapturedAmount() *Money {
+	if x != nil {
+		return x.CapturedAmount
+	}
+	return nil
+}
+
+func (x *Payment) GetCard() *CardSummary {
+	if x != nil {
+		return x.Card
+	}
+	return nil
+}
+
+func (x *Payment) GetRefundedAmount() *Money {
+	if x != nil {
+		return x.RefundedAmount
+	}
+	return nil
+}
+
+func (x *Payment) GetQuote() *PriceQuote {
+	if x != nil {
+		return x.Quote
+	}
+	return nil
+}
+
+func (x *Payment) GetChainId() uint64 {
+	if x != nil {
+		return x.ChainId
+	}
+	return 0
+}
+
+func (x *Payment) GetWalletAddress() string {
+	if x != nil {
+		return x.WalletAddress
+	}
+	return ""
+}
+
+func (x *Payment) GetNetworkFee() *NetworkFee {
+	if x != nil {
+		return x.NetworkFee
+	}
+	return nil
+}
+
+func (x *Payment) GetNextAction() *NextAction {
+	if x != nil {
+		return x.NextAction
+	}
+	return nil
+}
+
+func (x *Payment) GetCardProvider() string {
+	if x != nil {
+		return x.CardProvider
+	}
+	return ""
+}
+
+type NextAction struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Type  NextActionType         `protobuf:"varint,1,opt,name=type,proto3,enum=payment.NextActionType" json:"type,omitempty"`
+	// Set for NEXT_ACTION_TYPE_REDIRECT_TO_URL.
+	RedirectUrl string `protobuf:"bytes,2,opt,name=redirect_url,json=redirectUrl,proto3" json:"redirect_url,omitempty"`
+	// Client secret of the PaymentIntent, for handleNextAction in Stripe.js.
+	ClientSecret  string `protobuf:"bytes,3,opt,name=client_secret,json=clientSecret,proto3" json:"client_secret,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NextAction) Reset() {
+	*x = NextAction{}
+	mi := &file_payment_service_proto_payment_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NextAction) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NextAction) ProtoMessage() {}
+
+func (x *NextAction) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_service_proto_payment_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NextAction.ProtoReflect.Descriptor instead.
+func (*NextAction) Descriptor() ([]byte, []int) {
+	return file_payment_service_proto_payment_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *NextAction) GetType() NextActionType {
+	if x != nil {
+		return x.Type
+	}
+	return NextActionType_NEXT_ACTION_TYPE_UNSPECIFIED
+}
+
+func (x *NextAction) GetRedirectUrl() string {
+	if x != nil {
+		return x.RedirectUrl
+	}
+	return ""
+}
+
+func (x *NextAction) GetClientSecret() string {
+	if x != nil {
+		return x.ClientSecret
+	}
+	return ""
+}
+
+type NetworkFee struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	ChainId uint64                 `protobuf:"varint,1,opt,name=chain_id,json=chainId,proto3" json:"chain_id,omitempty"`
+	GasUsed uint64                 `protobuf:"varint,2,opt,name=gas_used,json=gasUsed,proto3" json:"gas_used,omitempty"`
+	// Price paid per gas, in wei.
+	EffectiveGasPrice string `protobuf:"bytes,3,opt,name=effective_gas_price,json=effectiveGasPrice,proto3" json:"effective_gas_price,omitempty"`
+	// Total fee in the chain's native currency.
+	Amount        *Money `protobuf:"bytes,4,opt,name=amount,proto3" json:"amount,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NetworkFee) Reset() {
+	*x = NetworkFee{}
+	mi := &file_payment_service_proto_payment_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NetworkFee) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NetworkFee) ProtoMessage() {}
+
+func (x *NetworkFee) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_service_proto_payment_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NetworkFee.ProtoReflect.Descriptor instead.
+func (*NetworkFee) Descriptor() ([]byte, []int) {
+	return file_payment_service_proto_payment_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *NetworkFee) GetChainId() uint64 {
+	if x != nil {
+		return x.ChainId
+	}
+	return 0
+}
+
+func (x *NetworkFee) GetGasUsed() uint64 {
+	if x != nil {
+		return x.GasUsed
+	}
+	return 0
+}
+
+func (x *NetworkFee) GetEffectiveGasPrice() string {
+	if x != nil {
+		return x.EffectiveGasPrice
+	}
+	return ""
+}
+
+func (x *NetworkFee) GetAmount() *Money {
+	if x != nil {
+		return x.Amount
+	}
+	return nil
+}
+
+// PriceQuote locks the crypto amount a fiat payment is paid with.
+type PriceQuote struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Crypto currency paid on chain.
+	Currency string `protobuf:"bytes,1,opt,name=currency,proto3" json:"currency,omitempty"`
+	// Price of one whole unit of currency in the payment currency.
+	Rate   string `protobuf:"bytes,2,opt,name=rate,proto3" json:"rate,omitempty"`
+	Amount *Money `protobuf:"bytes,3,opt,name=amount,proto3" json:"amount,omitempty"`
+	// Payments up to this many basis points below amount are accepted.
+	SlippageBps   int32                  `protobuf:"varint,4,opt,name=slippage_bps,json=slippageBps,proto3" json:"slippage_bps,omitempty"`
+	ExpiresAt     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PriceQuote) Reset() {
+	*x = PriceQuote{}
+	mi := &file_payment_service_proto_payment_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PriceQuote) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PriceQuote) ProtoMessage() {}
+
+func (x *PriceQuote) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_service_proto_payment_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PriceQuote.ProtoReflect.Descriptor instead.
+func (*PriceQuote) Descriptor() ([]byte, []int) {
+	return file_payment_service_proto_payment_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *PriceQuote) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *PriceQuote) GetRate() string {
+	if x != nil {
+		return x.Rate
+	}
+	return ""
+}
+
+func (x *PriceQuote) GetAmount() *Money {
+	if x != nil {
+		return x.Amount
+	}
+	return nil
+}
+
+func (x *PriceQuote) GetSlippageBps() int32 {
+	if x != nil {
+		return x.SlippageBps
+	}
+	return 0
+}
+
+func (x *PriceQuote) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+func (x *PriceQuote) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+type StatusChange struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	From          PaymentStatus          `protobuf:"varint,1,opt,name=from,proto3,enum=payment.PaymentStatus" json:"from,omitempty"`
+	To            PaymentStatus          `protobuf:"varint,2,opt,name=to,proto3,enum=payment.PaymentStatus" json:"to,omitempty"`
+	Reason        string                 `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	At            *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=at,proto3" json:"at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StatusChange) Reset() {
+	*x = StatusChange{}
+	mi := &file_payment_service_proto_payment_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StatusChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StatusChange) ProtoMessage() {}
+
+func (x *StatusChange) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_service_proto_payment_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StatusChange.ProtoReflect.Descriptor instead.
+func (*StatusChange) Descriptor() ([]byte, []int) {
+	return file_payment_service_proto_payment_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *StatusChange) GetFrom() PaymentStatus {
+	if x != nil {
+		return x.From
+	}
+	return PaymentStatus_PAYMENT_STATUS_UNSPECIFIED
+}
+
+func (x *StatusChange) GetTo() PaymentStatus {
+	if x != nil {
+		return x.To
+	}
+	return PaymentStatus_PAYMENT_STATUS_UNSPECIFIED
+}
+
+func (x *StatusChange) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *StatusChange) GetAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.At
+	}
+	return nil
+}
+
+type InitiatePaymentRequest struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	OrderId string                 `protobuf:"bytes,1,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	UserId  string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	// Deprecated: use money. Only read when money is not set.
+	//
+	// Deprecated: Marked as deprecated in payment-service/proto/payment.proto.
+	Amount        float64       `protobuf:"fixed64,3,opt,name=amount,proto3" json:"amount,omitempty"`
+	Currency      string        `protobuf:"bytes,4,opt,name=currency,proto3" json:"currency,omitempty"`
+	PaymentMethod PaymentMethod `protobuf:"varint,5,opt,name=payment_method,json=paymentMethod,proto3,enum=payment.PaymentMethod" json:"payment_method,omitempty"`
+	CustomerEmail string        `protobuf:"bytes,6,opt,name=customer_email,json=customerEmail,proto3" json:"customer_email,omitempty"`
+	Money         *Money        `protobuf:"bytes,7,opt,name=money,proto3" json:"money,omitempty"`
+	// Optional client generated key. Replays with the same key and request
+	// return the original response; a different request fails with
+	// ALREADY_EXISTS. Failures that may have moved money, such as a
+	// processor timeout, are replayed too; check the payment before
+	// retrying with a new key.
+	IdempotencyKey string `protobuf:"bytes,8,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *InitiatePaymentRequest) Reset() {
+	*x = InitiatePaymentRequest{}
+	mi := &file_payment_service_proto_payment_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InitiatePaymentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InitiatePaymentRequest) ProtoMessage() {}
+
+func (x *InitiatePaymentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_service_proto_payment_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InitiatePaymentRequest.ProtoReflect.Descriptor instead.
+func (*InitiatePaymentRequest) Descriptor() ([]byte, []int) {
+	return file_payment_service_proto_payment_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *InitiatePaymentRequest) GetOrderId() string {
+	if x != nil {
+		return x.OrderId
+	}
+	return ""
+}
+
+func (x *InitiatePaymentRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+// Deprecated: Marked as deprecated in payment-service/proto/payment.proto.
+func (x *InitiatePaymentRequest) GetAmount() float64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *InitiatePaymentRequest) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *InitiatePaymentRequest) GetPaymentMethod() PaymentMethod {
+	if x != nil {
+		return x.PaymentMethod
+	}
+	return PaymentMethod_PAYMENT_METHOD_UNSPECIFIED
+}
+
+func (x *InitiatePaymentRequest) GetCustomerEmail() string {
+	if x != nil {
+		return x.CustomerEmail
+	}
+	return ""
+}
+
+func (x *InitiatePaymentRequest) GetMoney() *Money {
+	if x != nil {
+		return x.Money
+	}
+	return nil
+}
+
+func (x *InitiatePaymentRequest) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
+type CreditCardPaymentRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	PaymentId string                 `protobuf:"bytes,1,opt,name=payment_id,json=paymentId,proto3" json:"payment_id,omitempty"`
+	// See InitiatePaymentRequest.idempotency_key.
+	IdempotencyKey string `protobuf:"bytes,3,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+	// A Stripe PaymentMethod ID (pm_...) or a card vault token.
+	PaymentMethodToken string `protobuf:"bytes,4,opt,name=payment_method_token,json=paymentMethodToken,proto3" json:"payment_method_token,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *CreditCardPaymentRequest) Reset() {
+	*x = CreditCardPaymentRequest{}
+	mi := &file_payment_service_proto_payment_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreditCardPaymentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreditCardPaymentRequest) ProtoMessage() {}
+
+func (x *CreditCardPaymentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_service_proto_payment_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreditCardPaymentRequest.ProtoReflect.Descriptor instead.
+func (*CreditCardPaymentRequest) Descriptor() ([]byte, []int) {
+	return file_payment_service_proto_payment_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *CreditCardPaymentRequest) GetPaymentId() string {
+	if x != nil {
+		return x.PaymentId
+	}
+	return ""
+}
+
+func (x *CreditCardPaymentRequest) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
+func (x *CreditCardPaymentRequest) GetPaymentMethodToken() string {
+	if x != nil {
+		return x.PaymentMethodToken
+	}
+	return ""
+}
+
+// CardSummary is the masked card a payment was made with.
+type CardSummary struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Brand string                 `protobuf:"bytes,1,opt,name=brand,proto3" json:"brand,omitempty"`
+	Last4 string                 `protobuf:"bytes,2,opt,name=last4,proto3" json:"last4,omitempty"`
+	// "credit", "debit", "prepaid" or "unknown".
+	Funding string `protobuf:"bytes,3,opt,name=funding,proto3" json:"funding,omitempty"`
+	// ISO 3166 alpha-2 code of the issuing country, when known.
+	Country       string `protobuf:"bytes,4,opt,name=country,proto3" json:"country,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CardSummary) Reset() {
+	*x = CardSummary{}
+	mi := &file_payment_service_proto_payment_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CardSummary) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CardSummary) ProtoMessage() {}
+
+func (x *CardSummary) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_service_proto_payment_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CardSummary.ProtoReflect.Descriptor instead.
+func (*CardSummary) Descriptor() ([]byte, []int) {
+	return file_payment_service_proto_payment_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *CardSummary) GetBrand() string {
+	if x != nil {
+		return x.Brand
+	}
+	return ""
+}
+
+func (x *CardSummary) GetLast4() string {
+	if x != nil {
+		return x.Last4
+	}
+	return ""
+}
+
+func (x *CardSummary) GetFunding() string {
+	if x != nil {
+		return x.Funding
+	}
+	return ""
+}
+
+func (x *CardSummary) GetCountry() string {
+	if x != nil {
+		return x.Country
+	}
+	return ""
+}
+
+// ConfirmCreditCardPaymentRequest names a REQUIRES_ACTION payment whose
+// customer finished authenticating.
+type ConfirmCreditCardPaymentRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PaymentId     string                 `protobuf:"bytes,1,opt,name=payment_id,json=paymentId,proto3" json:"payment_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConfirmCreditCardPaymentRequest) Reset() {
+	*x = ConfirmCreditCardPaymentRequest{}
+	mi := &file_payment_service_proto_payment_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConfirmCreditCardPaymentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConfirmCreditCardPaymentRequest) ProtoMessage() {}
+
+func (x *ConfirmCreditCardPaymentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_service_proto_payment_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConfirmCreditCardPaymentRequest.ProtoReflect.Descriptor instead.
+func (*ConfirmCreditCardPaymentRequest) Descriptor() ([]byte, []int) {
+	return file_payment_service_proto_payment_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ConfirmCreditCardPaymentRequest) GetPaymentId() string {
+	if x != nil {
+		return x.PaymentId
+	}
+	return ""
+}
+
+type MetaMaskPaymentRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PaymentId     string                 `protobuf:"bytes,1,opt,name=payment_id,json=paymentId,proto3" json:"payment_id,omitempty"`
+	WalletAddress string                 `protobuf:"bytes,2,opt,name=wallet_address,json=walletAddress,proto3" json:"wallet_address,omitempty"`
+	// Crypto currency to pay a fiat payment with, ETH when unset. Payments
+	// priced in crypto are paid in their own currency.
+	Currency string `protobuf:"bytes,3,opt,name=currency,proto3" json:"currency,omitempty"`
+	// Chain to pay on, the default chain when unset.
+	ChainId uint64 `protobuf:"varint,4,opt,name=chain_id,json=chainId,proto3" json:"chain_id,omitempty"`
+	// Renders payment_uri as a QR code in the response.
+	QrCodeFormat  QRCodeFormat `protobuf:"varint,5,opt,name=qr_code_format,json=qrCodeFormat,proto3,enum=payment.QRCodeFormat" json:"qr_code_format,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MetaMaskPaymentRequest) Reset() {
+	*x = MetaMaskPaymentRequest{}
+	mi := &file_payment_service_proto_payment_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MetaMaskPaymentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MetaMaskPaymentRequest) ProtoMessage() {}
+
+func (x *MetaMaskPaymentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_service_proto_payment_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MetaMaskPaymentRequest.ProtoReflect.Descriptor instead.
+func (*MetaMaskPaymentRequest) Descriptor() ([]byte, []int) {
+	return file_payment_service_proto_payment_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *MetaMaskPaymentRequest) GetPaymentId() string {
+	if x != nil {
+		return x.PaymentId
+	}
+	return ""
+}
+
+func (x *MetaMaskPaymentRequest) GetWalletAddress() string {
+	if x != nil {
+		return x.WalletAddress
+	}
+	return ""
+}
+
+func (x *MetaMaskPaymentRequest) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *MetaMaskPaymentRequest) GetChainId() uint64 {
+	if x != nil {
+		return x.ChainId
+	}
+	return 0
+}
+
+func (x *MetaMaskPaymentRequest) GetQrCodeFormat() QRCodeFormat {
+	if x != nil {
+		return x.QrCodeFormat
+	}
+	return QRCodeFormat_QR_CODE_FORMAT_UNSPECIFIED
+}
+
+type MetaMaskPaymentResponse struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	PaymentId       string                 `protobuf:"bytes,1,opt,name=payment_id,json=paymentId,proto3" json:"payment_id,omitempty"`
+	TransactionHash string                 `protobuf:"bytes,2,opt,name=transaction_hash,json=transactionHash,proto3" json:"transaction_hash,omitempty"`
+	ContractAddress string                 `protobuf:"bytes,3,opt,name=contract_address,json=contractAddress,proto3" json:"contract_address,omitempty"`
+	// Set for payments in the native currency.
+	PaymentAmountWei string `protobuf:"bytes,4,opt,name=payment_amount_wei,json=paymentAmountWei,proto3" json:"payment_amount_wei,omitempty"`
+	// Set for payments in an ERC-20 token. The wallet sends approve_call_data
+	// to token_address, then transfer_call_data to contract_address.
+	Token *Token `protobuf:"bytes,5,opt,name=token,proto3" json:"token,omitempty"`
+	// Amount in the token's base units.
+	TokenAmount      string `protobuf:"bytes,6,opt,name=token_amount,json=tokenAmount,proto3" json:"token_amount,omitempty"`
+	ApproveCallData  string `protobuf:"bytes,7,opt,name=approve_call_data,json=approveCallData,proto3" json:"approve_call_data,omitempty"`
+	TransferCallData string `protobuf:"bytes,8,opt,name=transfer_call_data,json=transferCallData,proto3" json:"transfer_call_data,omitempty"`
+	// The locked price of a fiat payment; pay before it expires.
+	Quote *PriceQuote `protobuf:"bytes,9,opt,name=quote,proto3" json:"quote,omitempty"`
+	// Chain the transaction must be sent on.
+	ChainId uint64 `protobuf:"varint,10,opt,name=chain_id,json=chainId,proto3" json:"chain_id,omitempty"`
+	// The payment call, ready to pass to eth_sendTransaction. For token
+	// payments it is sent after approve_call_data.
+	Transaction *TransactionRequest `protobuf:"bytes,11,opt,name=transaction,proto3" json:"transaction,omitempty"`
+	// EIP-681 request for native payments, for wallets that scan a QR code.
+	PaymentUri string `protobuf:"bytes,12,opt,name=payment_uri,json=paymentUri,proto3" json:"payment_uri,omitempty"`
+	// payment_uri as a QR code in the requested format.
+	QrCode            []byte `protobuf:"bytes,13,opt,name=qr_code,json=qrCode,proto3" json:"qr_code,omitempty"`
+	QrCodeContentType string `protobuf:"bytes,14,opt,name=qr_code_content_type,json=qrCodeContentType,proto3" json:"qr_code_content_type,omitempty"`
+	// Expected network fee of transaction.
+	Fee           *FeeEstimate `protobuf:"bytes,15,opt,name=fee,proto3" json:"fee,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MetaMaskPaymentResponse) Reset() {
+	*x = MetaMaskPaymentResponse{}
+	mi := &file_payment_service_proto_payment_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MetaMaskPaymentResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MetaMaskPaymentResponse) ProtoMessage() {}
+
+func (x *MetaMaskPaymentResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_service_proto_payment_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MetaMaskPaymentResponse.ProtoReflect.Descriptor instead.
+func (*MetaMaskPaymentResponse) Descriptor() ([]byte, []int) {
+	return file_payment_service_proto_payment_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *MetaMaskPaymentResponse) GetPaymentId() string {
+	if x != nil {
+		return x.PaymentId
+	}
+	return ""
+}
+
+func (x *MetaMaskPaymentResponse) GetTransactionHash() string {
+	if x != nil {
+		return x.TransactionHash
+	}
+	return ""
+}
+
+func (x *MetaMaskPaymentResponse) GetContractAddress() string {
+	if x != nil {
+		return x.ContractAddress
+	}
+	return ""
+}
+
+func (x *MetaMaskPaymentResponse) GetPaymentAmountWei() string {
+	if x != nil {
+		return x.PaymentAmountWei
+	}
+	return ""
+}
+
+func (x *MetaMaskPaymentResponse) GetToken() *Token {
+	if x != nil {
+		return x.Token
+	}
+	return nil
+}
+
+func (x *MetaMaskPaymentResponse) GetTokenAmount() string {
+	if x != nil {
+		return x.TokenAmount
+	}
+	return ""
+}
+
+func (x *MetaMaskPaymentResponse) GetApproveCallData() string {
+	if x != nil {
+		return x.ApproveCallData
+	}
+	return ""
+}
+
+func (x *MetaMaskPaymentResponse) GetTransferCallData() string {
+	if x != nil {
+		return x.TransferCallData
+	}
+	return ""
+}
+
+func (x *MetaMaskPaymentResponse) GetQuote() *PriceQuote {
+	if x != nil {
+		return x.Quote
+	}
+	return nil
+}
+
+func (x *MetaMaskPaymentResponse) GetChainId() uint64 {
+	if x != nil {
+		return x.ChainId
+	}
+	return 0
+}
+
+func (x *MetaMaskPaymentResponse) GetTransaction() *TransactionRequest {
+	if x != nil {
+		return x.Transaction
+	}
+	return nil
+}
+
+func (x *MetaMaskPaymentResponse) GetPaymentUri() string {
+	if x != nil {
+		return x.PaymentUri
+	}
+	return ""
+}
+
+func (x *MetaMaskPaymentResponse) GetQrCode() []byte {
+	if x != nil {
+		return x.QrCode
+	}
+	return nil
+}
+
+func (x *MetaMaskPaymentResponse) GetQrCodeContentType() string {
+	if x != nil {
+		return x.QrCodeContentType
+	}
+	return ""
+}
+
+func (x *MetaMaskPaymentResponse) GetFee() *FeeEstimate {
+	if x != nil {
+		return x.Fee
+	}
+	return nil
+}
+
+// FeeEstimate is an EIP-1559 fee estimate. Per-gas prices are in wei.
+type FeeEstimate struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Unset when the gas cannot be estimated yet.
+	GasLimit             uint64 `protobuf:"varint,1,opt,name=gas_limit,json=gasLimit,proto3" json:"gas_limit,omitempty"`
+	BaseFeePerGas        string `protobuf:"bytes,2,opt,name=base_fee_per_gas,json=baseFeePerGas,proto3" json:"base_fee_per_gas,omitempty"`
+	MaxPriorityFeePerGas string `protobuf:"bytes,3,opt,name=max_priority_fee_per_gas,json=maxPriorityFeePerGas,proto3" json:"max_priority_fee_per_gas,omitempty"`
+	MaxFeePerGas         string `protobuf:"bytes,4,opt,name=max_fee_per_gas,json=maxFeePerGas,proto3" json:"max_fee_per_gas,omitempty"`
+	// gas_limit * max_fee_per_gas in the chain's native currency.
+	MaxCost       *Money `protobuf:"bytes,5,opt,name=max_cost,json=maxCost,proto3" json:"max_cost,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FeeEstimate) Reset() {
+	*x = FeeEstimate{}
+	mi := &file_payment_service_proto_payment_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FeeEstimate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FeeEstimate) ProtoMessage() {}
+
+func (x *FeeEstimate) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_service_proto_payment_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FeeEstimate.ProtoReflect.Descriptor instead.
+func (*FeeEstimate) Descriptor() ([]byte, []int) {
+	return file_payment_service_proto_payment_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *FeeEstimate) GetGasLimit() uint64 {
+	if x != nil {
+		return x.GasLimit
+	}
+	return 0
+}
+
+func (x *FeeEstimate) GetBaseFeePerGas() string {
+	if x != nil {
+		return x.BaseFeePerGas
+	}
+	return ""
+}
+
+func (x *FeeEstimate) GetMaxPriorityFeePerGas() string {
+	if x != nil {
+		return x.MaxPriorityFeePerGas
+	}
+	return ""
+}
+
+func (x *FeeEstimate) GetMaxFeePerGas() string {
+	if x != nil {
+		return x.MaxFeePerGas
+	}
+	return ""
+}
+
+func (x *FeeEstimate) GetMaxCost() *Money {
+	if x != nil {
+		return x.MaxCost
+	}
+	return nil
+}
+
+// TransactionRequest uses the hex encoding of eth_sendTransaction.
+type TransactionRequest struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	From    string                 `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"`
+	To      string                 `protobuf:"bytes,2,opt,name=to,proto3" json:"to,omitempty"`
+	Value   string                 `protobuf:"bytes,3,opt,name=value,proto3" json:"value,omitempty"`
+	Data    string                 `protobuf:"bytes,4,opt,name=data,proto3" json:"data,omitempty"`
+	ChainId string                 `protobuf:"bytes,5,opt,name=chain_id,json=chainId,proto3" json:"chain_id,omitempty"`
+	// Unset when the gas could not be estimated; the wallet estimates it.
+	Gas                  string `protobuf:"bytes,6,opt,name=gas,proto3" json:"gas,omitempty"`
+	MaxPriorityFeePerGas string `protobuf:"bytes,7,opt,name=max_priority_fee_per_gas,json=maxPriorityFeePerGas,proto3" json:"max_priority_fee_per_gas,omitempty"`
+	MaxFeePerGas         string `protobuf:"bytes,8,opt,name=max_fee_per_gas,json=maxFeePerGas,proto3" json:"max_fee_per_gas,omitempty"`
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
+}
+
+func (x *TransactionRequest) Reset() {
+	*x = TransactionRequest{}
+	mi := &file_payment_service_proto_payment_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TransactionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TransactionRequest) ProtoMessage() {}
+
+func (x *TransactionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_service_proto_payment_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TransactionRequest.ProtoReflect.Descriptor instead.
+func (*TransactionRequest) Descriptor() ([]byte, []int) {
+	return file_payment_service_proto_payment_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *TransactionRequest) GetFrom() string {
+	if x != nil {
+		return x.From
+	}
+	return ""
+}
+
+func (x *TransactionRequest) GetTo() string {
+	if x != nil {
+		return x.To
+	}
+	return ""
+}
+
+func (x *TransactionRequest) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+func (x *TransactionRequest) GetData() string {
+	if x != nil {
+		return x.Data
+	}
+	return ""
+}
+
+func (x *TransactionRequest) GetChainId() string {
+	if x != nil {
+		return x.ChainId
+	}
+	return ""
+}
+
+func (x *TransactionRequest) GetGas() string {
+	if x != nil {
+		return x.Gas
+	}
+	return ""
+}
+
+func (x *TransactionRequest) GetMaxPriorityFeePerGas() string {
+	if x != nil {
+		return x.MaxPriorityFeePerGas
+	}
+	return ""
+}
+
+func (x *TransactionRequest) GetMaxFeePerGas() string {
+	if x != nil {
+		return x.MaxFeePerGas
+	}
+	return ""
+}
+
+type Token struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	OrderId       string                 `protobuf:"bytes,2,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
-	UserId        string                 `protobuf:"bytes,3,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
-	Amount        float64                `protobuf:"fixed64,4,opt,name=amount,proto3" json:"amount,omitempty"`
-	Currency      string                 `protobuf:"bytes,5,opt,name=currency,proto3" json:"currency,omitempty"`
-	Status        PaymentStatus          `protobuf:"varint,6,opt,name=status,proto3,enum=payment.PaymentStatus" json:"status,omitempty"`
-	PaymentMethod PaymentMethod          `protobuf:"varint,7,opt,name=payment_method,json=paymentMethod,proto3,enum=payment.PaymentMethod" json:"payment_method,omitempty"`
-	TransactionId string                 `protobuf:"bytes,8,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
-	ErrorMessage  string                 `protobuf:"bytes,9,opt,name=error_message,json=errorMessage,proto3" json:"error_message,omitempty"`
-	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
-	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,11,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	Symbol        string                 `protobuf:"bytes,1,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	Address       string                 `protobuf:"bytes,2,opt,name=address,proto3" json:"address,omitempty"`
+	Decimals      int32                  `protobuf:"varint,3,opt,name=decimals,proto3" json:"decimals,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Token) Reset() {
+	*x = Token{}
+	mi := &file_payment_service_proto_payment_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Token) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Token) ProtoMessage() {}
+
+func (x *Token) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_service_proto_payment_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Token.ProtoReflect.Descriptor instead.
+func (*Token) Descriptor() ([]byte, []int) {
+	return file_payment_service_proto_payment_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *Token) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+func (x *Token) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *Token) GetDecimals() int32 {
+	if x != nil {
+		return x.Decimals
+	}
+	return 0
+}
+
+type ConfirmMetaMaskPaymentRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	PaymentId       string                 `protobuf:"bytes,1,opt,name=payment_id,json=paymentId,proto3" json:"payment_id,omitempty"`
+	TransactionHash string                 `protobuf:"bytes,2,opt,name=transaction_hash,json=transactionHash,proto3" json:"transaction_hash,omitempty"`
+	// Chain the transaction was sent on. When set it must match the chain
+	// the payment was initiated on.
+	ChainId       uint64 `protobuf:"varint,3,opt,name=chain_id,json=chainId,proto3" json:"chain_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConfirmMetaMaskPaymentRequest) Reset() {
+	*x = ConfirmMetaMaskPaymentRequest{}
+	mi := &file_payment_service_proto_payment_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConfirmMetaMaskPaymentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConfirmMetaMaskPaymentRequest) ProtoMessage() {}
+
+func (x *ConfirmMetaMaskPaymentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_service_proto_payment_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConfirmMetaMaskPaymentRequest.ProtoReflect.Descriptor instead.
+func (*ConfirmMetaMaskPaymentRequest) Descriptor() ([]byte, []int) {
+	return file_payment_service_proto_payment_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *ConfirmMetaMaskPaymentRequest) GetPaymentId() string {
+	if x != nil {
+		return x.PaymentId
+	}
+	return ""
+}
+
+func (x *ConfirmMetaMaskPaymentRequest) GetTransactionHash() string {
+	if x != nil {
+		return x.TransactionHash
+	}
+	return ""
+}
+
+func (x *ConfirmMetaMaskPaymentRequest) GetChainId() uint64 {
+	if x != nil {
+		return x.ChainId
+	}
+	return 0
+}
+
+type WalletChallengeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PaymentId     string                 `protobuf:"bytes,1,opt,name=payment_id,json=paymentId,proto3" json:"payment_id,omitempty"`
+	WalletAddress string                 `protobuf:"bytes,2,opt,name=wallet_address,json=walletAddress,proto3" json:"wallet_address,omitempty"`
+	// EIP-712 when unset.
+	Scheme SignatureScheme `protobuf:"varint,3,opt,name=scheme,proto3,enum=payment.SignatureScheme" json:"scheme,omitempty"`
+	// Chain the signature is bound to, the payment's chain when unset.
+	ChainId       uint64 `protobuf:"varint,4,opt,name=chain_id,json=chainId,proto3" json:"chain_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WalletChallengeRequest) Reset() {
+	*x = WalletChallengeRequest{}
+	mi := &file_payment_service_proto_payment_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WalletChallengeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WalletChallengeRequest) ProtoMessage() {}
+
+func (x *WalletChallengeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_service_proto_payment_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WalletChallengeRequest.ProtoReflect.Descriptor instead.
+func (*WalletChallengeRequest) Descriptor() ([]byte, []int) {
+	return file_payment_service_proto_payment_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *WalletChallengeRequest) GetPaymentId() string {
+	if x != nil {
+		return x.PaymentId
+	}
+	return ""
+}
+
+func (x *WalletChallengeRequest) GetWalletAddress() string {
+	if x != nil {
+		return x.WalletAddress
+	}
+	return ""
+}
+
+func (x *WalletChallengeRequest) GetScheme() SignatureScheme {
+	if x != nil {
+		return x.Scheme
+	}
+	return SignatureScheme_SIGNATURE_SCHEME_UNSPECIFIED
+}
+
+func (x *WalletChallengeRequest) GetChainId() uint64 {
+	if x != nil {
+		return x.ChainId
+	}
+	return 0
+}
+
+type WalletChallenge struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Nonce  string                 `protobuf:"bytes,1,opt,name=nonce,proto3" json:"nonce,omitempty"`
+	Scheme SignatureScheme        `protobuf:"varint,2,opt,name=scheme,proto3,enum=payment.SignatureScheme" json:"scheme,omitempty"`
+	// What the wallet signs: the EIP-712 typed data as JSON for
+	// eth_signTypedData_v4, or the message for personal_sign.
+	Payload       string                 `protobuf:"bytes,3,opt,name=payload,proto3" json:"payload,omitempty"`
+	ExpiresAt     *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *Payment) Reset() {
-	*x = Payment{}
-	mi := &file_payment_service_proto_payment_proto_msgTypes[0]
+func (x *WalletChallenge) Reset() {
+	*x = WalletChallenge{}
+	mi := &file_payment_service_proto_payment_proto_msgTypes[17]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *Payment) String() string {
+func (x *WalletChallenge) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Payment) ProtoMessage() {}
+func (*WalletChallenge) ProtoMessage() {}
 
-func (x *Payment) ProtoReflect() protoreflect.Message {
-	mi := &file_payment_service_proto_payment_proto_msgTypes[0]
+func (x *WalletChallenge) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_service_proto_payment_proto_msgTypes[17]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -175,114 +1935,186 @@ func (x *Payment) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Payment.ProtoReflect.Descriptor instead.
-func (*Payment) Descriptor() ([]byte, []int) {
-	return file_payment_service_proto_payment_proto_rawDescGZIP(), []int{0}
+// Deprecated: Use WalletChallenge.ProtoReflect.Descriptor instead.
+func (*WalletChallenge) Descriptor() ([]byte, []int) {
+	return file_payment_service_proto_payment_proto_rawDescGZIP(), []int{17}
 }
 
-func (x *Payment) GetId() string {
+func (x *WalletChallenge) GetNonce() string {
 	if x != nil {
-		return x.Id
+		return x.Nonce
 	}
 	return ""
 }
 
-func (x *Payment) GetOrderId() string {
+func (x *WalletChallenge) GetScheme() SignatureScheme {
 	if x != nil {
-		return x.OrderId
+		return x.Scheme
 	}
-	return ""
+	return SignatureScheme_SIGNATURE_SCHEME_UNSPECIFIED
 }
 
-func (x *Payment) GetUserId() string {
+func (x *WalletChallenge) GetPayload() string {
 	if x != nil {
-		return x.UserId
+		return x.Payload
 	}
 	return ""
 }
 
-func (x *Payment) GetAmount() float64 {
+func (x *WalletChallenge) GetExpiresAt() *timestamppb.Timestamp {
 	if x != nil {
-		return x.Amount
+		return x.ExpiresAt
 	}
-	return 0
+	return nil
 }
 
-func (x *Payment) GetCurrency() string {
+type VerifyWalletOwnershipRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	PaymentId string                 `protobuf:"bytes,1,opt,name=payment_id,json=paymentId,proto3" json:"payment_id,omitempty"`
+	Nonce     string                 `protobuf:"bytes,2,opt,name=nonce,proto3" json:"nonce,omitempty"`
+	// Hex encoded 65 byte signature returned by the wallet.
+	Signature     string `protobuf:"bytes,3,opt,name=signature,proto3" json:"signature,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VerifyWalletOwnershipRequest) Reset() {
+	*x = VerifyWalletOwnershipRequest{}
+	mi := &file_payment_service_proto_payment_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VerifyWalletOwnershipRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerifyWalletOwnershipRequest) ProtoMessage() {}
+
+func (x *VerifyWalletOwnershipRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_service_proto_payment_proto_msgTypes[18]
 	if x != nil {
-		return x.Currency
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return ""
+	return mi.MessageOf(x)
 }
 
-func (x *Payment) GetStatus() PaymentStatus {
+// Deprecated: Use VerifyWalletOwnershipRequest.ProtoReflect.Descriptor instead.
+func (*VerifyWalletOwnershipRequest) Descriptor() ([]byte, []int) {
+	return file_payment_service_proto_payment_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *VerifyWalletOwnershipRequest) GetPaymentId() string {
 	if x != nil {
-		return x.Status
+		return x.PaymentId
 	}
-	return PaymentStatus_PAYMENT_STATUS_UNSPECIFIED
+	return ""
 }
 
-func (x *Payment) GetPaymentMethod() PaymentMethod {
+func (x *VerifyWalletOwnershipRequest) GetNonce() string {
 	if x != nil {
-		return x.PaymentMethod
+		return x.Nonce
 	}
-	return PaymentMethod_PAYMENT_METHOD_UNSPECIFIED
+	return ""
 }
 
-func (x *Payment) GetTransactionId() string {
+func (x *VerifyWalletOwnershipRequest) GetSignature() string {
 	if x != nil {
-		return x.TransactionId
+		return x.Signature
 	}
 	return ""
 }
 
-func (x *Payment) GetErrorMessage() string {
+type CapturePaymentRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	PaymentId string                 `protobuf:"bytes,1,opt,name=payment_id,json=paymentId,proto3" json:"payment_id,omitempty"`
+	// Amount to capture, at most the authorized amount. Unset captures the
+	// full authorization.
+	Amount *Money `protobuf:"bytes,2,opt,name=amount,proto3" json:"amount,omitempty"`
+	// See InitiatePaymentRequest.idempotency_key.
+	IdempotencyKey string `protobuf:"bytes,3,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *CapturePaymentRequest) Reset() {
+	*x = CapturePaymentRequest{}
+	mi := &file_payment_service_proto_payment_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CapturePaymentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CapturePaymentRequest) ProtoMessage() {}
+
+func (x *CapturePaymentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_service_proto_payment_proto_msgTypes[19]
 	if x != nil {
-		return x.ErrorMessage
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CapturePaymentRequest.ProtoReflect.Descriptor instead.
+func (*CapturePaymentRequest) Descriptor() ([]byte, []int) {
+	return file_payment_service_proto_payment_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *CapturePaymentRequest) GetPaymentId() string {
+	if x != nil {
+		return x.PaymentId
 	}
 	return ""
 }
 
-func (x *Payment) GetCreatedAt() *timestamppb.Timestamp {
+func (x *CapturePaymentRequest) GetAmount() *Money {
 	if x != nil {
-		return x.CreatedAt
+		return x.Amount
 	}
 	return nil
 }
 
-func (x *Payment) GetUpdatedAt() *timestamppb.Timestamp {
+func (x *CapturePaymentRequest) GetIdempotencyKey() string {
 	if x != nil {
-		return x.UpdatedAt
+		return x.IdempotencyKey
 	}
-	return nil
+	return ""
 }
 
-type InitiatePaymentRequest struct {
+type VoidPaymentRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	OrderId       string                 `protobuf:"bytes,1,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
-	UserId        string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
-	Amount        float64                `protobuf:"fixed64,3,opt,name=amount,proto3" json:"amount,omitempty"`
-	Currency      string                 `protobuf:"bytes,4,opt,name=currency,proto3" json:"currency,omitempty"`
-	PaymentMethod PaymentMethod          `protobuf:"varint,5,opt,name=payment_method,json=paymentMethod,proto3,enum=payment.PaymentMethod" json:"payment_method,omitempty"`
+	PaymentId     string                 `protobuf:"bytes,1,opt,name=payment_id,json=paymentId,proto3" json:"payment_id,omitempty"`
+	Reason        string                 `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *InitiatePaymentRequest) Reset() {
-	*x = InitiatePaymentRequest{}
-	mi := &file_payment_service_proto_payment_proto_msgTypes[1]
+func (x *VoidPaymentRequest) Reset() {
+	*x = VoidPaymentRequest{}
+	mi := &file_payment_service_proto_payment_proto_msgTypes[20]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *InitiatePaymentRequest) String() string {
+func (x *VoidPaymentRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*InitiatePaymentRequest) ProtoMessage() {}
+func (*VoidPaymentRequest) ProtoMessage() {}
 
-func (x *InitiatePaymentRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_payment_service_proto_payment_proto_msgTypes[1]
+func (x *VoidPaymentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_service_proto_payment_proto_msgTypes[20]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -293,69 +2125,117 @@ func (x *InitiatePaymentRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use InitiatePaymentRequest.ProtoReflect.Descriptor instead.
-func (*InitiatePaymentRequest) Descriptor() ([]byte, []int) {
-	return file_payment_service_proto_payment_proto_rawDescGZIP(), []int{1}
+// Deprecated: Use VoidPaymentRequest.ProtoReflect.Descriptor instead.
+func (*VoidPaymentRequest) Descriptor() ([]byte, []int) {
+	return file_payment_service_proto_payment_proto_rawDescGZIP(), []int{20}
 }
 
-func (x *InitiatePaymentRequest) GetOrderId() string {
+func (x *VoidPaymentRequest) GetPaymentId() string {
 	if x != nil {
-		return x.OrderId
+		return x.PaymentId
 	}
 	return ""
 }
 
-func (x *InitiatePaymentRequest) GetUserId() string {
+func (x *VoidPaymentRequest) GetReason() string {
 	if x != nil {
-		return x.UserId
+		return x.Reason
 	}
 	return ""
 }
 
-func (x *InitiatePaymentRequest) GetAmount() float64 {
+type ReviewPaymentRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	PaymentId string                 `protobuf:"bytes,1,opt,name=payment_id,json=paymentId,proto3" json:"payment_id,omitempty"`
+	// Approved payments made with ProcessCreditCardPayment are captured,
+	// others stay authorized. Rejected payments are voided.
+	Approve       bool   `protobuf:"varint,2,opt,name=approve,proto3" json:"approve,omitempty"`
+	Reviewer      string `protobuf:"bytes,3,opt,name=reviewer,proto3" json:"reviewer,omitempty"`
+	Note          string `protobuf:"bytes,4,opt,name=note,proto3" json:"note,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReviewPaymentRequest) Reset() {
+	*x = ReviewPaymentRequest{}
+	mi := &file_payment_service_proto_payment_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReviewPaymentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReviewPaymentRequest) ProtoMessage() {}
+
+func (x *ReviewPaymentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_service_proto_payment_proto_msgTypes[21]
 	if x != nil {
-		return x.Amount
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return 0
+	return mi.MessageOf(x)
 }
 
-func (x *InitiatePaymentRequest) GetCurrency() string {
+// Deprecated: Use ReviewPaymentRequest.ProtoReflect.Descriptor instead.
+func (*ReviewPaymentRequest) Descriptor() ([]byte, []int) {
+	return file_payment_service_proto_payment_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *ReviewPaymentRequest) GetPaymentId() string {
 	if x != nil {
-		return x.Currency
+		return x.PaymentId
 	}
 	return ""
 }
 
-func (x *InitiatePaymentRequest) GetPaymentMethod() PaymentMethod {
+func (x *ReviewPaymentRequest) GetApprove() bool {
 	if x != nil {
-		return x.PaymentMethod
+		return x.Approve
 	}
-	return PaymentMethod_PAYMENT_METHOD_UNSPECIFIED
+	return false
 }
 
-type CreditCardPaymentRequest struct {
+func (x *ReviewPaymentRequest) GetReviewer() string {
+	if x != nil {
+		return x.Reviewer
+	}
+	return ""
+}
+
+func (x *ReviewPaymentRequest) GetNote() string {
+	if x != nil {
+		return x.Note
+	}
+	return ""
+}
+
+type GetRiskDecisionsRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	PaymentId     string                 `protobuf:"bytes,1,opt,name=payment_id,json=paymentId,proto3" json:"payment_id,omitempty"`
-	CardInfo      *CreditCardInfo        `protobuf:"bytes,2,opt,name=card_info,json=cardInfo,proto3" json:"card_info,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *CreditCardPaymentRequest) Reset() {
-	*x = CreditCardPaymentRequest{}
-	mi := &file_payment_service_proto_payment_proto_msgTypes[2]
+func (x *GetRiskDecisionsRequest) Reset() {
+	*x = GetRiskDecisionsRequest{}
+	mi := &file_payment_service_proto_payment_proto_msgTypes[22]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *CreditCardPaymentRequest) String() string {
+func (x *GetRiskDecisionsRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*CreditCardPaymentRequest) ProtoMessage() {}
+func (*GetRiskDecisionsRequest) ProtoMessage() {}
 
-func (x *CreditCardPaymentRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_payment_service_proto_payment_proto_msgTypes[2]
+func (x *GetRiskDecisionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_service_proto_payment_proto_msgTypes[22]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -366,51 +2246,94 @@ func (x *CreditCardPaymentRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use CreditCardPaymentRequest.ProtoReflect.Descriptor instead.
-func (*CreditCardPaymentRequest) Descriptor() ([]byte, []int) {
-	return file_payment_service_proto_payment_proto_rawDescGZIP(), []int{2}
+// Deprecated: Use GetRiskDecisionsRequest.ProtoReflect.Descriptor instead.
+func (*GetRiskDecisionsRequest) Descriptor() ([]byte, []int) {
+	return file_payment_service_proto_payment_proto_rawDescGZIP(), []int{22}
 }
 
-func (x *CreditCardPaymentRequest) GetPaymentId() string {
+func (x *GetRiskDecisionsRequest) GetPaymentId() string {
 	if x != nil {
 		return x.PaymentId
 	}
 	return ""
 }
 
-func (x *CreditCardPaymentRequest) GetCardInfo() *CreditCardInfo {
+type GetRiskDecisionsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Decisions     []*RiskDecisionRecord  `protobuf:"bytes,1,rep,name=decisions,proto3" json:"decisions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetRiskDecisionsResponse) Reset() {
+	*x = GetRiskDecisionsResponse{}
+	mi := &file_payment_service_proto_payment_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetRiskDecisionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRiskDecisionsResponse) ProtoMessage() {}
+
+func (x *GetRiskDecisionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_service_proto_payment_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRiskDecisionsResponse.ProtoReflect.Descriptor instead.
+func (*GetRiskDecisionsResponse) Descriptor() ([]byte, []int) {
+	return file_payment_service_proto_payment_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *GetRiskDecisionsResponse) GetDecisions() []*RiskDecisionRecord {
 	if x != nil {
-		return x.CardInfo
+		return x.Decisions
 	}
 	return nil
 }
 
-type CreditCardInfo struct {
-	state          protoimpl.MessageState `protogen:"open.v1"`
-	CardNumber     string                 `protobuf:"bytes,1,opt,name=card_number,json=cardNumber,proto3" json:"card_number,omitempty"`
-	ExpiryMonth    string                 `protobuf:"bytes,2,opt,name=expiry_month,json=expiryMonth,proto3" json:"expiry_month,omitempty"`
-	ExpiryYear     string                 `protobuf:"bytes,3,opt,name=expiry_year,json=expiryYear,proto3" json:"expiry_year,omitempty"`
-	Cvv            string                 `protobuf:"bytes,4,opt,name=cvv,proto3" json:"cvv,omitempty"`
-	CardholderName string                 `protobuf:"bytes,5,opt,name=cardholder_name,json=cardholderName,proto3" json:"cardholder_name,omitempty"`
-	unknownFields  protoimpl.UnknownFields
-	sizeCache      protoimpl.SizeCache
+// A risk decision on a payment, made by the risk engine or, when reviewer
+// is set, by a person.
+type RiskDecisionRecord struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Id        string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	PaymentId string                 `protobuf:"bytes,2,opt,name=payment_id,json=paymentId,proto3" json:"payment_id,omitempty"`
+	Decision  RiskDecision           `protobuf:"varint,3,opt,name=decision,proto3,enum=payment.RiskDecision" json:"decision,omitempty"`
+	Score     int32                  `protobuf:"varint,4,opt,name=score,proto3" json:"score,omitempty"`
+	// Names of the risk rules the payment matched.
+	Rules         []string               `protobuf:"bytes,5,rep,name=rules,proto3" json:"rules,omitempty"`
+	Reviewer      string                 `protobuf:"bytes,6,opt,name=reviewer,proto3" json:"reviewer,omitempty"`
+	Note          string                 `protobuf:"bytes,7,opt,name=note,proto3" json:"note,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *CreditCardInfo) Reset() {
-	*x = CreditCardInfo{}
-	mi := &file_payment_service_proto_payment_proto_msgTypes[3]
+func (x *RiskDecisionRecord) Reset() {
+	*x = RiskDecisionRecord{}
+	mi := &file_payment_service_proto_payment_proto_msgTypes[24]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *CreditCardInfo) String() string {
+func (x *RiskDecisionRecord) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*CreditCardInfo) ProtoMessage() {}
+func (*RiskDecisionRecord) ProtoMessage() {}
 
-func (x *CreditCardInfo) ProtoReflect() protoreflect.Message {
-	mi := &file_payment_service_proto_payment_proto_msgTypes[3]
+func (x *RiskDecisionRecord) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_service_proto_payment_proto_msgTypes[24]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -421,69 +2344,98 @@ func (x *CreditCardInfo) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use CreditCardInfo.ProtoReflect.Descriptor instead.
-func (*CreditCardInfo) Descriptor() ([]byte, []int) {
-	return file_payment_service_proto_payment_proto_rawDescGZIP(), []int{3}
+// Deprecated: Use RiskDecisionRecord.ProtoReflect.Descriptor instead.
+func (*RiskDecisionRecord) Descriptor() ([]byte, []int) {
+	return file_payment_service_proto_payment_proto_rawDescGZIP(), []int{24}
 }
 
-func (x *CreditCardInfo) GetCardNumber() string {
+func (x *RiskDecisionRecord) GetId() string {
 	if x != nil {
-		return x.CardNumber
+		return x.Id
 	}
 	return ""
 }
 
-func (x *CreditCardInfo) GetExpiryMonth() string {
+func (x *RiskDecisionRecord) GetPaymentId() string {
 	if x != nil {
-		return x.ExpiryMonth
+		return x.PaymentId
 	}
 	return ""
 }
 
-func (x *CreditCardInfo) GetExpiryYear() string {
+func (x *RiskDecisionRecord) GetDecision() RiskDecision {
 	if x != nil {
-		return x.ExpiryYear
+		return x.Decision
 	}
-	return ""
+	return RiskDecision_RISK_DECISION_UNSPECIFIED
+}
+
+func (x *RiskDecisionRecord) GetScore() int32 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+func (x *RiskDecisionRecord) GetRules() []string {
+	if x != nil {
+		return x.Rules
+	}
+	return nil
 }
 
-func (x *CreditCardInfo) GetCvv() string {
+func (x *RiskDecisionRecord) GetReviewer() string {
 	if x != nil {
-		return x.Cvv
+		return x.Reviewer
 	}
 	return ""
 }
 
-func (x *CreditCardInfo) GetCardholderName() string {
+func (x *RiskDecisionRecord) GetNote() string {
 	if x != nil {
-		return x.CardholderName
+		return x.Note
 	}
 	return ""
 }
 
-type MetaMaskPaymentRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	PaymentId     string                 `protobuf:"bytes,1,opt,name=payment_id,json=paymentId,proto3" json:"payment_id,omitempty"`
-	WalletAddress string                 `protobuf:"bytes,2,opt,name=wallet_address,json=walletAddress,proto3" json:"wallet_address,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *RiskDecisionRecord) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
 }
 
-func (x *MetaMaskPaymentRequest) Reset() {
-	*x = MetaMaskPaymentRequest{}
-	mi := &file_payment_service_proto_payment_proto_msgTypes[4]
+type Refund struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Id        string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	PaymentId string                 `protobuf:"bytes,2,opt,name=payment_id,json=paymentId,proto3" json:"payment_id,omitempty"`
+	Amount    *Money                 `protobuf:"bytes,3,opt,name=amount,proto3" json:"amount,omitempty"`
+	Reason    string                 `protobuf:"bytes,4,opt,name=reason,proto3" json:"reason,omitempty"`
+	Status    RefundStatus           `protobuf:"varint,5,opt,name=status,proto3,enum=payment.RefundStatus" json:"status,omitempty"`
+	// The processor's reference for the refund, e.g. a Stripe refund ID.
+	ProcessorReference string                 `protobuf:"bytes,6,opt,name=processor_reference,json=processorReference,proto3" json:"processor_reference,omitempty"`
+	ErrorMessage       string                 `protobuf:"bytes,7,opt,name=error_message,json=errorMessage,proto3" json:"error_message,omitempty"`
+	CreatedAt          *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt          *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *Refund) Reset() {
+	*x = Refund{}
+	mi := &file_payment_service_proto_payment_proto_msgTypes[25]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *MetaMaskPaymentRequest) String() string {
+func (x *Refund) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*MetaMaskPaymentRequest) ProtoMessage() {}
+func (*Refund) ProtoMessage() {}
 
-func (x *MetaMaskPaymentRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_payment_service_proto_payment_proto_msgTypes[4]
+func (x *Refund) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_service_proto_payment_proto_msgTypes[25]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -491,53 +2443,104 @@ func (x *MetaMaskPaymentRequest) ProtoReflect() protoreflect.Message {
 		}
 		return ms
 	}
-	return mi.MessageOf(x)
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Refund.ProtoReflect.Descriptor instead.
+func (*Refund) Descriptor() ([]byte, []int) {
+	return file_payment_service_proto_payment_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *Refund) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Refund) GetPaymentId() string {
+	if x != nil {
+		return x.PaymentId
+	}
+	return ""
+}
+
+func (x *Refund) GetAmount() *Money {
+	if x != nil {
+		return x.Amount
+	}
+	return nil
+}
+
+func (x *Refund) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
 }
 
-// Deprecated: Use MetaMaskPaymentRequest.ProtoReflect.Descriptor instead.
-func (*MetaMaskPaymentRequest) Descriptor() ([]byte, []int) {
-	return file_payment_service_proto_payment_proto_rawDescGZIP(), []int{4}
+func (x *Refund) GetStatus() RefundStatus {
+	if x != nil {
+		return x.Status
+	}
+	return RefundStatus_REFUND_STATUS_UNSPECIFIED
 }
 
-func (x *MetaMaskPaymentRequest) GetPaymentId() string {
+func (x *Refund) GetProcessorReference() string {
 	if x != nil {
-		return x.PaymentId
+		return x.ProcessorReference
 	}
 	return ""
 }
 
-func (x *MetaMaskPaymentRequest) GetWalletAddress() string {
+func (x *Refund) GetErrorMessage() string {
 	if x != nil {
-		return x.WalletAddress
+		return x.ErrorMessage
 	}
 	return ""
 }
 
-type MetaMaskPaymentResponse struct {
-	state            protoimpl.MessageState `protogen:"open.v1"`
-	PaymentId        string                 `protobuf:"bytes,1,opt,name=payment_id,json=paymentId,proto3" json:"payment_id,omitempty"`
-	TransactionHash  string                 `protobuf:"bytes,2,opt,name=transaction_hash,json=transactionHash,proto3" json:"transaction_hash,omitempty"`
-	ContractAddress  string                 `protobuf:"bytes,3,opt,name=contract_address,json=contractAddress,proto3" json:"contract_address,omitempty"`
-	PaymentAmountWei string                 `protobuf:"bytes,4,opt,name=payment_amount_wei,json=paymentAmountWei,proto3" json:"payment_amount_wei,omitempty"`
-	unknownFields    protoimpl.UnknownFields
-	sizeCache        protoimpl.SizeCache
+func (x *Refund) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
 }
 
-func (x *MetaMaskPaymentResponse) Reset() {
-	*x = MetaMaskPaymentResponse{}
-	mi := &file_payment_service_proto_payment_proto_msgTypes[5]
+func (x *Refund) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+type RefundPaymentRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	PaymentId string                 `protobuf:"bytes,1,opt,name=payment_id,json=paymentId,proto3" json:"payment_id,omitempty"`
+	// Amount to refund. Unset refunds everything not refunded yet.
+	Amount *Money `protobuf:"bytes,2,opt,name=amount,proto3" json:"amount,omitempty"`
+	Reason string `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	// See InitiatePaymentRequest.idempotency_key.
+	IdempotencyKey string `protobuf:"bytes,4,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *RefundPaymentRequest) Reset() {
+	*x = RefundPaymentRequest{}
+	mi := &file_payment_service_proto_payment_proto_msgTypes[26]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *MetaMaskPaymentResponse) String() string {
+func (x *RefundPaymentRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*MetaMaskPaymentResponse) ProtoMessage() {}
+func (*RefundPaymentRequest) ProtoMessage() {}
 
-func (x *MetaMaskPaymentResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_payment_service_proto_payment_proto_msgTypes[5]
+func (x *RefundPaymentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_service_proto_payment_proto_msgTypes[26]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -548,62 +2551,61 @@ func (x *MetaMaskPaymentResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use MetaMaskPaymentResponse.ProtoReflect.Descriptor instead.
-func (*MetaMaskPaymentResponse) Descriptor() ([]byte, []int) {
-	return file_payment_service_proto_payment_proto_rawDescGZIP(), []int{5}
+// Deprecated: Use RefundPaymentRequest.ProtoReflect.Descriptor instead.
+func (*RefundPaymentRequest) Descriptor() ([]byte, []int) {
+	return file_payment_service_proto_payment_proto_rawDescGZIP(), []int{26}
 }
 
-func (x *MetaMaskPaymentResponse) GetPaymentId() string {
+func (x *RefundPaymentRequest) GetPaymentId() string {
 	if x != nil {
 		return x.PaymentId
 	}
 	return ""
 }
 
-func (x *MetaMaskPaymentResponse) GetTransactionHash() string {
+func (x *RefundPaymentRequest) GetAmount() *Money {
 	if x != nil {
-		return x.TransactionHash
+		return x.Amount
 	}
-	return ""
+	return nil
 }
 
-func (x *MetaMaskPaymentResponse) GetContractAddress() string {
+func (x *RefundPaymentRequest) GetReason() string {
 	if x != nil {
-		return x.ContractAddress
+		return x.Reason
 	}
 	return ""
 }
 
-func (x *MetaMaskPaymentResponse) GetPaymentAmountWei() string {
+func (x *RefundPaymentRequest) GetIdempotencyKey() string {
 	if x != nil {
-		return x.PaymentAmountWei
+		return x.IdempotencyKey
 	}
 	return ""
 }
 
-type ConfirmMetaMaskPaymentRequest struct {
-	state           protoimpl.MessageState `protogen:"open.v1"`
-	PaymentId       string                 `protobuf:"bytes,1,opt,name=payment_id,json=paymentId,proto3" json:"payment_id,omitempty"`
-	TransactionHash string                 `protobuf:"bytes,2,opt,name=transaction_hash,json=transactionHash,proto3" json:"transaction_hash,omitempty"`
-	unknownFields   protoimpl.UnknownFields
-	sizeCache       protoimpl.SizeCache
+type ListRefundsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PaymentId     string                 `protobuf:"bytes,1,opt,name=payment_id,json=paymentId,proto3" json:"payment_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ConfirmMetaMaskPaymentRequest) Reset() {
-	*x = ConfirmMetaMaskPaymentRequest{}
-	mi := &file_payment_service_proto_payment_proto_msgTypes[6]
+func (x *ListRefundsRequest) Reset() {
+	*x = ListRefundsRequest{}
+	mi := &file_payment_service_proto_payment_proto_msgTypes[27]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ConfirmMetaMaskPaymentRequest) String() string {
+func (x *ListRefundsRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ConfirmMetaMaskPaymentRequest) ProtoMessage() {}
+func (*ListRefundsRequest) ProtoMessage() {}
 
-func (x *ConfirmMetaMaskPaymentRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_payment_service_proto_payment_proto_msgTypes[6]
+func (x *ListRefundsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_service_proto_payment_proto_msgTypes[27]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -614,23 +2616,60 @@ func (x *ConfirmMetaMaskPaymentRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ConfirmMetaMaskPaymentRequest.ProtoReflect.Descriptor instead.
-func (*ConfirmMetaMaskPaymentRequest) Descriptor() ([]byte, []int) {
-	return file_payment_service_proto_payment_proto_rawDescGZIP(), []int{6}
+// Deprecated: Use ListRefundsRequest.ProtoReflect.Descriptor instead.
+func (*ListRefundsRequest) Descriptor() ([]byte, []int) {
+	return file_payment_service_proto_payment_proto_rawDescGZIP(), []int{27}
 }
 
-func (x *ConfirmMetaMaskPaymentRequest) GetPaymentId() string {
+func (x *ListRefundsRequest) GetPaymentId() string {
 	if x != nil {
 		return x.PaymentId
 	}
 	return ""
 }
 
-func (x *ConfirmMetaMaskPaymentRequest) GetTransactionHash() string {
+type ListRefundsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Refunds       []*Refund              `protobuf:"bytes,1,rep,name=refunds,proto3" json:"refunds,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRefundsResponse) Reset() {
+	*x = ListRefundsResponse{}
+	mi := &file_payment_service_proto_payment_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRefundsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRefundsResponse) ProtoMessage() {}
+
+func (x *ListRefundsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_service_proto_payment_proto_msgTypes[28]
 	if x != nil {
-		return x.TransactionHash
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return ""
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRefundsResponse.ProtoReflect.Descriptor instead.
+func (*ListRefundsResponse) Descriptor() ([]byte, []int) {
+	return file_payment_service_proto_payment_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *ListRefundsResponse) GetRefunds() []*Refund {
+	if x != nil {
+		return x.Refunds
+	}
+	return nil
 }
 
 type GetPaymentRequest struct {
@@ -642,7 +2681,7 @@ type GetPaymentRequest struct {
 
 func (x *GetPaymentRequest) Reset() {
 	*x = GetPaymentRequest{}
-	mi := &file_payment_service_proto_payment_proto_msgTypes[7]
+	mi := &file_payment_service_proto_payment_proto_msgTypes[29]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -654,7 +2693,7 @@ func (x *GetPaymentRequest) String() string {
 func (*GetPaymentRequest) ProtoMessage() {}
 
 func (x *GetPaymentRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_payment_service_proto_payment_proto_msgTypes[7]
+	mi := &file_payment_service_proto_payment_proto_msgTypes[29]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -667,7 +2706,7 @@ func (x *GetPaymentRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetPaymentRequest.ProtoReflect.Descriptor instead.
 func (*GetPaymentRequest) Descriptor() ([]byte, []int) {
-	return file_payment_service_proto_payment_proto_rawDescGZIP(), []int{7}
+	return file_payment_service_proto_payment_proto_rawDescGZIP(), []int{29}
 }
 
 func (x *GetPaymentRequest) GetPaymentId() string {
@@ -686,7 +2725,7 @@ type GetPaymentsByOrderRequest struct {
 
 func (x *GetPaymentsByOrderRequest) Reset() {
 	*x = GetPaymentsByOrderRequest{}
-	mi := &file_payment_service_proto_payment_proto_msgTypes[8]
+	mi := &file_payment_service_proto_payment_proto_msgTypes[30]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -698,7 +2737,7 @@ func (x *GetPaymentsByOrderRequest) String() string {
 func (*GetPaymentsByOrderRequest) ProtoMessage() {}
 
 func (x *GetPaymentsByOrderRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_payment_service_proto_payment_proto_msgTypes[8]
+	mi := &file_payment_service_proto_payment_proto_msgTypes[30]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -711,7 +2750,7 @@ func (x *GetPaymentsByOrderRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetPaymentsByOrderRequest.ProtoReflect.Descriptor instead.
 func (*GetPaymentsByOrderRequest) Descriptor() ([]byte, []int) {
-	return file_payment_service_proto_payment_proto_rawDescGZIP(), []int{8}
+	return file_payment_service_proto_payment_proto_rawDescGZIP(), []int{30}
 }
 
 func (x *GetPaymentsByOrderRequest) GetOrderId() string {
@@ -730,7 +2769,7 @@ type GetPaymentsByOrderResponse struct {
 
 func (x *GetPaymentsByOrderResponse) Reset() {
 	*x = GetPaymentsByOrderResponse{}
-	mi := &file_payment_service_proto_payment_proto_msgTypes[9]
+	mi := &file_payment_service_proto_payment_proto_msgTypes[31]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -742,7 +2781,7 @@ func (x *GetPaymentsByOrderResponse) String() string {
 func (*GetPaymentsByOrderResponse) ProtoMessage() {}
 
 func (x *GetPaymentsByOrderResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_payment_service_proto_payment_proto_msgTypes[9]
+	mi := &file_payment_service_proto_payment_proto_msgTypes[31]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -755,7 +2794,7 @@ func (x *GetPaymentsByOrderResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetPaymentsByOrderResponse.ProtoReflect.Descriptor instead.
 func (*GetPaymentsByOrderResponse) Descriptor() ([]byte, []int) {
-	return file_payment_service_proto_payment_proto_rawDescGZIP(), []int{9}
+	return file_payment_service_proto_payment_proto_rawDescGZIP(), []int{31}
 }
 
 func (x *GetPaymentsByOrderResponse) GetPayments() []*Payment {
@@ -766,18 +2805,22 @@ func (x *GetPaymentsByOrderResponse) GetPayments() []*Payment {
 }
 
 type UpdatePaymentStatusRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	PaymentId     string                 `protobuf:"bytes,1,opt,name=payment_id,json=paymentId,proto3" json:"payment_id,omitempty"`
-	Status        PaymentStatus          `protobuf:"varint,2,opt,name=status,proto3,enum=payment.PaymentStatus" json:"status,omitempty"`
-	TransactionId string                 `protobuf:"bytes,3,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
-	ErrorMessage  string                 `protobuf:"bytes,4,opt,name=error_message,json=errorMessage,proto3" json:"error_message,omitempty"`
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	PaymentId string                 `protobuf:"bytes,1,opt,name=payment_id,json=paymentId,proto3" json:"payment_id,omitempty"`
+	// Only FAILED and CANCELLED can be set; other statuses are rejected with
+	// INVALID_ARGUMENT. Payments complete, are held, captured, voided, refunded
+	// and disputed through the RPCs and webhooks that move the money.
+	Status PaymentStatus `protobuf:"varint,2,opt,name=status,proto3,enum=payment.PaymentStatus" json:"status,omitempty"`
+	// Ignored; completing a payment is no longer possible here.
+	TransactionId string `protobuf:"bytes,3,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
+	ErrorMessage  string `protobuf:"bytes,4,opt,name=error_message,json=errorMessage,proto3" json:"error_message,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *UpdatePaymentStatusRequest) Reset() {
 	*x = UpdatePaymentStatusRequest{}
-	mi := &file_payment_service_proto_payment_proto_msgTypes[10]
+	mi := &file_payment_service_proto_payment_proto_msgTypes[32]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -789,7 +2832,7 @@ func (x *UpdatePaymentStatusRequest) String() string {
 func (*UpdatePaymentStatusRequest) ProtoMessage() {}
 
 func (x *UpdatePaymentStatusRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_payment_service_proto_payment_proto_msgTypes[10]
+	mi := &file_payment_service_proto_payment_proto_msgTypes[32]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -802,7 +2845,7 @@ func (x *UpdatePaymentStatusRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UpdatePaymentStatusRequest.ProtoReflect.Descriptor instead.
 func (*UpdatePaymentStatusRequest) Descriptor() ([]byte, []int) {
-	return file_payment_service_proto_payment_proto_rawDescGZIP(), []int{10}
+	return file_payment_service_proto_payment_proto_rawDescGZIP(), []int{32}
 }
 
 func (x *UpdatePaymentStatusRequest) GetPaymentId() string {
@@ -844,7 +2887,7 @@ type GetPendingPaymentsRequest struct {
 
 func (x *GetPendingPaymentsRequest) Reset() {
 	*x = GetPendingPaymentsRequest{}
-	mi := &file_payment_service_proto_payment_proto_msgTypes[11]
+	mi := &file_payment_service_proto_payment_proto_msgTypes[33]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -856,7 +2899,7 @@ func (x *GetPendingPaymentsRequest) String() string {
 func (*GetPendingPaymentsRequest) ProtoMessage() {}
 
 func (x *GetPendingPaymentsRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_payment_service_proto_payment_proto_msgTypes[11]
+	mi := &file_payment_service_proto_payment_proto_msgTypes[33]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -869,7 +2912,7 @@ func (x *GetPendingPaymentsRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetPendingPaymentsRequest.ProtoReflect.Descriptor instead.
 func (*GetPendingPaymentsRequest) Descriptor() ([]byte, []int) {
-	return file_payment_service_proto_payment_proto_rawDescGZIP(), []int{11}
+	return file_payment_service_proto_payment_proto_rawDescGZIP(), []int{33}
 }
 
 func (x *GetPendingPaymentsRequest) GetUserId() string {
@@ -903,7 +2946,7 @@ type GetPendingPaymentsResponse struct {
 
 func (x *GetPendingPaymentsResponse) Reset() {
 	*x = GetPendingPaymentsResponse{}
-	mi := &file_payment_service_proto_payment_proto_msgTypes[12]
+	mi := &file_payment_service_proto_payment_proto_msgTypes[34]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -915,7 +2958,7 @@ func (x *GetPendingPaymentsResponse) String() string {
 func (*GetPendingPaymentsResponse) ProtoMessage() {}
 
 func (x *GetPendingPaymentsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_payment_service_proto_payment_proto_msgTypes[12]
+	mi := &file_payment_service_proto_payment_proto_msgTypes[34]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -928,7 +2971,7 @@ func (x *GetPendingPaymentsResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetPendingPaymentsResponse.ProtoReflect.Descriptor instead.
 func (*GetPendingPaymentsResponse) Descriptor() ([]byte, []int) {
-	return file_payment_service_proto_payment_proto_rawDescGZIP(), []int{12}
+	return file_payment_service_proto_payment_proto_rawDescGZIP(), []int{34}
 }
 
 func (x *GetPendingPaymentsResponse) GetPayments() []*Payment {
@@ -955,7 +2998,7 @@ type RetryPaymentRequest struct {
 
 func (x *RetryPaymentRequest) Reset() {
 	*x = RetryPaymentRequest{}
-	mi := &file_payment_service_proto_payment_proto_msgTypes[13]
+	mi := &file_payment_service_proto_payment_proto_msgTypes[35]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -967,7 +3010,7 @@ func (x *RetryPaymentRequest) String() string {
 func (*RetryPaymentRequest) ProtoMessage() {}
 
 func (x *RetryPaymentRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_payment_service_proto_payment_proto_msgTypes[13]
+	mi := &file_payment_service_proto_payment_proto_msgTypes[35]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -980,7 +3023,7 @@ func (x *RetryPaymentRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RetryPaymentRequest.ProtoReflect.Descriptor instead.
 func (*RetryPaymentRequest) Descriptor() ([]byte, []int) {
-	return file_payment_service_proto_payment_proto_rawDescGZIP(), []int{13}
+	return file_payment_service_proto_payment_proto_rawDescGZIP(), []int{35}
 }
 
 func (x *RetryPaymentRequest) GetPaymentId() string {
@@ -997,16 +3040,187 @@ func (x *RetryPaymentRequest) GetNewPaymentMethod() PaymentMethod {
 	return PaymentMethod_PAYMENT_METHOD_UNSPECIFIED
 }
 
+type NetworkFeeReportRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	From  *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"`
+	// Now when unset.
+	To            *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=to,proto3" json:"to,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NetworkFeeReportRequest) Reset() {
+	*x = NetworkFeeReportRequest{}
+	mi := &file_payment_service_proto_payment_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NetworkFeeReportRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NetworkFeeReportRequest) ProtoMessage() {}
+
+func (x *NetworkFeeReportRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_service_proto_payment_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NetworkFeeReportRequest.ProtoReflect.Descriptor instead.
+func (*NetworkFeeReportRequest) Descriptor() ([]byte, []int) {
+	return file_payment_service_proto_payment_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *NetworkFeeReportRequest) GetFrom() *timestamppb.Timestamp {
+	if x != nil {
+		return x.From
+	}
+	return nil
+}
+
+func (x *NetworkFeeReportRequest) GetTo() *timestamppb.Timestamp {
+	if x != nil {
+		return x.To
+	}
+	return nil
+}
+
+type NetworkFeeReport struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Totals        []*NetworkFeeTotal     `protobuf:"bytes,1,rep,name=totals,proto3" json:"totals,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NetworkFeeReport) Reset() {
+	*x = NetworkFeeReport{}
+	mi := &file_payment_service_proto_payment_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NetworkFeeReport) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NetworkFeeReport) ProtoMessage() {}
+
+func (x *NetworkFeeReport) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_service_proto_payment_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NetworkFeeReport.ProtoReflect.Descriptor instead.
+func (*NetworkFeeReport) Descriptor() ([]byte, []int) {
+	return file_payment_service_proto_payment_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *NetworkFeeReport) GetTotals() []*NetworkFeeTotal {
+	if x != nil {
+		return x.Totals
+	}
+	return nil
+}
+
+// NetworkFeeTotal sums the fees of one chain and currency.
+type NetworkFeeTotal struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ChainId       uint64                 `protobuf:"varint,1,opt,name=chain_id,json=chainId,proto3" json:"chain_id,omitempty"`
+	Total         *Money                 `protobuf:"bytes,2,opt,name=total,proto3" json:"total,omitempty"`
+	GasUsed       uint64                 `protobuf:"varint,3,opt,name=gas_used,json=gasUsed,proto3" json:"gas_used,omitempty"`
+	PaymentCount  int64                  `protobuf:"varint,4,opt,name=payment_count,json=paymentCount,proto3" json:"payment_count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NetworkFeeTotal) Reset() {
+	*x = NetworkFeeTotal{}
+	mi := &file_payment_service_proto_payment_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NetworkFeeTotal) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NetworkFeeTotal) ProtoMessage() {}
+
+func (x *NetworkFeeTotal) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_service_proto_payment_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NetworkFeeTotal.ProtoReflect.Descriptor instead.
+func (*NetworkFeeTotal) Descriptor() ([]byte, []int) {
+	return file_payment_service_proto_payment_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *NetworkFeeTotal) GetChainId() uint64 {
+	if x != nil {
+		return x.ChainId
+	}
+	return 0
+}
+
+func (x *NetworkFeeTotal) GetTotal() *Money {
+	if x != nil {
+		return x.Total
+	}
+	return nil
+}
+
+func (x *NetworkFeeTotal) GetGasUsed() uint64 {
+	if x != nil {
+		return x.GasUsed
+	}
+	return 0
+}
+
+func (x *NetworkFeeTotal) GetPaymentCount() int64 {
+	if x != nil {
+		return x.PaymentCount
+	}
+	return 0
+}
+
 var File_payment_service_proto_payment_proto protoreflect.FileDescriptor
 
 const file_payment_service_proto_payment_proto_rawDesc = "" +
 	"\n" +
-	"#payment-service/proto/payment.proto\x12\apayment\x1a\x1fgoogle/protobuf/timestamp.proto\x1a\x1bgoogle/protobuf/empty.proto\"\xb2\x03\n" +
+	"#payment-service/proto/payment.proto\x12\apayment\x1a\x1fgoogle/protobuf/timestamp.proto\x1a\x1bgoogle/protobuf/empty.proto\"`\n" +
+	"\x05Money\x12\x1f\n" +
+	"\vminor_units\x18\x01 \x01(\x03R\n" +
+	"minorUnits\x12\x1a\n" +
+	"\bcurrency\x18\x02 \x01(\tR\bcurrency\x12\x1a\n" +
+	"\bexponent\x18\x03 \x01(\x05R\bexponent\"\xe4\a\n" +
 	"\aPayment\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x19\n" +
 	"\border_id\x18\x02 \x01(\tR\aorderId\x12\x17\n" +
-	"\auser_id\x18\x03 \x01(\tR\x06userId\x12\x16\n" +
-	"\x06amount\x18\x04 \x01(\x01R\x06amount\x12\x1a\n" +
+	"\auser_id\x18\x03 \x01(\tR\x06userId\x12\x1a\n" +
+	"\x06amount\x18\x04 \x01(\x01B\x02\x18\x01R\x06amount\x12\x1a\n" +
 	"\bcurrency\x18\x05 \x01(\tR\bcurrency\x12.\n" +
 	"\x06status\x18\x06 \x01(\x0e2\x16.payment.PaymentStatusR\x06status\x12=\n" +
 	"\x0epayment_method\x18\a \x01(\x0e2\x16.payment.PaymentMethodR\rpaymentMethod\x12%\n" +
@@ -1016,39 +3230,191 @@ const file_payment_service_proto_payment_proto_rawDesc = "" +
 	"created_at\x18\n" +
 	" \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
 	"\n" +
-	"updated_at\x18\v \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\"\xbf\x01\n" +
+	"updated_at\x18\v \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x12$\n" +
+	"\x05money\x18\f \x01(\v2\x0e.payment.MoneyR\x05money\x12/\n" +
+	"\ahistory\x18\r \x03(\v2\x15.payment.StatusChangeR\ahistory\x12;\n" +
+	"\x11authorized_amount\x18\x0e \x01(\v2\x0e.payment.MoneyR\x10authorizedAmount\x127\n" +
+	"\x0fcaptured_amount\x18\x0f \x01(\v2\x0e.payment.MoneyR\x0ecapturedAmount\x12(\n" +
+	"\x04card\x18\x10 \x01(\v2\x14.payment.CardSummaryR\x04card\x127\n" +
+	"\x0frefunded_amount\x18\x11 \x01(\v2\x0e.payment.MoneyR\x0erefundedAmount\x12)\n" +
+	"\x05quote\x18\x12 \x01(\v2\x13.payment.PriceQuoteR\x05quote\x12\x19\n" +
+	"\bchain_id\x18\x13 \x01(\x04R\achainId\x12%\n" +
+	"\x0ewallet_address\x18\x14 \x01(\tR\rwalletAddress\x124\n" +
+	"\vnetwork_fee\x18\x15 \x01(\v2\x13.payment.NetworkFeeR\n" +
+	"networkFee\x124\n" +
+	"\vnext_action\x18\x16 \x01(\v2\x13.payment.NextActionR\n" +
+	"nextAction\x12#\n" +
+	"\rcard_provider\x18\x17 \x01(\tR\fcardProvider\"\x81\x01\n" +
+	"\n" +
+	"NextAction\x12+\n" +
+	"\x04type\x18\x01 \x01(\x0e2\x17.payment.NextActionTypeR\x04type\x12!\n" +
+	"\fredirect_url\x18\x02 \x01(\tR\vredirectUrl\x12#\n" +
+	"\rclient_secret\x18\x03 \x01(\tR\fclientSecret\"\x9a\x01\n" +
+	"\n" +
+	"NetworkFee\x12\x19\n" +
+	"\bchain_id\x18\x01 \x01(\x04R\achainId\x12\x19\n" +
+	"\bgas_used\x18\x02 \x01(\x04R\agasUsed\x12.\n" +
+	"\x13effective_gas_price\x18\x03 \x01(\tR\x11effectiveGasPrice\x12&\n" +
+	"\x06amount\x18\x04 \x01(\v2\x0e.payment.MoneyR\x06amount\"\xfd\x01\n" +
+	"\n" +
+	"PriceQuote\x12\x1a\n" +
+	"\bcurrency\x18\x01 \x01(\tR\bcurrency\x12\x12\n" +
+	"\x04rate\x18\x02 \x01(\tR\x04rate\x12&\n" +
+	"\x06amount\x18\x03 \x01(\v2\x0e.payment.MoneyR\x06amount\x12!\n" +
+	"\fslippage_bps\x18\x04 \x01(\x05R\vslippageBps\x129\n" +
+	"\n" +
+	"expires_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\x129\n" +
+	"\n" +
+	"created_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"\xa6\x01\n" +
+	"\fStatusChange\x12*\n" +
+	"\x04from\x18\x01 \x01(\x0e2\x16.payment.PaymentStatusR\x04from\x12&\n" +
+	"\x02to\x18\x02 \x01(\x0e2\x16.payment.PaymentStatusR\x02to\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\x12*\n" +
+	"\x02at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\x02at\"\xb9\x02\n" +
 	"\x16InitiatePaymentRequest\x12\x19\n" +
 	"\border_id\x18\x01 \x01(\tR\aorderId\x12\x17\n" +
-	"\auser_id\x18\x02 \x01(\tR\x06userId\x12\x16\n" +
-	"\x06amount\x18\x03 \x01(\x01R\x06amount\x12\x1a\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x12\x1a\n" +
+	"\x06amount\x18\x03 \x01(\x01B\x02\x18\x01R\x06amount\x12\x1a\n" +
 	"\bcurrency\x18\x04 \x01(\tR\bcurrency\x12=\n" +
-	"\x0epayment_method\x18\x05 \x01(\x0e2\x16.payment.PaymentMethodR\rpaymentMethod\"o\n" +
+	"\x0epayment_method\x18\x05 \x01(\x0e2\x16.payment.PaymentMethodR\rpaymentMethod\x12%\n" +
+	"\x0ecustomer_email\x18\x06 \x01(\tR\rcustomerEmail\x12$\n" +
+	"\x05money\x18\a \x01(\v2\x0e.payment.MoneyR\x05money\x12'\n" +
+	"\x0fidempotency_key\x18\b \x01(\tR\x0eidempotencyKey\"\xa5\x01\n" +
 	"\x18CreditCardPaymentRequest\x12\x1d\n" +
 	"\n" +
-	"payment_id\x18\x01 \x01(\tR\tpaymentId\x124\n" +
-	"\tcard_info\x18\x02 \x01(\v2\x17.payment.CreditCardInfoR\bcardInfo\"\xb0\x01\n" +
-	"\x0eCreditCardInfo\x12\x1f\n" +
-	"\vcard_number\x18\x01 \x01(\tR\n" +
-	"cardNumber\x12!\n" +
-	"\fexpiry_month\x18\x02 \x01(\tR\vexpiryMonth\x12\x1f\n" +
-	"\vexpiry_year\x18\x03 \x01(\tR\n" +
-	"expiryYear\x12\x10\n" +
-	"\x03cvv\x18\x04 \x01(\tR\x03cvv\x12'\n" +
-	"\x0fcardholder_name\x18\x05 \x01(\tR\x0ecardholderName\"^\n" +
+	"payment_id\x18\x01 \x01(\tR\tpaymentId\x12'\n" +
+	"\x0fidempotency_key\x18\x03 \x01(\tR\x0eidempotencyKey\x120\n" +
+	"\x14payment_method_token\x18\x04 \x01(\tR\x12paymentMethodTokenJ\x04\b\x02\x10\x03R\tcard_info\"m\n" +
+	"\vCardSummary\x12\x14\n" +
+	"\x05brand\x18\x01 \x01(\tR\x05brand\x12\x14\n" +
+	"\x05last4\x18\x02 \x01(\tR\x05last4\x12\x18\n" +
+	"\afunding\x18\x03 \x01(\tR\afunding\x12\x18\n" +
+	"\acountry\x18\x04 \x01(\tR\acountry\"@\n" +
+	"\x1fConfirmCreditCardPaymentRequest\x12\x1d\n" +
+	"\n" +
+	"payment_id\x18\x01 \x01(\tR\tpaymentId\"\xd2\x01\n" +
 	"\x16MetaMaskPaymentRequest\x12\x1d\n" +
 	"\n" +
 	"payment_id\x18\x01 \x01(\tR\tpaymentId\x12%\n" +
-	"\x0ewallet_address\x18\x02 \x01(\tR\rwalletAddress\"\xbc\x01\n" +
+	"\x0ewallet_address\x18\x02 \x01(\tR\rwalletAddress\x12\x1a\n" +
+	"\bcurrency\x18\x03 \x01(\tR\bcurrency\x12\x19\n" +
+	"\bchain_id\x18\x04 \x01(\x04R\achainId\x12;\n" +
+	"\x0eqr_code_format\x18\x05 \x01(\x0e2\x15.payment.QRCodeFormatR\fqrCodeFormat\"\xf7\x04\n" +
 	"\x17MetaMaskPaymentResponse\x12\x1d\n" +
 	"\n" +
 	"payment_id\x18\x01 \x01(\tR\tpaymentId\x12)\n" +
 	"\x10transaction_hash\x18\x02 \x01(\tR\x0ftransactionHash\x12)\n" +
 	"\x10contract_address\x18\x03 \x01(\tR\x0fcontractAddress\x12,\n" +
-	"\x12payment_amount_wei\x18\x04 \x01(\tR\x10paymentAmountWei\"i\n" +
+	"\x12payment_amount_wei\x18\x04 \x01(\tR\x10paymentAmountWei\x12$\n" +
+	"\x05token\x18\x05 \x01(\v2\x0e.payment.TokenR\x05token\x12!\n" +
+	"\ftoken_amount\x18\x06 \x01(\tR\vtokenAmount\x12*\n" +
+	"\x11approve_call_data\x18\a \x01(\tR\x0fapproveCallData\x12,\n" +
+	"\x12transfer_call_data\x18\b \x01(\tR\x10transferCallData\x12)\n" +
+	"\x05quote\x18\t \x01(\v2\x13.payment.PriceQuoteR\x05quote\x12\x19\n" +
+	"\bchain_id\x18\n" +
+	" \x01(\x04R\achainId\x12=\n" +
+	"\vtransaction\x18\v \x01(\v2\x1b.payment.TransactionRequestR\vtransaction\x12\x1f\n" +
+	"\vpayment_uri\x18\f \x01(\tR\n" +
+	"paymentUri\x12\x17\n" +
+	"\aqr_code\x18\r \x01(\fR\x06qrCode\x12/\n" +
+	"\x14qr_code_content_type\x18\x0e \x01(\tR\x11qrCodeContentType\x12&\n" +
+	"\x03fee\x18\x0f \x01(\v2\x14.payment.FeeEstimateR\x03fee\"\xdd\x01\n" +
+	"\vFeeEstimate\x12\x1b\n" +
+	"\tgas_limit\x18\x01 \x01(\x04R\bgasLimit\x12'\n" +
+	"\x10base_fee_per_gas\x18\x02 \x01(\tR\rbaseFeePerGas\x126\n" +
+	"\x18max_priority_fee_per_gas\x18\x03 \x01(\tR\x14maxPriorityFeePerGas\x12%\n" +
+	"\x0fmax_fee_per_gas\x18\x04 \x01(\tR\fmaxFeePerGas\x12)\n" +
+	"\bmax_cost\x18\x05 \x01(\v2\x0e.payment.MoneyR\amaxCost\"\xee\x01\n" +
+	"\x12TransactionRequest\x12\x12\n" +
+	"\x04from\x18\x01 \x01(\tR\x04from\x12\x0e\n" +
+	"\x02to\x18\x02 \x01(\tR\x02to\x12\x14\n" +
+	"\x05value\x18\x03 \x01(\tR\x05value\x12\x12\n" +
+	"\x04data\x18\x04 \x01(\tR\x04data\x12\x19\n" +
+	"\bchain_id\x18\x05 \x01(\tR\achainId\x12\x10\n" +
+	"\x03gas\x18\x06 \x01(\tR\x03gas\x126\n" +
+	"\x18max_priority_fee_per_gas\x18\a \x01(\tR\x14maxPriorityFeePerGas\x12%\n" +
+	"\x0fmax_fee_per_gas\x18\b \x01(\tR\fmaxFeePerGas\"U\n" +
+	"\x05Token\x12\x16\n" +
+	"\x06symbol\x18\x01 \x01(\tR\x06symbol\x12\x18\n" +
+	"\aaddress\x18\x02 \x01(\tR\aaddress\x12\x1a\n" +
+	"\bdecimals\x18\x03 \x01(\x05R\bdecimals\"\x84\x01\n" +
 	"\x1dConfirmMetaMaskPaymentRequest\x12\x1d\n" +
 	"\n" +
 	"payment_id\x18\x01 \x01(\tR\tpaymentId\x12)\n" +
-	"\x10transaction_hash\x18\x02 \x01(\tR\x0ftransactionHash\"2\n" +
+	"\x10transaction_hash\x18\x02 \x01(\tR\x0ftransactionHash\x12\x19\n" +
+	"\bchain_id\x18\x03 \x01(\x04R\achainId\"\xab\x01\n" +
+	"\x16WalletChallengeRequest\x12\x1d\n" +
+	"\n" +
+	"payment_id\x18\x01 \x01(\tR\tpaymentId\x12%\n" +
+	"\x0ewallet_address\x18\x02 \x01(\tR\rwalletAddress\x120\n" +
+	"\x06scheme\x18\x03 \x01(\x0e2\x18.payment.SignatureSchemeR\x06scheme\x12\x19\n" +
+	"\bchain_id\x18\x04 \x01(\x04R\achainId\"\xae\x01\n" +
+	"\x0fWalletChallenge\x12\x14\n" +
+	"\x05nonce\x18\x01 \x01(\tR\x05nonce\x120\n" +
+	"\x06scheme\x18\x02 \x01(\x0e2\x18.payment.SignatureSchemeR\x06scheme\x12\x18\n" +
+	"\apayload\x18\x03 \x01(\tR\apayload\x129\n" +
+	"\n" +
+	"expires_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\"q\n" +
+	"\x1cVerifyWalletOwnershipRequest\x12\x1d\n" +
+	"\n" +
+	"payment_id\x18\x01 \x01(\tR\tpaymentId\x12\x14\n" +
+	"\x05nonce\x18\x02 \x01(\tR\x05nonce\x12\x1c\n" +
+	"\tsignature\x18\x03 \x01(\tR\tsignature\"\x87\x01\n" +
+	"\x15CapturePaymentRequest\x12\x1d\n" +
+	"\n" +
+	"payment_id\x18\x01 \x01(\tR\tpaymentId\x12&\n" +
+	"\x06amount\x18\x02 \x01(\v2\x0e.payment.MoneyR\x06amount\x12'\n" +
+	"\x0fidempotency_key\x18\x03 \x01(\tR\x0eidempotencyKey\"K\n" +
+	"\x12VoidPaymentRequest\x12\x1d\n" +
+	"\n" +
+	"payment_id\x18\x01 \x01(\tR\tpaymentId\x12\x16\n" +
+	"\x06reason\x18\x02 \x01(\tR\x06reason\"\x7f\n" +
+	"\x14ReviewPaymentRequest\x12\x1d\n" +
+	"\n" +
+	"payment_id\x18\x01 \x01(\tR\tpaymentId\x12\x18\n" +
+	"\aapprove\x18\x02 \x01(\bR\aapprove\x12\x1a\n" +
+	"\breviewer\x18\x03 \x01(\tR\breviewer\x12\x12\n" +
+	"\x04note\x18\x04 \x01(\tR\x04note\"8\n" +
+	"\x17GetRiskDecisionsRequest\x12\x1d\n" +
+	"\n" +
+	"payment_id\x18\x01 \x01(\tR\tpaymentId\"U\n" +
+	"\x18GetRiskDecisionsResponse\x129\n" +
+	"\tdecisions\x18\x01 \x03(\v2\x1b.payment.RiskDecisionRecordR\tdecisions\"\x8d\x02\n" +
+	"\x12RiskDecisionRecord\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1d\n" +
+	"\n" +
+	"payment_id\x18\x02 \x01(\tR\tpaymentId\x121\n" +
+	"\bdecision\x18\x03 \x01(\x0e2\x15.payment.RiskDecisionR\bdecision\x12\x14\n" +
+	"\x05score\x18\x04 \x01(\x05R\x05score\x12\x14\n" +
+	"\x05rules\x18\x05 \x03(\tR\x05rules\x12\x1a\n" +
+	"\breviewer\x18\x06 \x01(\tR\breviewer\x12\x12\n" +
+	"\x04note\x18\a \x01(\tR\x04note\x129\n" +
+	"\n" +
+	"created_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"\xf2\x02\n" +
+	"\x06Refund\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1d\n" +
+	"\n" +
+	"payment_id\x18\x02 \x01(\tR\tpaymentId\x12&\n" +
+	"\x06amount\x18\x03 \x01(\v2\x0e.payment.MoneyR\x06amount\x12\x16\n" +
+	"\x06reason\x18\x04 \x01(\tR\x06reason\x12-\n" +
+	"\x06status\x18\x05 \x01(\x0e2\x15.payment.RefundStatusR\x06status\x12/\n" +
+	"\x13processor_reference\x18\x06 \x01(\tR\x12processorReference\x12#\n" +
+	"\rerror_message\x18\a \x01(\tR\ferrorMessage\x129\n" +
+	"\n" +
+	"created_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\"\x9e\x01\n" +
+	"\x14RefundPaymentRequest\x12\x1d\n" +
+	"\n" +
+	"payment_id\x18\x01 \x01(\tR\tpaymentId\x12&\n" +
+	"\x06amount\x18\x02 \x01(\v2\x0e.payment.MoneyR\x06amount\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\x12'\n" +
+	"\x0fidempotency_key\x18\x04 \x01(\tR\x0eidempotencyKey\"3\n" +
+	"\x12ListRefundsRequest\x12\x1d\n" +
+	"\n" +
+	"payment_id\x18\x01 \x01(\tR\tpaymentId\"@\n" +
+	"\x13ListRefundsResponse\x12)\n" +
+	"\arefunds\x18\x01 \x03(\v2\x0f.payment.RefundR\arefunds\"2\n" +
 	"\x11GetPaymentRequest\x12\x1d\n" +
 	"\n" +
 	"payment_id\x18\x01 \x01(\tR\tpaymentId\"6\n" +
@@ -1072,7 +3438,17 @@ const file_payment_service_proto_payment_proto_rawDesc = "" +
 	"\x13RetryPaymentRequest\x12\x1d\n" +
 	"\n" +
 	"payment_id\x18\x01 \x01(\tR\tpaymentId\x12D\n" +
-	"\x12new_payment_method\x18\x02 \x01(\x0e2\x16.payment.PaymentMethodR\x10newPaymentMethod*\xde\x01\n" +
+	"\x12new_payment_method\x18\x02 \x01(\x0e2\x16.payment.PaymentMethodR\x10newPaymentMethod\"u\n" +
+	"\x17NetworkFeeReportRequest\x12.\n" +
+	"\x04from\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\x04from\x12*\n" +
+	"\x02to\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x02to\"D\n" +
+	"\x10NetworkFeeReport\x120\n" +
+	"\x06totals\x18\x01 \x03(\v2\x18.payment.NetworkFeeTotalR\x06totals\"\x92\x01\n" +
+	"\x0fNetworkFeeTotal\x12\x19\n" +
+	"\bchain_id\x18\x01 \x01(\x04R\achainId\x12$\n" +
+	"\x05total\x18\x02 \x01(\v2\x0e.payment.MoneyR\x05total\x12\x19\n" +
+	"\bgas_used\x18\x03 \x01(\x04R\agasUsed\x12#\n" +
+	"\rpayment_count\x18\x04 \x01(\x03R\fpaymentCount*\xe0\x03\n" +
 	"\rPaymentStatus\x12\x1e\n" +
 	"\x1aPAYMENT_STATUS_UNSPECIFIED\x10\x00\x12\x1a\n" +
 	"\x16PAYMENT_STATUS_PENDING\x10\x01\x12\x1d\n" +
@@ -1080,22 +3456,64 @@ const file_payment_service_proto_payment_proto_rawDesc = "" +
 	"\x18PAYMENT_STATUS_COMPLETED\x10\x03\x12\x19\n" +
 	"\x15PAYMENT_STATUS_FAILED\x10\x04\x12\x1c\n" +
 	"\x18PAYMENT_STATUS_CANCELLED\x10\x05\x12\x1b\n" +
-	"\x17PAYMENT_STATUS_REFUNDED\x10\x06*l\n" +
+	"\x17PAYMENT_STATUS_REFUNDED\x10\x06\x12%\n" +
+	"!PAYMENT_STATUS_PARTIALLY_REFUNDED\x10\a\x12\x1d\n" +
+	"\x19PAYMENT_STATUS_AUTHORIZED\x10\b\x12\x1b\n" +
+	"\x17PAYMENT_STATUS_CAPTURED\x10\t\x12\x19\n" +
+	"\x15PAYMENT_STATUS_VOIDED\x10\n" +
+	"\x12\x1b\n" +
+	"\x17PAYMENT_STATUS_DISPUTED\x10\v\x12\x1f\n" +
+	"\x1bPAYMENT_STATUS_CHARGED_BACK\x10\f\x12\"\n" +
+	"\x1ePAYMENT_STATUS_REQUIRES_ACTION\x10\r\x12 \n" +
+	"\x1cPAYMENT_STATUS_MANUAL_REVIEW\x10\x0e*l\n" +
 	"\rPaymentMethod\x12\x1e\n" +
 	"\x1aPAYMENT_METHOD_UNSPECIFIED\x10\x00\x12\x1e\n" +
 	"\x1aPAYMENT_METHOD_CREDIT_CARD\x10\x01\x12\x1b\n" +
-	"\x17PAYMENT_METHOD_METAMASK\x10\x022\xe1\x05\n" +
+	"\x17PAYMENT_METHOD_METAMASK\x10\x02*v\n" +
+	"\x0eNextActionType\x12 \n" +
+	"\x1cNEXT_ACTION_TYPE_UNSPECIFIED\x10\x00\x12$\n" +
+	" NEXT_ACTION_TYPE_REDIRECT_TO_URL\x10\x01\x12\x1c\n" +
+	"\x18NEXT_ACTION_TYPE_USE_SDK\x10\x02*^\n" +
+	"\fQRCodeFormat\x12\x1e\n" +
+	"\x1aQR_CODE_FORMAT_UNSPECIFIED\x10\x00\x12\x16\n" +
+	"\x12QR_CODE_FORMAT_PNG\x10\x01\x12\x16\n" +
+	"\x12QR_CODE_FORMAT_SVG\x10\x02*m\n" +
+	"\x0fSignatureScheme\x12 \n" +
+	"\x1cSIGNATURE_SCHEME_UNSPECIFIED\x10\x00\x12\x1b\n" +
+	"\x17SIGNATURE_SCHEME_EIP712\x10\x01\x12\x1b\n" +
+	"\x17SIGNATURE_SCHEME_EIP191\x10\x02*x\n" +
+	"\fRiskDecision\x12\x1d\n" +
+	"\x19RISK_DECISION_UNSPECIFIED\x10\x00\x12\x17\n" +
+	"\x13RISK_DECISION_ALLOW\x10\x01\x12\x18\n" +
+	"\x14RISK_DECISION_REVIEW\x10\x02\x12\x16\n" +
+	"\x12RISK_DECISION_DENY\x10\x03*\x7f\n" +
+	"\fRefundStatus\x12\x1d\n" +
+	"\x19REFUND_STATUS_UNSPECIFIED\x10\x00\x12\x19\n" +
+	"\x15REFUND_STATUS_PENDING\x10\x01\x12\x1b\n" +
+	"\x17REFUND_STATUS_SUCCEEDED\x10\x02\x12\x18\n" +
+	"\x14REFUND_STATUS_FAILED\x10\x032\xaf\f\n" +
 	"\x0ePaymentService\x12D\n" +
 	"\x0fInitiatePayment\x12\x1f.payment.InitiatePaymentRequest\x1a\x10.payment.Payment\x12O\n" +
-	"\x18ProcessCreditCardPayment\x12!.payment.CreditCardPaymentRequest\x1a\x10.payment.Payment\x12\\\n" +
+	"\x18ProcessCreditCardPayment\x12!.payment.CreditCardPaymentRequest\x1a\x10.payment.Payment\x12V\n" +
+	"\x18ConfirmCreditCardPayment\x12(.payment.ConfirmCreditCardPaymentRequest\x1a\x10.payment.Payment\x12\\\n" +
 	"\x17InitiateMetaMaskPayment\x12\x1f.payment.MetaMaskPaymentRequest\x1a .payment.MetaMaskPaymentResponse\x12R\n" +
-	"\x16ConfirmMetaMaskPayment\x12&.payment.ConfirmMetaMaskPaymentRequest\x1a\x10.payment.Payment\x12:\n" +
+	"\x16ConfirmMetaMaskPayment\x12&.payment.ConfirmMetaMaskPaymentRequest\x1a\x10.payment.Payment\x12S\n" +
+	"\x16RequestWalletChallenge\x12\x1f.payment.WalletChallengeRequest\x1a\x18.payment.WalletChallenge\x12P\n" +
+	"\x15VerifyWalletOwnership\x12%.payment.VerifyWalletOwnershipRequest\x1a\x10.payment.Payment\x12Q\n" +
+	"\x1aAuthorizeCreditCardPayment\x12!.payment.CreditCardPaymentRequest\x1a\x10.payment.Payment\x12B\n" +
+	"\x0eCapturePayment\x12\x1e.payment.CapturePaymentRequest\x1a\x10.payment.Payment\x12<\n" +
+	"\vVoidPayment\x12\x1b.payment.VoidPaymentRequest\x1a\x10.payment.Payment\x12@\n" +
+	"\rReviewPayment\x12\x1d.payment.ReviewPaymentRequest\x1a\x10.payment.Payment\x12W\n" +
+	"\x10GetRiskDecisions\x12 .payment.GetRiskDecisionsRequest\x1a!.payment.GetRiskDecisionsResponse\x12?\n" +
+	"\rRefundPayment\x12\x1d.payment.RefundPaymentRequest\x1a\x0f.payment.Refund\x12H\n" +
+	"\vListRefunds\x12\x1b.payment.ListRefundsRequest\x1a\x1c.payment.ListRefundsResponse\x12:\n" +
 	"\n" +
 	"GetPayment\x12\x1a.payment.GetPaymentRequest\x1a\x10.payment.Payment\x12]\n" +
 	"\x12GetPaymentsByOrder\x12\".payment.GetPaymentsByOrderRequest\x1a#.payment.GetPaymentsByOrderResponse\x12L\n" +
 	"\x13UpdatePaymentStatus\x12#.payment.UpdatePaymentStatusRequest\x1a\x10.payment.Payment\x12]\n" +
 	"\x12GetPendingPayments\x12\".payment.GetPendingPaymentsRequest\x1a#.payment.GetPendingPaymentsResponse\x12>\n" +
-	"\fRetryPayment\x12\x1c.payment.RetryPaymentRequest\x1a\x10.payment.PaymentB/Z-github.com/yourusername/payment-service/protob\x06proto3"
+	"\fRetryPayment\x12\x1c.payment.RetryPaymentRequest\x1a\x10.payment.Payment\x12R\n" +
+	"\x13GetNetworkFeeReport\x12 .payment.NetworkFeeReportRequest\x1a\x19.payment.NetworkFeeReportB)Z'github.com/hsibAD/payment-service/protob\x06proto3"
 
 var (
 	file_payment_service_proto_payment_proto_rawDescOnce sync.Once
@@ -1109,61 +3527,153 @@ func file_payment_service_proto_payment_proto_rawDescGZIP() []byte {
 	return file_payment_service_proto_payment_proto_rawDescData
 }
 
-var file_payment_service_proto_payment_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
-var file_payment_service_proto_payment_proto_msgTypes = make([]protoimpl.MessageInfo, 14)
+var file_payment_service_proto_payment_proto_enumTypes = make([]protoimpl.EnumInfo, 7)
+var file_payment_service_proto_payment_proto_msgTypes = make([]protoimpl.MessageInfo, 39)
 var file_payment_service_proto_payment_proto_goTypes = []any{
-	(PaymentStatus)(0),                    // 0: payment.PaymentStatus
-	(PaymentMethod)(0),                    // 1: payment.PaymentMethod
-	(*Payment)(nil),                       // 2: payment.Payment
-	(*InitiatePaymentRequest)(nil),        // 3: payment.InitiatePaymentRequest
-	(*CreditCardPaymentRequest)(nil),      // 4: payment.CreditCardPaymentRequest
-	(*CreditCardInfo)(nil),                // 5: payment.CreditCardInfo
-	(*MetaMaskPaymentRequest)(nil),        // 6: payment.MetaMaskPaymentRequest
-	(*MetaMaskPaymentResponse)(nil),       // 7: payment.MetaMaskPaymentResponse
-	(*ConfirmMetaMaskPaymentRequest)(nil), // 8: payment.ConfirmMetaMaskPaymentRequest
-	(*GetPaymentRequest)(nil),             // 9: payment.GetPaymentRequest
-	(*GetPaymentsByOrderRequest)(nil),     // 10: payment.GetPaymentsByOrderRequest
-	(*GetPaymentsByOrderResponse)(nil),    // 11: payment.GetPaymentsByOrderResponse
-	(*UpdatePaymentStatusRequest)(nil),    // 12: payment.UpdatePaymentStatusRequest
-	(*GetPendingPaymentsRequest)(nil),     // 13: payment.GetPendingPaymentsRequest
-	(*GetPendingPaymentsResponse)(nil),    // 14: payment.GetPendingPaymentsResponse
-	(*RetryPaymentRequest)(nil),           // 15: payment.RetryPaymentRequest
-	(*timestamppb.Timestamp)(nil),         // 16: google.protobuf.Timestamp
+	(PaymentStatus)(0),                      // 0: payment.PaymentStatus
+	(PaymentMethod)(0),                      // 1: payment.PaymentMethod
+	(NextActionType)(0),                     // 2: payment.NextActionType
+	(QRCodeFormat)(0),                       // 3: payment.QRCodeFormat
+	(SignatureScheme)(0),                    // 4: payment.SignatureScheme
+	(RiskDecision)(0),                       // 5: payment.RiskDecision
+	(RefundStatus)(0),                       // 6: payment.RefundStatus
+	(*Money)(nil),                           // 7: payment.Money
+	(*Payment)(nil),                         // 8: payment.Payment
+	(*NextAction)(nil),                      // 9: payment.NextAction
+	(*NetworkFee)(nil),                      // 10: payment.NetworkFee
+	(*PriceQuote)(nil),                      // 11: payment.PriceQuote
+	(*StatusChange)(nil),                    // 12: payment.StatusChange
+	(*InitiatePaymentRequest)(nil),          // 13: payment.InitiatePaymentRequest
+	(*CreditCardPaymentRequest)(nil),        // 14: payment.CreditCardPaymentRequest
+	(*CardSummary)(nil),                     // 15: payment.CardSummary
+	(*ConfirmCreditCardPaymentRequest)(nil), // 16: payment.ConfirmCreditCardPaymentRequest
+	(*MetaMaskPaymentRequest)(nil),          // 17: payment.MetaMaskPaymentRequest
+	(*MetaMaskPaymentResponse)(nil),         // 18: payment.MetaMaskPaymentResponse
+	(*FeeEstimate)(nil),                     // 19: payment.FeeEstimate
+	(*TransactionRequest)(nil),              // 20: payment.TransactionRequest
+	(*Token)(nil),                           // 21: payment.Token
+	(*ConfirmMetaMaskPaymentRequest)(nil),   // 22: payment.ConfirmMetaMaskPaymentRequest
+	(*WalletChallengeRequest)(nil),          // 23: payment.WalletChallengeRequest
+	(*WalletChallenge)(nil),                 // 24: payment.WalletChallenge
+	(*VerifyWalletOwnershipRequest)(nil),    // 25: payment.VerifyWalletOwnershipRequest
+	(*CapturePaymentRequest)(nil),           // 26: payment.CapturePaymentRequest
+	(*VoidPaymentRequest)(nil),              // 27: payment.VoidPaymentRequest
+	(*ReviewPaymentRequest)(nil),            // 28: payment.ReviewPaymentRequest
+	(*GetRiskDecisionsRequest)(nil),         // 29: payment.GetRiskDecisionsRequest
+	(*GetRiskDecisionsResponse)(nil),        // 30: payment.GetRiskDecisionsResponse
+	(*RiskDecisionRecord)(nil),              // 31: payment.RiskDecisionRecord
+	(*Refund)(nil),                          // 32: payment.Refund
+	(*RefundPaymentRequest)(nil),            // 33: payment.RefundPaymentRequest
+	(*ListRefundsRequest)(nil),              // 34: payment.ListRefundsRequest
+	(*ListRefundsResponse)(nil),             // 35: payment.ListRefundsResponse
+	(*GetPaymentRequest)(nil),               // 36: payment.GetPaymentRequest
+	(*GetPaymentsByOrderRequest)(nil),       // 37: payment.GetPaymentsByOrderRequest
+	(*GetPaymentsByOrderResponse)(nil),      // 38: payment.GetPaymentsByOrderResponse
+	(*UpdatePaymentStatusRequest)(nil),      // 39: payment.UpdatePaymentStatusRequest
+	(*GetPendingPaymentsRequest)(nil),       // 40: payment.GetPendingPaymentsRequest
+	(*GetPendingPaymentsResponse)(nil),      // 41: payment.GetPendingPaymentsResponse
+	(*RetryPaymentRequest)(nil),             // 42: payment.RetryPaymentRequest
+	(*NetworkFeeReportRequest)(nil),         // 43: payment.NetworkFeeReportRequest
+	(*NetworkFeeReport)(nil),                // 44: payment.NetworkFeeReport
+	(*NetworkFeeTotal)(nil),                 // 45: payment.NetworkFeeTotal
+	(*timestamppb.Timestamp)(nil),           // 46: google.protobuf.Timestamp
 }
 var file_payment_service_proto_payment_proto_depIdxs = []int32{
 	0,  // 0: payment.Payment.status:type_name -> payment.PaymentStatus
 	1,  // 1: payment.Payment.payment_method:type_name -> payment.PaymentMethod
-	16, // 2: payment.Payment.created_at:type_name -> google.protobuf.Timestamp
-	16, // 3: payment.Payment.updated_at:type_name -> google.protobuf.Timestamp
-	1,  // 4: payment.InitiatePaymentRequest.payment_method:type_name -> payment.PaymentMethod
-	5,  // 5: payment.CreditCardPaymentRequest.card_info:type_name -> payment.CreditCardInfo
-	2,  // 6: payment.GetPaymentsByOrderResponse.payments:type_name -> payment.Payment
-	0,  // 7: payment.UpdatePaymentStatusRequest.status:type_name -> payment.PaymentStatus
-	2,  // 8: payment.GetPendingPaymentsResponse.payments:type_name -> payment.Payment
-	1,  // 9: payment.RetryPaymentRequest.new_payment_method:type_name -> payment.PaymentMethod
-	3,  // 10: payment.PaymentService.InitiatePayment:input_type -> payment.InitiatePaymentRequest
-	4,  // 11: payment.PaymentService.ProcessCreditCardPayment:input_type -> payment.CreditCardPaymentRequest
-	6,  // 12: payment.PaymentService.InitiateMetaMaskPayment:input_type -> payment.MetaMaskPaymentRequest
-	8,  // 13: payment.PaymentService.ConfirmMetaMaskPayment:input_type -> payment.ConfirmMetaMaskPaymentRequest
-	9,  // 14: payment.PaymentService.GetPayment:input_type -> payment.GetPaymentRequest
-	10, // 15: payment.PaymentService.GetPaymentsByOrder:input_type -> payment.GetPaymentsByOrderRequest
-	12, // 16: payment.PaymentService.UpdatePaymentStatus:input_type -> payment.UpdatePaymentStatusRequest
-	13, // 17: payment.PaymentService.GetPendingPayments:input_type -> payment.GetPendingPaymentsRequest
-	15, // 18: payment.PaymentService.RetryPayment:input_type -> payment.RetryPaymentRequest
-	2,  // 19: payment.PaymentService.InitiatePayment:output_type -> payment.Payment
-	2,  // 20: payment.PaymentService.ProcessCreditCardPayment:output_type -> payment.Payment
-	7,  // 21: payment.PaymentService.InitiateMetaMaskPayment:output_type -> payment.MetaMaskPaymentResponse
-	2,  // 22: payment.PaymentService.ConfirmMetaMaskPayment:output_type -> payment.Payment
-	2,  // 23: payment.PaymentService.GetPayment:output_type -> payment.Payment
-	11, // 24: payment.PaymentService.GetPaymentsByOrder:output_type -> payment.GetPaymentsByOrderResponse
-	2,  // 25: payment.PaymentService.UpdatePaymentStatus:output_type -> payment.Payment
-	14, // 26: payment.PaymentService.GetPendingPayments:output_type -> payment.GetPendingPaymentsResponse
-	2,  // 27: payment.PaymentService.RetryPayment:output_type -> payment.Payment
-	19, // [19:28] is the sub-list for method output_type
-	10, // [10:19] is the sub-list for method input_type
-	10, // [10:10] is the sub-list for extension type_name
-	10, // [10:10] is the sub-list for extension extendee
-	0,  // [0:10] is the sub-list for field type_name
+	46, // 2: payment.Payment.created_at:type_name -> google.protobuf.Timestamp
+	46, // 3: payment.Payment.updated_at:type_name -> google.protobuf.Timestamp
+	7,  // 4: payment.Payment.money:type_name -> payment.Money
+	12, // 5: payment.Payment.history:type_name -> payment.StatusChange
+	7,  // 6: payment.Payment.authorized_amount:type_name -> payment.Money
+	7,  // 7: payment.Payment.captured_amount:type_name -> payment.Money
+	15, // 8: payment.Payment.card:type_name -> payment.CardSummary
+	7,  // 9: payment.Payment.refunded_amount:type_name -> payment.Money
+	11, // 10: payment.Payment.quote:type_name -> payment.PriceQuote
+	10, // 11: payment.Payment.network_fee:type_name -> payment.NetworkFee
+	9,  // 12: payment.Payment.next_action:type_name -> payment.NextAction
+	2,  // 13: payment.NextAction.type:type_name -> payment.NextActionType
+	7,  // 14: payment.NetworkFee.amount:type_name -> payment.Money
+	7,  // 15: payment.PriceQuote.amount:type_name -> payment.Money
+	46, // 16: payment.PriceQuote.expires_at:type_name -> google.protobuf.Timestamp
+	46, // 17: payment.PriceQuote.created_at:type_name -> google.protobuf.Timestamp
+	0,  // 18: payment.StatusChange.from:type_name -> payment.PaymentStatus
+	0,  // 19: payment.StatusChange.to:type_name -> payment.PaymentStatus
+	46, // 20: payment.StatusChange.at:type_name -> google.protobuf.Timestamp
+	1,  // 21: payment.InitiatePaymentRequest.payment_method:type_name -> payment.PaymentMethod
+	7,  // 22: payment.InitiatePaymentRequest.money:type_name -> payment.Money
+	3,  // 23: payment.MetaMaskPaymentRequest.qr_code_format:type_name -> payment.QRCodeFormat
+	21, // 24: payment.MetaMaskPaymentResponse.token:type_name -> payment.Token
+	11, // 25: payment.MetaMaskPaymentResponse.quote:type_name -> payment.PriceQuote
+	20, // 26: payment.MetaMaskPaymentResponse.transaction:type_name -> payment.TransactionRequest
+	19, // 27: payment.MetaMaskPaymentResponse.fee:type_name -> payment.FeeEstimate
+	7,  // 28: payment.FeeEstimate.max_cost:type_name -> payment.Money
+	4,  // 29: payment.WalletChallengeRequest.scheme:type_name -> payment.SignatureScheme
+	4,  // 30: payment.WalletChallenge.scheme:type_name -> payment.SignatureScheme
+	46, // 31: payment.WalletChallenge.expires_at:type_name -> google.protobuf.Timestamp
+	7,  // 32: payment.CapturePaymentRequest.amount:type_name -> payment.Money
+	31, // 33: payment.GetRiskDecisionsResponse.decisions:type_name -> payment.RiskDecisionRecord
+	5,  // 34: payment.RiskDecisionRecord.decision:type_name -> payment.RiskDecision
+	46, // 35: payment.RiskDecisionRecord.created_at:type_name -> google.protobuf.Timestamp
+	7,  // 36: payment.Refund.amount:type_name -> payment.Money
+	6,  // 37: payment.Refund.status:type_name -> payment.RefundStatus
+	46, // 38: payment.Refund.created_at:type_name -> google.protobuf.Timestamp
+	46, // 39: payment.Refund.updated_at:type_name -> google.protobuf.Timestamp
+	7,  // 40: payment.RefundPaymentRequest.amount:type_name -> payment.Money
+	32, // 41: payment.ListRefundsResponse.refunds:type_name -> payment.Refund
+	8,  // 42: payment.GetPaymentsByOrderResponse.payments:type_name -> payment.Payment
+	0,  // 43: payment.UpdatePaymentStatusRequest.status:type_name -> payment.PaymentStatus
+	8,  // 44: payment.GetPendingPaymentsResponse.payments:type_name -> payment.Payment
+	1,  // 45: payment.RetryPaymentRequest.new_payment_method:type_name -> payment.PaymentMethod
+	46, // 46: payment.NetworkFeeReportRequest.from:type_name -> google.protobuf.Timestamp
+	46, // 47: payment.NetworkFeeReportRequest.to:type_name -> google.protobuf.Timestamp
+	45, // 48: payment.NetworkFeeReport.totals:type_name -> payment.NetworkFeeTotal
+	7,  // 49: payment.NetworkFeeTotal.total:type_name -> payment.Money
+	13, // 50: payment.PaymentService.InitiatePayment:input_type -> payment.InitiatePaymentRequest
+	14, // 51: payment.PaymentService.ProcessCreditCardPayment:input_type -> payment.CreditCardPaymentRequest
+	16, // 52: payment.PaymentService.ConfirmCreditCardPayment:input_type -> payment.ConfirmCreditCardPaymentRequest
+	17, // 53: payment.PaymentService.InitiateMetaMaskPayment:input_type -> payment.MetaMaskPaymentRequest
+	22, // 54: payment.PaymentService.ConfirmMetaMaskPayment:input_type -> payment.ConfirmMetaMaskPaymentRequest
+	23, // 55: payment.PaymentService.RequestWalletChallenge:input_type -> payment.WalletChallengeRequest
+	25, // 56: payment.PaymentService.VerifyWalletOwnership:input_type -> payment.VerifyWalletOwnershipRequest
+	14, // 57: payment.PaymentService.AuthorizeCreditCardPayment:input_type -> payment.CreditCardPaymentRequest
+	26, // 58: payment.PaymentService.CapturePayment:input_type -> payment.CapturePaymentRequest
+	27, // 59: payment.PaymentService.VoidPayment:input_type -> payment.VoidPaymentRequest
+	28, // 60: payment.PaymentService.ReviewPayment:input_type -> payment.ReviewPaymentRequest
+	29, // 61: payment.PaymentService.GetRiskDecisions:input_type -> payment.GetRiskDecisionsRequest
+	33, // 62: payment.PaymentService.RefundPayment:input_type -> payment.RefundPaymentRequest
+	34, // 63: payment.PaymentService.ListRefunds:input_type -> payment.ListRefundsRequest
+	36, // 64: payment.PaymentService.GetPayment:input_type -> payment.GetPaymentRequest
+	37, // 65: payment.PaymentService.GetPaymentsByOrder:input_type -> payment.GetPaymentsByOrderRequest
+	39, // 66: payment.PaymentService.UpdatePaymentStatus:input_type -> payment.UpdatePaymentStatusRequest
+	40, // 67: payment.PaymentService.GetPendingPayments:input_type -> payment.GetPendingPaymentsRequest
+	42, // 68: payment.PaymentService.RetryPayment:input_type -> payment.RetryPaymentRequest
+	43, // 69: payment.PaymentService.GetNetworkFeeReport:input_type -> payment.NetworkFeeReportRequest
+	8,  // 70: payment.PaymentService.InitiatePayment:output_type -> payment.Payment
+	8,  // 71: payment.PaymentService.ProcessCreditCardPayment:output_type -> payment.Payment
+	8,  // 72: payment.PaymentService.ConfirmCreditCardPayment:output_type -> payment.Payment
+	18, // 73: payment.PaymentService.InitiateMetaMaskPayment:output_type -> payment.MetaMaskPaymentResponse
+	8,  // 74: payment.PaymentService.ConfirmMetaMaskPayment:output_type -> payment.Payment
+	24, // 75: payment.PaymentService.RequestWalletChallenge:output_type -> payment.WalletChallenge
+	8,  // 76: payment.PaymentService.VerifyWalletOwnership:output_type -> payment.Payment
+	8,  // 77: payment.PaymentService.AuthorizeCreditCardPayment:output_type -> payment.Payment
+	8,  // 78: payment.PaymentService.CapturePayment:output_type -> payment.Payment
+	8,  // 79: payment.PaymentService.VoidPayment:output_type -> payment.Payment
+	8,  // 80: payment.PaymentService.ReviewPayment:output_type -> payment.Payment
+	30, // 81: payment.PaymentService.GetRiskDecisions:output_type -> payment.GetRiskDecisionsResponse
+	32, // 82: payment.PaymentService.RefundPayment:output_type -> payment.Refund
+	35, // 83: payment.PaymentService.ListRefunds:output_type -> payment.ListRefundsResponse
+	8,  // 84: payment.PaymentService.GetPayment:output_type -> payment.Payment
+	38, // 85: payment.PaymentService.GetPaymentsByOrder:output_type -> payment.GetPaymentsByOrderResponse
+	8,  // 86: payment.PaymentService.UpdatePaymentStatus:output_type -> payment.Payment
+	41, // 87: payment.PaymentService.GetPendingPayments:output_type -> payment.GetPendingPaymentsResponse
+	8,  // 88: payment.PaymentService.RetryPayment:output_type -> payment.Payment
+	44, // 89: payment.PaymentService.GetNetworkFeeReport:output_type -> payment.NetworkFeeReport
+	70, // [70:90] is the sub-list for method output_type
+	50, // [50:70] is the sub-list for method input_type
+	50, // [50:50] is the sub-list for extension type_name
+	50, // [50:50] is the sub-list for extension extendee
+	0,  // [0:50] is the sub-list for field type_name
 }
 
 func init() { file_payment_service_proto_payment_proto_init() }
@@ -1176,8 +3686,8 @@ func file_payment_service_proto_payment_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_payment_service_proto_payment_proto_rawDesc), len(file_payment_service_proto_payment_proto_rawDesc)),
-			NumEnums:      2,
-			NumMessages:   14,
+			NumEnums:      7,
+			NumMessages:   39,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
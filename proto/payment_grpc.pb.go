@@ -19,15 +19,26 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	PaymentService_InitiatePayment_FullMethodName          = "/payment.PaymentService/InitiatePayment"
-	PaymentService_ProcessCreditCardPayment_FullMethodName = "/payment.PaymentService/ProcessCreditCardPayment"
-	PaymentService_InitiateMetaMaskPayment_FullMethodName  = "/payment.PaymentService/InitiateMetaMaskPayment"
-	PaymentService_ConfirmMetaMaskPayment_FullMethodName   = "/payment.PaymentService/ConfirmMetaMaskPayment"
-	PaymentService_GetPayment_FullMethodName               = "/payment.PaymentService/GetPayment"
-	PaymentService_GetPaymentsByOrder_FullMethodName       = "/payment.PaymentService/GetPaymentsByOrder"
-	PaymentService_UpdatePaymentStatus_FullMethodName      = "/payment.PaymentService/UpdatePaymentStatus"
-	PaymentService_GetPendingPayments_FullMethodName       = "/payment.PaymentService/GetPendingPayments"
-	PaymentService_RetryPayment_FullMethodName             = "/payment.PaymentService/RetryPayment"
+	PaymentService_InitiatePayment_FullMethodName            = "/payment.PaymentService/InitiatePayment"
+	PaymentService_ProcessCreditCardPayment_FullMethodName   = "/payment.PaymentService/ProcessCreditCardPayment"
+	PaymentService_ConfirmCreditCardPayment_FullMethodName   = "/payment.PaymentService/ConfirmCreditCardPayment"
+	PaymentService_InitiateMetaMaskPayment_FullMethodName    = "/payment.PaymentService/InitiateMetaMaskPayment"
+	PaymentService_ConfirmMetaMaskPayment_FullMethodName     = "/payment.PaymentService/ConfirmMetaMaskPayment"
+	PaymentService_RequestWalletChallenge_FullMethodName     = "/payment.PaymentService/RequestWalletChallenge"
+	PaymentService_VerifyWalletOwnership_FullMethodName      = "/payment.PaymentService/VerifyWalletOwnership"
+	PaymentService_AuthorizeCreditCardPayment_FullMethodName = "/payment.PaymentService/AuthorizeCreditCardPayment"
+	PaymentService_CapturePayment_FullMethodName             = "/payment.PaymentService/CapturePayment"
+	PaymentService_VoidPayment_FullMethodName                = "/payment.PaymentService/VoidPayment"
+	PaymentService_ReviewPayment_FullMethodName              = "/payment.PaymentService/ReviewPayment"
+	PaymentService_GetRiskDecisions_FullMethodName           = "/payment.PaymentService/GetRiskDecisions"
+	PaymentService_RefundPayment_FullMethodName              = "/payment.PaymentService/RefundPayment"
+	PaymentService_ListRefunds_FullMethodName                = "/payment.PaymentService/ListRefunds"
+	PaymentService_GetPayment_FullMethodName                 = "/payment.PaymentService/GetPayment"
+	PaymentService_GetPaymentsByOrder_FullMethodName         = "/payment.PaymentService/GetPaymentsByOrder"
+	PaymentService_UpdatePaymentStatus_FullMethodName        = "/payment.PaymentService/UpdatePaymentStatus"
+	PaymentService_GetPendingPayments_FullMethodName         = "/payment.PaymentService/GetPendingPayments"
+	PaymentService_RetryPayment_FullMethodName               = "/payment.PaymentService/RetryPayment"
+	PaymentService_GetNetworkFeeReport_FullMethodName        = "/payment.PaymentService/GetNetworkFeeReport"
 )
 
 // PaymentServiceClient is the client API for PaymentService service.
@@ -37,8 +48,25 @@ type PaymentServiceClient interface {
 	// Payment Processing
 	InitiatePayment(ctx context.Context, in *InitiatePaymentRequest, opts ...grpc.CallOption) (*Payment, error)
 	ProcessCreditCardPayment(ctx context.Context, in *CreditCardPaymentRequest, opts ...grpc.CallOption) (*Payment, error)
+	// Finishes a card payment in PAYMENT_STATUS_REQUIRES_ACTION after the
+	// customer authenticated it.
+	ConfirmCreditCardPayment(ctx context.Context, in *ConfirmCreditCardPaymentRequest, opts ...grpc.CallOption) (*Payment, error)
 	InitiateMetaMaskPayment(ctx context.Context, in *MetaMaskPaymentRequest, opts ...grpc.CallOption) (*MetaMaskPaymentResponse, error)
 	ConfirmMetaMaskPayment(ctx context.Context, in *ConfirmMetaMaskPaymentRequest, opts ...grpc.CallOption) (*Payment, error)
+	// Wallet ownership
+	RequestWalletChallenge(ctx context.Context, in *WalletChallengeRequest, opts ...grpc.CallOption) (*WalletChallenge, error)
+	VerifyWalletOwnership(ctx context.Context, in *VerifyWalletOwnershipRequest, opts ...grpc.CallOption) (*Payment, error)
+	// Authorize-then-capture
+	AuthorizeCreditCardPayment(ctx context.Context, in *CreditCardPaymentRequest, opts ...grpc.CallOption) (*Payment, error)
+	CapturePayment(ctx context.Context, in *CapturePaymentRequest, opts ...grpc.CallOption) (*Payment, error)
+	VoidPayment(ctx context.Context, in *VoidPaymentRequest, opts ...grpc.CallOption) (*Payment, error)
+	// Risk review
+	// Approves or rejects a card payment in PAYMENT_STATUS_MANUAL_REVIEW.
+	ReviewPayment(ctx context.Context, in *ReviewPaymentRequest, opts ...grpc.CallOption) (*Payment, error)
+	GetRiskDecisions(ctx context.Context, in *GetRiskDecisionsRequest, opts ...grpc.CallOption) (*GetRiskDecisionsResponse, error)
+	// Refunds
+	RefundPayment(ctx context.Context, in *RefundPaymentRequest, opts ...grpc.CallOption) (*Refund, error)
+	ListRefunds(ctx context.Context, in *ListRefundsRequest, opts ...grpc.CallOption) (*ListRefundsResponse, error)
 	// Payment Status
 	GetPayment(ctx context.Context, in *GetPaymentRequest, opts ...grpc.CallOption) (*Payment, error)
 	GetPaymentsByOrder(ctx context.Context, in *GetPaymentsByOrderRequest, opts ...grpc.CallOption) (*GetPaymentsByOrderResponse, error)
@@ -46,6 +74,8 @@ type PaymentServiceClient interface {
 	// Payment Recovery
 	GetPendingPayments(ctx context.Context, in *GetPendingPaymentsRequest, opts ...grpc.CallOption) (*GetPendingPaymentsResponse, error)
 	RetryPayment(ctx context.Context, in *RetryPaymentRequest, opts ...grpc.CallOption) (*Payment, error)
+	// Reporting
+	GetNetworkFeeReport(ctx context.Context, in *NetworkFeeReportRequest, opts ...grpc.CallOption) (*NetworkFeeReport, error)
 }
 
 type paymentServiceClient struct {
@@ -76,6 +106,16 @@ func (c *paymentServiceClient) ProcessCreditCardPayment(ctx context.Context, in
 	return out, nil
 }
 
+func (c *paymentServiceClient) ConfirmCreditCardPayment(ctx context.Context, in *ConfirmCreditCardPaymentRequest, opts ...grpc.CallOption) (*Payment, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Payment)
+	err := c.cc.Invoke(ctx, PaymentService_ConfirmCreditCardPayment_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *paymentServiceClient) InitiateMetaMaskPayment(ctx context.Context, in *MetaMaskPaymentRequest, opts ...grpc.CallOption) (*MetaMaskPaymentResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(MetaMaskPaymentResponse)
@@ -96,6 +136,96 @@ func (c *paymentServiceClient) ConfirmMetaMaskPayment(ctx context.Context, in *C
 	return out, nil
 }
 
+func (c *paymentServiceClient) RequestWalletChallenge(ctx context.Context, in *WalletChallengeRequest, opts ...grpc.CallOption) (*WalletChallenge, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(WalletChallenge)
+	err := c.cc.Invoke(ctx, PaymentService_RequestWalletChallenge_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paymentServiceClient) VerifyWalletOwnership(ctx context.Context, in *VerifyWalletOwnershipRequest, opts ...grpc.CallOption) (*Payment, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Payment)
+	err := c.cc.Invoke(ctx, PaymentService_VerifyWalletOwnership_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paymentServiceClient) AuthorizeCreditCardPayment(ctx context.Context, in *CreditCardPaymentRequest, opts ...grpc.CallOption) (*Payment, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Payment)
+	err := c.cc.Invoke(ctx, PaymentService_AuthorizeCreditCardPayment_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paymentServiceClient) CapturePayment(ctx context.Context, in *CapturePaymentRequest, opts ...grpc.CallOption) (*Payment, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Payment)
+	err := c.cc.Invoke(ctx, PaymentService_CapturePayment_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paymentServiceClient) VoidPayment(ctx context.Context, in *VoidPaymentRequest, opts ...grpc.CallOption) (*Payment, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Payment)
+	err := c.cc.Invoke(ctx, PaymentService_VoidPayment_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paymentServiceClient) ReviewPayment(ctx context.Context, in *ReviewPaymentRequest, opts ...grpc.CallOption) (*Payment, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Payment)
+	err := c.cc.Invoke(ctx, PaymentService_ReviewPayment_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paymentServiceClient) GetRiskDecisions(ctx context.Context, in *GetRiskDecisionsRequest, opts ...grpc.CallOption) (*GetRiskDecisionsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetRiskDecisionsResponse)
+	err := c.cc.Invoke(ctx, PaymentService_GetRiskDecisions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paymentServiceClient) RefundPayment(ctx context.Context, in *RefundPaymentRequest, opts ...grpc.CallOption) (*Refund, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Refund)
+	err := c.cc.Invoke(ctx, PaymentService_RefundPayment_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paymentServiceClient) ListRefunds(ctx context.Context, in *ListRefundsRequest, opts ...grpc.CallOption) (*ListRefundsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListRefundsResponse)
+	err := c.cc.Invoke(ctx, PaymentService_ListRefunds_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *paymentServiceClient) GetPayment(ctx context.Context, in *GetPaymentRequest, opts ...grpc.CallOption) (*Payment, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(Payment)
@@ -146,6 +276,16 @@ func (c *paymentServiceClient) RetryPayment(ctx context.Context, in *RetryPaymen
 	return out, nil
 }
 
+func (c *paymentServiceClient) GetNetworkFeeReport(ctx context.Context, in *NetworkFeeReportRequest, opts ...grpc.CallOption) (*NetworkFeeReport, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(NetworkFeeReport)
+	err := c.cc.Invoke(ctx, PaymentService_GetNetworkFeeReport_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // PaymentServiceServer is the server API for PaymentService service.
 // All implementations must embed UnimplementedPaymentServiceServer
 // for forward compatibility.
@@ -153,8 +293,25 @@ type PaymentServiceServer interface {
 	// Payment Processing
 	InitiatePayment(context.Context, *InitiatePaymentRequest) (*Payment, error)
 	ProcessCreditCardPayment(context.Context, *CreditCardPaymentRequest) (*Payment, error)
+	// Finishes a card payment in PAYMENT_STATUS_REQUIRES_ACTION after the
+	// customer authenticated it.
+	ConfirmCreditCardPayment(context.Context, *ConfirmCreditCardPaymentRequest) (*Payment, error)
 	InitiateMetaMaskPayment(context.Context, *MetaMaskPaymentRequest) (*MetaMaskPaymentResponse, error)
 	ConfirmMetaMaskPayment(context.Context, *ConfirmMetaMaskPaymentRequest) (*Payment, error)
+	// Wallet ownership
+	RequestWalletChallenge(context.Context, *WalletChallengeRequest) (*WalletChallenge, error)
+	VerifyWalletOwnership(context.Context, *VerifyWalletOwnershipRequest) (*Payment, error)
+	// Authorize-then-capture
+	AuthorizeCreditCardPayment(context.Context, *CreditCardPaymentRequest) (*Payment, error)
+	CapturePayment(context.Context, *CapturePaymentRequest) (*Payment, error)
+	VoidPayment(context.Context, *VoidPaymentRequest) (*Payment, error)
+	// Risk review
+	// Approves or rejects a card payment in PAYMENT_STATUS_MANUAL_REVIEW.
+	ReviewPayment(context.Context, *ReviewPaymentRequest) (*Payment, error)
+	GetRiskDecisions(context.Context, *GetRiskDecisionsRequest) (*GetRiskDecisionsResponse, error)
+	// Refunds
+	RefundPayment(context.Context, *RefundPaymentRequest) (*Refund, error)
+	ListRefunds(context.Context, *ListRefundsRequest) (*ListRefundsResponse, error)
 	// Payment Status
 	GetPayment(context.Context, *GetPaymentRequest) (*Payment, error)
 	GetPaymentsByOrder(context.Context, *GetPaymentsByOrderRequest) (*GetPaymentsByOrderResponse, error)
@@ -162,6 +319,8 @@ type PaymentServiceServer interface {
 	// Payment Recovery
 	GetPendingPayments(context.Context, *GetPendingPaymentsRequest) (*GetPendingPaymentsResponse, error)
 	RetryPayment(context.Context, *RetryPaymentRequest) (*Payment, error)
+	// Reporting
+	GetNetworkFeeReport(context.Context, *NetworkFeeReportRequest) (*NetworkFeeReport, error)
 	mustEmbedUnimplementedPaymentServiceServer()
 }
 
@@ -178,12 +337,42 @@ func (UnimplementedPaymentServiceServer) InitiatePayment(context.Context, *Initi
 func (UnimplementedPaymentServiceServer) ProcessCreditCardPayment(context.Context, *CreditCardPaymentRequest) (*Payment, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ProcessCreditCardPayment not implemented")
 }
+func (UnimplementedPaymentServiceServer) ConfirmCreditCardPayment(context.Context, *ConfirmCreditCardPaymentRequest) (*Payment, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ConfirmCreditCardPayment not implemented")
+}
 func (UnimplementedPaymentServiceServer) InitiateMetaMaskPayment(context.Context, *MetaMaskPaymentRequest) (*MetaMaskPaymentResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method InitiateMetaMaskPayment not implemented")
 }
 func (UnimplementedPaymentServiceServer) ConfirmMetaMaskPayment(context.Context, *ConfirmMetaMaskPaymentRequest) (*Payment, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ConfirmMetaMaskPayment not implemented")
 }
+func (UnimplementedPaymentServiceServer) RequestWalletChallenge(context.Context, *WalletChallengeRequest) (*WalletChallenge, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RequestWalletChallenge not implemented")
+}
+func (UnimplementedPaymentServiceServer) VerifyWalletOwnership(context.Context, *VerifyWalletOwnershipRequest) (*Payment, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method VerifyWalletOwnership not implemented")
+}
+func (UnimplementedPaymentServiceServer) AuthorizeCreditCardPayment(context.Context, *CreditCardPaymentRequest) (*Payment, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AuthorizeCreditCardPayment not implemented")
+}
+func (UnimplementedPaymentServiceServer) CapturePayment(context.Context, *CapturePaymentRequest) (*Payment, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CapturePayment not implemented")
+}
+func (UnimplementedPaymentServiceServer) VoidPayment(context.Context, *VoidPaymentRequest) (*Payment, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method VoidPayment not implemented")
+}
+func (UnimplementedPaymentServiceServer) ReviewPayment(context.Context, *ReviewPaymentRequest) (*Payment, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReviewPayment not implemented")
+}
+func (UnimplementedPaymentServiceServer) GetRiskDecisions(context.Context, *GetRiskDecisionsRequest) (*GetRiskDecisionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetRiskDecisions not implemented")
+}
+func (UnimplementedPaymentServiceServer) RefundPayment(context.Context, *RefundPaymentRequest) (*Refund, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RefundPayment not implemented")
+}
+func (UnimplementedPaymentServiceServer) ListRefunds(context.Context, *ListRefundsRequest) (*ListRefundsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListRefunds not implemented")
+}
 func (UnimplementedPaymentServiceServer) GetPayment(context.Context, *GetPaymentRequest) (*Payment, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetPayment not implemented")
 }
@@ -199,6 +388,9 @@ func (UnimplementedPaymentServiceServer) GetPendingPayments(context.Context, *Ge
 func (UnimplementedPaymentServiceServer) RetryPayment(context.Context, *RetryPaymentRequest) (*Payment, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method RetryPayment not implemented")
 }
+func (UnimplementedPaymentServiceServer) GetNetworkFeeReport(context.Context, *NetworkFeeReportRequest) (*NetworkFeeReport, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetNetworkFeeReport not implemented")
+}
 func (UnimplementedPaymentServiceServer) mustEmbedUnimplementedPaymentServiceServer() {}
 func (UnimplementedPaymentServiceServer) testEmbeddedByValue()                        {}
 
@@ -256,6 +448,24 @@ func _PaymentService_ProcessCreditCardPayment_Handler(srv interface{}, ctx conte
 	return interceptor(ctx, in, info, handler)
 }
 
+func _PaymentService_ConfirmCreditCardPayment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConfirmCreditCardPaymentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentServiceServer).ConfirmCreditCardPayment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PaymentService_ConfirmCreditCardPayment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentServiceServer).ConfirmCreditCardPayment(ctx, req.(*ConfirmCreditCardPaymentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _PaymentService_InitiateMetaMaskPayment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(MetaMaskPaymentRequest)
 	if err := dec(in); err != nil {
@@ -292,6 +502,168 @@ func _PaymentService_ConfirmMetaMaskPayment_Handler(srv interface{}, ctx context
 	return interceptor(ctx, in, info, handler)
 }
 
+func _PaymentService_RequestWalletChallenge_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WalletChallengeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentServiceServer).RequestWalletChallenge(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PaymentService_RequestWalletChallenge_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentServiceServer).RequestWalletChallenge(ctx, req.(*WalletChallengeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PaymentService_VerifyWalletOwnership_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VerifyWalletOwnershipRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentServiceServer).VerifyWalletOwnership(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PaymentService_VerifyWalletOwnership_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentServiceServer).VerifyWalletOwnership(ctx, req.(*VerifyWalletOwnershipRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PaymentService_AuthorizeCreditCardPayment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreditCardPaymentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentServiceServer).AuthorizeCreditCardPayment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PaymentService_AuthorizeCreditCardPayment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentServiceServer).AuthorizeCreditCardPayment(ctx, req.(*CreditCardPaymentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PaymentService_CapturePayment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CapturePaymentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentServiceServer).CapturePayment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PaymentService_CapturePayment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentServiceServer).CapturePayment(ctx, req.(*CapturePaymentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PaymentService_VoidPayment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VoidPaymentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentServiceServer).VoidPayment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PaymentService_VoidPayment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentServiceServer).VoidPayment(ctx, req.(*VoidPaymentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PaymentService_ReviewPayment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReviewPaymentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentServiceServer).ReviewPayment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PaymentService_ReviewPayment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentServiceServer).ReviewPayment(ctx, req.(*ReviewPaymentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PaymentService_GetRiskDecisions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRiskDecisionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentServiceServer).GetRiskDecisions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PaymentService_GetRiskDecisions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentServiceServer).GetRiskDecisions(ctx, req.(*GetRiskDecisionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PaymentService_RefundPayment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RefundPaymentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentServiceServer).RefundPayment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PaymentService_RefundPayment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentServiceServer).RefundPayment(ctx, req.(*RefundPaymentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PaymentService_ListRefunds_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRefundsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentServiceServer).ListRefunds(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PaymentService_ListRefunds_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentServiceServer).ListRefunds(ctx, req.(*ListRefundsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _PaymentService_GetPayment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(GetPaymentRequest)
 	if err := dec(in); err != nil {
@@ -382,6 +754,24 @@ func _PaymentService_RetryPayment_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _PaymentService_GetNetworkFeeReport_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(NetworkFeeReportRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentServiceServer).GetNetworkFeeReport(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PaymentService_GetNetworkFeeReport_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentServiceServer).GetNetworkFeeReport(ctx, req.(*NetworkFeeReportRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // PaymentService_ServiceDesc is the grpc.ServiceDesc for PaymentService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -397,6 +787,10 @@ var PaymentService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ProcessCreditCardPayment",
 			Handler:    _PaymentService_ProcessCreditCardPayment_Handler,
 		},
+		{
+			MethodName: "ConfirmCreditCardPayment",
+			Handler:    _PaymentService_ConfirmCreditCardPayment_Handler,
+		},
 		{
 			MethodName: "InitiateMetaMaskPayment",
 			Handler:    _PaymentService_InitiateMetaMaskPayment_Handler,
@@ -405,6 +799,42 @@ var PaymentService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ConfirmMetaMaskPayment",
 			Handler:    _PaymentService_ConfirmMetaMaskPayment_Handler,
 		},
+		{
+			MethodName: "RequestWalletChallenge",
+			Handler:    _PaymentService_RequestWalletChallenge_Handler,
+		},
+		{
+			MethodName: "VerifyWalletOwnership",
+			Handler:    _PaymentService_VerifyWalletOwnership_Handler,
+		},
+		{
+			MethodName: "AuthorizeCreditCardPayment",
+			Handler:    _PaymentService_AuthorizeCreditCardPayment_Handler,
+		},
+		{
+			MethodName: "CapturePayment",
+			Handler:    _PaymentService_CapturePayment_Handler,
+		},
+		{
+			MethodName: "VoidPayment",
+			Handler:    _PaymentService_VoidPayment_Handler,
+		},
+		{
+			MethodName: "ReviewPayment",
+			Handler:    _PaymentService_ReviewPayment_Handler,
+		},
+		{
+			MethodName: "GetRiskDecisions",
+			Handler:    _PaymentService_GetRiskDecisions_Handler,
+		},
+		{
+			MethodName: "RefundPayment",
+			Handler:    _PaymentService_RefundPayment_Handler,
+		},
+		{
+			MethodName: "ListRefunds",
+			Handler:    _PaymentService_ListRefunds_Handler,
+		},
 		{
 			MethodName: "GetPayment",
 			Handler:    _PaymentService_GetPayment_Handler,
@@ -425,6 +855,10 @@ var PaymentService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "RetryPayment",
 			Handler:    _PaymentService_RetryPayment_Handler,
 		},
+		{
+			MethodName: "GetNetworkFeeReport",
+			Handler:    _PaymentService_GetNetworkFeeReport_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "payment-service/proto/payment.proto",